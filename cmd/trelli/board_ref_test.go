@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+func TestBoardRefs(t *testing.T) {
+	fc := cli.FileConfig{"boards": map[string]any{"aliases": map[string]any{"roadmap": "XobnRsYv"}}}
+	for ref, want := range map[string]string{
+		"https://trello.com/b/XobnRsYv/my-board":     "XobnRsYv",
+		"https://trello.com/b/XobnRsYv":              "XobnRsYv",
+		"trello.com/b/XobnRsYv/my-board?filter=open": "XobnRsYv",
+		" roadmap ":                 "XobnRsYv",
+		"acme/my-board":             "acme/my-board",
+		"https://trello.com/c/AbCd": "https://trello.com/c/AbCd",
+	} {
+		if got := fc.ResolveBoardAlias(ref); got != want {
+			t.Errorf("resolveBoardAlias(%q) = %q, want %q", ref, got, want)
+		}
+	}
+
+	clitest.WithStubRoutes(t, map[string]string{
+		"/1/organizations/acme/boards": `[
+			{"id": "b1", "name": "Engineering", "shortLink": "EnGi", "url": "https://trello.com/b/EnGi/engineering"},
+			{"id": "b2", "name": "Plans", "shortLink": "PlA1", "url": "https://trello.com/b/PlA1/plans"},
+			{"id": "b3", "name": "Plans", "shortLink": "PlA2", "url": "https://trello.com/b/PlA2/plans-1"}
+		]`,
+	})
+	stub := clitest.NewStub(t)
+	want := clitest.RunCLI(t, stub, "lists", "list", "--board", "b1")
+	for _, board := range []string{"https://trello.com/b/b1/engineering", "acme/engineering", "acme/EnGi"} {
+		if got := clitest.RunCLI(t, stub, "lists", "list", "--board", board); got != want {
+			t.Errorf("lists list --board %s:\n%s\nwant:\n%s", board, got, want)
+		}
+	}
+	if got := clitest.RunCLI(t, stub, "lists", "list", "--board", "acme/plans"); !strings.Contains(got, `board "plans" is ambiguous in Workspace "acme" (2 boards)`) {
+		t.Errorf("ambiguous board:\n%s", got)
+	}
+	if got := clitest.RunCLI(t, stub, "lists", "list", "--board", "acme/roadmap"); !strings.Contains(got, `board "roadmap" not found in Workspace "acme"`) {
+		t.Errorf("missing board:\n%s", got)
+	}
+}
+
+func TestNoBoardConfigured(t *testing.T) {
+	stub := clitest.NewStub(t)
+	for _, args := range [][]string{
+		{"--board", "", "lists", "list"},
+		{"--board", "", "cards", "create", "--list-name", "To Do", "--name", "x"},
+	} {
+		if got := clitest.RunCLI(t, stub, args...); !strings.Contains(got, cli.ErrNoBoard.Error()) {
+			t.Errorf("%s without a board:\n%s", strings.Join(args, " "), got)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"trelli/internal/clitest"
+)
+
+func TestCopyWithoutClipboard(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("WAYLAND_DISPLAY", "")
+	stub := clitest.NewStub(t)
+	for _, args := range [][]string{
+		{"cards", "create", "--list", "l1", "--name", "Write tests", "--copy"},
+		{"boards", "show", "b1", "--copy-id"},
+	} {
+		got := clitest.RunCLI(t, stub, args...)
+		if strings.Contains(got, "--- error") || !strings.Contains(got, "ID") {
+			t.Errorf("%s without a clipboard tool:\n%s", strings.Join(args, " "), got)
+		}
+	}
+}
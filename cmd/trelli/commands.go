@@ -0,0 +1,92 @@
+package main
+
+import (
+	"trelli/internal/cli"
+	"trelli/internal/cmd/audit"
+	"trelli/internal/cmd/auth"
+	"trelli/internal/cmd/backup"
+	"trelli/internal/cmd/boards"
+	"trelli/internal/cmd/cache"
+	"trelli/internal/cmd/calendar"
+	"trelli/internal/cmd/cards"
+	"trelli/internal/cmd/check"
+	"trelli/internal/cmd/checklists"
+	"trelli/internal/cmd/cleanup"
+	"trelli/internal/cmd/comments"
+	"trelli/internal/cmd/completion"
+	"trelli/internal/cmd/config"
+	"trelli/internal/cmd/docs"
+	"trelli/internal/cmd/exec"
+	"trelli/internal/cmd/export"
+	"trelli/internal/cmd/find"
+	"trelli/internal/cmd/git"
+	"trelli/internal/cmd/grep"
+	"trelli/internal/cmd/importcmd"
+	"trelli/internal/cmd/initcmd"
+	"trelli/internal/cmd/labels"
+	"trelli/internal/cmd/lists"
+	"trelli/internal/cmd/mail2card"
+	"trelli/internal/cmd/mcp"
+	"trelli/internal/cmd/metrics"
+	"trelli/internal/cmd/notify"
+	"trelli/internal/cmd/open"
+	"trelli/internal/cmd/report"
+	"trelli/internal/cmd/restore"
+	"trelli/internal/cmd/rpc"
+	"trelli/internal/cmd/rules"
+	"trelli/internal/cmd/serve"
+	"trelli/internal/cmd/sync"
+	"trelli/internal/cmd/time"
+	"trelli/internal/cmd/undo"
+	"trelli/internal/cmd/watch"
+)
+
+var versionCommand = cli.Command{Name: "version", Summary: "Show CLI version"}
+
+var helpCommand = cli.Command{Name: "help", Summary: "Show help for command"}
+
+// init registers the commands in help order. Each lives in its own
+// package under internal/cmd; main handles help and version itself.
+func init() {
+	cli.Register(
+		boards.Command,
+		lists.Command,
+		cards.Command,
+		comments.Command,
+		checklists.Command,
+		labels.Command,
+		config.Command,
+		auth.Command,
+		initcmd.Command,
+		completion.Command,
+		sync.Command,
+		export.Command,
+		importcmd.Command,
+		mail2card.Command,
+		report.Command,
+		grep.Command,
+		find.Command,
+		time.Command,
+		rules.Command,
+		backup.Command,
+		restore.Command,
+		cleanup.Command,
+		audit.Command,
+		check.Command,
+		serve.Command,
+		calendar.Command,
+		metrics.Command,
+		rpc.Command,
+		mcp.Command,
+		exec.Command,
+		git.Command,
+		notify.Command,
+		watch.Command,
+		open.Command,
+		undo.Command,
+		cache.Command,
+		docs.Command,
+		helpCommand,
+		versionCommand,
+	)
+}
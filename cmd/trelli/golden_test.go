@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+func TestGolden(t *testing.T) {
+	srv := clitest.NewStub(t)
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"boards_list", []string{"boards", "list"}},
+		{"boards_list_json", []string{"--json", "boards", "list"}},
+		{"boards_list_csv", []string{"-o", "csv", "boards", "list"}},
+		{"boards_show", []string{"boards", "show", "b1"}},
+		{"boards_list_template", []string{"--template", "{{.shortLink}} {{.name}}", "boards", "list"}},
+		{"boards_list_filter_empty", []string{"boards", "list", "--filter", "marketing"}},
+		{"lists_list", []string{"lists", "list"}},
+		{"cards_list", []string{"cards", "list", "--list", "l1"}},
+		{"cards_list_positional_alias", []string{"c", "ls", "l1"}},
+		{"cards_list_empty", []string{"cards", "list", "l2"}},
+		{"cards_list_count", []string{"cards", "list", "l1", "--count"}},
+		{"cards_list_count_json", []string{"--json", "cards", "list", "l1", "--count"}},
+		{"cards_list_quiet", []string{"cards", "list", "l1", "-q"}},
+		{"cards_list_all_json", []string{"--json", "cards", "list", "l1", "--all"}},
+		{"cards_list_all_csv", []string{"-o", "csv", "cards", "list", "l1", "--all"}},
+		{"cards_list_modified_since", []string{"--json", "cards", "list", "l1", "--modified-since", "2026-02-05"}},
+		{"cards_list_modified_since_count", []string{"cards", "list", "l1", "--modified-since", "2026-02-05", "--count"}},
+		{"cards_list_modified_since_invalid", []string{"cards", "list", "l1", "--modified-since", "soon"}},
+		{"watch_unknown_type", []string{"watch", "--type", "createCard,movedCard"}},
+		{"cards_list_list_name", []string{"cards", "list", "--list-name", "to do"}},
+		{"cards_show", []string{"cards", "show", "c1"}},
+		{"cards_show_full", []string{"cards", "show", "c1", "--full"}},
+		{"cards_show_full_json", []string{"--json", "cards", "show", "c1", "--full"}},
+		{"cards_show_full_csv", []string{"-o", "csv", "cards", "show", "c1", "--full"}},
+		{"cards_show_not_found", []string{"cards", "show", "nope"}},
+		{"cards_show_not_found_json", []string{"--json", "cards", "show", "nope"}},
+		{"cards_show_missing_card", []string{"cards", "show"}},
+		{"cards_create_dry_run", []string{"--dry-run", "cards", "create", "--list", "l1", "--name", "New card", "--desc", "Details"}},
+		{"cards_create_dry_run_json", []string{"--dry-run", "--json", "cards", "create", "--list", "l1", "--name", "New card"}},
+		{"cards_create", []string{"cards", "create", "--list", "l1", "--name", "New card"}},
+		{"cards_create_label_names", []string{"--dry-run", "cards", "create", "--list-name", "To Do", "--name", "New card", "--labels", "bug,green"}},
+		{"cards_create_unknown_label", []string{"cards", "create", "--list-name", "To Do", "--name", "New card", "--labels", "urgent"}},
+		{"cards_create_members", []string{"--dry-run", "cards", "create", "--list-name", "To Do", "--name", "New card", "--members", "@Ada,m2"}},
+		{"cards_create_unknown_member", []string{"cards", "create", "--list-name", "To Do", "--name", "New card", "--members", "@alan"}},
+		{"cards_assign", []string{"cards", "assign", "c1", "@grace"}},
+		{"cards_assign_remove_dry_run", []string{"--dry-run", "cards", "assign", "c1", "--members", "@ada", "--remove"}},
+		{"cards_create_reminder_dry_run", []string{"--dry-run", "cards", "create", "--list", "l1", "--name", "New card", "--due", "2026-03-01T12:00:00Z", "--reminder", "1d"}},
+		{"cards_create_reminder_without_due", []string{"cards", "create", "--list", "l1", "--name", "New card", "--reminder", "60"}},
+		{"cards_update", []string{"cards", "update", "c1", "--due", "2026-03-02T09:00:00Z", "--reminder", "60"}},
+		{"cards_update_clear_dry_run", []string{"--dry-run", "cards", "update", "c1", "--due", "none", "--reminder", "none"}},
+		{"cards_update_invalid_reminder", []string{"cards", "update", "c1", "--reminder", "soon"}},
+		{"cards_update_nothing", []string{"cards", "update", "c1"}},
+		{"cards_desc_append_dry_run", []string{"--dry-run", "cards", "desc", "append", "c1", "--text", "2026-02-12: deployed the fix"}},
+		{"cards_desc_prepend", []string{"cards", "desc", "prepend", "--card", "c1", "--text", "**Blocked** on review"}},
+		{"cards_desc_missing_text", []string{"cards", "desc", "append", "c1"}},
+		{"cards_label_add", []string{"cards", "label", "add", "c1", "--label", "feature"}},
+		{"cards_label_remove_dry_run", []string{"--dry-run", "cards", "label", "remove", "c1", "red,lb2"}},
+		{"cards_unknown_flag", []string{"cards", "list", "l1", "--bogus"}},
+		{"comments_list", []string{"comments", "list", "c1"}},
+		{"checklists_list", []string{"checklists", "list", "c1"}},
+		{"checklists_list_json", []string{"--json", "checklists", "list", "c1"}},
+		{"exec_script", []string{"exec", "--file", "testdata/exec/script.trelli"}},
+		{"exec_stop_on_error", []string{"exec", "testdata/exec/script.trelli", "--stop-on-error"}},
+		{"git_branch", []string{"git", "branch", "c1"}},
+		{"find", []string{"find", "login"}},
+		{"find_scope_json", []string{"--json", "find", "--scope", "b2", "BUDGET"}},
+		{"find_csv", []string{"-o", "csv", "find", "-E", "^(fix|write)"}},
+		{"git_branch_json", []string{"--json", "git", "branch", "c1", "--prefix", "fix"}},
+		{"git_prepare_commit_msg", []string{"git", "prepare-commit-msg", "--card", "c1"}},
+		{"import_jira_dry_run", []string{"--dry-run", "import", "jira", "--file", "testdata/jira/export.csv", "--url", "https://acme.atlassian.net"}},
+		{"import_jira_json", []string{"--json", "import", "jira", "--file", "testdata/jira/export.csv"}},
+		{"import_taskwarrior_dry_run", []string{"--dry-run", "import", "taskwarrior", "testdata/taskwarrior/tasks.json"}},
+		{"import_markdown_dry_run", []string{"--dry-run", "import", "markdown", "testdata/markdown/notes.md", "--list-name", "To Do"}},
+		{"import_todotxt_dry_run", []string{"--dry-run", "import", "todotxt", "--file", "testdata/todotxt/todo.txt"}},
+		{"import_trello_export_dry_run", []string{"--dry-run", "import", "trello-export", "--file", "testdata/trello-export/board.json"}},
+		{"restore_dry_run", []string{"--dry-run", "restore", "testdata/backup/EnGi.json"}},
+		{"restore_only_lists_json", []string{"--dry-run", "--json", "restore", "--file", "testdata/backup/EnGi.json", "--only-lists", "to do"}},
+		{"backup_diff", []string{"backup", "diff", "testdata/backup/EnGi.json", "testdata/backup/EnGi-later.json"}},
+		{"backup_diff_live", []string{"backup", "diff", "--against-live", "testdata/backup/EnGi.json"}},
+		{"export_taskwarrior", []string{"export", "taskwarrior"}},
+		{"sync_boards_dry_run", []string{"--dry-run", "sync", "boards", "--source", "b1", "--target", "b2"}},
+		{"sync_boards_one_way", []string{"--dry-run", "sync", "boards", "--source", "b1", "--target", "b2", "--one-way"}},
+		{"unknown_output", []string{"-o", "yaml", "boards", "list"}},
+		{"bad_token", []string{"--token", "revoked", "boards", "list"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clitest.CheckGolden(t, tt.name, clitest.RunCLI(t, srv, tt.args...))
+		})
+	}
+}
+
+func TestGoldenHelp(t *testing.T) {
+	for _, name := range []string{"cards", "boards"} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			c, ok := cli.FindCommand(name)
+			if !ok {
+				t.Fatalf("no command %q", name)
+			}
+			cli.WriteCommandHelp(&buf, c)
+			clitest.CheckGolden(t, "help_"+name, buf.String())
+		})
+	}
+}
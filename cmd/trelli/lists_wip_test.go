@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+func TestListsWIP(t *testing.T) {
+	clitest.WithStubRoutes(t, map[string]string{
+		"/1/lists/l1": `{"id": "l1", "name": "To Do"}`,
+		"/1/lists/l2": `{"id": "l2", "name": "Done"}`,
+	})
+	stub := clitest.NewStub(t)
+	limits := func(todo int) cli.FileConfig {
+		return cli.FileConfig{"wip": map[string]any{"to do": json.Number(strconv.Itoa(todo)), "Done": json.Number("5")}}
+	}
+	clitest.CheckGolden(t, "lists_wip", clitest.RunCLIWithConfig(t, stub, limits(2), "lists", "wip", "b1"))
+	clitest.CheckGolden(t, "lists_wip_over", clitest.RunCLIWithConfig(t, stub, limits(1), "lists", "wip", "--board", "b1"))
+	if got := clitest.RunCLI(t, stub, "lists", "wip"); !strings.Contains(got, "no WIP limits configured") {
+		t.Errorf("without limits: %s", got)
+	}
+	for v, want := range map[string]string{
+		"five": `config wip.Doing: expected an integer, got "five"`,
+		"-1":   "config wip.Doing: expected a positive integer, got -1",
+	} {
+		fc := cli.FileConfig{"wip": map[string]any{"Doing": v}}
+		if got := clitest.RunCLIWithConfig(t, stub, fc, "lists", "wip", "b1"); !strings.Contains(got, want) {
+			t.Errorf("wip limit %q: %s", v, got)
+		}
+		if got := clitest.RunCLIWithConfig(t, stub, fc, "--dry-run", "cards", "move", "c1", "--list", "l1", "--enforce-wip"); !strings.Contains(got, want) {
+			t.Errorf("move with wip limit %q: %s", v, got)
+		}
+	}
+
+	if got := clitest.RunCLIWithConfig(t, stub, limits(2), "--dry-run", "cards", "move", "c3", "--list", "l1", "--enforce-wip"); !strings.Contains(got, `would put list "To Do" at 3 cards, over its WIP limit of 2`) {
+		t.Errorf("enforced move: %s", got)
+	}
+	if got := clitest.RunCLIWithConfig(t, stub, limits(2), "--dry-run", "cards", "move", "c1", "--list", "l2", "--enforce-wip"); !strings.Contains(got, "DRY RUN: PUT /1/cards/c1") {
+		t.Errorf("move under the limit: %s", got)
+	}
+	if got := clitest.RunCLIWithConfig(t, stub, limits(1), "--dry-run", "cards", "move", "c1", "--list", "l1", "--enforce-wip"); !strings.Contains(got, "DRY RUN: PUT /1/cards/c1") {
+		t.Errorf("move within the list: %s", got)
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+
+	"trelli/internal/clitest"
+)
+
+var markdownRoutes = map[string]string{
+	"/1/cards/c1": `{"id": "c1", "name": "Fix login, again", "idList": "l1", "shortUrl": "https://trello.com/c/AbCd", "closed": false,
+		"desc": "## Steps\n\nUsers are **logged out** after _5 minutes_, see [the log](https://example.com/log).\n\n- [x] Reproduce\n- [ ] Fix ` + "`session.ttl`" + `\n\n` + "```" + `\nttl: 300\n` + "```" + `"}`,
+	"/1/cards/c1/checklists": `[]`,
+	"/1/cards/c1/actions": `[
+		{"id": "a2", "type": "commentCard", "date": "2026-02-11T10:00:00.000Z", "data": {"text": "> Seen on staging too.\n\nAlso ~~prod~~ **staging-2**."}, "memberCreator": {"username": "grace", "fullName": "Grace Hopper"}},
+		{"id": "a1", "type": "commentCard", "date": "2026-02-10T09:30:00.000Z", "data": {"text": "1. Log in\n2. Wait"}, "memberCreator": {"username": "ada", "fullName": "Ada Lovelace"}}
+	]`,
+}
+
+func TestMarkdownOutput(t *testing.T) {
+	clitest.WithStubRoutes(t, markdownRoutes)
+	stub := clitest.NewStub(t)
+	clitest.CheckGolden(t, "cards_show_full_markdown", clitest.RunCLI(t, stub, "cards", "show", "c1", "--full"))
+	clitest.CheckGolden(t, "cards_show_full_raw", clitest.RunCLI(t, stub, "cards", "show", "c1", "--full", "--raw"))
+	clitest.CheckGolden(t, "comments_list_pretty", clitest.RunCLI(t, stub, "comments", "list", "c1", "--pretty"))
+	clitest.CheckGolden(t, "cards_show_full_redact", clitest.RunCLI(t, stub, "--redact", "cards", "show", "c1", "--full"))
+	clitest.CheckGolden(t, "comments_list_pretty_redact", clitest.RunCLI(t, stub, "--redact", "comments", "list", "c1", "--pretty"))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+func TestRedact(t *testing.T) {
+	clitest.WithStubRoutes(t, map[string]string{
+		"/1/boards/b1/cards": `[
+			{"id": "c1", "name": "Fix login, again", "idList": "l1", "due": "2026-03-01T12:00:00.000Z", "idMembers": ["m1"], "shortUrl": "https://trello.com/c/AbCd"},
+			{"id": "c2", "name": "Write \"release\" notes", "idList": "l1", "due": "2026-02-20T12:00:00.000Z", "idMembers": ["m1", "m2"], "shortUrl": "https://trello.com/c/EfGh"}
+		]`,
+	})
+	stub := clitest.NewStub(t)
+
+	got := clitest.RunCLI(t, stub, "--redact", "check", "overdue")
+	for _, name := range []string{"ada", "grace"} {
+		if strings.Contains(got, name) {
+			t.Errorf("--redact shows %q:\n%s", name, got)
+		}
+	}
+	if !strings.Contains(got, "@user-"+cli.PseudonymID("m1")+",@user-"+cli.PseudonymID("m2")) || !strings.Contains(got, "Fix login, again") {
+		t.Errorf("--redact check overdue:\n%s", got)
+	}
+
+	got = clitest.RunCLI(t, stub, "--redact=all", "--json", "check", "overdue")
+	for _, name := range []string{"ada", "grace", "Fix login", "release"} {
+		if strings.Contains(got, name) {
+			t.Errorf("--redact=all --json shows %q:\n%s", name, got)
+		}
+	}
+	if !strings.Contains(got, `"name": "Card `+cli.PseudonymID("c2")+`"`) {
+		t.Errorf("--redact=all --json check overdue:\n%s", got)
+	}
+
+	r := cli.NewRedactor(cli.RedactMembers)
+	r.Learn([]byte(`[{"id": "a1", "data": {"text": "@ada, Ada Lovelace asked", "card": {"id": "c1", "name": "Fix login"}},
+		"memberCreator": {"id": "m1", "username": "ada", "fullName": "Ada Lovelace", "initials": "AL"}}]`))
+	h := cli.PseudonymID("m1")
+	if got := r.RedactText("@ada, Ada Lovelace asked about Fix login"); got != "@user-"+h+", User "+h+" asked about Fix login" {
+		t.Errorf("redactText = %q", got)
+	}
+	if got := r.RedactText("ada"); got != "user-"+h {
+		t.Errorf("redactText(ada) = %q", got)
+	}
+	if got := string(r.RedactJSON([]byte(`{"username":"ada","text":"hi @ada","name":"adam"}`))); got != `{"username":"user-`+h+`","text":"hi @user-`+h+`","name":"adam"}` {
+		t.Errorf("redactJSON = %s", got)
+	}
+}
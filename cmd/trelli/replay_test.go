@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"trelli/internal/clitest"
+)
+
+func TestRecordReplayRoundTrip(t *testing.T) {
+	srv := clitest.NewStub(t)
+	dir := filepath.Join(t.TempDir(), "fixtures")
+	commands := [][]string{
+		{"boards", "list"},
+		{"--json", "cards", "show", "c1", "--full"},
+		{"cards", "create", "--list", "l1", "--name", "Write tests"},
+	}
+	recorded := make([]string, len(commands))
+	for i, args := range commands {
+		recorded[i] = clitest.RunCLI(t, srv, append([]string{"--record", dir}, args...)...)
+		if strings.Contains(recorded[i], "--- error") {
+			t.Fatalf("%s while recording:\n%s", strings.Join(args, " "), recorded[i])
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no fixtures recorded: %v", err)
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "test-token") || strings.Contains(string(data), "test-key") {
+			t.Errorf("%s contains credentials:\n%s", filepath.Base(f), data)
+		}
+	}
+
+	// Replay with the server gone: the same output, without the network.
+	srv.Close()
+	for i, args := range commands {
+		got := clitest.RunCLI(t, srv, append([]string{"--replay", dir}, args...)...)
+		if got != recorded[i] {
+			t.Errorf("%s replayed:\n%s\nrecorded:\n%s", strings.Join(args, " "), got, recorded[i])
+		}
+	}
+
+	got := clitest.RunCLI(t, srv, "--replay", dir, "lists", "list")
+	if !strings.Contains(got, "replay: no recording") {
+		t.Errorf("unrecorded request replayed as:\n%s", got)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// undoAll reverses every journaled entry, newest first, and returns the
+// writes that made.
+func undoAll(t *testing.T, srv *httptest.Server, writes *[]string) []string {
+	t.Helper()
+	entries, err := cli.ReadJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := clitest.TestConfig(t, srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	*writes = nil
+	for i := len(entries) - 1; i >= 0; i-- {
+		if err := entries[i].Reverse(cfg.Context, client); err != nil {
+			t.Fatalf("reverse %s: %v", entries[i].Summary, err)
+		}
+	}
+	return *writes
+}
+
+func TestUndoCardLabels(t *testing.T) {
+	srv, writes := clitest.WriteRecorder(t, clitest.NewStub(t))
+	clitest.RunCLI(t, srv, "cards", "label", "add", "c1", "Bug,Feature")
+	got := undoAll(t, srv, writes)
+	want := []string{"DELETE /1/cards/c1/idLabels/lb2", "DELETE /1/cards/c1/idLabels/lb1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("undo cards label add = %q, want %q", got, want)
+	}
+
+	clitest.RunCLI(t, srv, "cards", "label", "remove", "c1", "Bug")
+	got = undoAll(t, srv, writes)
+	want = []string{"POST /1/cards/c1/idLabels"}
+	if !slices.Equal(got, want) {
+		t.Errorf("undo cards label remove = %q, want %q", got, want)
+	}
+}
+
+func TestUndoCardAssign(t *testing.T) {
+	srv, writes := clitest.WriteRecorder(t, clitest.NewStub(t))
+	clitest.RunCLI(t, srv, "cards", "assign", "c1", "@ada")
+	got := undoAll(t, srv, writes)
+	want := []string{"DELETE /1/cards/c1/idMembers/m1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("undo cards assign = %q, want %q", got, want)
+	}
+
+	clitest.RunCLI(t, srv, "cards", "assign", "c1", "@grace", "--remove")
+	got = undoAll(t, srv, writes)
+	want = []string{"POST /1/cards/c1/idMembers"}
+	if !slices.Equal(got, want) {
+		t.Errorf("undo cards assign --remove = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// actionTypes are the Trello action types a board's feed reports, which
+// --type and --events accept.
+var actionTypes = []string{
+	"addAttachmentToCard", "addChecklistToCard", "addLabelToCard", "addMemberToBoard",
+	"addMemberToCard", "addToOrganizationBoard", "commentCard", "convertToCardFromCheckItem",
+	"copyBoard", "copyCard", "copyCommentCard", "createBoard", "createCard", "createCheckItem",
+	"createCustomField", "createLabel", "createList", "deleteAttachmentFromCard", "deleteCard",
+	"deleteCheckItem", "deleteComment", "deleteLabel", "disablePowerUp", "emailCard",
+	"enablePowerUp", "makeAdminOfBoard", "makeNormalMemberOfBoard", "makeObserverOfBoard",
+	"moveCardFromBoard", "moveCardToBoard", "moveListFromBoard", "moveListToBoard",
+	"removeChecklistFromCard", "removeFromOrganizationBoard", "removeLabelFromCard",
+	"removeMemberFromBoard", "removeMemberFromCard", "updateBoard", "updateCard",
+	"updateCheckItem", "updateCheckItemStateOnCard", "updateChecklist", "updateComment",
+	"updateCustomFieldItem", "updateLabel", "updateList",
+}
+
+// ActionTypesHelp is the help section listing actionTypes.
+var ActionTypesHelp = HelpSection{Title: "Action types", Body: `Pass several as a comma-separated list or by repeating the flag; names
+are matched case-insensitively. updateCard covers moves, renames, due
+dates, and archiving.
+
+` + wrapWords(actionTypes, 72)}
+
+// ActionTypesFlag collects action types from a repeatable, comma-separated
+// flag, rejecting names Trello does not know.
+type ActionTypesFlag []string
+
+func (f *ActionTypesFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *ActionTypesFlag) Set(s string) error {
+	for _, name := range SplitIDs(s) {
+		i := slices.IndexFunc(actionTypes, func(t string) bool { return strings.EqualFold(t, name) })
+		if i < 0 {
+			return fmt.Errorf("unknown action type %q (see -h for the list)", name)
+		}
+		if !slices.Contains(*f, actionTypes[i]) {
+			*f = append(*f, actionTypes[i])
+		}
+	}
+	return nil
+}
+
+// wrapWords joins words with ", ", breaking lines before width.
+func wrapWords(words []string, width int) string {
+	var b strings.Builder
+	line := 0
+	for i, w := range words {
+		if i < len(words)-1 {
+			w += ","
+		}
+		switch {
+		case i == 0:
+		case line+1+len(w) > width:
+			b.WriteString("\n")
+			line = 0
+		default:
+			b.WriteString(" ")
+			line++
+		}
+		b.WriteString(w)
+		line += len(w)
+	}
+	return b.String()
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const TrelloAppKeyURL = "https://trello.com/app-key"
+
+// LoadStoredCredentials fills a missing key/token from the configured
+// credentials.exec command, then from the OS keychain unless
+// credentials.store is "none". A keychain that fails, rather than holding
+// nothing, is an error; a platform without a keychain holds nothing.
+func LoadStoredCredentials(cfg *Config) error {
+	if cfg.APIKey != "" && cfg.Token != "" {
+		return nil
+	}
+	command := cfg.File.GetString("credentials.exec")
+	if cfg.Profile != "" {
+		command = FirstNonEmpty(cfg.File.GetString("profiles."+cfg.Profile+".credentials.exec"), command)
+	}
+	if command != "" {
+		key, token, err := execCredentials(command)
+		if err != nil {
+			return err
+		}
+		if cfg.APIKey == "" {
+			cfg.APIKey = key
+		}
+		if cfg.Token == "" {
+			cfg.Token = token
+		}
+		if cfg.APIKey != "" && cfg.Token != "" {
+			return nil
+		}
+	}
+
+	if cfg.File.GetString("credentials.store") == "none" {
+		return nil
+	}
+	store, err := NewCredentialStore()
+	if errors.Is(err, ErrNoCredentialStore) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	load := func(field string, dst *string) error {
+		if *dst != "" {
+			return nil
+		}
+		v, err := store.Get(CredentialAccount(cfg.Profile, field))
+		if errors.Is(err, ErrCredentialNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading the %s from %s: %w", field, store.Name(), err)
+		}
+		*dst = v
+		return nil
+	}
+	if err := load("key", &cfg.APIKey); err != nil {
+		return err
+	}
+	return load("token", &cfg.Token)
+}
+
+func AuthorizeURL(key string) string {
+	q := url.Values{}
+	q.Set("expiration", "never")
+	q.Set("name", "trelli")
+	q.Set("scope", "read,write")
+	q.Set("response_type", "token")
+	q.Set("key", key)
+	return "https://trello.com/1/authorize?" + q.Encode()
+}
+
+func FetchMe(ctx context.Context, client *Client) (Member, error) {
+	return client.Members.Me(ctx)
+}
+
+// Prompt reads one line from in; secret input is not echoed on Unix terminals.
+func Prompt(in *bufio.Reader, label string, secret bool) (string, error) {
+	fmt.Fprint(os.Stderr, label)
+	if secret && runtime.GOOS != "windows" && IsTerminal(os.Stdin) {
+		if setTerminalEcho(false) == nil {
+			defer func() {
+				_ = setTerminalEcho(true)
+				fmt.Fprintln(os.Stderr)
+			}()
+		}
+	}
+	line, err := in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func setTerminalEcho(on bool) error {
+	arg := "-echo"
+	if on {
+		arg = "echo"
+	}
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"trelli/pkg/trello"
+)
+
+// MaxBatchRoutes is Trello's limit on routes per /1/batch call.
+const MaxBatchRoutes = 10
+
+// GetRequest is one GET that may be coalesced into a /1/batch call.
+type GetRequest struct {
+	Path  string // e.g. /1/cards/<id>
+	Query url.Values
+	Out   any
+}
+
+// batchResult is one element of a /1/batch response: {"200": body} on
+// success, an error object otherwise.
+type batchResult struct {
+	OK         json.RawMessage `json:"200"`
+	StatusCode int             `json:"statusCode"`
+	Message    string          `json:"message"`
+	Name       string          `json:"name"`
+}
+
+// GetAll performs reqs, coalescing them into /1/batch calls of up to
+// MaxBatchRoutes routes. Requests already answered in this invocation are
+// served from Memo. A single request, or any request in offline mode, goes
+// through do unchanged.
+func (c *Client) GetAll(ctx context.Context, reqs ...GetRequest) error {
+	pending := reqs[:0:0]
+	for _, r := range reqs {
+		var err error
+		if r.Path, r.Query, _, err = c.resolveBoardRefs(ctx, r.Path, r.Query, nil); err != nil {
+			return err
+		}
+		raw, ok := c.Memo.Lookup(MemoKey(r.Path, r.Query))
+		if !ok {
+			pending = append(pending, r)
+			continue
+		}
+		c.Stats.hit()
+		if r.Out != nil {
+			if err := json.Unmarshal(raw, r.Out); err != nil {
+				return err
+			}
+		}
+	}
+	reqs = pending
+	if len(reqs) == 1 || c.Offline {
+		tasks := make([]func() error, len(reqs))
+		for i, r := range reqs {
+			tasks[i] = func() error { return c.Do(ctx, http.MethodGet, r.Path, r.Query, nil, r.Out) }
+		}
+		return Parallel(c.Concurrency, tasks...)
+	}
+	var tasks []func() error
+	for start := 0; start < len(reqs); start += MaxBatchRoutes {
+		chunk := reqs[start:min(start+MaxBatchRoutes, len(reqs))]
+		tasks = append(tasks, func() error { return c.batch(ctx, chunk) })
+	}
+	return Parallel(c.Concurrency, tasks...)
+}
+
+func (c *Client) batch(ctx context.Context, reqs []GetRequest) error {
+	routes := make([]string, len(reqs))
+	for i, r := range reqs {
+		// Routes omit the version prefix; commas inside them must be escaped
+		// because the urls parameter is comma-separated.
+		route := strings.TrimPrefix(r.Path, "/1")
+		if len(r.Query) > 0 {
+			route += "?" + r.Query.Encode()
+		}
+		routes[i] = route
+	}
+	query := url.Values{}
+	query.Set("urls", strings.Join(routes, ","))
+	var results []batchResult
+	if err := c.Do(ctx, http.MethodGet, "/1/batch", query, nil, &results); err != nil {
+		return err
+	}
+	if len(results) != len(reqs) {
+		return fmt.Errorf("batch returned %d results for %d routes", len(results), len(reqs))
+	}
+	for i, res := range results {
+		r := reqs[i]
+		if res.OK == nil {
+			return trello.NewAPIError(res.StatusCode, FirstNonEmpty(res.Message, res.Name, "batch route failed: "+r.Path))
+		}
+		if r.Out != nil {
+			if err := json.Unmarshal(res.OK, r.Out); err != nil {
+				return err
+			}
+		}
+		c.Snapshots.save(r.Path, r.Query, res.OK, "")
+		c.Memo.put(MemoKey(r.Path, r.Query), res.OK)
+	}
+	return nil
+}
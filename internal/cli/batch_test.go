@@ -0,0 +1,138 @@
+package cli_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+	"trelli/pkg/trello"
+)
+
+// batchServer answers /1/batch with a card for every /cards/<id> route
+// except those in missing, and records the routes of each call.
+func batchServer(t *testing.T, missing ...string) (*httptest.Server, func() [][]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var calls [][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1/batch" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		routes := strings.Split(r.URL.Query().Get("urls"), ",")
+		mu.Lock()
+		calls = append(calls, routes)
+		mu.Unlock()
+		results := make([]any, len(routes))
+		for i, route := range routes {
+			id := strings.TrimPrefix(route, "/cards/")
+			if slices.Contains(missing, id) {
+				results[i] = map[string]any{"statusCode": 404, "message": "card not found", "name": "NotFound"}
+				continue
+			}
+			results[i] = map[string]any{"200": trello.Card{ID: id, Name: "Card " + id}}
+		}
+		json.NewEncoder(w).Encode(results)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() [][]string {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls
+	}
+}
+
+func testClient(t *testing.T, srv *httptest.Server) *cli.Client {
+	t.Helper()
+	cfg, _, err := clitest.TestConfig(t, srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func cardRequests(ids ...string) ([]cli.GetRequest, []trello.Card) {
+	cards := make([]trello.Card, len(ids))
+	reqs := make([]cli.GetRequest, len(ids))
+	for i, id := range ids {
+		reqs[i] = cli.GetRequest{Path: "/1/cards/" + id, Out: &cards[i]}
+	}
+	return reqs, cards
+}
+
+func TestGetAllChunks(t *testing.T) {
+	srv, calls := batchServer(t)
+	client := testClient(t, srv)
+
+	ids := make([]string, 2*cli.MaxBatchRoutes+3)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("c%d", i)
+	}
+	reqs, cards := cardRequests(ids...)
+	if err := client.GetAll(context.Background(), reqs...); err != nil {
+		t.Fatal(err)
+	}
+	for i, card := range cards {
+		if card.ID != ids[i] || card.Name != "Card "+ids[i] {
+			t.Errorf("card %d = %+v", i, card)
+		}
+	}
+	sizes := map[int]int{}
+	routes := 0
+	for _, call := range calls() {
+		sizes[len(call)]++
+		routes += len(call)
+	}
+	if len(calls()) != 3 || sizes[cli.MaxBatchRoutes] != 2 || sizes[3] != 1 || routes != len(ids) {
+		t.Errorf("batch calls = %v, want two of %d routes and one of 3", calls(), cli.MaxBatchRoutes)
+	}
+
+	// The same cards again come from the memo, without another call.
+	reqs, cards = cardRequests(ids[:cli.MaxBatchRoutes+1]...)
+	if err := client.GetAll(context.Background(), reqs...); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls()) != 3 || cards[cli.MaxBatchRoutes].ID != ids[cli.MaxBatchRoutes] {
+		t.Errorf("repeat made %d batch calls in total, last card %+v", len(calls()), cards[cli.MaxBatchRoutes])
+	}
+}
+
+func TestGetAllMissingRoute(t *testing.T) {
+	srv, calls := batchServer(t, "c2")
+	client := testClient(t, srv)
+
+	reqs, cards := cardRequests("c1", "c2", "c3")
+	err := client.GetAll(context.Background(), reqs...)
+	var apiErr *trello.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound || !strings.Contains(err.Error(), "card not found") {
+		t.Fatalf("getAll = %v, want the 404 of the missing route", err)
+	}
+	if cards[0].ID != "c1" {
+		t.Errorf("card before the missing one = %+v", cards[0])
+	}
+	if len(calls()) != 1 {
+		t.Errorf("batch calls = %v, want one", calls())
+	}
+
+	// Routes answered before the failure are memoized; the rest are not.
+	if _, ok := client.Memo.Lookup(cli.MemoKey("/1/cards/c1", nil)); !ok {
+		t.Error("c1 not memoized")
+	}
+	if _, ok := client.Memo.Lookup(cli.MemoKey("/1/cards/c3", nil)); ok {
+		t.Error("c3 memoized after the failure")
+	}
+}
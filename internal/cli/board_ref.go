@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// boardLinkPattern finds the shortLink in a Trello board URL, with or
+// without the scheme and the board's name after it.
+var boardLinkPattern = regexp.MustCompile(`^(?:https?://)?(?:www\.)?trello\.com/b/([A-Za-z0-9]+)(?:[/?#].*)?$`)
+
+// workspaceBoardRef splits a board given as workspace/board-slug, e.g.
+// acme/roadmap-2026.
+func workspaceBoardRef(ref string) (workspace, slug string, ok bool) {
+	workspace, slug, ok = strings.Cut(ref, "/")
+	if !ok || workspace == "" || slug == "" || strings.ContainsAny(slug, "/:") {
+		return "", "", false
+	}
+	return workspace, slug, true
+}
+
+// resolveBoardRefs replaces boards given as workspace/board-slug with
+// their ids wherever a request names a board: the /1/boards/ path, the
+// idBoard form field, and the idBoards query parameter. Query and form are
+// cloned before they change.
+func (c *Client) resolveBoardRefs(ctx context.Context, p string, query, form url.Values) (string, url.Values, url.Values, error) {
+	if rest, ok := strings.CutPrefix(p, "/1/boards/"); ok {
+		segment, tail, _ := strings.Cut(rest, "/")
+		if ref, err := url.PathUnescape(segment); err == nil {
+			if _, _, ok := workspaceBoardRef(ref); ok {
+				id, err := c.resolveWorkspaceBoard(ctx, ref)
+				if err != nil {
+					return "", nil, nil, err
+				}
+				p = "/1/boards/" + url.PathEscape(id)
+				if tail != "" {
+					p += "/" + tail
+				}
+			}
+		}
+	}
+	if ref := form.Get("idBoard"); ref != "" {
+		if _, _, ok := workspaceBoardRef(ref); ok {
+			id, err := c.resolveWorkspaceBoard(ctx, ref)
+			if err != nil {
+				return "", nil, nil, err
+			}
+			form = maps.Clone(form)
+			form.Set("idBoard", id)
+		}
+	}
+	if refs := query.Get("idBoards"); strings.Contains(refs, "/") {
+		ids := strings.Split(refs, ",")
+		for i, ref := range ids {
+			if _, _, ok := workspaceBoardRef(ref); ok {
+				id, err := c.resolveWorkspaceBoard(ctx, ref)
+				if err != nil {
+					return "", nil, nil, err
+				}
+				ids[i] = id
+			}
+		}
+		query = maps.Clone(query)
+		query.Set("idBoards", strings.Join(ids, ","))
+	}
+	return p, query, form, nil
+}
+
+// resolveWorkspaceBoard finds the board named by workspace/board-slug
+// among the Workspace's boards. The slug is the name part of the board's
+// URL, so trello.com/b/XobnRsYv/roadmap-2026 is roadmap-2026; a shortLink
+// or the board's name is accepted too.
+func (c *Client) resolveWorkspaceBoard(ctx context.Context, ref string) (string, error) {
+	workspace, slug, _ := workspaceBoardRef(ref)
+	query := url.Values{}
+	query.Set("filter", "all")
+	query.Set("fields", "id,name,shortLink,url")
+	var boards []Board
+	if err := c.Do(ctx, http.MethodGet, "/1/organizations/"+url.PathEscape(workspace)+"/boards", query, nil, &boards); err != nil {
+		return "", fmt.Errorf("board %q: %w", ref, err)
+	}
+	var matches []Board
+	for _, b := range boards {
+		name := b.URL[strings.LastIndex(b.URL, "/")+1:]
+		if strings.EqualFold(name, slug) || b.ShortLink == slug || strings.EqualFold(b.Name, slug) {
+			matches = append(matches, b)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0].ID, nil
+	case 0:
+		return "", fmt.Errorf("board %q not found in Workspace %q", slug, workspace)
+	}
+	return "", fmt.Errorf("board %q is ambiguous in Workspace %q (%d boards); use its shortLink or URL", slug, workspace, len(matches))
+}
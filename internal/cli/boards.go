@@ -0,0 +1,21 @@
+package cli
+
+import (
+	"context"
+	"net/url"
+)
+
+func BoardLabelsRequest(boardID string, out *[]Label) GetRequest {
+	query := url.Values{}
+	query.Set("fields", "id,name,color")
+	return GetRequest{Path: "/1/boards/" + url.PathEscape(boardID) + "/labels", Query: query, Out: out}
+}
+
+func FetchBoardLabels(ctx context.Context, client *Client, boardID string) ([]Label, error) {
+	var labels []Label
+	if err := client.GetAll(ctx, BoardLabelsRequest(boardID, &labels)); err != nil {
+		return nil, err
+	}
+	client.Names.Put("labels:"+boardID, labels)
+	return labels, nil
+}
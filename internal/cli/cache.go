@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const defaultCacheTTL = time.Hour
+
+// nameCache persists board, list, label, and member listings under the user
+// cache dir so name resolution such as --list-name does not cost an API call
+// on every invocation. Entries are scoped by profile. A nil *nameCache
+// caches nothing.
+type nameCache struct {
+	mu      sync.Mutex
+	path    string
+	scope   string
+	ttl     time.Duration
+	bypass  bool
+	entries map[string]nameCacheEntry
+}
+
+type nameCacheEntry struct {
+	Fetched time.Time       `json:"fetched"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// CacheDir returns config cache.dir, or trelli under the user cache dir.
+func CacheDir(cfg Config) (string, error) {
+	if cfg.CacheDir != "" {
+		return cfg.CacheDir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "trelli"), nil
+}
+
+// NewNameCache returns the name cache for cfg's profile. With --no-cache
+// lookups always miss, but fetched listings are still stored.
+func NewNameCache(cfg Config) *nameCache {
+	dir, err := CacheDir(cfg)
+	if err != nil {
+		return nil
+	}
+	ttl := cfg.CacheTTL
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	return &nameCache{path: filepath.Join(dir, "names.json"), scope: FirstNonEmpty(cfg.Profile, "default"), ttl: ttl, bypass: cfg.NoCache}
+}
+
+func (c *nameCache) load() {
+	if c.entries != nil {
+		return
+	}
+	c.entries = map[string]nameCacheEntry{}
+	if data, err := os.ReadFile(c.path); err == nil {
+		_ = json.Unmarshal(data, &c.entries)
+	}
+}
+
+// Get decodes a fresh entry for key into out and reports whether it did.
+func (c *nameCache) Get(key string, out any) bool {
+	if c == nil || c.bypass {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	entry, ok := c.entries[c.scope+"/"+key]
+	if !ok || time.Since(entry.Fetched) >= c.ttl {
+		return false
+	}
+	return json.Unmarshal(entry.Data, out) == nil
+}
+
+// Put stores v under key. The cache is best effort; write failures are
+// ignored.
+func (c *nameCache) Put(key string, v any) {
+	if c == nil {
+		return
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Re-read so concurrent invocations lose as little as possible.
+	c.entries = nil
+	c.load()
+	c.entries[c.scope+"/"+key] = nameCacheEntry{Fetched: time.Now().UTC(), Data: raw}
+	data, err := json.Marshal(c.entries)
+	if err != nil || os.MkdirAll(filepath.Dir(c.path), 0o700) != nil {
+		return
+	}
+	tmp := c.path + ".tmp"
+	if os.WriteFile(tmp, data, 0o600) == nil {
+		_ = os.Rename(tmp, c.path)
+	}
+}
+
+// CachedLookup returns the cached listing for key, or calls fetch (which is
+// expected to refresh the cache) when there is none. The bool reports a
+// cache hit so callers can retry with fresh data when a name is missing.
+func CachedLookup[T any](ctx context.Context, client *Client, key string, fetch func(context.Context, *Client) ([]T, error)) ([]T, bool, error) {
+	var items []T
+	if client.Names.Get(key, &items) {
+		client.Stats.hit()
+		return items, true, nil
+	}
+	items, err := fetch(ctx, client)
+	return items, false, err
+}
+
+func BoardsRequest(out *[]Board) GetRequest {
+	query := url.Values{}
+	query.Set("filter", "open")
+	query.Set("fields", "id,name,shortLink,url,closed")
+	return GetRequest{Path: "/1/members/me/boards", Query: query, Out: out}
+}
+
+func BoardMembersRequest(boardID string, out *[]Member) GetRequest {
+	query := url.Values{}
+	query.Set("fields", "id,username,fullName")
+	return GetRequest{Path: "/1/boards/" + url.PathEscape(boardID) + "/members", Query: query, Out: out}
+}
+
+func FetchBoards(ctx context.Context, client *Client) ([]Board, error) {
+	var boards []Board
+	if err := client.GetAll(ctx, BoardsRequest(&boards)); err != nil {
+		return nil, err
+	}
+	client.Names.Put("boards", boards)
+	return boards, nil
+}
+
+func FetchBoardMembers(ctx context.Context, client *Client, boardID string) ([]Member, error) {
+	var members []Member
+	if err := client.GetAll(ctx, BoardMembersRequest(boardID, &members)); err != nil {
+		return nil, err
+	}
+	client.Names.Put("members:"+boardID, members)
+	return members, nil
+}
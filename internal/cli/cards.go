@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"trelli/pkg/trello"
+)
+
+// CardFields returns the card fields to request: everything Card holds, or
+// with --minimal only what the cards table shows.
+func CardFields(cfg Config) string {
+	if cfg.Minimal {
+		return "id,name,idList,shortUrl,due,closed"
+	}
+	return trello.CardFields
+}
+
+// CardDraft is a new card as the local APIs (serve, rpc) accept it, with
+// Trello's field names. ListName and Board resolve the list as --list-name
+// does; Board defaults to the default board.
+type CardDraft struct {
+	IDList    string   `json:"idList,omitempty"`
+	ListName  string   `json:"listName,omitempty"`
+	Board     string   `json:"board,omitempty"`
+	Name      string   `json:"name"`
+	Desc      string   `json:"desc,omitempty"`
+	Due       string   `json:"due,omitempty"`
+	IDLabels  []string `json:"idLabels,omitempty"`
+	IDMembers []string `json:"idMembers,omitempty"`
+	// DueReminder is minutes before Due; -1 turns the reminder off.
+	DueReminder *int `json:"dueReminder,omitempty"`
+}
+
+// CreateCard resolves the list of d, creates the card, and records it for
+// undo.
+func CreateCard(ctx context.Context, client *Client, cfg Config, d CardDraft) (Card, error) {
+	boardID := cfg.File.ResolveBoardAlias(FirstNonEmpty(d.Board, cfg.BoardID))
+	listID, err := ResolveListID(ctx, client, boardID, d.IDList, d.ListName)
+	if err != nil {
+		return Card{}, err
+	}
+	card, err := client.Cards.Create(ctx, trello.CreateCardRequest{
+		ListID:    listID,
+		Name:      d.Name,
+		Desc:      d.Desc,
+		Due:       d.Due,
+		LabelIDs:  d.IDLabels,
+		MemberIDs: d.IDMembers,
+	})
+	if err != nil {
+		if errors.Is(err, ErrDryRun) && d.DueReminder != nil {
+			return Card{}, setDueReminder(ctx, client, "", *d.DueReminder)
+		}
+		return Card{}, err
+	}
+	RecordUndo(cfg, JournalEntry{Action: "cards.create", Target: card.ID, Summary: "create card " + card.Name})
+	if d.DueReminder != nil {
+		if err := setDueReminder(ctx, client, card.ID, *d.DueReminder); err != nil {
+			return card, fmt.Errorf("created card %s but could not set its reminder: %w", card.ID, err)
+		}
+		card.DueReminder = d.DueReminder
+	}
+	return card, nil
+}
+
+// MoveCard moves a card to listID and records the list it left for undo.
+func MoveCard(ctx context.Context, client *Client, cfg Config, cardID, listID string) (Card, error) {
+	// The current list is needed to undo the move.
+	before, err := client.Cards.Get(ctx, cardID, "idList")
+	if err != nil {
+		return Card{}, err
+	}
+	card, err := client.Cards.Move(ctx, cardID, listID)
+	if err != nil {
+		return Card{}, err
+	}
+	if before.IDList != card.IDList {
+		RecordUndo(cfg, JournalEntry{Action: "cards.move", Target: card.ID, From: before.IDList, Summary: "move card " + card.Name})
+	}
+	return card, nil
+}
+
+// SplitIDs splits a comma-separated id list such as --labels.
+func SplitIDs(s string) []string {
+	var ids []string
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func CardRequest(cardID, fields string, out *Card) GetRequest {
+	query := url.Values{}
+	query.Set("fields", fields)
+	return GetRequest{Path: "/1/cards/" + url.PathEscape(cardID), Query: query, Out: out}
+}
+
+func CardsTable(cards []Card) Table {
+	t := Table{Columns: []string{"ID", "NAME", "LIST", "DUE", "CLOSED", "URL"}, Empty: "No cards found."}
+	for _, c := range cards {
+		t.Rows = append(t.Rows, []string{c.ID, c.Name, c.IDList, c.Due, strconv.FormatBool(c.Closed), FirstNonEmpty(c.ShortURL, c.URL)})
+	}
+	return t
+}
+
+// LinkedCard is a board card with the attachments that link it to an
+// outside issue.
+type LinkedCard struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	IDList           string       `json:"idList"`
+	Closed           bool         `json:"closed"`
+	DateLastActivity time.Time    `json:"dateLastActivity"`
+	Attachments      []Attachment `json:"attachments"`
+}
+
+// FetchLinkedCards returns the board's cards, archived ones included, with
+// their attachment URLs, which pair cards with issues imported or synced
+// from elsewhere.
+func FetchLinkedCards(ctx context.Context, client *Client, boardID string) ([]LinkedCard, error) {
+	query := url.Values{}
+	query.Set("filter", "all")
+	query.Set("fields", "id,name,idList,closed,dateLastActivity")
+	query.Set("attachments", "true")
+	query.Set("attachment_fields", "url")
+	var cards []LinkedCard
+	err := client.Do(ctx, http.MethodGet, "/1/boards/"+url.PathEscape(boardID)+"/cards", query, nil, &cards)
+	return cards, err
+}
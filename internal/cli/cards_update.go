@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// setDueReminder sets the reminder of a new card, which Trello does not
+// take when creating one.
+func setDueReminder(ctx context.Context, client *Client, cardID string, minutes int) error {
+	form := url.Values{"dueReminder": {strconv.Itoa(minutes)}}
+	if cardID == "" {
+		// Under --dry-run the card was not created and has no id yet.
+		return client.PrintDryRun(http.MethodPut, "/1/cards/{new card}", nil, form)
+	}
+	_, err := client.Cards.Update(ctx, cardID, form)
+	return err
+}
@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"net/url"
+)
+
+func ChecklistsRequest(cardID string, out *[]Checklist) GetRequest {
+	query := url.Values{}
+	query.Set("checkItems", "all")
+	query.Set("checkItem_fields", "name,state,pos")
+	return GetRequest{Path: "/1/cards/" + url.PathEscape(cardID) + "/checklists", Query: query, Out: out}
+}
+
+func ChecklistsTable(checklists []Checklist) Table {
+	t := Table{Columns: []string{"CHECKLIST_ID", "CHECKLIST_NAME", "ITEM_ID", "ITEM_STATE", "ITEM_NAME"}, Empty: "No checklists found."}
+	for _, cl := range checklists {
+		if len(cl.CheckItems) == 0 {
+			t.Rows = append(t.Rows, []string{cl.ID, cl.Name, "", "", ""})
+			continue
+		}
+		for _, item := range cl.CheckItems {
+			t.Rows = append(t.Rows, []string{cl.ID, cl.Name, item.ID, item.State, item.Name})
+		}
+	}
+	return t
+}
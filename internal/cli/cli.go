@@ -0,0 +1,405 @@
+// Package cli is the core of the trelli command line: configuration and
+// global flags, the API client with its caches and transport middleware,
+// output rendering, and the command registry that cmd/trelli fills from
+// the packages under internal/cmd.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"trelli/pkg/trello"
+)
+
+const defaultTimeout = 20 * time.Second
+
+// Version, Commit, and Date describe the build; release builds set them
+// with -ldflags -X.
+var (
+	Version = "dev"
+	Commit  = "none"
+	Date    = "unknown"
+)
+
+var (
+	ErrHelpDisplayed = errors.New("help displayed")
+	// ErrDryRun stops a command after Client.do printed the mutating request
+	// it would have sent.
+	ErrDryRun = errors.New("dry run")
+	// ErrNoBoard is returned by commands that need a board when neither
+	// --board nor a default board is given.
+	ErrNoBoard = errors.New("no board configured; set board.default or add an alias")
+)
+
+type Config struct {
+	APIKey  string
+	Token   string
+	BaseURL string
+	BoardID string
+	// Output is the output format; JSON mirrors Output == "json" for the
+	// error, dry-run, and stats writers. Renderer writes command results;
+	// with --redact it replaces the names Redact learns from responses.
+	Output     string
+	Template   string
+	JSON       bool
+	Renderer   Renderer
+	Redact     *redactor
+	Verbose    int
+	Quiet      bool
+	LogFormat  string
+	NoProgress bool
+	Profile    string
+	Yes        bool
+	Force      bool
+	DryRun     bool
+	Timeout    time.Duration
+
+	Proxy              string
+	CACert             string
+	InsecureSkipVerify bool
+
+	Offline     bool
+	CacheTTL    time.Duration
+	MaxRetries  int
+	Rate        float64
+	Concurrency int
+	NoCache     bool
+	CacheDir    string
+	Minimal     bool
+	Stats       bool
+	LogFile     string
+	Record      string
+	Replay      string
+
+	// Context bounds every request of the invocation; main cancels it on
+	// Ctrl-C.
+	Context context.Context
+	// Transport, when set, replaces the network (or --replay) transport
+	// below the middleware, so tests can answer requests in-process.
+	Transport http.RoundTripper
+	// StdinBusy is set while stdin carries input, such as an exec script,
+	// so confirm must not read answers from it.
+	StdinBusy bool
+
+	ConfigPath string
+	File       FileConfig
+	// ConfigErr is set when the config file exists but cannot be loaded;
+	// commands that need config fail with it, `config edit` can repair it.
+	ConfigErr error
+}
+
+// The resource types live in the trello package; the aliases keep the
+// CLI's names.
+type (
+	Board         = trello.Board
+	TrelloList    = trello.List
+	Card          = trello.Card
+	Label         = trello.Label
+	Member        = trello.Member
+	CommentAction = trello.Comment
+	Checklist     = trello.Checklist
+	ChecklistItem = trello.ChecklistItem
+	Attachment    = trello.Attachment
+)
+
+func ParseGlobal(args []string) (Config, []string, bool, error) {
+	var cfg Config
+	fs := flag.NewFlagSet("trelli", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var help, veryVerbose, noRetry bool
+	verbose := verbosity(0)
+	var redact redactFlag
+	fs.StringVar(&cfg.APIKey, "key", "", "Trello API key (default: TRELLO_API_KEY)")
+	fs.StringVar(&cfg.Token, "token", "", "Trello token (default: TRELLO_TOKEN)")
+	fs.StringVar(&cfg.BoardID, "board", "", "Default board id or shortLink (default: TRELLO_BOARD_ID or config board.default)")
+	fs.StringVar(&cfg.BaseURL, "base-url", "", "API base URL (default: TRELLO_BASE_URL, config base_url, or "+trello.DefaultBaseURL+")")
+	fs.StringVar(&cfg.Profile, "profile", "", "Config profile (default: TRELLI_PROFILE or config profile)")
+	fs.BoolVar(&cfg.JSON, "json", false, "Print raw JSON")
+	fs.StringVar(&cfg.Output, "output", "", "Output format: table, json, csv, or template (default: config output or table)")
+	fs.StringVar(&cfg.Output, "o", "", "Output format: table, json, csv, or template (default: config output or table)")
+	fs.StringVar(&cfg.Template, "template", "", "Go template for --output template")
+	fs.Var(&redact, "redact", "Replace member names, and card titles with =all, by pseudonyms")
+	fs.Var(&verbose, "v", "Log HTTP requests to stderr (repeat for bodies)")
+	fs.Var(&verbose, "verbose", "Log HTTP requests to stderr (repeat for bodies)")
+	fs.BoolVar(&veryVerbose, "vv", false, "Log HTTP requests and bodies to stderr")
+	fs.BoolVar(&cfg.Quiet, "quiet", false, "Log only errors to stderr")
+	fs.StringVar(&cfg.LogFormat, "log-format", "", "Diagnostics format: plain, text, or json (default: config log_format or plain)")
+	fs.BoolVar(&cfg.NoProgress, "no-progress", false, "Disable the stderr progress indicator")
+	fs.BoolVar(&cfg.Yes, "yes", false, "Skip confirmation prompts")
+	fs.BoolVar(&cfg.Yes, "y", false, "Skip confirmation prompts")
+	fs.BoolVar(&cfg.Force, "force", false, "Allow destructive commands without a terminal")
+	fs.BoolVar(&cfg.DryRun, "dry-run", false, "Print mutating requests instead of sending them")
+	fs.DurationVar(&cfg.Timeout, "timeout", 0, "Per-request timeout (default: config timeout or 20s)")
+	fs.IntVar(&cfg.MaxRetries, "max-retries", trello.DefaultMaxRetries, "Retries for rate-limited or failed requests")
+	fs.BoolVar(&noRetry, "no-retry", false, "Disable retries")
+	fs.Float64Var(&cfg.Rate, "rate", trello.DefaultRate, "Maximum requests per second (0 disables pacing)")
+	fs.IntVar(&cfg.Concurrency, "concurrency", defaultConcurrency, "Maximum concurrent requests")
+	fs.StringVar(&cfg.Proxy, "proxy", "", "HTTP(S) proxy URL (default: config proxy, then HTTPS_PROXY/NO_PROXY)")
+	fs.StringVar(&cfg.CACert, "ca-cert", "", "PEM file with extra trusted CA certificates")
+	fs.BoolVar(&cfg.InsecureSkipVerify, "insecure-skip-verify", false, "Disable TLS certificate verification")
+	fs.BoolVar(&cfg.Minimal, "minimal", false, "Request only the fields shown in tables")
+	fs.BoolVar(&cfg.NoCache, "no-cache", false, "Bypass cached responses and names")
+	fs.BoolVar(&cfg.Offline, "offline", false, "Serve read commands from the local snapshot cache")
+	fs.BoolVar(&cfg.Stats, "stats", false, "Print API call statistics to stderr at exit")
+	fs.StringVar(&cfg.Record, "record", "", "Record HTTP interactions as fixtures in dir")
+	fs.StringVar(&cfg.Replay, "replay", "", "Answer requests from fixtures in dir without the network")
+	fs.StringVar(&cfg.LogFile, "log-file", "", "Append JSON request logs to file (default: config log_file)")
+	fs.BoolVar(&help, "h", false, "Show help")
+	fs.BoolVar(&help, "help", false, "Show help")
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, nil, false, err
+	}
+	cfg.Verbose = int(verbose)
+	if veryVerbose && cfg.Verbose < 2 {
+		cfg.Verbose = 2
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	cfg.File = FileConfig{}
+	cfg.ConfigPath, cfg.ConfigErr = configPath()
+	if cfg.ConfigErr == nil {
+		if fc, err := loadConfigFile(cfg.ConfigPath); err != nil {
+			cfg.ConfigErr = err
+		} else {
+			cfg.File = fc
+		}
+	}
+
+	// Precedence: flags, then the selected profile, then environment
+	// variables, then top-level config values, then built-in defaults.
+	if !set["profile"] {
+		cfg.Profile = FirstNonEmpty(strings.TrimSpace(os.Getenv("TRELLI_PROFILE")), cfg.File.GetString("profile"))
+	}
+	profile := func(field string) string {
+		if cfg.Profile == "" {
+			return ""
+		}
+		return cfg.File.GetString("profiles." + cfg.Profile + "." + field)
+	}
+	if !set["key"] {
+		cfg.APIKey = FirstNonEmpty(profile("key"), strings.TrimSpace(os.Getenv("TRELLO_API_KEY")))
+	}
+	if !set["token"] {
+		cfg.Token = FirstNonEmpty(profile("token"), strings.TrimSpace(os.Getenv("TRELLO_TOKEN")))
+	}
+	if !set["board"] {
+		cfg.BoardID = FirstNonEmpty(profile("board"), strings.TrimSpace(os.Getenv("TRELLO_BOARD_ID")), cfg.File.GetString("board.default"))
+	}
+	cfg.BoardID = cfg.File.ResolveBoardAlias(cfg.BoardID)
+	switch {
+	case cfg.JSON:
+		cfg.Output = OutputJSON
+	case set["output"] || set["o"]:
+	case cfg.Template != "":
+		cfg.Output = OutputTemplate
+	default:
+		cfg.Output = FirstNonEmpty(cfg.File.GetString("output"), OutputTable)
+	}
+	cfg.JSON = cfg.Output == OutputJSON
+	renderer, err := newRenderer(cfg.Output, cfg.Template)
+	if err != nil {
+		return Config{}, nil, false, err
+	}
+	cfg.Renderer = renderer
+	if redact != "" {
+		cfg.Redact = NewRedactor(string(redact))
+		cfg.Renderer = redactRenderer{next: renderer, redact: cfg.Redact}
+	}
+	if cfg.Quiet && cfg.Verbose > 0 {
+		return Config{}, nil, false, errors.New("--quiet and --verbose cannot be combined")
+	}
+	if !set["log-format"] {
+		cfg.LogFormat = FirstNonEmpty(cfg.File.GetString("log_format"), LogFormatPlain)
+	}
+	logger, err := newLogger(os.Stderr, cfg.LogFormat, logLevel(cfg.Quiet, cfg.Verbose))
+	if err != nil {
+		return Config{}, nil, false, err
+	}
+	slog.SetDefault(logger)
+	if !set["base-url"] {
+		cfg.BaseURL = FirstNonEmpty(strings.TrimSpace(os.Getenv("TRELLO_BASE_URL")), cfg.File.GetString("base_url"), trello.DefaultBaseURL)
+	}
+	if err := checkBaseURL(cfg.BaseURL); err != nil {
+		return Config{}, nil, false, err
+	}
+	if !set["proxy"] {
+		cfg.Proxy = cfg.File.GetString("proxy")
+	}
+	if !set["ca-cert"] {
+		cfg.CACert = cfg.File.GetString("tls.ca_cert")
+	}
+	if !set["insecure-skip-verify"] {
+		v, _ := cfg.File.Get("tls.insecure_skip_verify")
+		cfg.InsecureSkipVerify = v == true
+	}
+	if !set["log-file"] {
+		cfg.LogFile = cfg.File.GetString("log_file")
+	}
+	if !set["timeout"] {
+		cfg.Timeout = defaultTimeout
+		if err := configDuration(cfg.File, "timeout", &cfg.Timeout); err != nil {
+			return Config{}, nil, false, err
+		}
+	}
+
+	if !set["max-retries"] {
+		if err := configNumber(cfg.File, "max_retries", strconv.Atoi, &cfg.MaxRetries); err != nil {
+			return Config{}, nil, false, err
+		}
+	}
+	if !set["rate"] {
+		parseFloat := func(s string) (float64, error) { return strconv.ParseFloat(s, 64) }
+		if err := configNumber(cfg.File, "rate", parseFloat, &cfg.Rate); err != nil {
+			return Config{}, nil, false, err
+		}
+	}
+	if !set["concurrency"] {
+		if err := configNumber(cfg.File, "concurrency", strconv.Atoi, &cfg.Concurrency); err != nil {
+			return Config{}, nil, false, err
+		}
+	}
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	if noRetry || cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.Record != "" && cfg.Replay != "" {
+		return Config{}, nil, false, errors.New("--record and --replay cannot be combined")
+	}
+	if cfg.Offline && cfg.Replay != "" {
+		return Config{}, nil, false, errors.New("--offline and --replay cannot be combined")
+	}
+	if cfg.Record != "" || cfg.Replay != "" {
+		// Cached names and ETags would make the requests of a replay differ
+		// from those recorded; pacing only slows replays down.
+		cfg.NoCache = true
+	}
+	if cfg.Replay != "" {
+		cfg.Rate = 0
+	}
+	cfg.CacheDir = cfg.File.GetString("cache.dir")
+	if err := configDuration(cfg.File, "cache.ttl", &cfg.CacheTTL); err != nil {
+		return Config{}, nil, false, err
+	}
+
+	return cfg, fs.Args(), help, nil
+}
+
+// configDuration sets *d to the duration at key in the config file, when
+// the key is set. Invalid and negative durations are errors rather than
+// falling back to the default.
+func configDuration(fc FileConfig, key string, d *time.Duration) error {
+	v, ok := fc.Get(key)
+	if !ok {
+		return nil
+	}
+	parsed, err := time.ParseDuration(strings.TrimSpace(fmt.Sprint(v)))
+	if err != nil {
+		return fmt.Errorf("config %s: %w", key, err)
+	}
+	if parsed < 0 {
+		return fmt.Errorf("config %s: %s is negative", key, parsed)
+	}
+	*d = parsed
+	return nil
+}
+
+// configNumber sets *n to the number at key in the config file, parsed by
+// parse, when the key is set.
+func configNumber[T int | float64](fc FileConfig, key string, parse func(string) (T, error), n *T) error {
+	v, ok := fc.Get(key)
+	if !ok {
+		return nil
+	}
+	parsed, err := parse(strings.TrimSpace(fmt.Sprint(v)))
+	if err != nil {
+		return fmt.Errorf("config %s: %w", key, err)
+	}
+	*n = parsed
+	return nil
+}
+
+// checkBaseURL rejects base URLs that cannot address an API and warns when
+// credentials would travel unencrypted to anything but a local mock.
+func checkBaseURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid base URL %q: expected http(s)://host[/path]", raw)
+	}
+	if u.Scheme == "http" {
+		host := u.Hostname()
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			slog.Warn(fmt.Sprintf("base URL %s is not HTTPS; the API key and token are sent unencrypted", raw), "url", raw)
+		}
+	}
+	return nil
+}
+
+func FirstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ExitOnError fails with err unless it is nil or only reports that help
+// was shown or a dry run stopped.
+func ExitOnError(err error, cfg Config) {
+	if err == nil || errors.Is(err, ErrHelpDisplayed) || errors.Is(err, ErrDryRun) {
+		return
+	}
+	Fail(err, cfg.JSON)
+}
+
+// Fail reports err on stderr (as JSON when requested) and exits non-zero.
+func Fail(err error, asJSON bool) {
+	if errors.Is(err, context.Canceled) {
+		WriteError(os.Stderr, errors.New("interrupted"), asJSON)
+		os.Exit(130)
+	}
+	WriteError(os.Stderr, err, asJSON)
+	var tokenErr *TokenError
+	if errors.As(err, &tokenErr) {
+		os.Exit(exitTokenInvalid)
+	}
+	var checkErr *CheckFailedError
+	if errors.As(err, &checkErr) {
+		os.Exit(exitCheckFailed)
+	}
+	os.Exit(1)
+}
+
+func HasJSONFlag(args []string) bool {
+	for i, a := range args {
+		if a == "--" {
+			break
+		}
+		switch a {
+		case "--json", "-json", "--json=true", "-json=true", "--output=json", "-output=json", "-o=json":
+			return true
+		case "--output", "-output", "-o":
+			if i+1 < len(args) && args[i+1] == OutputJSON {
+				return true
+			}
+		}
+	}
+	return false
+}
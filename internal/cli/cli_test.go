@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigValues(t *testing.T) {
+	fc := FileConfig{
+		"timeout":     "45s",
+		"max_retries": json.Number("2"),
+		"cache":       map[string]any{"ttl": "soon"},
+		"concurrency": json.Number("2.5"),
+		"log_file":    "-5s",
+	}
+	d := defaultTimeout
+	if err := configDuration(fc, "timeout", &d); err != nil || d != 45*time.Second {
+		t.Errorf("configDuration(timeout) = %s, %v", d, err)
+	}
+	d = defaultTimeout
+	if err := configDuration(fc, "rate", &d); err != nil || d != defaultTimeout {
+		t.Errorf("configDuration of an unset key = %s, %v", d, err)
+	}
+	if err := configDuration(fc, "cache.ttl", &d); err == nil || !strings.HasPrefix(err.Error(), `config cache.ttl: time: invalid duration "soon"`) {
+		t.Errorf("configDuration(cache.ttl) error = %v", err)
+	}
+	if err := configDuration(fc, "log_file", &d); err == nil || err.Error() != "config log_file: -5s is negative" {
+		t.Errorf("configDuration of a negative duration error = %v", err)
+	}
+
+	n := 0
+	if err := configNumber(fc, "max_retries", strconv.Atoi, &n); err != nil || n != 2 {
+		t.Errorf("configNumber(max_retries) = %d, %v", n, err)
+	}
+	if err := configNumber(fc, "concurrency", strconv.Atoi, &n); err == nil || !strings.HasPrefix(err.Error(), "config concurrency: ") {
+		t.Errorf("configNumber(concurrency) error = %v", err)
+	}
+}
@@ -0,0 +1,290 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+
+	"trelli/pkg/trello"
+)
+
+// Client wraps the trello API client with the CLI's dry-run, offline,
+// caching, and accounting layers. Its embedded services send requests
+// through Do, so they get all of them.
+type Client struct {
+	// API sends requests, with retries and pacing in its transport layers;
+	// Client adds everything else. Commands can be exercised against a
+	// fake without a network.
+	API apiSender
+	trello.Services
+	// DryRun makes Do print non-GET requests to DryRunOut and return
+	// ErrDryRun instead of sending them.
+	DryRun     bool
+	DryRunOut  io.Writer
+	DryRunJSON bool
+	// Offline serves GET requests from Snapshots instead of the network;
+	// successful online GETs refresh Snapshots.
+	Offline   bool
+	Snapshots *snapshotStore
+	// Concurrency bounds requests in flight for commands that fan out.
+	Concurrency int
+	// NoCache skips ETag revalidation against Snapshots.
+	NoCache bool
+	// Names caches board/list/label/member listings used to resolve names.
+	Names *nameCache
+	// Memo sends each unique GET once per invocation; nil for long-running
+	// commands (see LongRunning).
+	Memo *memo
+	// Stats counts traffic for --stats; nil when not requested.
+	Stats *apiStats
+	// Redact learns the names to redact from responses; nil without
+	// --redact.
+	Redact *redactor
+	// Progress is the stderr progress indicator; nil when disabled.
+	Progress *progress
+}
+
+// LongRunning prepares c for a command that keeps running, such as a poll
+// loop or a server: the memo lasts one invocation, and would otherwise
+// answer every repeat of a GET with its first response.
+func (c *Client) LongRunning() {
+	c.Memo = nil
+}
+
+// Connect fills in stored credentials and returns a client for cfg.
+func Connect(cfg Config) (*Client, error) {
+	if err := LoadStoredCredentials(&cfg); err != nil {
+		return nil, err
+	}
+	if cfg.APIKey == "" || cfg.Token == "" {
+		if err := checkProfile(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return NewClient(cfg)
+}
+
+// checkProfile reports a selected profile that has no entry in the config;
+// it explains missing credentials better than the generic message.
+func checkProfile(cfg Config) error {
+	if cfg.Profile == "" {
+		return nil
+	}
+	if _, ok := cfg.File.Lookup("profiles." + cfg.Profile); !ok {
+		return fmt.Errorf("unknown profile %q: run `trelli --profile %s auth login` or define profiles.%s.key/token with `trelli config set`", cfg.Profile, cfg.Profile, cfg.Profile)
+	}
+	return nil
+}
+
+func NewClient(cfg Config) (*Client, error) {
+	if (cfg.APIKey == "" || cfg.Token == "") && !cfg.Offline && cfg.Replay == "" {
+		return nil, errors.New("missing credentials: set TRELLO_API_KEY and TRELLO_TOKEN (or pass --key/--token, select a --profile, or run trelli auth login)")
+	}
+	transport := cfg.Transport
+	var err error
+	switch {
+	case transport != nil:
+	case cfg.Replay != "":
+		transport, err = newReplayTransport(cfg.Replay)
+	default:
+		transport, err = NewTransport(cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Layers are added innermost first: recording sees the wire, metering
+	// sees every attempt as the command made it.
+	var layers []trello.Middleware
+	if cfg.Record != "" {
+		record, err := recordMiddleware(cfg.Record)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, record)
+	}
+	if cfg.LogFile != "" {
+		logger, err := logMiddleware(cfg.LogFile)
+		if err != nil {
+			return nil, fmt.Errorf("opening log file: %w", err)
+		}
+		layers = append(layers, logger)
+	}
+	if cfg.Verbose > 0 {
+		layers = append(layers, func(next http.RoundTripper) http.RoundTripper {
+			return &traceTransport{next: next, log: slog.Default(), level: cfg.Verbose}
+		})
+	}
+	var stats *apiStats
+	if cfg.Stats {
+		stats = &apiStats{}
+	}
+	// Tracing and structured logs would interleave with the spinner.
+	prog := newProgress(cfg.NoProgress || cfg.Verbose > 0 || cfg.Quiet || cfg.LogFormat != LogFormatPlain)
+	layers = append(layers, func(next http.RoundTripper) http.RoundTripper {
+		return &meterTransport{next: next, progress: prog, stats: stats}
+	})
+
+	api := trello.New(cfg.APIKey, cfg.Token,
+		trello.WithBaseURL(cfg.BaseURL),
+		trello.WithHTTPClient(&http.Client{Transport: transport}),
+		trello.WithTimeout(cfg.Timeout),
+		trello.WithRetryPolicy(trello.RetryPolicy{
+			MaxRetries: cfg.MaxRetries,
+			Retryable:  retryable,
+			OnRetry:    func(*http.Request) { stats.retry() },
+		}),
+		trello.WithRateLimit(cfg.Rate),
+		trello.WithMiddleware(layers...),
+	)
+	c := &Client{
+		API:         api,
+		DryRun:      cfg.DryRun,
+		DryRunOut:   os.Stdout,
+		DryRunJSON:  cfg.JSON,
+		Offline:     cfg.Offline,
+		Snapshots:   newSnapshotStore(cfg),
+		Names:       NewNameCache(cfg),
+		NoCache:     cfg.NoCache,
+		Concurrency: cfg.Concurrency,
+		Memo:        newMemo(),
+		Stats:       stats,
+		Redact:      cfg.Redact,
+		Progress:    prog,
+	}
+	if cfg.Replay != "" {
+		// Replayed responses must not reach the real cache and snapshots.
+		c.Snapshots, c.Names = nil, nil
+	}
+	c.Services = trello.NewServices(c)
+	return c, nil
+}
+
+// Do implements trello.Doer. Mutations print instead under --dry-run and
+// fail under --offline; GETs are answered from the offline snapshots, the
+// in-process memo, or the network with ETag revalidation.
+func (c *Client) Do(ctx context.Context, method, p string, query, form url.Values, out any) error {
+	p, query, form, err := c.resolveBoardRefs(ctx, p, query, form)
+	if err != nil {
+		return err
+	}
+	if c.DryRun && method != http.MethodGet {
+		return c.PrintDryRun(method, p, query, form)
+	}
+	if c.Offline {
+		if method != http.MethodGet {
+			return errors.New("offline: cannot send changes without the network (drop --offline)")
+		}
+		c.Stats.hit()
+	}
+	var raw []byte
+	switch {
+	case c.Offline:
+		var cached json.RawMessage
+		err = c.Snapshots.load(p, query, &cached)
+		raw = cached
+	case method == http.MethodGet:
+		fetched := false
+		raw, err = c.Memo.do(ctx, MemoKey(p, query), func() ([]byte, error) {
+			fetched = true
+			return c.fetch(ctx, method, p, query, form)
+		})
+		if err == nil && !fetched {
+			c.Stats.hit()
+		}
+	default:
+		c.Memo.Reset()
+		raw, err = c.fetch(ctx, method, p, query, form)
+	}
+	if err != nil {
+		return err
+	}
+	c.Redact.Learn(raw)
+	if out == nil || len(bytes.TrimSpace(raw)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// Upload implements trello.Uploader with the same dry-run and offline
+// handling as Do; under --dry-run the file is shown by name and size.
+func (c *Client) Upload(ctx context.Context, p string, form url.Values, file trello.File, out any) error {
+	if c.DryRun {
+		shown := url.Values{}
+		for k, v := range form {
+			shown[k] = v
+		}
+		shown.Set("file", fmt.Sprintf("%s (%d bytes)", file.Name, len(file.Data)))
+		return c.PrintDryRun(http.MethodPost, p, nil, shown)
+	}
+	if c.Offline {
+		return errors.New("offline: cannot send changes without the network (drop --offline)")
+	}
+	c.Memo.Reset()
+	return c.API.Upload(ctx, p, form, file, out)
+}
+
+// fetch sends a request and returns the response body. GET responses are
+// revalidated against and stored in Snapshots.
+func (c *Client) fetch(ctx context.Context, method, p string, query, form url.Values) ([]byte, error) {
+	u, err := c.API.Endpoint(p, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Revalidate a cached GET response instead of downloading it again.
+	var cached snapshotEntry
+	var etag string
+	if method == http.MethodGet && !c.NoCache {
+		if entry, ok := c.Snapshots.entry(p, query); ok {
+			cached, etag = entry, entry.ETag
+		}
+	}
+
+	raw, header, err := c.API.Send(ctx, method, u, form, etag)
+	if errors.Is(err, trello.ErrNotModified) {
+		c.Stats.hit()
+		raw, err = cached.Data, nil
+	}
+	if err != nil {
+		return nil, timeoutHint(err)
+	}
+
+	if method == http.MethodGet && json.Valid(raw) {
+		c.Snapshots.save(p, query, raw, FirstNonEmpty(header.Get("ETag"), etag))
+	}
+	return raw, nil
+}
+
+func (c *Client) PrintDryRun(method, p string, query, form url.Values) error {
+	target := p
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	if c.DryRunJSON {
+		enc := json.NewEncoder(c.DryRunOut)
+		if err := enc.Encode(map[string]any{"dryRun": true, "method": method, "path": target, "form": form}); err != nil {
+			return err
+		}
+		return ErrDryRun
+	}
+	fmt.Fprintf(c.DryRunOut, "DRY RUN: %s %s\n", method, target)
+	keys := make([]string, 0, len(form))
+	for k := range form {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range form[k] {
+			fmt.Fprintf(c.DryRunOut, "  %s=%s\n", k, v)
+		}
+	}
+	return ErrDryRun
+}
@@ -0,0 +1,92 @@
+package cli_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+	"trelli/pkg/trello"
+)
+
+// etagServer serves /1/boards/b1 with an ETag, answers a matching
+// If-None-Match with 304, and records the If-None-Match of each request.
+func etagServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Header.Get("If-None-Match"))
+		mu.Unlock()
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(`{"id": "b1", "name": "Engineering"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), seen...)
+	}
+}
+
+// getBoard fetches b1 with a fresh client for cfg, as a new invocation
+// would, and returns it with the client's cache hits.
+func getBoard(t *testing.T, cfg cli.Config) (trello.Board, int64) {
+	t.Helper()
+	cfg.Stats = true
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var board trello.Board
+	if err := client.Do(context.Background(), http.MethodGet, "/1/boards/b1", nil, nil, &board); err != nil {
+		t.Fatal(err)
+	}
+	return board, client.Stats.CacheHits.Load()
+}
+
+func TestFetchRevalidatesWithETag(t *testing.T) {
+	srv, seen := etagServer(t)
+	cfg, _, err := clitest.TestConfig(t, srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	board, hits := getBoard(t, cfg)
+	if board.Name != "Engineering" || hits != 0 {
+		t.Errorf("first fetch: %+v, %d hits", board, hits)
+	}
+	// The second invocation revalidates the snapshot and, on 304, serves it.
+	board, hits = getBoard(t, cfg)
+	if board.Name != "Engineering" || hits != 1 {
+		t.Errorf("revalidated fetch: %+v, %d hits", board, hits)
+	}
+	if got := seen(); len(got) != 2 || got[0] != "" || got[1] != `"v1"` {
+		t.Errorf("If-None-Match sent = %q, want none then \"v1\"", got)
+	}
+}
+
+func TestFetchNoCacheSkipsRevalidation(t *testing.T) {
+	srv, seen := etagServer(t)
+	cfg, _, err := clitest.TestConfig(t, srv, "--no-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	getBoard(t, cfg)
+	board, hits := getBoard(t, cfg)
+	if board.Name != "Engineering" || hits != 0 {
+		t.Errorf("fetch with --no-cache: %+v, %d hits", board, hits)
+	}
+	if got := seen(); len(got) != 2 || got[0] != "" || got[1] != "" {
+		t.Errorf("If-None-Match sent with --no-cache = %q, want none", got)
+	}
+}
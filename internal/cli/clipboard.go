@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// CopyFlags holds --copy/--copy-id for commands that print a resource.
+type CopyFlags struct {
+	URL bool
+	ID  bool
+}
+
+func AddCopyFlags(fs *flag.FlagSet, c *CopyFlags) {
+	fs.BoolVar(&c.URL, "copy", false, "Copy the short URL to the clipboard")
+	fs.BoolVar(&c.ID, "copy-id", false, "Copy the id to the clipboard")
+}
+
+// Apply copies the id or url when requested and notes it on stderr so
+// stdout stays parseable. Callers apply it after printing the resource, and
+// a failure only warns: the command itself has succeeded, and running it
+// again to retry the copy could create a second card.
+func (c CopyFlags) Apply(id, url string) {
+	text := url
+	if c.ID {
+		text = id
+	} else if !c.URL {
+		return
+	}
+	if text == "" {
+		slog.Warn("nothing to copy to the clipboard")
+		return
+	}
+	if err := copyToClipboard(text); err != nil {
+		slog.Warn(err.Error())
+		return
+	}
+	slog.Info(fmt.Sprintf("Copied %s to the clipboard", text), "copied", text)
+}
+
+// copyToClipboard writes text to the system clipboard using the platform's
+// clipboard tool.
+func copyToClipboard(text string) error {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip"}}
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, []string{"wl-copy"})
+		}
+		candidates = append(candidates,
+			[]string{"xclip", "-selection", "clipboard"},
+			[]string{"xsel", "--clipboard", "--input"},
+		)
+	}
+	for _, argv := range candidates {
+		path, err := exec.LookPath(argv[0])
+		if err != nil {
+			continue
+		}
+		// xclip and wl-copy stay in the background to serve the selection;
+		// leaving stdout/stderr unattached keeps Run from waiting on them.
+		cmd := exec.Command(path, argv[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("copying to clipboard: %w", commandError(argv[0], err, ""))
+		}
+		return nil
+	}
+	return errors.New("no clipboard tool found (install wl-copy, xclip, or xsel)")
+}
@@ -0,0 +1,407 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Flag documents one flag. Arg is the value placeholder shown in help;
+// it is empty for boolean flags.
+type Flag struct {
+	Name  string
+	Short string
+	Arg   string
+	Desc  string
+}
+
+func (f Flag) TakesValue() bool { return f.Arg != "" }
+
+func (f Flag) Label() string {
+	s := "--" + f.Name
+	if len(f.Name) <= 2 {
+		s = "-" + f.Name
+	}
+	if f.Short != "" {
+		s = "-" + f.Short + ", " + s
+	}
+	if f.Arg != "" {
+		s += " <" + f.Arg + ">"
+	}
+	return s
+}
+
+type Subcommand struct {
+	Name    string
+	Aliases []string
+	// Usage lists synopses following "trelli <command> ", one per form.
+	Usage []string
+	Flags []Flag
+}
+
+type HelpSection struct {
+	Title string
+	Body  string
+}
+
+// RunFunc runs a command with the arguments after its name. client is nil
+// unless the command's mode is ModeClient.
+type RunFunc func(client *Client, cfg Config, args []string) error
+
+// Local adapts a runner that needs no client.
+func Local(run func(Config, []string) error) RunFunc {
+	return func(_ *Client, cfg Config, args []string) error { return run(cfg, args) }
+}
+
+// Mode says what main sets up before running a command.
+type Mode int
+
+const (
+	// ModeClient commands get a connected client, unless they were only
+	// asked for help.
+	ModeClient Mode = iota
+	// ModeOnline commands may reach the API but resolve credentials
+	// themselves.
+	ModeOnline
+	// ModeOffline commands need neither the network nor a valid config
+	// file, so they can repair it.
+	ModeOffline
+)
+
+// Command is the single source for help text, shell completion, the
+// generated man/markdown reference, and dispatch.
+type Command struct {
+	Name        string
+	Aliases     []string
+	Summary     string
+	Description string
+	// Usage lists synopses following "trelli <command>" for commands
+	// without subcommands.
+	Usage       []string
+	Subcommands []Subcommand
+	// Options documents command-level flags in addition to the union of
+	// subcommand flags.
+	Options  []Flag
+	Sections []HelpSection
+
+	// Run executes the command; nil for help and version, which main
+	// handles itself. Mode says what main prepares first.
+	Run  RunFunc
+	Mode Mode
+	// BareIsAction runs the command with no arguments instead of showing
+	// its help.
+	BareIsAction bool
+}
+
+var JSONOption = Flag{Name: "json", Desc: "Output raw JSON"}
+
+var GlobalFlags = []Flag{
+	{Name: "key", Arg: "key", Desc: "Trello API key (default: TRELLO_API_KEY)"},
+	{Name: "token", Arg: "token", Desc: "Trello token (default: TRELLO_TOKEN)"},
+	{Name: "board", Arg: "id", Desc: "Default board id/shortLink/alias/URL or workspace/board-slug (default: TRELLO_BOARD_ID or config board.default)"},
+	{Name: "base-url", Arg: "url", Desc: "API base URL for mocks or gateways (default: TRELLO_BASE_URL, config base_url, or https://api.trello.com)"},
+	{Name: "profile", Arg: "name", Desc: "Use credentials and board from config profile (default: TRELLI_PROFILE or config profile)"},
+	{Name: "json", Desc: `Output raw JSON; errors go to stderr as {"error": {...}}`},
+	{Name: "output", Short: "o", Arg: "format", Desc: "Output format: table, json, csv, or template (default: config output or table)"},
+	{Name: "template", Arg: "text", Desc: "Go template run per result with JSON field names, e.g. '{{.id}} {{.name}}'"},
+	{Name: "redact", Desc: "Replace member names with stable pseudonyms in the output; --redact=all also card titles"},
+	{Name: "verbose", Short: "v", Desc: "Log HTTP method, URL (credentials redacted), status, latency to stderr"},
+	{Name: "vv", Desc: "Also log request and response bodies"},
+	{Name: "quiet", Desc: "Log only errors to stderr: no warnings, notices, or progress spinner"},
+	{Name: "log-format", Arg: "format", Desc: "Diagnostics on stderr as plain, text (slog key=value), or json lines (default: config log_format or plain)"},
+	{Name: "no-progress", Desc: "Disable the progress spinner on stderr (TTY only)"},
+	{Name: "timeout", Arg: "duration", Desc: "Per-request timeout, e.g. 60s (default: config timeout or 20s)"},
+	{Name: "max-retries", Arg: "n", Desc: "Retry 429s, transient 5xx, and network errors up to n times (default: config max_retries or 3)"},
+	{Name: "no-retry", Desc: "Disable retries"},
+	{Name: "concurrency", Arg: "n", Desc: "Maximum concurrent requests for commands that fan out (default: config concurrency or 4)"},
+	{Name: "rate", Arg: "n", Desc: "Maximum requests per second (default: config rate or 9, under Trello's 100 per 10s; 0 disables)"},
+	{Name: "minimal", Desc: "Request only the fields shown in table output, shrinking payloads (also with --json)"},
+	{Name: "no-cache", Desc: "Ignore cached responses and names; always fetch fresh data"},
+	{Name: "stats", Desc: "Print API calls, bytes sent/received, retries, cache hits, and total latency to stderr at exit"},
+	{Name: "record", Arg: "dir", Desc: "Save every HTTP interaction (credentials stripped) as a fixture file in dir"},
+	{Name: "replay", Arg: "dir", Desc: "Serve requests from fixtures recorded with --record; no network, no credentials needed"},
+	{Name: "log-file", Arg: "file", Desc: "Append one JSON line per request (redacted URL, status, timing) to file (default: config log_file)"},
+	{Name: "offline", Desc: "Serve boards/lists/cards list and cards show from the last cached responses"},
+	{Name: "proxy", Arg: "url", Desc: "HTTP(S) proxy URL (default: config proxy, then HTTPS_PROXY/NO_PROXY)"},
+	{Name: "ca-cert", Arg: "file", Desc: "PEM file with extra trusted CA certificates (e.g. corporate proxy CA)"},
+	{Name: "insecure-skip-verify", Desc: "Disable TLS certificate verification (unsafe; last resort)"},
+	{Name: "dry-run", Desc: "Print the method, path, and form of mutating requests instead of sending them"},
+	{Name: "yes", Short: "y", Desc: "Skip confirmation prompts for destructive commands"},
+	{Name: "force", Desc: "Run destructive commands without a prompt when stdin is not a terminal"},
+	{Name: "help", Short: "h", Desc: "Show help"},
+}
+
+var (
+	BoardFlag       = Flag{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (used with --list-name)"}
+	CardFlag        = Flag{Name: "card", Arg: "id", Desc: "Card id"}
+	ListFlag        = Flag{Name: "list", Arg: "id", Desc: "List id"}
+	ListNameFlag    = Flag{Name: "list-name", Arg: "name", Desc: "List name (resolved on board)"}
+	YesFlag         = Flag{Name: "yes", Short: "y", Desc: "Skip the confirmation prompt"}
+	ForceFlag       = Flag{Name: "force", Desc: "Proceed without a prompt when stdin is not a terminal"}
+	CopyFlag        = Flag{Name: "copy", Desc: "Copy the card's short URL to the clipboard (show, create)"}
+	CopyIDFlag      = Flag{Name: "copy-id", Desc: "Copy the card id to the clipboard (show, create)"}
+	RawMarkdownFlag = Flag{Name: "raw", Desc: "Print descriptions and comments as written instead of rendering their Markdown"}
+)
+
+var RootExamples = []string{
+	"trelli boards list",
+	"trelli lists list --board XobnRsYv",
+	`trelli cards create --list-name "To Do" --name "Build CLI" --desc "Initial implementation"`,
+	`trelli comments add --card <cardId> --text "Started implementation"`,
+	`trelli cards show <cardId>`,
+	`trelli checklists add-item --checklist <checklistId> --name "Write tests"`,
+}
+
+// commands lists the registered commands in help order. Each command's
+// spec, including its runner, lives in its package under internal/cmd;
+// main registers them.
+var commands []Command
+
+// Register adds commands to the registry that help, completion, docs, and
+// dispatch read, after those registered before.
+func Register(cmds ...Command) {
+	commands = append(commands, cmds...)
+}
+
+// Commands returns the registered commands in help order.
+func Commands() []Command {
+	return commands
+}
+
+func ConfigKeysHelp() string {
+	var b strings.Builder
+	for i, k := range configKeys {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%-34s %s", strings.ReplaceAll(k.Name, "*", "<name>"), k.Desc)
+	}
+	return b.String()
+}
+
+// FindCommand looks up a command by name or alias.
+func FindCommand(name string) (Command, bool) {
+	for _, c := range commands {
+		if c.Name == name || containsString(c.Aliases, name) {
+			return c, true
+		}
+	}
+	return Command{}, false
+}
+
+// Subcommand looks up a subcommand by name or alias.
+func (c Command) Subcommand(name string) (Subcommand, bool) {
+	for _, s := range c.Subcommands {
+		if s.Name == name || containsString(s.Aliases, name) {
+			return s, true
+		}
+	}
+	return Subcommand{}, false
+}
+
+func containsString(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveAliases rewrites command and subcommand aliases (e.g. `c ls`) in
+// the post-global-flag arguments to their canonical names.
+func ResolveAliases(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	out := append([]string{}, args...)
+	if out[0] == "help" {
+		if len(out) > 1 {
+			if c, ok := FindCommand(out[1]); ok {
+				out[1] = c.Name
+			}
+		}
+		return out
+	}
+	c, ok := FindCommand(out[0])
+	if !ok {
+		return out
+	}
+	out[0] = c.Name
+	if len(out) > 1 {
+		if sub, ok := c.Subcommand(out[1]); ok {
+			out[1] = sub.Name
+		}
+	}
+	return out
+}
+
+// Documented reports whether c has its own help and reference page.
+func (c Command) Documented() bool {
+	return len(c.Subcommands) > 0 || len(c.Usage) > 0
+}
+
+// UsageLines returns the full synopses, e.g. "trelli cards show --card <cardId>".
+func (c Command) UsageLines() []string {
+	var lines []string
+	for _, u := range c.Usage {
+		lines = append(lines, strings.TrimSpace("trelli "+c.Name+" "+u))
+	}
+	for _, s := range c.Subcommands {
+		for _, u := range s.Usage {
+			lines = append(lines, "trelli "+c.Name+" "+u)
+		}
+	}
+	return lines
+}
+
+// Flags returns the union of subcommand flags (first description wins)
+// followed by command-level options.
+func (c Command) Flags() []Flag {
+	seen := map[string]bool{}
+	var out []Flag
+	for _, s := range c.Subcommands {
+		for _, f := range s.Flags {
+			if !seen[f.Name] {
+				seen[f.Name] = true
+				out = append(out, f)
+			}
+		}
+	}
+	for _, f := range c.Options {
+		if !seen[f.Name] {
+			seen[f.Name] = true
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func FindFlagSpec(flags []Flag, name string) (Flag, bool) {
+	for _, f := range flags {
+		if f.Name == name || (f.Short != "" && f.Short == name) {
+			return f, true
+		}
+	}
+	return Flag{}, false
+}
+
+func indent(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = prefix + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func writeFlagTable(w io.Writer, flags []Flag) {
+	width := 16
+	for _, f := range flags {
+		if n := len(f.Label()) + 2; n > width {
+			width = n
+		}
+	}
+	for _, f := range flags {
+		fmt.Fprintf(w, "  %-*s%s\n", width, f.Label(), f.Desc)
+	}
+}
+
+func WriteCommandHelp(w io.Writer, c Command) {
+	fmt.Fprintln(w, "Usage:")
+	for _, l := range c.UsageLines() {
+		fmt.Fprintf(w, "  %s\n", l)
+	}
+	if c.Description != "" {
+		fmt.Fprintf(w, "\nDescription:\n%s\n", indent(c.Description, "  "))
+	}
+	for _, s := range c.Sections {
+		fmt.Fprintf(w, "\n%s:\n%s\n", s.Title, indent(s.Body, "  "))
+	}
+	if opts := c.Flags(); len(opts) > 0 {
+		fmt.Fprintln(w, "\nOptions:")
+		writeFlagTable(w, opts)
+	}
+}
+
+func writeRootHelp(w io.Writer) {
+	fmt.Fprint(w, `trelli - Efficient Trello CLI
+
+Usage:
+  trelli [global options] <command> <subcommand> [options]
+  trelli help [command]
+  trelli version
+
+Global options:
+`)
+	writeFlagTable(w, GlobalFlags)
+
+	fmt.Fprintln(w, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-12s%s\n", c.Name, c.Summary)
+	}
+
+	fmt.Fprintln(w, "\nAliases:")
+	for _, c := range commands {
+		if len(c.Aliases) > 0 {
+			fmt.Fprintf(w, "  %-12s%s\n", c.Name, strings.Join(c.Aliases, ", "))
+		}
+	}
+	fmt.Fprintf(w, "  %-12s%s\n", "list", "ls (subcommand)")
+	fmt.Fprintf(w, "  %-12s%s\n", "move", "mv (subcommand)")
+
+	fmt.Fprintln(w, "\nSubcommands:")
+	for _, c := range commands {
+		if len(c.Subcommands) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(c.Subcommands))
+		for _, s := range c.Subcommands {
+			names = append(names, s.Name)
+		}
+		fmt.Fprintf(w, "  %s %s\n", c.Name, strings.Join(names, " | "))
+	}
+
+	fmt.Fprintln(w, "\nDetailed usage:")
+	for _, c := range commands {
+		for _, l := range c.UsageLines() {
+			fmt.Fprintf(w, "  %s\n", l)
+		}
+	}
+
+	fmt.Fprintf(w, "\nPlugins:\n  Other commands run %s<command> from PATH with credentials, board, and\n  settings in TRELLO_*/TRELLI_* environment variables.\n", PluginPrefix)
+	if plugins := listPlugins(); len(plugins) > 0 {
+		fmt.Fprintf(w, "  Installed: %s\n", strings.Join(plugins, ", "))
+	}
+
+	fmt.Fprintln(w, "\nExamples:")
+	for _, e := range RootExamples {
+		fmt.Fprintf(w, "  %s\n", e)
+	}
+	fmt.Fprint(w, `
+For command help:
+  trelli help cards
+  trelli cards --help
+`)
+}
+
+func PrintRootHelp() {
+	writeRootHelp(os.Stdout)
+}
+
+// CommandHelp returns a function printing help for the named command, as
+// expected by ParseFlagSet.
+func CommandHelp(name string) func() {
+	return func() { PrintCommandHelp(name) }
+}
+
+func PrintCommandHelp(cmd string) {
+	c, ok := FindCommand(cmd)
+	if !ok || !c.Documented() {
+		PrintRootHelp()
+		return
+	}
+	WriteCommandHelp(os.Stdout, c)
+}
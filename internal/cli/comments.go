@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// AddComment comments on a card and records the comment for undo.
+func AddComment(ctx context.Context, client *Client, cfg Config, cardID, text string) (CommentAction, error) {
+	created, err := client.Comments.Add(ctx, cardID, text)
+	if err != nil {
+		return CommentAction{}, err
+	}
+	RecordUndo(cfg, JournalEntry{Action: "comments.add", Target: created.ID, Summary: "comment on card " + cardID})
+	return created, nil
+}
+
+func CommentsRequest(cardID string, limit int, out *[]CommentAction) GetRequest {
+	query := url.Values{}
+	query.Set("filter", "commentCard")
+	query.Set("fields", "data,date,type")
+	query.Set("memberCreator_fields", "username,fullName")
+	query.Set("limit", fmt.Sprintf("%d", limit))
+	return GetRequest{Path: "/1/cards/" + url.PathEscape(cardID) + "/actions", Query: query, Out: out}
+}
+
+func CommentsTable(actions []CommentAction) Table {
+	t := Table{Columns: []string{"ID", "DATE", "AUTHOR", "COMMENT"}, Empty: "No comments found."}
+	for _, a := range actions {
+		author := strings.TrimSpace(FirstNonEmpty(a.MemberCreator.FullName, a.MemberCreator.Username))
+		t.Rows = append(t.Rows, []string{a.ID, a.Date, author, a.Data.Text})
+	}
+	return t
+}
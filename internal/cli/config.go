@@ -0,0 +1,428 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// configKey describes a supported config file setting. Keys are dotted paths
+// into the nested JSON document, e.g. "board.default".
+type configKey struct {
+	Name     string
+	Kind     string // "string", "bool", "int", or "scalar" (any of them)
+	Desc     string
+	Validate func(v any) error
+}
+
+var configKeys = []configKey{
+	{Name: "board.default", Kind: "string", Desc: "Default board id or shortLink"},
+	{Name: "output", Kind: "string", Desc: "Default output format: table|json|csv", Validate: oneOf("table", "json", "csv")},
+	{Name: "timeout", Kind: "string", Desc: "Per-request timeout, e.g. 60s", Validate: validDuration},
+	{Name: "max_retries", Kind: "int", Desc: "Retries for rate-limited (429) or transient failures (default 3, 0 disables)"},
+	{Name: "rate", Kind: "scalar", Desc: "Maximum requests per second (default 9, 0 disables pacing)", Validate: nonNegativeNumber},
+	{Name: "concurrency", Kind: "int", Desc: "Maximum concurrent requests for commands that fan out (default 4)"},
+	{Name: "cache.dir", Kind: "string", Desc: "Directory for cached names and responses (default <user cache dir>/trelli)"},
+	{Name: "cache.ttl", Kind: "string", Desc: "How long cached board/list/label/member names stay fresh (default 1h)", Validate: validDuration},
+	{Name: "base_url", Kind: "string", Desc: "API base URL (default https://api.trello.com)"},
+	{Name: "log_file", Kind: "string", Desc: "Append JSON request logs to this file"},
+	{Name: "log_format", Kind: "string", Desc: "Diagnostics format on stderr: plain|text|json", Validate: oneOf(LogFormatPlain, LogFormatText, LogFormatJSON)},
+	{Name: "proxy", Kind: "string", Desc: "HTTP(S) proxy URL; overrides HTTPS_PROXY/NO_PROXY"},
+	{Name: "tls.ca_cert", Kind: "string", Desc: "PEM file with extra trusted CA certificates"},
+	{Name: "tls.insecure_skip_verify", Kind: "bool", Desc: "Disable TLS certificate verification (unsafe)"},
+	{Name: "profile", Kind: "string", Desc: "Profile used when --profile/TRELLI_PROFILE is unset"},
+	{Name: "profiles.*.key", Kind: "string", Desc: "Trello API key for the profile"},
+	{Name: "profiles.*.token", Kind: "string", Desc: "Trello token for the profile"},
+	{Name: "profiles.*.board", Kind: "string", Desc: "Default board id or shortLink for the profile"},
+	{Name: "credentials.store", Kind: "string", Desc: "Where auth login stores credentials: keychain|none", Validate: oneOf("keychain", "none")},
+	{Name: "credentials.exec", Kind: "string", Desc: "Shell command printing the token, or key=/token= lines"},
+	{Name: "profiles.*.credentials.exec", Kind: "string", Desc: "credentials.exec override for the profile"},
+	{Name: "boards.aliases.*", Kind: "string", Desc: "Board id or shortLink accepted as --board <name>"},
+	{Name: "wip.*", Kind: "int", Desc: "WIP limit for lists with this name on any board: most open cards (lists wip, cards move)", Validate: positiveInt},
+	{Name: "boards.*.*", Kind: "scalar", Desc: "Default flag value for a boards subcommand, e.g. boards.list.filter"},
+	{Name: "lists.*.*", Kind: "scalar", Desc: "Default flag value for a lists subcommand"},
+	{Name: "cards.*.*", Kind: "scalar", Desc: "Default flag value for a cards subcommand, e.g. cards.list.limit"},
+	{Name: "comments.*.*", Kind: "scalar", Desc: "Default flag value for a comments subcommand"},
+	{Name: "checklists.*.*", Kind: "scalar", Desc: "Default flag value for a checklists subcommand"},
+	{Name: "import.*.*", Kind: "scalar", Desc: "Default flag value for an import subcommand, e.g. import.jira.url"},
+	{Name: "import.jira.fields.*", Kind: "string", Desc: "Jira CSV column or REST field id for key|summary|description|status|components|due"},
+	{Name: "import.jira.statuses.*", Kind: "string", Desc: "List name for a Jira status (default: the list named like the status)"},
+	{Name: "import.jira.components.*", Kind: "string", Desc: "Label name for a Jira component (default: the label named like it)"},
+}
+
+func oneOf(allowed ...string) func(v any) error {
+	return func(v any) error {
+		s, _ := v.(string)
+		for _, a := range allowed {
+			if s == a {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+	}
+}
+
+func nonNegativeNumber(v any) error {
+	f, err := strconv.ParseFloat(fmt.Sprint(v), 64)
+	if err != nil || f < 0 {
+		return fmt.Errorf("expected a non-negative number, got %v", v)
+	}
+	return nil
+}
+
+func positiveInt(v any) error {
+	if n, _ := v.(int); n < 1 {
+		return fmt.Errorf("expected a positive integer, got %v", v)
+	}
+	return nil
+}
+
+func validDuration(v any) error {
+	s, _ := v.(string)
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("expected a positive duration such as 60s, got %q", s)
+	}
+	return nil
+}
+
+// LookupConfigKey finds the key definition for name; a "*" segment in a
+// definition matches any single segment (e.g. a profile name).
+func LookupConfigKey(name string) (configKey, bool) {
+	parts := strings.Split(name, ".")
+	for _, k := range configKeys {
+		pattern := strings.Split(k.Name, ".")
+		if len(pattern) != len(parts) {
+			continue
+		}
+		match := true
+		for i := range pattern {
+			if parts[i] == "" || (pattern[i] != "*" && pattern[i] != parts[i]) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return k, true
+		}
+	}
+	return configKey{}, false
+}
+
+// FileConfig is the decoded config file: nested JSON objects keyed by the
+// segments of dotted config keys.
+type FileConfig map[string]any
+
+// configPath returns TRELLI_CONFIG or <user config dir>/trelli/config.json.
+func configPath() (string, error) {
+	if p := strings.TrimSpace(os.Getenv("TRELLI_CONFIG")); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "trelli", "config.json"), nil
+}
+
+func loadConfigFile(path string) (FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return FileConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	fc, err := ParseConfigFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	return fc, nil
+}
+
+func ParseConfigFile(data []byte) (FileConfig, error) {
+	fc := FileConfig{}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return fc, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&fc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if err := fc.validate(); err != nil {
+		return nil, err
+	}
+	return fc, nil
+}
+
+func SaveConfigFile(path string, fc FileConfig) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(fc, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (fc FileConfig) validate() error {
+	for key, value := range fc.Flatten() {
+		k, ok := LookupConfigKey(key)
+		if !ok {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		if _, err := CoerceConfigValue(k, value); err != nil {
+			return fmt.Errorf("config key %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// CoerceConfigValue converts raw (a CLI string or decoded JSON value) to the
+// key's kind and runs its validator.
+func CoerceConfigValue(k configKey, raw any) (any, error) {
+	var v any
+	switch k.Kind {
+	case "scalar":
+		switch t := raw.(type) {
+		case string, bool, json.Number:
+			v = t
+		default:
+			return nil, fmt.Errorf("expected a string, number, or boolean")
+		}
+	case "bool":
+		switch t := raw.(type) {
+		case bool:
+			v = t
+		case string:
+			b, err := strconv.ParseBool(t)
+			if err != nil {
+				return nil, fmt.Errorf("expected a boolean, got %q", t)
+			}
+			v = b
+		default:
+			return nil, fmt.Errorf("expected a boolean")
+		}
+	case "int":
+		var s string
+		switch t := raw.(type) {
+		case json.Number:
+			s = t.String()
+		case string:
+			s = t
+		default:
+			return nil, fmt.Errorf("expected an integer")
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer, got %q", s)
+		}
+		v = n
+	default:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string")
+		}
+		v = s
+	}
+	if k.Validate != nil {
+		if err := k.Validate(v); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// Get returns the leaf value at key.
+func (fc FileConfig) Get(key string) (any, bool) {
+	v, ok := fc.Lookup(key)
+	if _, isMap := v.(map[string]any); isMap {
+		return nil, false
+	}
+	return v, ok
+}
+
+// Lookup returns the value at key, which may be a nested object.
+func (fc FileConfig) Lookup(key string) (any, bool) {
+	parts := strings.Split(key, ".")
+	var cur any = map[string]any(fc)
+	for _, part := range parts {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		cur, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func (fc FileConfig) GetString(key string) string {
+	v, ok := fc.Get(key)
+	if !ok {
+		return ""
+	}
+	s, _ := v.(string)
+	return strings.TrimSpace(s)
+}
+
+// GetStringMap returns the string values of the object at key, e.g. the
+// import.jira.statuses mapping; other values are skipped.
+func (fc FileConfig) GetStringMap(key string) map[string]string {
+	out := map[string]string{}
+	m, _ := fc.Lookup(key)
+	values, _ := m.(map[string]any)
+	for k, v := range values {
+		if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
+			out[k] = strings.TrimSpace(s)
+		}
+	}
+	return out
+}
+
+func (fc FileConfig) Set(key string, value any) {
+	parts := strings.Split(key, ".")
+	m := map[string]any(fc)
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			m[part] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = value
+}
+
+// Unset removes key and prunes parent objects left empty.
+func (fc FileConfig) Unset(key string) bool {
+	return unsetPath(map[string]any(fc), strings.Split(key, "."))
+}
+
+func unsetPath(m map[string]any, parts []string) bool {
+	if len(parts) == 1 {
+		if _, ok := m[parts[0]]; !ok {
+			return false
+		}
+		delete(m, parts[0])
+		return true
+	}
+	child, ok := m[parts[0]].(map[string]any)
+	if !ok {
+		return false
+	}
+	removed := unsetPath(child, parts[1:])
+	if removed && len(child) == 0 {
+		delete(m, parts[0])
+	}
+	return removed
+}
+
+// Flatten returns all leaf values keyed by their dotted path.
+func (fc FileConfig) Flatten() map[string]any {
+	out := map[string]any{}
+	flattenInto(out, "", map[string]any(fc))
+	return out
+}
+
+func flattenInto(out map[string]any, prefix string, m map[string]any) {
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if child, ok := v.(map[string]any); ok {
+			flattenInto(out, key, child)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+// WithCommandDefaults prepends --flag=value pairs configured under
+// <command>.<subcommand>.<flag> to the subcommand's arguments, so explicit
+// flags given later on the command line override them.
+func (fc FileConfig) WithCommandDefaults(command string, args []string) []string {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return args
+	}
+	m, ok := fc.Lookup(command + "." + args[0])
+	if !ok {
+		return args
+	}
+	values, ok := m.(map[string]any)
+	if !ok {
+		return args
+	}
+	names := make([]string, 0, len(values))
+	for name, v := range values {
+		if _, isMap := v.(map[string]any); !isMap {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	out := make([]string, 0, len(args)+len(names))
+	out = append(out, args[0])
+	for _, name := range names {
+		out = append(out, "--"+name+"="+FormatConfigValue(values[name]))
+	}
+	return append(out, args[1:]...)
+}
+
+// ResolveBoardAlias maps a configured board alias to its id and a board
+// URL to its shortLink; other values are returned unchanged. A board given
+// as workspace/board-slug is resolved by the client when it is requested.
+func (fc FileConfig) ResolveBoardAlias(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if m := boardLinkPattern.FindStringSubmatch(ref); m != nil {
+		return m[1]
+	}
+	if ref == "" || strings.Contains(ref, ".") {
+		return ref
+	}
+	if id := fc.GetString("boards.aliases." + ref); id != "" {
+		return id
+	}
+	return ref
+}
+
+func FormatConfigValue(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case nil:
+		return ""
+	default:
+		data, _ := json.Marshal(t)
+		return string(data)
+	}
+}
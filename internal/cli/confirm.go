@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var errAborted = errors.New("aborted")
+
+// AddConfirmFlags registers --yes/-y and --force on a destructive
+// subcommand; they are equivalent to the global flags.
+func AddConfirmFlags(fs *flag.FlagSet, cfg *Config) {
+	fs.BoolVar(&cfg.Yes, "yes", cfg.Yes, "Skip the confirmation prompt")
+	fs.BoolVar(&cfg.Yes, "y", cfg.Yes, "Skip the confirmation prompt")
+	fs.BoolVar(&cfg.Force, "force", cfg.Force, "Proceed without a prompt when stdin is not a terminal")
+}
+
+// WillPrompt reports whether confirm will show its prompt, so callers can
+// skip fetching details that only the prompt lists.
+func WillPrompt(cfg Config) bool {
+	return !cfg.Yes && !cfg.DryRun && CanPrompt(cfg)
+}
+
+// CanPrompt reports whether answers can be read from stdin: it is a
+// terminal and not already carrying other input.
+func CanPrompt(cfg Config) bool {
+	return !cfg.StdinBusy && IsTerminal(os.Stdin)
+}
+
+// Confirm asks before a destructive operation, listing the affected targets.
+// --yes and --dry-run skip the prompt; without a terminal on stdin, or
+// while stdin carries an exec script, --force is required.
+func Confirm(cfg Config, action string, targets []string) error {
+	if cfg.Yes || cfg.DryRun {
+		return nil
+	}
+	if !CanPrompt(cfg) {
+		if cfg.Force {
+			return nil
+		}
+		if cfg.StdinBusy {
+			return fmt.Errorf("%s needs confirmation, which cannot be asked while the script is read from stdin: pass --yes or --force", action)
+		}
+		return fmt.Errorf("%s needs confirmation: pass --yes (or --force when stdin is not a terminal)", action)
+	}
+
+	fmt.Fprintf(os.Stderr, "About to %s:\n", action)
+	for _, t := range targets {
+		fmt.Fprintf(os.Stderr, "  %s\n", t)
+	}
+	// Read in the background so Ctrl-C, which cancels cfg.Context, also
+	// ends the prompt.
+	type reply struct {
+		answer string
+		err    error
+	}
+	replies := make(chan reply, 1)
+	go func() {
+		answer, err := Prompt(bufio.NewReader(os.Stdin), "Proceed? [y/N]: ", false)
+		replies <- reply{answer, err}
+	}()
+	ctx := cfg.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var r reply
+	select {
+	case r = <-replies:
+	case <-ctx.Done():
+		fmt.Fprintln(os.Stderr)
+		return ctx.Err()
+	}
+	if r.err != nil {
+		return r.err
+	}
+	switch strings.ToLower(r.answer) {
+	case "y", "yes":
+		return nil
+	}
+	return errAborted
+}
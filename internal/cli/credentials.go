@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const credentialService = "trelli"
+
+var ErrCredentialNotFound = errors.New("credential not found")
+
+// ErrNoCredentialStore is returned by NewCredentialStore when the platform
+// has no supported keychain, so nothing can have been stored in one.
+var ErrNoCredentialStore = errors.New("no OS keychain available")
+
+// credentialStore persists secrets outside of environment variables and the
+// config file.
+type credentialStore interface {
+	Name() string
+	Get(account string) (string, error)
+	Set(account, secret string) error
+	Delete(account string) error
+}
+
+// NewCredentialStore returns the OS keychain backend for this platform, or
+// an error when none is available.
+func NewCredentialStore() (credentialStore, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("security"); err == nil {
+			return macKeychain{}, nil
+		}
+	case "windows":
+		if _, err := exec.LookPath("powershell"); err == nil {
+			return windowsVault{}, nil
+		}
+	default:
+		if _, err := exec.LookPath("secret-tool"); err == nil {
+			return secretService{}, nil
+		}
+	}
+	if runtime.GOOS == "linux" {
+		return nil, fmt.Errorf("%w: install secret-tool (libsecret) for Secret Service support", ErrNoCredentialStore)
+	}
+	return nil, fmt.Errorf("%w on %s", ErrNoCredentialStore, runtime.GOOS)
+}
+
+// execCredentials runs a user-configured helper (e.g. "pass show trello")
+// through the shell. Its stdout is either a single line holding the token, or
+// key=<key> and token=<token> lines (TRELLO_API_KEY=/TRELLO_TOKEN= also work).
+func execCredentials(command string) (key, token string, err error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	// The helper may prompt (e.g. for a GPG passphrase).
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", "", fmt.Errorf("credentials.exec %q failed: %w", command, err)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 1 && !strings.Contains(lines[0], "=") {
+		return "", lines[0], nil
+	}
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "key", "trello_api_key":
+			key = strings.TrimSpace(value)
+		case "token", "trello_token":
+			token = strings.TrimSpace(value)
+		}
+	}
+	if key == "" && token == "" {
+		return "", "", fmt.Errorf("credentials.exec %q printed no key= or token= lines", command)
+	}
+	return key, token, nil
+}
+
+// CredentialAccount names the keychain entry for a profile's key or token.
+func CredentialAccount(profile, field string) string {
+	if profile == "" {
+		profile = "default"
+	}
+	return profile + ":" + field
+}
+
+func runCredentialCommand(stdin string, name string, args ...string) (string, error) {
+	stdout, stderr, err := credentialCommand(stdin, name, args...)
+	return stdout, commandError(name, err, stderr)
+}
+
+// credentialCommand runs a keychain tool and returns its trimmed output.
+func credentialCommand(stdin string, name string, args ...string) (stdout, stderr string, err error) {
+	cmd := exec.Command(name, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var out, errOut bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+	err = cmd.Run()
+	return strings.TrimSpace(out.String()), strings.TrimSpace(errOut.String()), err
+}
+
+func commandError(name string, err error, stderr string) error {
+	if err == nil {
+		return nil
+	}
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("%s: %s (%w)", name, msg, err)
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
+
+// macKeychain uses the macOS `security` tool. Secrets are passed through
+// interactive mode on stdin so they never appear in the process list.
+type macKeychain struct{}
+
+func (macKeychain) Name() string { return "macOS Keychain" }
+
+func (macKeychain) Get(account string) (string, error) {
+	out, err := runCredentialCommand("", "security", "find-generic-password", "-s", credentialService, "-a", account, "-w")
+	if err != nil {
+		if strings.Contains(err.Error(), "could not be found") {
+			return "", ErrCredentialNotFound
+		}
+		return "", err
+	}
+	return out, nil
+}
+
+func (macKeychain) Set(account, secret string) error {
+	line := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", shellQuote(credentialService), shellQuote(account), shellQuote(secret))
+	_, err := runCredentialCommand(line, "security", "-i")
+	return err
+}
+
+func (macKeychain) Delete(account string) error {
+	_, err := runCredentialCommand("", "security", "delete-generic-password", "-s", credentialService, "-a", account)
+	if err != nil && strings.Contains(err.Error(), "could not be found") {
+		return ErrCredentialNotFound
+	}
+	return err
+}
+
+// secretService uses libsecret's `secret-tool` (GNOME Keyring, KWallet).
+type secretService struct{}
+
+func (secretService) Name() string { return "Secret Service" }
+
+// Get treats a lookup that fails silently or prints nothing as not found:
+// secret-tool exits 1 without a message when nothing matches. Anything it
+// says on failure, such as a locked keyring or no D-Bus session, is an error.
+func (secretService) Get(account string) (string, error) {
+	out, stderr, err := credentialCommand("", "secret-tool", "lookup", "service", credentialService, "account", account)
+	var exitErr *exec.ExitError
+	if (errors.As(err, &exitErr) && stderr == "") || (err == nil && out == "") {
+		return "", ErrCredentialNotFound
+	}
+	if err != nil {
+		return "", commandError("secret-tool", err, stderr)
+	}
+	return out, nil
+}
+
+func (secretService) Set(account, secret string) error {
+	_, err := runCredentialCommand(secret, "secret-tool", "store", "--label=trelli "+account, "service", credentialService, "account", account)
+	return err
+}
+
+// Delete looks the secret up first, since secret-tool clear succeeds even
+// when nothing matches.
+func (s secretService) Delete(account string) error {
+	if _, err := s.Get(account); err != nil {
+		return err
+	}
+	_, err := runCredentialCommand("", "secret-tool", "clear", "service", credentialService, "account", account)
+	return err
+}
+
+// windowsVault uses the Windows Credential Manager via PasswordVault.
+type windowsVault struct{}
+
+const windowsVaultPrelude = `$ErrorActionPreference = 'Stop'; [void][Windows.Security.Credentials.PasswordVault,Windows.Security.Credentials,ContentType=WindowsRuntime]; $v = New-Object Windows.Security.Credentials.PasswordVault; `
+
+func (windowsVault) Name() string { return "Windows Credential Manager" }
+
+func (windowsVault) Get(account string) (string, error) {
+	script := windowsVaultPrelude + fmt.Sprintf(`try { $c = $v.Retrieve(%s, %s) } catch { exit 44 }; $c.RetrievePassword(); $c.Password`, powerShellQuote(credentialService), powerShellQuote(account))
+	out, err := runCredentialCommand("", "powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+		return "", ErrCredentialNotFound
+	}
+	return out, err
+}
+
+func (windowsVault) Set(account, secret string) error {
+	script := windowsVaultPrelude + fmt.Sprintf(`$s = [Console]::In.ReadToEnd().Trim(); $v.Add((New-Object Windows.Security.Credentials.PasswordCredential(%s, %s, $s)))`, powerShellQuote(credentialService), powerShellQuote(account))
+	_, err := runCredentialCommand(secret, "powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	return err
+}
+
+func (windowsVault) Delete(account string) error {
+	script := windowsVaultPrelude + fmt.Sprintf(`try { $c = $v.Retrieve(%s, %s) } catch { exit 44 }; $v.Remove($c)`, powerShellQuote(credentialService), powerShellQuote(account))
+	_, err := runCredentialCommand("", "powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+		return ErrCredentialNotFound
+	}
+	return err
+}
+
+// powerShellQuote makes s a PowerShell single-quoted string, in which only
+// quote characters need escaping, by doubling. PowerShell also closes such
+// strings on the typographic single quotes, so those are doubled as well.
+func powerShellQuote(s string) string {
+	return "'" + strings.NewReplacer("'", "''", "\u2018", "\u2018\u2018", "\u2019", "\u2019\u2019", "\u201a", "\u201a\u201a", "\u201b", "\u201b\u201b").Replace(s) + "'"
+}
+
+func shellQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
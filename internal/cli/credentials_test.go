@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeSecretTool is a secret-tool that keeps one secret per account in
+// files, and like the real one exits 1 silently when a lookup finds
+// nothing and 0 when clear does. With $SECRETS_LOCKED set it fails as the
+// real one does on a locked keyring.
+const fakeSecretTool = `#!/bin/sh
+for a; do f="$SECRETS/$a"; done
+if [ -n "$SECRETS_LOCKED" ]; then echo "Cannot get secret of a locked object" >&2; exit 1; fi
+case "$1" in
+store) cat > "$f" ;;
+lookup) [ -f "$f" ] || exit 1; cat "$f" ;;
+clear) rm -f "$f" ;;
+esac
+`
+
+// withFakeSecretTool puts fakeSecretTool first on PATH.
+func withFakeSecretTool(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "secret-tool"), []byte(fakeSecretTool), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("SECRETS", dir)
+}
+
+func TestSecretServiceNotFound(t *testing.T) {
+	withFakeSecretTool(t)
+
+	store := secretService{}
+	if _, err := store.Get("default.token"); !errors.Is(err, ErrCredentialNotFound) {
+		t.Errorf("Get of a missing secret: %v", err)
+	}
+	if err := store.Delete("default.token"); !errors.Is(err, ErrCredentialNotFound) {
+		t.Errorf("Delete of a missing secret: %v", err)
+	}
+	if err := store.Set("default.token", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.Get("default.token"); err != nil || got != "s3cret" {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+	if err := store.Delete("default.token"); err != nil {
+		t.Errorf("Delete: %v", err)
+	}
+	if _, err := store.Get("default.token"); !errors.Is(err, ErrCredentialNotFound) {
+		t.Errorf("Get after Delete: %v", err)
+	}
+}
+
+func TestSecretServiceLocked(t *testing.T) {
+	withFakeSecretTool(t)
+	t.Setenv("SECRETS_LOCKED", "1")
+
+	_, err := secretService{}.Get("default:token")
+	if err == nil || errors.Is(err, ErrCredentialNotFound) || !strings.Contains(err.Error(), "locked object") {
+		t.Errorf("Get from a locked keyring: %v", err)
+	}
+	if runtime.GOOS == "darwin" {
+		return
+	}
+	cfg := Config{File: FileConfig{}}
+	if err := LoadStoredCredentials(&cfg); err == nil || !strings.Contains(err.Error(), "reading the key from Secret Service") {
+		t.Errorf("LoadStoredCredentials from a locked keyring: %v", err)
+	}
+}
+
+func TestPowerShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"default":          `'default'`,
+		"o'brien":          `'o''brien'`,
+		"x'); Remove-Item": `'x''); Remove-Item'`,
+		"it\u2019s":        "'it\u2019\u2019s'",
+		"$env:TRELLO":      `'$env:TRELLO'`,
+	}
+	for in, want := range tests {
+		if got := powerShellQuote(in); got != want {
+			t.Errorf("powerShellQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"trelli/pkg/trello"
+)
+
+// exitTokenInvalid is the exit status for a TokenError, so scripts can tell
+// "log in again" apart from other failures.
+const exitTokenInvalid = 3
+
+// exitCheckFailed is the exit status of a check that found something, so
+// cron jobs and CI can tell it apart from a check that could not run.
+const exitCheckFailed = 2
+
+// CheckFailedError is returned by a check that found something; main
+// exits with exitCheckFailed.
+type CheckFailedError struct {
+	Msg string
+}
+
+func (e *CheckFailedError) Error() string { return e.Msg }
+
+// APIError and TokenError are the trello package's error types.
+type (
+	APIError   = trello.APIError
+	TokenError = trello.TokenError
+)
+
+// tokenLoginMessage is printed for a TokenError.
+const tokenLoginMessage = "token expired or revoked — run `trelli auth login`"
+
+type errorPayload struct {
+	Status  int    `json:"status,omitempty"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+// errorHint suggests a next step for well-known failure causes.
+func errorHint(err error) string {
+	var tokenErr *TokenError
+	var rateErr *trello.RateLimitError
+	switch {
+	case errors.As(err, &tokenErr):
+		return "run trelli auth login, or set a new TRELLO_TOKEN (or --token)"
+	case errors.Is(err, trello.ErrUnauthorized):
+		return "check TRELLO_API_KEY and TRELLO_TOKEN (or --key/--token)"
+	case errors.Is(err, trello.ErrForbidden):
+		return "the token lacks permission for this resource"
+	case errors.Is(err, trello.ErrNotFound):
+		return "check that the id or shortLink exists and is visible to you"
+	case errors.As(err, &rateErr):
+		if rateErr.RetryAfter > 0 {
+			return fmt.Sprintf("rate limited by Trello; retry in %s", rateErr.RetryAfter.Round(time.Second))
+		}
+		return "rate limited by Trello; wait and retry"
+	case errors.Is(err, trello.ErrServer):
+		return "Trello server error; retry later"
+	}
+	return ""
+}
+
+func WriteError(w io.Writer, err error, asJSON bool) {
+	message := err.Error()
+	var tokenErr *TokenError
+	if errors.As(err, &tokenErr) {
+		// The library's message cannot name the CLI's remedy.
+		message = tokenLoginMessage
+	}
+	if !asJSON {
+		fmt.Fprintln(w, message)
+		return
+	}
+	enc := json.NewEncoder(w)
+	_ = enc.Encode(map[string]errorPayload{"error": NewErrorPayload(err)})
+}
+
+// NewErrorPayload describes err for machine readers: the API's own message
+// and status when there is one, and a hint.
+func NewErrorPayload(err error) errorPayload {
+	payload := errorPayload{Message: err.Error(), Hint: errorHint(err)}
+	var tokenErr *TokenError
+	if errors.As(err, &tokenErr) {
+		payload.Message = tokenLoginMessage
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		payload.Status = apiErr.Status
+		if apiErr.Message != "" && tokenErr == nil {
+			payload.Message = apiErr.Message
+		}
+	}
+	return payload
+}
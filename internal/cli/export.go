@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"time"
+)
+
+// ExportWindow is how many cards are fetched between checkpoints. Each card
+// needs two routes, so a window fills several concurrent /1/batch calls.
+const ExportWindow = 50
+
+// BoardExport is the document written by boards export.
+type BoardExport struct {
+	Board    Board          `json:"board"`
+	Lists    []TrelloList   `json:"lists"`
+	Labels   []Label        `json:"labels"`
+	Cards    []ExportedCard `json:"cards"`
+	Exported time.Time      `json:"exported"`
+}
+
+type ExportedCard struct {
+	Card
+	IDLabels    []string        `json:"idLabels"`
+	DueComplete bool            `json:"dueComplete"`
+	Pos         float64         `json:"pos"`
+	Checklists  []Checklist     `json:"checklists"`
+	Comments    []CommentAction `json:"comments"`
+}
+
+// exportState is the checkpoint kept next to the output while an export
+// runs: the board skeleton, every card to export, and how many of them
+// (in order) are complete in Export.Cards.
+type exportState struct {
+	Export  BoardExport    `json:"export"`
+	Pending []ExportedCard `json:"pending"`
+}
+
+func ExportCheckpointPath(out string) string {
+	return out + ".partial"
+}
+
+// ExportBoard writes boardID with every card's checklists and comments to
+// out, checkpointing after each window of cards. With resume it continues
+// from the checkpoint left by an interrupted run.
+func ExportBoard(ctx context.Context, client *Client, boardID, out string, resume bool) (BoardExport, error) {
+	checkpoint := ExportCheckpointPath(out)
+	var state exportState
+	data, err := os.ReadFile(checkpoint)
+	switch {
+	case err == nil && !resume:
+		return BoardExport{}, fmt.Errorf("an interrupted export exists at %s: pass --resume to continue it or delete it", checkpoint)
+	case err == nil:
+		if err := json.Unmarshal(data, &state); err != nil {
+			return BoardExport{}, fmt.Errorf("reading %s: %w", checkpoint, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return BoardExport{}, err
+	case resume:
+		return BoardExport{}, fmt.Errorf("nothing to resume: %s does not exist", checkpoint)
+	default:
+		if state, err = StartExport(ctx, client, boardID); err != nil {
+			return BoardExport{}, err
+		}
+		if err := WriteJSONFile(checkpoint, state); err != nil {
+			return BoardExport{}, err
+		}
+	}
+
+	total := len(state.Export.Cards) + len(state.Pending)
+	client.Progress.SetTask("Exporting cards", total)
+	defer client.Progress.EndTask()
+	client.Progress.Advance(len(state.Export.Cards))
+	for len(state.Pending) > 0 {
+		window := state.Pending[:min(ExportWindow, len(state.Pending))]
+		cards, err := FetchCardDetails(ctx, client, window)
+		if err != nil {
+			return BoardExport{}, fmt.Errorf("%w (progress saved: %d of %d cards; rerun with --resume)", err, len(state.Export.Cards), total)
+		}
+		state.Export.Cards = append(state.Export.Cards, cards...)
+		state.Pending = state.Pending[len(window):]
+		client.Progress.Advance(len(cards))
+		if err := WriteJSONFile(checkpoint, state); err != nil {
+			return BoardExport{}, err
+		}
+	}
+
+	state.Export.Exported = time.Now().UTC()
+	if err := WriteJSONFile(out, state.Export); err != nil {
+		return BoardExport{}, err
+	}
+	return state.Export, os.Remove(checkpoint)
+}
+
+// FetchCardDetails returns a copy of cards with their checklists and
+// comments.
+func FetchCardDetails(ctx context.Context, client *Client, window []ExportedCard) ([]ExportedCard, error) {
+	cards := slices.Clone(window)
+	reqs := make([]GetRequest, 0, 2*len(cards))
+	for i, c := range cards {
+		reqs = append(reqs,
+			ChecklistsRequest(c.ID, &cards[i].Checklists),
+			CommentsRequest(c.ID, CommentPageSize, &cards[i].Comments))
+	}
+	if err := client.GetAll(ctx, reqs...); err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+// StartExport fetches the board skeleton and the list of cards to export.
+func StartExport(ctx context.Context, client *Client, boardID string) (exportState, error) {
+	var state exportState
+	if err := client.GetAll(ctx,
+		BoardRequest(boardID, &state.Export.Board),
+		BoardListsRequest(boardID, &state.Export.Lists),
+		BoardLabelsRequest(boardID, &state.Export.Labels),
+	); err != nil {
+		return state, err
+	}
+	query := url.Values{}
+	query.Set("fields", CardFields(Config{})+",idLabels,dueComplete,pos")
+	err := StreamArray(ctx, client, "/1/boards/"+url.PathEscape(boardID)+"/cards", query, func(c ExportedCard) error {
+		state.Pending = append(state.Pending, c)
+		return nil
+	})
+	state.Export.Cards = []ExportedCard{}
+	return state, err
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+func ShouldSkipAuthForHelp(args []string) bool {
+	if len(args) == 0 {
+		return true
+	}
+	first := strings.TrimSpace(strings.ToLower(args[0]))
+	if first == "help" || first == "-h" || first == "--help" {
+		return true
+	}
+	for _, a := range args {
+		if a == "-h" || a == "--help" {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseFlagSet parses args, allowing flags and positional arguments to be
+// interspersed; positionals are available via fs.Args() afterwards.
+func ParseFlagSet(fs *flag.FlagSet, args []string, helpFn func()) error {
+	if err := fs.Parse(flagsFirst(fs, args)); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			helpFn()
+			return ErrHelpDisplayed
+		}
+		return err
+	}
+	return nil
+}
+
+// flagsFirst moves positional arguments after all flags so the standard
+// flag package (which stops at the first positional) sees every flag.
+func flagsFirst(fs *flag.FlagSet, args []string) []string {
+	var flags, positional []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(a, "-") || a == "-" {
+			positional = append(positional, a)
+			continue
+		}
+		flags = append(flags, a)
+		name := strings.TrimLeft(a, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		f := fs.Lookup(name)
+		if f == nil {
+			continue
+		}
+		if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() {
+			continue
+		}
+		if i+1 < len(args) {
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+	if len(positional) == 0 {
+		return flags
+	}
+	return append(append(flags, "--"), positional...)
+}
+
+// TakePositional fills each empty destination, in order, from the positional
+// arguments left after flag parsing, e.g. `cards show <cardId>`.
+func TakePositional(fs *flag.FlagSet, dsts ...*string) error {
+	args := fs.Args()
+	for _, d := range dsts {
+		if len(args) == 0 {
+			break
+		}
+		if strings.TrimSpace(*d) == "" {
+			*d = args[0]
+			args = args[1:]
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("%s: unexpected argument %q", fs.Name(), args[0])
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// GrepFields are the parts of a card grep can search, in output order.
+var GrepFields = []string{"name", "desc", "comments", "checklists"}
+
+// grepSnippetContext is how many characters around a match a snippet keeps.
+const grepSnippetContext = 40
+
+// grepMatch is one line of a card that matches.
+type grepMatch struct {
+	Field  string `json:"field"`
+	Author string `json:"author,omitempty"`
+	Date   string `json:"date,omitempty"`
+	Text   string `json:"text"`
+}
+
+// GrepResult is a card with its matching lines.
+type GrepResult struct {
+	ID      string      `json:"id"`
+	Name    string      `json:"name"`
+	List    string      `json:"list"`
+	URL     string      `json:"url"`
+	Matches []grepMatch `json:"matches"`
+}
+
+// GrepChecklist is a board checklist with the card it is on.
+type GrepChecklist struct {
+	Checklist
+	IDCard string `json:"idCard"`
+}
+
+// ParseGrepFields reads --in.
+func ParseGrepFields(in string) ([]string, error) {
+	fields := SplitIDs(in)
+	for _, f := range fields {
+		if !slices.Contains(GrepFields, f) {
+			return nil, fmt.Errorf("invalid --in %q: want a comma-separated list of %s", f, strings.Join(GrepFields, ", "))
+		}
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("--in needs at least one field")
+	}
+	return fields, nil
+}
+
+// GrepPattern compiles the pattern, quoting it unless it is a regular
+// expression.
+func GrepPattern(pattern string, isRegex, caseSensitive bool) (*regexp.Regexp, error) {
+	if !isRegex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if !caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return re, nil
+}
+
+// GrepBoard downloads the fields of the board's open cards and returns the
+// cards with lines matching re, in board order.
+func GrepBoard(ctx context.Context, client *Client, boardID string, re *regexp.Regexp, fields []string) ([]GrepResult, error) {
+	var cards []Card
+	var lists []TrelloList
+	var checklists []GrepChecklist
+	cardQuery := url.Values{}
+	cardQuery.Set("filter", "open")
+	cardQuery.Set("fields", "id,name,desc,idList,shortUrl,closed")
+	reqs := []GetRequest{
+		{Path: "/1/boards/" + url.PathEscape(boardID) + "/cards", Query: cardQuery, Out: &cards},
+		BoardListsRequest(boardID, &lists),
+	}
+	if slices.Contains(fields, "checklists") {
+		query := url.Values{}
+		query.Set("fields", "id,name,idCard")
+		query.Set("checkItem_fields", "name,state")
+		reqs = append(reqs, GetRequest{Path: "/1/boards/" + url.PathEscape(boardID) + "/checklists", Query: query, Out: &checklists})
+	}
+	if err := client.GetAll(ctx, reqs...); err != nil {
+		return nil, err
+	}
+	var comments []NotifyAction
+	if slices.Contains(fields, "comments") {
+		var err error
+		if comments, err = fetchBoardComments(ctx, client, boardID); err != nil {
+			return nil, err
+		}
+	}
+
+	listNames := map[string]string{}
+	for _, l := range lists {
+		listNames[l.ID] = l.Name
+	}
+	matches := map[string][]grepMatch{}
+	add := func(cardID string, m grepMatch, text string) {
+		for _, line := range strings.Split(text, "\n") {
+			if loc := re.FindStringIndex(line); loc != nil {
+				m.Text = grepSnippet(line, loc)
+				matches[cardID] = append(matches[cardID], m)
+			}
+		}
+	}
+	for _, f := range GrepFields {
+		if !slices.Contains(fields, f) {
+			continue
+		}
+		switch f {
+		case "name":
+			for _, c := range cards {
+				add(c.ID, grepMatch{Field: "name"}, c.Name)
+			}
+		case "desc":
+			for _, c := range cards {
+				add(c.ID, grepMatch{Field: "desc"}, c.Desc)
+			}
+		case "comments":
+			// Oldest first, as they read on the card.
+			for i := len(comments) - 1; i >= 0; i-- {
+				a := comments[i]
+				if a.Data.Card == nil {
+					continue
+				}
+				author := strings.TrimSpace(FirstNonEmpty(a.MemberCreator.FullName, a.MemberCreator.Username))
+				add(a.Data.Card.ID, grepMatch{Field: "comment", Author: author, Date: a.Date}, a.Data.Text)
+			}
+		case "checklists":
+			for _, cl := range checklists {
+				add(cl.IDCard, grepMatch{Field: "checklist"}, cl.Name)
+				for _, item := range cl.CheckItems {
+					add(cl.IDCard, grepMatch{Field: "checklist"}, item.Name)
+				}
+			}
+		}
+	}
+
+	results := []GrepResult{}
+	for _, c := range cards {
+		if c.Closed || len(matches[c.ID]) == 0 {
+			continue
+		}
+		results = append(results, GrepResult{
+			ID:      c.ID,
+			Name:    c.Name,
+			List:    FirstNonEmpty(listNames[c.IDList], c.IDList),
+			URL:     FirstNonEmpty(c.ShortURL, c.URL),
+			Matches: matches[c.ID],
+		})
+	}
+	return results, nil
+}
+
+// fetchBoardComments pages through every comment on the board, newest
+// first.
+func fetchBoardComments(ctx context.Context, client *Client, boardID string) ([]NotifyAction, error) {
+	var all []NotifyAction
+	before := ""
+	for {
+		query := url.Values{}
+		query.Set("filter", "commentCard")
+		query.Set("limit", fmt.Sprint(ActionPageSize))
+		query.Set("fields", "id,type,date,data")
+		query.Set("memberCreator_fields", "username,fullName")
+		if before != "" {
+			query.Set("before", before)
+		}
+		var page []NotifyAction
+		if err := client.Do(ctx, http.MethodGet, "/1/boards/"+url.PathEscape(boardID)+"/actions", query, nil, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < ActionPageSize {
+			return all, nil
+		}
+		before = page[len(page)-1].ID
+	}
+}
+
+// grepSnippet shortens line to the match at loc with some context on
+// either side.
+func grepSnippet(line string, loc []int) string {
+	start, end := loc[0]-grepSnippetContext, loc[1]+grepSnippetContext
+	prefix, suffix := "…", "…"
+	if start <= 0 {
+		start, prefix = 0, ""
+	}
+	if end >= len(line) {
+		end, suffix = len(line), ""
+	}
+	// Keep multi-byte characters whole.
+	for start > 0 && !utf8.RuneStart(line[start]) {
+		start--
+	}
+	for end < len(line) && !utf8.RuneStart(line[end]) {
+		end++
+	}
+	return prefix + strings.TrimSpace(line[start:end]) + suffix
+}
+
+// PrintGrepResults writes each card followed by its matching lines, the
+// matches highlighted on a terminal.
+func PrintGrepResults(w io.Writer, results []GrepResult, re *regexp.Regexp, mode markdownMode) {
+	if len(results) == 0 {
+		fmt.Fprintln(w, "No matches.")
+		return
+	}
+	for i, r := range results {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, strings.TrimSpace(fmt.Sprintf("%s  %s  (%s)  %s", r.ID, mode.style("1", "22", r.Name), r.List, r.URL)))
+		for _, m := range r.Matches {
+			label := m.Field
+			if m.Author != "" {
+				label += " by " + m.Author + ", " + DateOnly(m.Date)
+			}
+			text := re.ReplaceAllStringFunc(m.Text, func(s string) string { return mode.style("1;31", "22;39", s) })
+			fmt.Fprintf(w, "  %s: %s\n", mode.style("2", "22", label), text)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGrepSnippet(t *testing.T) {
+	line := strings.Repeat("a", 50) + "MATCH" + strings.Repeat("é", 50)
+	loc := []int{50, 55}
+	got := grepSnippet(line, loc)
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || !strings.Contains(got, "MATCH") {
+		t.Errorf("grepSnippet = %q", got)
+	}
+	if got := grepSnippet("short MATCH", []int{6, 11}); got != "short MATCH" {
+		t.Errorf("grepSnippet = %q", got)
+	}
+}
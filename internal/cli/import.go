@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ImportItem is an issue from another tracker, ready to become a card.
+// Link, when set, is attached to the card and pairs the two on later
+// imports.
+type ImportItem struct {
+	Key         string
+	Name        string
+	Desc        string
+	List        string
+	Labels      []string
+	Due         string
+	DueComplete bool
+	Link        string
+	Checklists  []Checklist
+	Comments    []string
+}
+
+// ImportLayout is what an import wants on the board beyond what its items
+// use: lists and labels, created in order even when unused, and the colors
+// of labels.
+type ImportLayout struct {
+	Lists  []string
+	Labels []Label
+}
+
+// ImportStep is one change an import makes, or under --dry-run would make.
+type ImportStep struct {
+	Action string   `json:"action"`
+	Issue  string   `json:"issue,omitempty"`
+	Name   string   `json:"name"`
+	List   string   `json:"list,omitempty"`
+	Labels []string `json:"labels,omitempty"`
+	Card   string   `json:"card,omitempty"`
+	// Checklists and Comments count what is added to a created card.
+	Checklists int `json:"checklists,omitempty"`
+	Comments   int `json:"comments,omitempty"`
+}
+
+func ImportTable(steps []ImportStep) Table {
+	t := Table{Columns: []string{"ACTION", "ISSUE", "NAME", "LIST", "LABELS", "CARD"}, Empty: "Nothing to import."}
+	for _, s := range steps {
+		t.Rows = append(t.Rows, []string{s.Action, s.Issue, s.Name, s.List, strings.Join(s.Labels, ","), s.Card})
+	}
+	return t
+}
+
+// ImportItems creates the missing lists and labels of layout and items on
+// a board, then a card per item not imported before. Under --dry-run it
+// only returns the plan.
+func ImportItems(ctx context.Context, client *Client, cfg Config, boardID string, layout ImportLayout, items []ImportItem) ([]ImportStep, error) {
+	lists, err := FetchBoardLists(ctx, client, boardID)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := FetchBoardLabels(ctx, client, boardID)
+	if err != nil {
+		return nil, err
+	}
+	// Cards already imported carry the item's link as an attachment.
+	imported := map[string]string{}
+	if slices.ContainsFunc(items, func(item ImportItem) bool { return item.Link != "" }) {
+		cards, err := FetchLinkedCards(ctx, client, boardID)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range cards {
+			for _, a := range c.Attachments {
+				imported[a.URL] = c.ID
+			}
+		}
+	}
+
+	// Names are matched case-insensitively; the keys are lower case.
+	listIDs := map[string]string{}
+	for _, l := range lists {
+		if _, ok := listIDs[strings.ToLower(l.Name)]; !ok {
+			listIDs[strings.ToLower(l.Name)] = l.ID
+		}
+	}
+	labelIDs := map[string]string{}
+	for _, l := range labels {
+		if _, ok := labelIDs[strings.ToLower(l.Name)]; !ok && l.Name != "" {
+			labelIDs[strings.ToLower(l.Name)] = l.ID
+		}
+	}
+
+	var plan, cards []ImportStep
+	for _, name := range layout.Lists {
+		if _, ok := listIDs[strings.ToLower(name)]; !ok {
+			listIDs[strings.ToLower(name)] = ""
+			plan = append(plan, ImportStep{Action: "create list", Name: name})
+		}
+	}
+	colors := map[string]string{}
+	for _, l := range layout.Labels {
+		colors[strings.ToLower(l.Name)] = l.Color
+		if _, ok := labelIDs[strings.ToLower(l.Name)]; !ok {
+			labelIDs[strings.ToLower(l.Name)] = ""
+			plan = append(plan, ImportStep{Action: "create label", Name: l.Name})
+		}
+	}
+	for _, item := range items {
+		if id, ok := imported[item.Link]; ok && item.Link != "" {
+			cards = append(cards, ImportStep{Action: "skip (imported)", Issue: item.Key, Name: item.Name, List: item.List, Card: id})
+			continue
+		}
+		if _, ok := listIDs[strings.ToLower(item.List)]; !ok {
+			listIDs[strings.ToLower(item.List)] = ""
+			plan = append(plan, ImportStep{Action: "create list", Name: item.List})
+		}
+		for _, name := range item.Labels {
+			if _, ok := labelIDs[strings.ToLower(name)]; !ok {
+				labelIDs[strings.ToLower(name)] = ""
+				plan = append(plan, ImportStep{Action: "create label", Name: name})
+			}
+		}
+		cards = append(cards, ImportStep{Action: "create card", Issue: item.Key, Name: item.Name, List: item.List, Labels: item.Labels, Checklists: len(item.Checklists), Comments: len(item.Comments)})
+	}
+	if cfg.DryRun {
+		return append(plan, cards...), nil
+	}
+
+	for _, step := range plan {
+		switch step.Action {
+		case "create list":
+			l, err := client.Lists.Create(ctx, boardID, step.Name)
+			if err != nil {
+				return nil, fmt.Errorf("creating list %q: %w", step.Name, err)
+			}
+			listIDs[strings.ToLower(step.Name)] = l.ID
+		case "create label":
+			l, err := client.Boards.CreateLabel(ctx, boardID, step.Name, colors[strings.ToLower(step.Name)])
+			if err != nil {
+				return nil, fmt.Errorf("creating label %q: %w", step.Name, err)
+			}
+			labelIDs[strings.ToLower(step.Name)] = l.ID
+		}
+	}
+	creates := 0
+	for _, step := range cards {
+		if step.Action == "create card" {
+			creates++
+		}
+	}
+	client.Progress.SetTask("Importing cards", creates)
+	defer client.Progress.EndTask()
+	n := 0
+	for i, item := range items {
+		step := &cards[i]
+		if step.Action != "create card" {
+			continue
+		}
+		var ids []string
+		for _, name := range item.Labels {
+			ids = append(ids, labelIDs[strings.ToLower(name)])
+		}
+		card, err := CreateCard(ctx, client, cfg, CardDraft{
+			IDList:   listIDs[strings.ToLower(item.List)],
+			Name:     item.Name,
+			Desc:     item.Desc,
+			Due:      item.Due,
+			IDLabels: ids,
+		})
+		if err == nil {
+			err = fillImportedCard(ctx, client, card.ID, item)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("importing %s: %w (%d cards imported before it)", FirstNonEmpty(item.Key, item.Name), err, n)
+		}
+		step.Card = card.ID
+		n++
+		client.Progress.Advance(1)
+	}
+	return append(plan, cards...), nil
+}
+
+// fillImportedCard adds what a card create cannot set: the due date's
+// completion, checklists, comments, and last the link that marks the item
+// imported, so a card that failed half way is imported again.
+func fillImportedCard(ctx context.Context, client *Client, cardID string, item ImportItem) error {
+	if item.DueComplete {
+		if _, err := client.Cards.Update(ctx, cardID, url.Values{"dueComplete": {"true"}}); err != nil {
+			return err
+		}
+	}
+	for _, cl := range item.Checklists {
+		checklist, err := client.Checklists.Create(ctx, cardID, cl.Name)
+		if err != nil {
+			return err
+		}
+		for _, it := range cl.CheckItems {
+			if _, err := client.Checklists.AddItem(ctx, checklist.ID, it.Name, it.State == "complete"); err != nil {
+				return err
+			}
+		}
+	}
+	for _, text := range item.Comments {
+		if _, err := client.Comments.Add(ctx, cardID, text); err != nil {
+			return err
+		}
+	}
+	if item.Link != "" {
+		if _, err := client.Cards.AttachURL(ctx, cardID, item.Link, item.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// LabelName is how prompts and messages show a label: its name in quotes,
+// or its color for a label without one.
+func LabelName(l Label) string {
+	if l.Name == "" {
+		return l.Color
+	}
+	return fmt.Sprintf("%q", l.Name)
+}
+
+// trelloIDPattern matches Trello object ids: 24 hex digits.
+var trelloIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{24}$`)
+
+// HasNames reports whether any of values is a name, color, or username
+// to look up rather than an id.
+func HasNames(values []string) bool {
+	return slices.ContainsFunc(values, func(v string) bool { return !trelloIDPattern.MatchString(v) })
+}
+
+// MatchLabel finds one of the board's labels by id, name, or color.
+func MatchLabel(board []Label, s string) (string, error) {
+	if trelloIDPattern.MatchString(s) {
+		return s, nil
+	}
+	var byColor []Label
+	for _, l := range board {
+		if l.ID == s || strings.EqualFold(l.Name, s) {
+			return l.ID, nil
+		}
+		if strings.EqualFold(l.Color, s) {
+			byColor = append(byColor, l)
+		}
+	}
+	switch len(byColor) {
+	case 0:
+		return "", fmt.Errorf("no label named or colored %q", s)
+	case 1:
+		return byColor[0].ID, nil
+	}
+	if i := slices.IndexFunc(byColor, func(l Label) bool { return l.Name == "" }); i >= 0 {
+		return byColor[i].ID, nil
+	}
+	names := make([]string, len(byColor))
+	for i, l := range byColor {
+		names[i] = fmt.Sprintf("%q", l.Name)
+	}
+	return "", fmt.Errorf("color %q is ambiguous: %s; use a name", s, strings.Join(names, ", "))
+}
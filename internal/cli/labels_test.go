@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestMatchLabel(t *testing.T) {
+	board := []Label{
+		{ID: "lb1", Name: "Bug", Color: "red"},
+		{ID: "lb2", Name: "Urgent", Color: "red"},
+		{ID: "lb3", Name: "", Color: "green"},
+		{ID: "lb4", Name: "Feature", Color: "green"},
+		{ID: "lb5", Name: "orange", Color: "purple"},
+	}
+	for in, want := range map[string]string{
+		"bug":                      "lb1",
+		"lb2":                      "lb2",
+		"green":                    "lb3", // the unnamed one
+		"orange":                   "lb5", // names win over colors
+		"5f0c8a1b2c3d4e5f6a7b8c9d": "5f0c8a1b2c3d4e5f6a7b8c9d",
+	} {
+		if got, err := MatchLabel(board, in); err != nil || got != want {
+			t.Errorf("matchLabel(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"red", "blue", "Bugs"} {
+		if got, err := MatchLabel(board, in); err == nil {
+			t.Errorf("matchLabel(%q) = %q, want an error", in, got)
+		}
+	}
+}
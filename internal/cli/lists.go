@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+func BoardListsRequest(boardID string, out *[]TrelloList) GetRequest {
+	query := url.Values{}
+	query.Set("fields", "id,name,closed,pos")
+	return GetRequest{Path: "/1/boards/" + url.PathEscape(boardID) + "/lists", Query: query, Out: out}
+}
+
+func FetchBoardLists(ctx context.Context, client *Client, boardID string) ([]TrelloList, error) {
+	var lists []TrelloList
+	if err := client.GetAll(ctx, BoardListsRequest(boardID, &lists)); err != nil {
+		return nil, err
+	}
+	client.Names.Put("lists:"+boardID, lists)
+	return lists, nil
+}
+
+func ResolveListID(ctx context.Context, client *Client, boardID, listID, listName string) (string, error) {
+	listID = strings.TrimSpace(listID)
+	listName = strings.TrimSpace(listName)
+	boardID = strings.TrimSpace(boardID)
+	if listID != "" {
+		return listID, nil
+	}
+	if listName == "" {
+		return "", errors.New("missing list target: provide --list or --list-name")
+	}
+	if boardID == "" {
+		return "", fmt.Errorf("--list-name needs a board: %w", ErrNoBoard)
+	}
+
+	lists, cached, err := CachedLookup(ctx, client, "lists:"+boardID, func(ctx context.Context, c *Client) ([]TrelloList, error) {
+		return FetchBoardLists(ctx, c, boardID)
+	})
+	if err != nil {
+		return "", err
+	}
+	if cached && !hasListNamed(lists, listName) {
+		// The list may have been created or renamed since it was cached.
+		if lists, err = FetchBoardLists(ctx, client, boardID); err != nil {
+			return "", err
+		}
+	}
+
+	target := strings.ToLower(listName)
+	exactMatches := make([]TrelloList, 0)
+	partialMatches := make([]TrelloList, 0)
+	for _, l := range lists {
+		name := strings.ToLower(l.Name)
+		if name == target {
+			exactMatches = append(exactMatches, l)
+			continue
+		}
+		if strings.Contains(name, target) {
+			partialMatches = append(partialMatches, l)
+		}
+	}
+	if len(exactMatches) == 1 {
+		return exactMatches[0].ID, nil
+	}
+	if len(exactMatches) > 1 {
+		return "", fmt.Errorf("list name %q is ambiguous on board %q (%d exact matches)", listName, boardID, len(exactMatches))
+	}
+	if len(partialMatches) == 1 {
+		return partialMatches[0].ID, nil
+	}
+	if len(partialMatches) > 1 {
+		return "", fmt.Errorf("list name %q is ambiguous on board %q (%d partial matches)", listName, boardID, len(partialMatches))
+	}
+	return "", fmt.Errorf("list name %q not found on board %q", listName, boardID)
+}
+
+// hasListNamed reports whether any list name contains name, ignoring case.
+func hasListNamed(lists []TrelloList, name string) bool {
+	name = strings.ToLower(name)
+	for _, l := range lists {
+		if strings.Contains(strings.ToLower(l.Name), name) {
+			return true
+		}
+	}
+	return false
+}
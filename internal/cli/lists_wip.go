@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WipStatus is a list's open cards against its WIP limit.
+type WipStatus struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Cards int    `json:"cards"`
+	Limit int    `json:"limit"`
+	Over  bool   `json:"over"`
+}
+
+// WipLimits returns the configured WIP limits by lower-case list name. A
+// limit that is not a positive integer is an error naming its list.
+func (fc FileConfig) WipLimits() (map[string]int, error) {
+	limits := map[string]int{}
+	m, _ := fc.Lookup("wip")
+	values, _ := m.(map[string]any)
+	key, _ := LookupConfigKey("wip.*")
+	for name, v := range values {
+		n, err := CoerceConfigValue(key, v)
+		if err != nil {
+			return nil, fmt.Errorf("config wip.%s: %w", name, err)
+		}
+		limits[strings.ToLower(strings.TrimSpace(name))] = n.(int)
+	}
+	return limits, nil
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"trelli/pkg/trello"
+)
+
+// logTransport appends one JSON line per HTTP round trip to a file, for
+// reviewing automation runs after the fact. Credentials are redacted from
+// URLs; bodies are never logged.
+type logTransport struct {
+	next http.RoundTripper
+	mu   sync.Mutex
+	enc  *json.Encoder
+}
+
+type logRecord struct {
+	Time       time.Time `json:"time"`
+	PID        int       `json:"pid"`
+	Method     string    `json:"method"`
+	URL        string    `json:"url"`
+	Status     int       `json:"status,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// newLogTransport opens path for appending, creating it readable only by
+// the user since URLs may name private boards and cards.
+func logMiddleware(path string) (trello.Middleware, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetEscapeHTML(false)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &logTransport{next: next, enc: enc}
+	}, nil
+}
+
+func (t *logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	rec := logRecord{
+		Time:       start.UTC(),
+		PID:        os.Getpid(),
+		Method:     req.Method,
+		URL:        trello.RedactURL(req.URL),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	} else {
+		rec.Status = resp.StatusCode
+	}
+	t.mu.Lock()
+	_ = t.enc.Encode(rec)
+	t.mu.Unlock()
+	return resp, err
+}
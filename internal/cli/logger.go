@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// Log formats accepted by --log-format and the log_format config key.
+const (
+	LogFormatPlain = "plain"
+	LogFormatText  = "text"
+	LogFormatJSON  = "json"
+)
+
+// newLogger returns the logger for diagnostics (warnings, notices, and HTTP
+// traces) written to w at level and above. Command results and fatal
+// errors are not logs and keep their own writers.
+func newLogger(w io.Writer, format string, level slog.Level) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+	switch format {
+	case LogFormatPlain:
+		return slog.New(&plainHandler{w: w, level: level, mu: &sync.Mutex{}}), nil
+	case LogFormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case LogFormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("unknown log format %q (want plain, text, or json)", format)
+}
+
+// logLevel maps --quiet and -v to the lowest level logged: errors only,
+// HTTP traces (debug) too, or warnings and notices by default.
+func logLevel(quiet bool, verbose int) slog.Level {
+	switch {
+	case quiet:
+		return slog.LevelError
+	case verbose > 0:
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
+}
+
+// plainHandler writes each record as its message alone, prefixed with
+// "warning: " or "error: " by level: the human-readable stderr trelli has
+// always printed. Attributes are for the structured formats.
+type plainHandler struct {
+	w     io.Writer
+	level slog.Level
+	mu    *sync.Mutex
+}
+
+func (h *plainHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *plainHandler) Handle(_ context.Context, r slog.Record) error {
+	prefix := ""
+	switch {
+	case r.Level >= slog.LevelError:
+		prefix = "error: "
+	case r.Level >= slog.LevelWarn:
+		prefix = "warning: "
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := fmt.Fprintln(h.w, prefix+r.Message)
+	return err
+}
+
+func (h *plainHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *plainHandler) WithGroup(string) slog.Handler      { return h }
@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// markdownMode says how card descriptions and comments, which Trello
+// stores as Markdown, are shown in the terminal.
+type markdownMode int
+
+const (
+	markdownRaw   markdownMode = iota // the text as written (--raw)
+	markdownPlain                     // rendered without escape codes, for pipes and NO_COLOR
+	markdownANSI                      // rendered with ANSI styles
+)
+
+// TerminalMarkdown picks the mode for stdout: styled on a terminal unless
+// NO_COLOR is set, plain otherwise, and raw with --raw.
+func TerminalMarkdown(raw bool) markdownMode {
+	switch {
+	case raw:
+		return markdownRaw
+	case os.Getenv("NO_COLOR") != "" || !IsTerminal(os.Stdout):
+		return markdownPlain
+	}
+	return markdownANSI
+}
+
+// style wraps s in the ANSI codes on and off in markdownANSI mode.
+func (m markdownMode) style(on, off, s string) string {
+	if m != markdownANSI || s == "" {
+		return s
+	}
+	return "\033[" + on + "m" + s + "\033[" + off + "m"
+}
+
+var (
+	mdFence   = regexp.MustCompile("^\\s*(```|~~~)")
+	mdHeading = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*\s*$`)
+	mdRule    = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	mdBullet  = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	mdTask    = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	mdOrdered = regexp.MustCompile(`^(\s*)(\d+)[.)]\s+(.*)$`)
+	mdQuote   = regexp.MustCompile(`^\s*>\s?(.*)$`)
+
+	// mdAtoms are the inline spans whose content is not styled further:
+	// code, links, autolinks, and backslash escapes.
+	mdAtoms    = regexp.MustCompile("`([^`]+)`|\\[([^\\]]+)\\]\\(([^)\\s]+)(?:\\s+\"[^\"]*\")?\\)|<(https?://[^>\\s]+)>|\\\\([\\\\`*_{}\\[\\]()#+\\-.!~>])")
+	mdBold     = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdItalic   = regexp.MustCompile(`\*([^*\s][^*]*)\*|(^|[^\w])_([^_\s][^_]*)_($|[^\w])`)
+	mdStrike   = regexp.MustCompile(`~~([^~]+)~~`)
+	mdRuleLine = strings.Repeat("─", 40)
+)
+
+// renderMarkdown renders the Markdown Trello supports in descriptions and
+// comments for reading in a terminal, starting each line with indent:
+// headings, bold, italics, strikethrough, lists, quotes, code, and links
+// with their URL shown after the text.
+func renderMarkdown(text string, mode markdownMode, indent string) string {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var b strings.Builder
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		if mode == markdownRaw {
+			b.WriteString(strings.TrimRight(indent+line, " ") + "\n")
+			continue
+		}
+		if mdFence.MatchString(line) {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			b.WriteString(indent + "    " + mode.style("36", "39", line) + "\n")
+			continue
+		}
+		if line = renderMarkdownLine(line, mode); line == "" {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(indent + line + "\n")
+	}
+	return b.String()
+}
+
+func renderMarkdownLine(line string, mode markdownMode) string {
+	if m := mdHeading.FindStringSubmatch(line); m != nil {
+		return mode.style("1;4", "24;22", renderInline(m[1], mode))
+	}
+	if mdRule.MatchString(line) {
+		return mode.style("2", "22", mdRuleLine)
+	}
+	if m := mdBullet.FindStringSubmatch(line); m != nil {
+		marker, item := "•", m[2]
+		if t := mdTask.FindStringSubmatch(item); t != nil {
+			marker, item = "☐", t[2]
+			if t[1] != " " {
+				marker = "☑"
+			}
+		}
+		return m[1] + marker + " " + renderInline(item, mode)
+	}
+	if m := mdOrdered.FindStringSubmatch(line); m != nil {
+		return m[1] + m[2] + ". " + renderInline(m[3], mode)
+	}
+	if m := mdQuote.FindStringSubmatch(line); m != nil {
+		return mode.style("2", "22", "│ ") + mode.style("3", "23", renderInline(m[1], mode))
+	}
+	if strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") {
+		return mode.style("36", "39", line)
+	}
+	return renderInline(line, mode)
+}
+
+// renderInline styles the inline Markdown of one line.
+func renderInline(s string, mode markdownMode) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range mdAtoms.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(renderEmphasis(s[last:m[0]], mode))
+		last = m[1]
+		switch {
+		case m[2] >= 0:
+			b.WriteString(mode.style("36", "39", s[m[2]:m[3]]))
+		case m[4] >= 0:
+			label, link := s[m[4]:m[5]], s[m[6]:m[7]]
+			if label == link {
+				b.WriteString(mode.style("4", "24", link))
+			} else {
+				b.WriteString(mode.style("4", "24", renderEmphasis(label, mode)) + " (" + link + ")")
+			}
+		case m[8] >= 0:
+			b.WriteString(mode.style("4", "24", s[m[8]:m[9]]))
+		default:
+			b.WriteString(s[m[10]:m[11]])
+		}
+	}
+	b.WriteString(renderEmphasis(s[last:], mode))
+	return b.String()
+}
+
+func renderEmphasis(s string, mode markdownMode) string {
+	s = mdBold.ReplaceAllStringFunc(s, func(m string) string {
+		return mode.style("1", "22", m[2:len(m)-2])
+	})
+	s = mdStrike.ReplaceAllStringFunc(s, func(m string) string {
+		return mode.style("9", "29", m[2:len(m)-2])
+	})
+	s = mdItalic.ReplaceAllStringFunc(s, func(m string) string {
+		sub := mdItalic.FindStringSubmatch(m)
+		if sub[1] != "" {
+			return mode.style("3", "23", sub[1])
+		}
+		return sub[2] + mode.style("3", "23", sub[3]) + sub[4]
+	})
+	return s
+}
+
+// PrintComments writes comments as blocks of an author and date line
+// followed by the comment, for comments list --pretty and cards show --full.
+// Names in both are redacted as the renderer would.
+func PrintComments(w io.Writer, actions []CommentAction, mode markdownMode, redact *redactor) {
+	if len(actions) == 0 {
+		fmt.Fprintln(w, "No comments found.")
+		return
+	}
+	for i, a := range actions {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		author := redact.RedactText(strings.TrimSpace(FirstNonEmpty(a.MemberCreator.FullName, a.MemberCreator.Username)))
+		fmt.Fprintf(w, "%s  %s\n", mode.style("1", "22", author), mode.style("2", "22", NoteTime(a.Date)+"  "+a.ID))
+		fmt.Fprint(w, renderMarkdown(redact.RedactText(a.Data.Text), mode, "  "))
+	}
+}
+
+// PrintCardText writes the description and comments of cards show --full
+// under their headings.
+func PrintCardText(w io.Writer, card Card, comments []CommentAction, mode markdownMode, redact *redactor) {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, mode.style("1", "22", "DESCRIPTION"))
+	if strings.TrimSpace(card.Desc) == "" {
+		fmt.Fprintln(w, "  No description.")
+	} else {
+		fmt.Fprint(w, renderMarkdown(redact.RedactText(card.Desc), mode, "  "))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, mode.style("1", "22", "COMMENTS"))
+	if len(comments) == 0 {
+		fmt.Fprintln(w, "  No comments found.")
+		return
+	}
+	var b strings.Builder
+	PrintComments(&b, comments, mode, redact)
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+		fmt.Fprintln(w, strings.TrimRight("  "+line, " "))
+	}
+}
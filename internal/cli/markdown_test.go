@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestRenderMarkdownANSI(t *testing.T) {
+	for in, want := range map[string]string{
+		"# Title":                     "\033[1;4mTitle\033[24;22m\n",
+		"**bold** and *it*":           "\033[1mbold\033[22m and \033[3mit\033[23m\n",
+		"a snake_case_name":           "a snake_case_name\n",
+		"`x **y**`":                   "\033[36mx **y**\033[39m\n",
+		"[docs](https://example.com)": "\033[4mdocs\033[24m (https://example.com)\n",
+		"- item":                      "• item\n",
+		"```\n**not bold**\n```":      "    \033[36m**not bold**\033[39m\n",
+		"<https://example.com/a_b_c>": "\033[4mhttps://example.com/a_b_c\033[24m\n",
+		"\\*literal\\*":               "*literal*\n",
+	} {
+		if got := renderMarkdown(in, markdownANSI, ""); got != want {
+			t.Errorf("renderMarkdown(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
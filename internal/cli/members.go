@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ResolveMemberIDs turns @usernames into the ids of the board's members;
+// ids pass through. Usernames match case-insensitively, with or without
+// the @. The board's members come from the name cache and are fetched
+// again when it lacks one of them.
+func ResolveMemberIDs(ctx context.Context, client *Client, boardID string, members []string) ([]string, error) {
+	if !HasNames(members) {
+		return members, nil
+	}
+	if strings.TrimSpace(boardID) == "" {
+		return nil, errors.New("@usernames need a board: pass --board or configure a default board")
+	}
+	fetch := func(ctx context.Context, c *Client) ([]Member, error) { return FetchBoardMembers(ctx, c, boardID) }
+	board, cached, err := CachedLookup(ctx, client, "members:"+boardID, fetch)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		id, ok := matchMember(board, m)
+		if !ok && cached {
+			// The member may have joined since the board was cached.
+			if board, err = fetch(ctx, client); err != nil {
+				return nil, err
+			}
+			cached = false
+			id, ok = matchMember(board, m)
+		}
+		if !ok {
+			names := make([]string, len(board))
+			for i, bm := range board {
+				names[i] = "@" + bm.Username
+			}
+			slices.Sort(names)
+			return nil, fmt.Errorf("no member @%s on board %s; its members are %s", strings.TrimPrefix(m, "@"), boardID, strings.Join(names, ", "))
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// matchMember finds one of the board's members by id or username.
+func matchMember(board []Member, s string) (string, bool) {
+	if trelloIDPattern.MatchString(s) {
+		return s, true
+	}
+	name := strings.TrimPrefix(s, "@")
+	for _, m := range board {
+		if m.ID == s || strings.EqualFold(m.Username, name) {
+			return m.ID, true
+		}
+	}
+	return "", false
+}
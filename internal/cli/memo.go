@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+// memo remembers GET response bodies for the rest of the invocation so each
+// unique GET is sent at most once, even when concurrent callers ask for it
+// at the same time. Failed fetches are not remembered. A nil *memo is a
+// valid no-op.
+type memo struct {
+	mu    sync.Mutex
+	calls map[string]*memoCall
+}
+
+type memoCall struct {
+	done chan struct{}
+	raw  []byte
+	err  error
+}
+
+func newMemo() *memo {
+	return &memo{calls: make(map[string]*memoCall)}
+}
+
+// MemoKey identifies a GET by path and query; credentials are added later
+// by endpoint and so never part of it.
+func MemoKey(p string, query url.Values) string {
+	return p + "?" + query.Encode()
+}
+
+// do returns the remembered body for key, waiting (until ctx ends) for an
+// in-flight fetch of the same key, or calls fetch and remembers its result.
+func (m *memo) do(ctx context.Context, key string, fetch func() ([]byte, error)) ([]byte, error) {
+	if m == nil {
+		return fetch()
+	}
+	m.mu.Lock()
+	if call, ok := m.calls[key]; ok {
+		m.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if call.err == nil {
+			return call.raw, nil
+		}
+		// The fetch we waited on failed; try again ourselves so a shared
+		// transient failure is not reported twice without a retry.
+		return m.do(ctx, key, fetch)
+	}
+	call := &memoCall{done: make(chan struct{})}
+	m.calls[key] = call
+	m.mu.Unlock()
+
+	call.raw, call.err = fetch()
+	if call.err != nil {
+		m.mu.Lock()
+		if m.calls[key] == call {
+			delete(m.calls, key)
+		}
+		m.mu.Unlock()
+	}
+	close(call.done)
+	return call.raw, call.err
+}
+
+// Lookup returns a completed, successful body for key.
+func (m *memo) Lookup(key string) ([]byte, bool) {
+	if m == nil {
+		return nil, false
+	}
+	m.mu.Lock()
+	call, ok := m.calls[key]
+	m.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	select {
+	case <-call.done:
+		return call.raw, call.err == nil
+	default:
+		return nil, false
+	}
+}
+
+// put remembers raw for key, e.g. a route answered inside a /1/batch call.
+func (m *memo) put(key string, raw []byte) {
+	if m == nil {
+		return
+	}
+	call := &memoCall{done: make(chan struct{}), raw: raw}
+	close(call.done)
+	m.mu.Lock()
+	m.calls[key] = call
+	m.mu.Unlock()
+}
+
+// Reset forgets everything; called on any change sent to the API, since
+// it may alter what a remembered GET would now return.
+func (m *memo) Reset() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.calls = make(map[string]*memoCall)
+	m.mu.Unlock()
+}
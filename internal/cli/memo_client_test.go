@@ -0,0 +1,79 @@
+package cli_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"trelli/pkg/trello"
+)
+
+// memoServer counts GETs of /1/boards/b1 and holds each for a moment, so
+// concurrent callers overlap.
+func memoServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var gets atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets.Add(1)
+			time.Sleep(20 * time.Millisecond)
+		}
+		w.Write([]byte(`{"id": "b1", "name": "Engineering"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &gets
+}
+
+func TestMemoConcurrentGets(t *testing.T) {
+	srv, gets := memoServer(t)
+	client := testClient(t, srv)
+
+	var wg sync.WaitGroup
+	boards := make([]trello.Board, 8)
+	errs := make([]error, len(boards))
+	for i := range boards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = client.Do(context.Background(), http.MethodGet, "/1/boards/b1", nil, nil, &boards[i])
+		}()
+	}
+	wg.Wait()
+	for i := range boards {
+		if errs[i] != nil || boards[i].Name != "Engineering" {
+			t.Errorf("caller %d: %+v, %v", i, boards[i], errs[i])
+		}
+	}
+	if n := gets.Load(); n != 1 {
+		t.Errorf("%d upstream GETs for %d identical callers, want 1", n, len(boards))
+	}
+}
+
+func TestMemoResetOnWrite(t *testing.T) {
+	srv, gets := memoServer(t)
+	client := testClient(t, srv)
+	ctx := context.Background()
+
+	get := func() {
+		t.Helper()
+		if err := client.Do(ctx, http.MethodGet, "/1/boards/b1", nil, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get()
+	get()
+	if n := gets.Load(); n != 1 {
+		t.Fatalf("repeated GET sent %d times, want 1", n)
+	}
+	if err := client.Do(ctx, http.MethodPut, "/1/boards/b1", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	get()
+	if n := gets.Load(); n != 2 {
+		t.Errorf("GET after a write sent %d times in total, want 2", n)
+	}
+}
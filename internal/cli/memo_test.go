@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMemoForgetsFailures(t *testing.T) {
+	m := newMemo()
+	ctx := context.Background()
+	calls := 0
+	failing := func() ([]byte, error) {
+		calls++
+		return nil, errors.New("boom")
+	}
+	if _, err := m.do(ctx, "k", failing); err == nil {
+		t.Fatal("want the fetch error")
+	}
+	if _, ok := m.Lookup("k"); ok {
+		t.Error("failed fetch remembered")
+	}
+	raw, err := m.do(ctx, "k", func() ([]byte, error) {
+		calls++
+		return []byte("ok"), nil
+	})
+	if err != nil || string(raw) != "ok" || calls != 2 {
+		t.Errorf("after a failure: %q, %v, %d calls", raw, err, calls)
+	}
+
+	var nilMemo *memo
+	if raw, err := nilMemo.do(ctx, "k", func() ([]byte, error) { return []byte("x"), nil }); err != nil || string(raw) != "x" {
+		t.Errorf("nil memo = %q, %v", raw, err)
+	}
+}
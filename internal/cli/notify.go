@@ -0,0 +1,310 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// NotifyAction is a board action as notifications need it.
+type NotifyAction struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Date string `json:"date"`
+	Data struct {
+		Text string `json:"text"`
+		Card *struct {
+			ID          string  `json:"id"`
+			Name        string  `json:"name"`
+			ShortLink   string  `json:"shortLink"`
+			Closed      *bool   `json:"closed"`
+			Due         *string `json:"due"`
+			DueComplete *bool   `json:"dueComplete"`
+		} `json:"card"`
+		List *struct {
+			Name   string `json:"name"`
+			Closed *bool  `json:"closed"`
+		} `json:"list"`
+		Label *struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"label"`
+		ListBefore *struct{ Name string } `json:"listBefore"`
+		ListAfter  *struct{ Name string } `json:"listAfter"`
+		Board      *struct{ Name string } `json:"board"`
+		Checklist  *struct{ Name string } `json:"checklist"`
+		CheckItem  *struct {
+			Name  string `json:"name"`
+			State string `json:"state"`
+		} `json:"checkItem"`
+		Attachment *struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"attachment"`
+		Member *struct{ Name string } `json:"member"`
+		Old    map[string]any         `json:"old"`
+	} `json:"data"`
+	MemberCreator actionMember `json:"memberCreator"`
+	// Member is the member added to or removed from a card or board.
+	Member *actionMember `json:"member"`
+}
+
+// actionMember is a member as actions embed it.
+type actionMember struct {
+	FullName string `json:"fullName"`
+	Username string `json:"username"`
+}
+
+// NotifyCursor marks the actions already seen: those up to Since, the
+// date of the newest one, including the ones in Seen dated exactly Since.
+type NotifyCursor struct {
+	Since string
+	Seen  map[string]bool
+}
+
+// StartNotifyCursor returns a cursor at the board's newest action, so
+// that only later actions are posted.
+func StartNotifyCursor(ctx context.Context, client *Client, boardID string) (NotifyCursor, error) {
+	query := url.Values{}
+	query.Set("limit", "1")
+	query.Set("fields", "id,date")
+	query.Set("memberCreator", "false")
+	var newest []NotifyAction
+	if err := client.Do(ctx, http.MethodGet, "/1/boards/"+url.PathEscape(boardID)+"/actions", query, nil, &newest); err != nil {
+		return NotifyCursor{}, err
+	}
+	if len(newest) == 0 {
+		return NotifyCursor{Since: time.Now().UTC().Format("2006-01-02T15:04:05.000Z"), Seen: map[string]bool{}}, nil
+	}
+	return NotifyCursor{Since: newest[0].Date, Seen: map[string]bool{newest[0].ID: true}}, nil
+}
+
+// NotifyNewActions hands the actions of types (all types when empty) after
+// the cursor to handle, oldest first, and advances it. A failed action is
+// logged and skipped so one bad message does not stall the rest.
+func NotifyNewActions(ctx context.Context, client *Client, boardID string, cursor *NotifyCursor, types []string, handle func(context.Context, NotifyAction) error) error {
+	var actions []NotifyAction
+	before := ""
+	for {
+		query := url.Values{}
+		query.Set("since", cursor.Since)
+		if before != "" {
+			query.Set("before", before)
+		}
+		if len(types) > 0 {
+			query.Set("filter", strings.Join(types, ","))
+		}
+		query.Set("limit", fmt.Sprint(ActionPageSize))
+		query.Set("fields", "id,type,date,data")
+		query.Set("memberCreator_fields", "fullName,username")
+		query.Set("member_fields", "fullName,username")
+		var page []NotifyAction
+		if err := client.Do(ctx, http.MethodGet, "/1/boards/"+url.PathEscape(boardID)+"/actions", query, nil, &page); err != nil {
+			return err
+		}
+		actions = append(actions, page...)
+		if len(page) < ActionPageSize {
+			break
+		}
+		before = page[len(page)-1].ID
+	}
+	// Actions arrive newest first.
+	slices.Reverse(actions)
+	for _, a := range actions {
+		if a.Date < cursor.Since || cursor.Seen[a.ID] {
+			continue
+		}
+		if err := handle(ctx, a); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			slog.Warn(fmt.Sprintf("%s %s: %v", a.Type, a.ID, err), "action", a.ID)
+		}
+		if a.Date > cursor.Since {
+			cursor.Since, cursor.Seen = a.Date, map[string]bool{}
+		}
+		cursor.Seen[a.ID] = true
+	}
+	return nil
+}
+
+// ActionMarkup styles the parts of an action description.
+type ActionMarkup struct {
+	Member func(string) string
+	List   func(string) string
+	Card   func(name, shortLink string) string
+	Name   func(string) string
+	Quote  func(string) string
+}
+
+// DescribeAction describes an action in a sentence styled by m, such as
+// `Ada moved "Fix bug" from Doing to Done`. Types without a sentence of
+// their own are named as they are.
+func DescribeAction(a NotifyAction, m ActionMarkup) string {
+	d := a.Data
+	who := m.Member(FirstNonEmpty(a.MemberCreator.FullName, a.MemberCreator.Username, "Someone"))
+	card := "a card"
+	if d.Card != nil {
+		card = m.Card(d.Card.Name, d.Card.ShortLink)
+	}
+	list := "a list"
+	if d.List != nil {
+		list = m.List(d.List.Name)
+	}
+	member := "a member"
+	switch {
+	case a.Member != nil:
+		member = m.Member(FirstNonEmpty(a.Member.FullName, a.Member.Username, "a member"))
+	case d.Member != nil && d.Member.Name != "":
+		member = m.Member(d.Member.Name)
+	}
+	label := "a label"
+	if d.Label != nil {
+		label = "label " + m.Name(FirstNonEmpty(d.Label.Name, d.Label.Color))
+	}
+	checklist := "a checklist"
+	if d.Checklist != nil {
+		checklist = "checklist " + m.Name(d.Checklist.Name)
+	}
+	item := "an item"
+	if d.CheckItem != nil {
+		item = m.Name(d.CheckItem.Name)
+	}
+
+	switch a.Type {
+	case "createCard":
+		if d.List != nil {
+			return fmt.Sprintf("%s created %s in %s", who, card, list)
+		}
+		return fmt.Sprintf("%s created %s", who, card)
+	case "commentCard":
+		return fmt.Sprintf("%s commented on %s:\n%s", who, card, m.Quote(d.Text))
+	case "updateComment":
+		return fmt.Sprintf("%s edited a comment on %s", who, card)
+	case "deleteComment":
+		return fmt.Sprintf("%s deleted a comment on %s", who, card)
+	case "updateCard":
+		return describeCardUpdate(a, who, card, m)
+	case "deleteCard":
+		return fmt.Sprintf("%s deleted a card", who)
+	case "copyCard":
+		return fmt.Sprintf("%s copied %s into %s", who, card, list)
+	case "emailCard":
+		return fmt.Sprintf("%s emailed %s into %s", who, card, list)
+	case "moveCardToBoard":
+		return fmt.Sprintf("%s moved %s to this board from another", who, card)
+	case "moveCardFromBoard":
+		return fmt.Sprintf("%s moved %s to another board", who, card)
+	case "convertToCardFromCheckItem":
+		return fmt.Sprintf("%s turned a checklist item into %s", who, card)
+	case "addMemberToCard":
+		if a.Member != nil && a.Member.Username == a.MemberCreator.Username {
+			return fmt.Sprintf("%s joined %s", who, card)
+		}
+		return fmt.Sprintf("%s added %s to %s", who, member, card)
+	case "removeMemberFromCard":
+		if a.Member != nil && a.Member.Username == a.MemberCreator.Username {
+			return fmt.Sprintf("%s left %s", who, card)
+		}
+		return fmt.Sprintf("%s removed %s from %s", who, member, card)
+	case "addLabelToCard":
+		return fmt.Sprintf("%s added %s to %s", who, label, card)
+	case "removeLabelFromCard":
+		return fmt.Sprintf("%s removed %s from %s", who, label, card)
+	case "addChecklistToCard":
+		return fmt.Sprintf("%s added %s to %s", who, checklist, card)
+	case "removeChecklistFromCard":
+		return fmt.Sprintf("%s removed %s from %s", who, checklist, card)
+	case "createCheckItem":
+		return fmt.Sprintf("%s added %s to %s on %s", who, item, checklist, card)
+	case "updateCheckItemStateOnCard":
+		if d.CheckItem != nil && d.CheckItem.State == "complete" {
+			return fmt.Sprintf("%s completed %s on %s", who, item, card)
+		}
+		return fmt.Sprintf("%s marked %s incomplete on %s", who, item, card)
+	case "addAttachmentToCard":
+		if d.Attachment != nil {
+			return fmt.Sprintf("%s attached %s to %s", who, m.Name(FirstNonEmpty(d.Attachment.Name, d.Attachment.URL)), card)
+		}
+		return fmt.Sprintf("%s added an attachment to %s", who, card)
+	case "deleteAttachmentFromCard":
+		return fmt.Sprintf("%s removed an attachment from %s", who, card)
+	case "createList":
+		return fmt.Sprintf("%s created list %s", who, list)
+	case "updateList":
+		switch {
+		case d.List != nil && d.List.Closed != nil && *d.List.Closed:
+			return fmt.Sprintf("%s archived list %s", who, list)
+		case d.List != nil && d.List.Closed != nil:
+			return fmt.Sprintf("%s restored list %s", who, list)
+		case d.Old["name"] != nil:
+			return fmt.Sprintf("%s renamed list %s to %s", who, m.List(fmt.Sprint(d.Old["name"])), list)
+		}
+		return fmt.Sprintf("%s updated list %s", who, list)
+	case "createLabel":
+		return fmt.Sprintf("%s created %s", who, label)
+	case "updateLabel":
+		return fmt.Sprintf("%s updated %s", who, label)
+	case "deleteLabel":
+		return fmt.Sprintf("%s deleted a label", who)
+	case "addMemberToBoard":
+		return fmt.Sprintf("%s added %s to the board", who, member)
+	case "removeMemberFromBoard":
+		return fmt.Sprintf("%s removed %s from the board", who, member)
+	case "updateBoard":
+		if d.Old["name"] != nil && d.Board != nil {
+			return fmt.Sprintf("%s renamed the board to %s", who, m.Name(d.Board.Name))
+		}
+		return fmt.Sprintf("%s updated the board", who)
+	}
+	if d.Card == nil {
+		return fmt.Sprintf("%s: %s", who, a.Type)
+	}
+	return fmt.Sprintf("%s: %s on %s", who, a.Type, card)
+}
+
+// describeCardUpdate describes an updateCard action by what changed.
+func describeCardUpdate(a NotifyAction, who, card string, m ActionMarkup) string {
+	d := a.Data
+	c := d.Card
+	switch {
+	case d.ListBefore != nil && d.ListAfter != nil:
+		return fmt.Sprintf("%s moved %s from %s to %s", who, card, m.List(d.ListBefore.Name), m.List(d.ListAfter.Name))
+	case c != nil && c.Closed != nil && *c.Closed:
+		return fmt.Sprintf("%s archived %s", who, card)
+	case c != nil && c.Closed != nil:
+		return fmt.Sprintf("%s unarchived %s", who, card)
+	case c != nil && d.Old["name"] != nil:
+		return fmt.Sprintf("%s renamed %s to %s", who, m.Name(fmt.Sprint(d.Old["name"])), card)
+	case c != nil && c.DueComplete != nil && *c.DueComplete:
+		return fmt.Sprintf("%s marked %s complete", who, card)
+	case c != nil && c.DueComplete != nil:
+		return fmt.Sprintf("%s marked %s incomplete", who, card)
+	case hasKey(d.Old, "due") && c != nil && c.Due != nil && *c.Due != "":
+		return fmt.Sprintf("%s set the due date of %s to %s", who, card, NoteTime(*c.Due))
+	case hasKey(d.Old, "due"):
+		return fmt.Sprintf("%s removed the due date of %s", who, card)
+	case hasKey(d.Old, "desc") && len(d.Old) == 1:
+		return fmt.Sprintf("%s edited the description of %s", who, card)
+	}
+	fields := make([]string, 0, len(d.Old))
+	for k := range d.Old {
+		fields = append(fields, k)
+	}
+	slices.Sort(fields)
+	if len(fields) > 0 {
+		return fmt.Sprintf("%s updated the %s of %s", who, strings.Join(fields, ", "), card)
+	}
+	return fmt.Sprintf("%s updated %s", who, card)
+}
+
+// hasKey reports whether m has key, even with a null value.
+func hasKey(m map[string]any, key string) bool {
+	_, ok := m[key]
+	return ok
+}
@@ -0,0 +1,21 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestActionTypesFlag(t *testing.T) {
+	var f ActionTypesFlag
+	for _, s := range []string{"createCard,COMMENTCARD", "updatecard", "createCard"} {
+		if err := f.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := f.String(); got != "createCard,commentCard,updateCard" {
+		t.Errorf("types = %q", got)
+	}
+	if err := f.Set("createCard,movedCard"); err == nil || !strings.Contains(err.Error(), `"movedCard"`) {
+		t.Errorf("Set(movedCard) = %v, want an unknown type error", err)
+	}
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// PluginPrefix names external commands: `trelli foo` runs trelli-foo from
+// PATH when foo is not a built-in command, as git and kubectl do.
+const PluginPrefix = "trelli-"
+
+// LookupPlugin returns the path of the plugin executable for cmd.
+func LookupPlugin(cmd string) (string, bool) {
+	if cmd == "" || strings.ContainsAny(cmd, `/\`) || strings.HasPrefix(cmd, "-") {
+		return "", false
+	}
+	path, err := exec.LookPath(PluginPrefix + cmd)
+	return path, err == nil
+}
+
+// listPlugins returns the names of plugins on PATH, without the prefix.
+// As with LookupPlugin, the first of several same-named executables wins.
+func listPlugins() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := strings.CutPrefix(e.Name(), PluginPrefix)
+			if !ok || e.IsDir() {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			if name == "" || seen[name] {
+				continue
+			}
+			if _, ok := LookupPlugin(name); !ok {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pluginEnv returns the environment for a plugin: the caller's, with the
+// resolved credentials, board, and settings of this invocation, so plugins
+// honor --profile, --board, the keychain, and the config file without
+// parsing them again.
+func pluginEnv(cfg Config) []string {
+	vars := map[string]string{
+		"TRELLO_API_KEY":  cfg.APIKey,
+		"TRELLO_TOKEN":    cfg.Token,
+		"TRELLO_BOARD_ID": cfg.BoardID,
+		"TRELLO_BASE_URL": cfg.BaseURL,
+		"TRELLI_PROFILE":  cfg.Profile,
+		"TRELLI_CONFIG":   cfg.ConfigPath,
+		"TRELLI_OUTPUT":   cfg.Output,
+	}
+	if self, err := os.Executable(); err == nil {
+		vars["TRELLI_BIN"] = self
+	}
+	env := make([]string, 0, len(os.Environ())+len(vars))
+	for _, kv := range os.Environ() {
+		k, _, _ := strings.Cut(kv, "=")
+		if _, overridden := vars[k]; !overridden {
+			env = append(env, kv)
+		}
+	}
+	for k, v := range vars {
+		if v != "" {
+			env = append(env, k+"="+v)
+		}
+	}
+	return env
+}
+
+// ExitPlugin runs the plugin and exits with its status.
+func ExitPlugin(cfg Config, path string, args []string) {
+	code, err := runPlugin(cfg, path, args)
+	if err != nil {
+		Fail(err, cfg.JSON)
+	}
+	os.Exit(code)
+}
+
+// runPlugin runs the plugin at path with args and returns its exit status.
+// Interrupts reach the plugin from the terminal directly; trelli waits for
+// it rather than exiting first.
+func runPlugin(cfg Config, path string, args []string) (int, error) {
+	if err := LoadStoredCredentials(&cfg); err != nil {
+		return 1, err
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = pluginEnv(cfg)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	if err := cmd.Start(); err != nil {
+		return 1, err
+	}
+	go func() {
+		for sig := range signals {
+			// The terminal already delivered SIGINT to the plugin's
+			// process group; forward only what was sent to trelli alone.
+			if sig != os.Interrupt {
+				_ = cmd.Process.Signal(sig)
+			}
+		}
+	}()
+	err := cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// ExitCode is -1 when the plugin was killed by a signal.
+		return max(exitErr.ExitCode(), 1), nil
+	}
+	if err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
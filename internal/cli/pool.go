@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"sync"
+)
+
+const defaultConcurrency = 4
+
+// Parallel runs tasks with at most n in flight and returns the first error
+// in task order. Every task runs even when an earlier one fails.
+func Parallel(n int, tasks ...func() error) error {
+	if n < 1 {
+		n = 1
+	}
+	errs := make([]error, len(tasks))
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = task()
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const progressDelay = 400 * time.Millisecond
+
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// progress draws a spinner with a counter on stderr while requests are in
+// flight. It counts requests, or the steps of a task such as the cards of
+// an export, with a bar once the number of steps is known. A nil *progress
+// is a valid no-op.
+type progress struct {
+	w io.Writer
+
+	mu      sync.Mutex
+	label   string
+	done    int
+	total   int
+	task    bool
+	active  int
+	drawn   bool
+	started time.Time
+	stop    chan struct{}
+}
+
+// newProgress returns a progress indicator, or nil when disabled or when
+// stderr is not a terminal.
+func newProgress(disabled bool) *progress {
+	if disabled || !IsTerminal(os.Stderr) {
+		return nil
+	}
+	return &progress{w: os.Stderr, label: "Working"}
+}
+
+// IsTerminal reports whether f is a character device other than the null
+// device, which is close enough to isatty without extra dependencies.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return false
+	}
+	return true
+}
+
+// SetTask counts the steps of an operation of total steps (0 if unknown)
+// under label, instead of requests, until EndTask. The task holds the
+// spinner open in between, so a run of quick requests still shows it.
+func (p *progress) SetTask(label string, total int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.label = label
+	p.total = total
+	p.done = 0
+	if !p.task {
+		p.task = true
+		p.begin()
+	}
+}
+
+// Advance marks n steps of the task done.
+func (p *progress) Advance(n int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+}
+
+// EndTask goes back to counting requests.
+func (p *progress) EndTask() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.task {
+		return
+	}
+	p.label = "Working"
+	p.total = 0
+	p.done = 0
+	p.task = false
+	p.end()
+}
+
+// Begin marks a unit of work as in flight and starts drawing after a short delay.
+func (p *progress) Begin() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.begin()
+}
+
+// begin is Begin with mu held.
+func (p *progress) begin() {
+	p.active++
+	if p.active > 1 {
+		return
+	}
+	p.started = time.Now()
+	p.stop = make(chan struct{})
+	go p.loop(p.stop)
+}
+
+// End marks a unit of work as finished and clears the line when idle.
+func (p *progress) End() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.task {
+		p.done++
+	}
+	p.end()
+}
+
+// end is End, without counting a request, with mu held.
+func (p *progress) end() {
+	if p.active == 0 {
+		return
+	}
+	p.active--
+	if p.active > 0 {
+		return
+	}
+	close(p.stop)
+	if p.drawn {
+		fmt.Fprint(p.w, "\r\033[K")
+		p.drawn = false
+	}
+}
+
+func (p *progress) loop(stop chan struct{}) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	frame := 0
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		p.mu.Lock()
+		select {
+		case <-stop:
+			p.mu.Unlock()
+			return
+		default:
+		}
+		if time.Since(p.started) >= progressDelay {
+			fmt.Fprintf(p.w, "\r\033[K%s %s %s", spinnerFrames[frame%len(spinnerFrames)], p.label, p.counter())
+			p.drawn = true
+			frame++
+		}
+		p.mu.Unlock()
+	}
+}
+
+// counter is "(12)", or a bar such as "[=====>      ] 120/500" when the
+// total is known. The caller holds mu.
+func (p *progress) counter() string {
+	if p.total <= 0 {
+		return fmt.Sprintf("(%d)", p.done)
+	}
+	const width = 20
+	filled := min(p.done, p.total) * width / p.total
+	bar := strings.Repeat("=", filled)
+	if filled < width {
+		bar += ">" + strings.Repeat(" ", width-filled-1)
+	}
+	return fmt.Sprintf("[%s] %d/%d", bar, p.done, p.total)
+}
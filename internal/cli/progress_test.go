@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressTask(t *testing.T) {
+	p := &progress{w: &strings.Builder{}, label: "Working"}
+	p.Begin()
+	p.End()
+	if got := p.counter(); got != "(1)" {
+		t.Errorf("request counter = %q", got)
+	}
+
+	p.SetTask("Exporting cards", 500)
+	p.Begin()
+	p.End()
+	p.Advance(120)
+	if got := p.counter(); got != "[====>               ] 120/500" {
+		t.Errorf("task counter = %q", got)
+	}
+	p.Advance(380)
+	if got := p.counter(); got != "[====================] 500/500" {
+		t.Errorf("finished task counter = %q", got)
+	}
+	p.EndTask()
+	if p.label != "Working" || p.counter() != "(0)" {
+		t.Errorf("after EndTask: %q %q", p.label, p.counter())
+	}
+
+	var nilProgress *progress
+	nilProgress.SetTask("x", 1)
+	nilProgress.Advance(1)
+	nilProgress.EndTask()
+}
+
+func TestProgressTaskKeepsSpinning(t *testing.T) {
+	var out strings.Builder
+	p := &progress{w: &out, label: "Working"}
+	p.SetTask("Exporting cards", 6)
+	// Sequential requests, each well under the draw delay.
+	for i := 0; i < 6; i++ {
+		p.Begin()
+		time.Sleep(progressDelay / 4)
+		p.End()
+		p.Advance(1)
+	}
+	p.mu.Lock()
+	drawn := out.String()
+	p.mu.Unlock()
+	if !strings.Contains(drawn, "Exporting cards [") {
+		t.Errorf("no frame drawn during the task: %q", drawn)
+	}
+	p.EndTask()
+	if p.active != 0 || !strings.HasSuffix(out.String(), "\r\033[K") {
+		t.Errorf("after EndTask: active %d, output %q", p.active, out.String())
+	}
+}
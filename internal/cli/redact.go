@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Modes of --redact: bare, it replaces member names; =all replaces card
+// titles as well.
+const (
+	RedactMembers = "members"
+	redactAll     = "all"
+)
+
+// redactFlag is --redact, a boolean flag that also takes a mode.
+type redactFlag string
+
+func (f *redactFlag) String() string   { return string(*f) }
+func (f *redactFlag) IsBoolFlag() bool { return true }
+
+func (f *redactFlag) Set(s string) error {
+	switch s {
+	case "true", RedactMembers:
+		*f = RedactMembers
+	case "false":
+		*f = ""
+	case redactAll:
+		*f = redactAll
+	default:
+		return fmt.Errorf("invalid --redact %q (want members or all)", s)
+	}
+	return nil
+}
+
+// redactor replaces the names of members, and the titles of cards when
+// cards is set, with pseudonyms in command output. It learns the names from
+// the API responses of the invocation; a pseudonym is derived from the id
+// of what it names, so it is the same on every run and board.
+type redactor struct {
+	cards bool
+
+	mu sync.Mutex
+	// names maps a name to its pseudonym where it is a whole value; text
+	// maps those that are also replaced within longer text, such as
+	// @mentions in comments.
+	names map[string]string
+	text  map[string]string
+	// plain and quoted replace in table cells and in JSON; nil until
+	// needed after names changed.
+	plain, quoted *strings.Replacer
+}
+
+func NewRedactor(mode string) *redactor {
+	return &redactor{cards: mode == redactAll, names: map[string]string{}, text: map[string]string{}}
+}
+
+// PseudonymID is a short stable hash of id.
+func PseudonymID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:3])
+}
+
+// Learn records the member names and card titles in a JSON response.
+func (r *redactor) Learn(raw []byte) {
+	if r == nil {
+		return
+	}
+	var v any
+	if json.Unmarshal(raw, &v) != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.walk("", v)
+}
+
+// learnValue records the names in v, a decoded response element.
+func (r *redactor) learnValue(v any) {
+	if r == nil {
+		return
+	}
+	if raw, err := json.Marshal(v); err == nil {
+		r.Learn(raw)
+	}
+}
+
+// walk finds members as objects with a username and cards as objects
+// with a list, or under a "card" key as in actions.
+func (r *redactor) walk(key string, v any) {
+	switch v := v.(type) {
+	case []any:
+		for _, e := range v {
+			r.walk(key, e)
+		}
+	case map[string]any:
+		id, _ := v["id"].(string)
+		if username, _ := v["username"].(string); username != "" {
+			h := PseudonymID(FirstNonEmpty(id, username))
+			r.add(username, "user-"+h, false)
+			r.add("@"+username, "@user-"+h, true)
+			if full, _ := v["fullName"].(string); full != "" {
+				r.add(full, "User "+h, utf8.RuneCountInString(full) >= 4)
+			}
+			if initials, _ := v["initials"].(string); initials != "" {
+				r.add(initials, "U", false)
+			}
+		}
+		_, inList := v["idList"]
+		if name, _ := v["name"].(string); r.cards && name != "" && (inList || key == "card") {
+			r.add(name, "Card "+PseudonymID(FirstNonEmpty(id, name)), utf8.RuneCountInString(name) >= 4)
+		}
+		for k, e := range v {
+			r.walk(k, e)
+		}
+	}
+}
+
+func (r *redactor) add(name, pseudonym string, inText bool) {
+	if r.names[name] == pseudonym && (!inText || r.text[name] == pseudonym) {
+		return
+	}
+	r.names[name] = pseudonym
+	if inText {
+		r.text[name] = pseudonym
+	}
+	r.plain, r.quoted = nil, nil
+}
+
+// replacers builds the replacers for the names learned so far. Longer
+// names come first so that a name wins over one it contains.
+func (r *redactor) replacers() (plain, quoted *strings.Replacer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.plain != nil {
+		return r.plain, r.quoted
+	}
+	type pair struct{ old, new string }
+	sorted := func(pairs []pair) []string {
+		slices.SortFunc(pairs, func(a, b pair) int {
+			return cmp.Or(cmp.Compare(len(b.old), len(a.old)), strings.Compare(a.old, b.old))
+		})
+		out := make([]string, 0, 2*len(pairs))
+		for _, p := range pairs {
+			out = append(out, p.old, p.new)
+		}
+		return out
+	}
+	var plainPairs, quotedPairs []pair
+	for name, pseudonym := range r.text {
+		plainPairs = append(plainPairs, pair{name, pseudonym})
+		quotedPairs = append(quotedPairs, pair{jsonEscape(name), jsonEscape(pseudonym)})
+	}
+	for name, pseudonym := range r.names {
+		quotedPairs = append(quotedPairs, pair{`"` + jsonEscape(name) + `"`, `"` + jsonEscape(pseudonym) + `"`})
+	}
+	r.plain = strings.NewReplacer(sorted(plainPairs)...)
+	r.quoted = strings.NewReplacer(sorted(quotedPairs)...)
+	return r.plain, r.quoted
+}
+
+// jsonEscape returns s as it appears inside a JSON string.
+func jsonEscape(s string) string {
+	raw, _ := json.Marshal(s)
+	return string(raw[1 : len(raw)-1])
+}
+
+// RedactText redacts a table cell or a line of prose.
+func (r *redactor) RedactText(s string) string {
+	if r == nil {
+		return s
+	}
+	r.mu.Lock()
+	pseudonym, ok := r.names[s]
+	r.mu.Unlock()
+	if ok {
+		return pseudonym
+	}
+	plain, _ := r.replacers()
+	return plain.Replace(s)
+}
+
+// RedactJSON redacts encoded JSON.
+func (r *redactor) RedactJSON(raw []byte) []byte {
+	if r == nil {
+		return raw
+	}
+	_, quoted := r.replacers()
+	return []byte(quoted.Replace(string(raw)))
+}
+
+// redactRenderer redacts results before another renderer writes them. The
+// value is redacted in its JSON form, which is what every renderer but the
+// table ones shows, and tables cell by cell.
+type redactRenderer struct {
+	next   Renderer
+	redact *redactor
+}
+
+func (rr redactRenderer) Render(w io.Writer, v any, tables ...Table) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	redacted := make([]Table, len(tables))
+	for i, t := range tables {
+		redacted[i] = Table{Columns: t.Columns, Rows: make([][]string, len(t.Rows)), Empty: t.Empty}
+		for j, row := range t.Rows {
+			redacted[i].Rows[j] = make([]string, len(row))
+			for k, cell := range row {
+				redacted[i].Rows[j][k] = rr.redact.RedactText(cell)
+			}
+		}
+	}
+	return rr.next.Render(w, json.RawMessage(rr.redact.RedactJSON(raw)), redacted...)
+}
@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+// Output formats accepted by --output and the output config key.
+const (
+	OutputTable    = "table"
+	OutputJSON     = "json"
+	OutputCSV      = "csv"
+	OutputTemplate = "template"
+)
+
+// Table is the tabular form of a command result, shared by the table and
+// CSV renderers. The table renderer prints Empty instead of a header when
+// there are no rows.
+type Table struct {
+	Columns []string
+	Rows    [][]string
+	Empty   string
+}
+
+// Renderer writes a command result in one output format. v is what JSON and
+// templates see; tables are its tabular form. Renderers that need a table
+// derive one from v when the command supplies none.
+type Renderer interface {
+	Render(w io.Writer, v any, tables ...Table) error
+}
+
+// newRenderer returns the renderer for an output format. A non-empty tmpl
+// is required by, and only used with, the template format.
+func newRenderer(format, tmpl string) (Renderer, error) {
+	switch format {
+	case OutputTable:
+		return TableRenderer{}, nil
+	case OutputJSON:
+		return JSONRenderer{}, nil
+	case OutputCSV:
+		return csvRenderer{}, nil
+	case OutputTemplate:
+		if tmpl == "" {
+			return nil, fmt.Errorf("--output template requires --template")
+		}
+		t, err := template.New("output").Funcs(template.FuncMap{"json": TemplateJSON}).Parse(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("--template: %w", err)
+		}
+		return templateRenderer{t: t}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q (want table, json, csv, or template)", format)
+}
+
+// Render writes v to stdout in the invocation's output format.
+func Render(cfg Config, v any, tables ...Table) error {
+	return cfg.Renderer.Render(os.Stdout, v, tables...)
+}
+
+// Structured reports whether results go to stdout as data rather than as
+// the human table-and-prose output.
+func (cfg Config) Structured() bool {
+	return cfg.Output != OutputTable
+}
+
+type TableRenderer struct{}
+
+func (TableRenderer) Render(w io.Writer, v any, tables ...Table) error {
+	if len(tables) == 0 {
+		t, err := valueTable(v)
+		if err != nil {
+			return err
+		}
+		tables = []Table{t}
+	}
+	for i, t := range tables {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if len(t.Rows) == 0 && t.Empty != "" {
+			fmt.Fprintln(w, t.Empty)
+			continue
+		}
+		tw := tabwriter.NewWriter(w, 2, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(t.Columns, "\t"))
+		for _, row := range t.Rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type JSONRenderer struct{}
+
+func (JSONRenderer) Render(w io.Writer, v any, _ ...Table) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// csvRenderer writes RFC 4180 CSV with a header row; several tables are
+// separated by a blank line.
+type csvRenderer struct{}
+
+func (csvRenderer) Render(w io.Writer, v any, tables ...Table) error {
+	if len(tables) == 0 {
+		t, err := valueTable(v)
+		if err != nil {
+			return err
+		}
+		tables = []Table{t}
+	}
+	for i, t := range tables {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		cw := csv.NewWriter(w)
+		if err := cw.Write(t.Columns); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(t.Rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateRenderer executes a text/template against v's JSON form, so
+// fields use their JSON names ({{.name}}). Arrays execute it once per
+// element; every execution ends in a newline.
+type templateRenderer struct {
+	t *template.Template
+}
+
+func (r templateRenderer) Render(w io.Writer, v any, _ ...Table) error {
+	data, err := JSONValue(v)
+	if err != nil {
+		return err
+	}
+	items, ok := data.([]any)
+	if !ok {
+		items = []any{data}
+	}
+	for _, item := range items {
+		var buf bytes.Buffer
+		if err := r.t.Execute(&buf, item); err != nil {
+			return fmt.Errorf("--template: %w", err)
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TemplateJSON(v any) (string, error) {
+	raw, err := json.Marshal(v)
+	return string(raw), err
+}
+
+// JSONValue returns v as the generic value its JSON encoding decodes to.
+func JSONValue(v any) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	err = json.Unmarshal(raw, &out)
+	return out, err
+}
+
+// valueTable derives a table from v's JSON form for commands without a
+// bespoke one: an object is one row, an array of objects one row per
+// element, with columns named after the sorted union of keys. Nested values
+// are written as compact JSON.
+func valueTable(v any) (Table, error) {
+	data, err := JSONValue(v)
+	if err != nil {
+		return Table{}, err
+	}
+	items, ok := data.([]any)
+	if !ok {
+		items = []any{data}
+	}
+	seen := map[string]bool{}
+	var columns []string
+	for _, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		for k := range obj {
+			if !seen[k] {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+	sort.Strings(columns)
+	if len(columns) == 0 {
+		columns = []string{"value"}
+	}
+
+	t := Table{Columns: columns}
+	for _, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			obj = map[string]any{"value": item}
+		}
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i] = cellText(obj[c])
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
+
+func cellText(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	raw, _ := json.Marshal(v)
+	return string(raw)
+}
@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"trelli/pkg/trello"
+)
+
+// fixture is one recorded HTTP interaction. Credentials never reach disk:
+// the key and token are stripped from URL, and they are not part of bodies.
+type fixture struct {
+	Method   string            `json:"method"`
+	URL      string            `json:"url"`
+	Body     string            `json:"body,omitempty"`
+	Status   int               `json:"status"`
+	Header   map[string]string `json:"header,omitempty"`
+	Response string            `json:"response"`
+}
+
+// errNoRecording reports a replayed request missing from the fixtures;
+// retrying cannot help.
+var errNoRecording = errors.New("replay: no recording")
+
+// fixtureHeaders are the response headers trelli reads and so records.
+var fixtureHeaders = []string{"Content-Type", "ETag", "Retry-After"}
+
+// fixtureNamer maps requests to fixture files. Repeats of the same request
+// within a run get increasing sequence numbers, so a recording replays the
+// same responses in the same order.
+type fixtureNamer struct {
+	dir  string
+	mu   sync.Mutex
+	seen map[string]int
+}
+
+func (n *fixtureNamer) name(req *http.Request, body []byte) (string, string) {
+	target := stripCredentials(req.URL)
+	sum := sha256.Sum256([]byte(req.Method + " " + target + "\n" + string(body)))
+	slug := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.TrimPrefix(req.URL.Path, "/"))
+	if len(slug) > 60 {
+		slug = slug[:60]
+	}
+	key := req.Method + "_" + slug + "-" + hex.EncodeToString(sum[:6])
+
+	n.mu.Lock()
+	n.seen[key]++
+	seq := n.seen[key]
+	n.mu.Unlock()
+	if seq > 1 {
+		key += fmt.Sprintf("-%d", seq)
+	}
+	return filepath.Join(n.dir, key+".json"), target
+}
+
+// stripCredentials returns u without the key and token parameters.
+func stripCredentials(u *url.URL) string {
+	stripped := *u
+	query := stripped.Query()
+	query.Del("key")
+	query.Del("token")
+	stripped.RawQuery = query.Encode()
+	stripped.Scheme, stripped.Host = "", ""
+	return stripped.String()
+}
+
+// normalizedBody returns body in a form that is the same for identical
+// requests. Multipart uploads are separated by a random boundary, so they
+// become their form fields, sorted, followed by a digest of each file.
+func normalizedBody(req *http.Request, body []byte) []byte {
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		return body
+	}
+	form, err := multipart.NewReader(bytes.NewReader(body), params["boundary"]).ReadForm(int64(len(body)) + 1)
+	if err != nil {
+		return body
+	}
+	defer form.RemoveAll()
+	var lines []string
+	for k, values := range form.Value {
+		for _, v := range values {
+			lines = append(lines, url.Values{k: {v}}.Encode())
+		}
+	}
+	sort.Strings(lines)
+	var files []string
+	for k, headers := range form.File {
+		for _, h := range headers {
+			f, err := h.Open()
+			if err != nil {
+				return body
+			}
+			sum := sha256.New()
+			_, err = io.Copy(sum, f)
+			f.Close()
+			if err != nil {
+				return body
+			}
+			files = append(files, fmt.Sprintf("%s: %s %s sha256:%x", k, h.Filename, h.Header.Get("Content-Type"), sum.Sum(nil)))
+		}
+	}
+	sort.Strings(files)
+	return []byte(strings.Join(append(lines, files...), "\n"))
+}
+
+// requestBody returns the normalized body of req, which fixtures are keyed
+// by and record.
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.GetBody == nil {
+		return nil, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return normalizedBody(req, raw), nil
+}
+
+// recordTransport sends requests through next and writes each interaction
+// to a fixture file.
+type recordTransport struct {
+	next http.RoundTripper
+	fixtureNamer
+}
+
+func recordMiddleware(dir string) (trello.Middleware, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	t := &recordTransport{fixtureNamer: fixtureNamer{dir: dir, seen: map[string]int{}}}
+	return func(next http.RoundTripper) http.RoundTripper {
+		t.next = next
+		return t
+	}, nil
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	file, target := t.name(req, body)
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	fx := fixture{Method: req.Method, URL: target, Body: string(body), Status: resp.StatusCode, Response: string(raw)}
+	for _, h := range fixtureHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			if fx.Header == nil {
+				fx.Header = map[string]string{}
+			}
+			fx.Header[h] = v
+		}
+	}
+	if err := WriteJSONFile(file, fx); err != nil {
+		return nil, fmt.Errorf("recording fixture: %w", err)
+	}
+	return resp, nil
+}
+
+// replayTransport answers requests from fixture files without touching the
+// network; a request with no recording fails.
+type replayTransport struct {
+	fixtureNamer
+}
+
+func newReplayTransport(dir string) (*replayTransport, error) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("replay: %s is not a fixture directory", dir)
+	}
+	return &replayTransport{fixtureNamer: fixtureNamer{dir: dir, seen: map[string]int{}}}, nil
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	file, target := t.name(req, body)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w for %s %s (%s)", errNoRecording, req.Method, target, filepath.Base(file))
+	}
+	var fx fixture
+	if err := json.Unmarshal(data, &fx); err != nil {
+		return nil, fmt.Errorf("replay: reading %s: %w", file, err)
+	}
+	header := http.Header{}
+	for k, v := range fx.Header {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fx.Status, http.StatusText(fx.Status)),
+		StatusCode:    fx.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(fx.Response)),
+		ContentLength: int64(len(fx.Response)),
+		Request:       req,
+	}, nil
+}
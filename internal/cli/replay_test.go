@@ -0,0 +1,48 @@
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+	"trelli/pkg/trello"
+)
+
+func TestReplayUploadAndCache(t *testing.T) {
+	srv := clitest.NewStub(t)
+	dir := filepath.Join(t.TempDir(), "fixtures")
+	file := trello.File{Name: "notes.txt", MimeType: "text/plain", Data: []byte("hello")}
+	attach := func(args ...string) cli.Config {
+		t.Helper()
+		cfg, _, err := clitest.TestConfig(t, srv, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, err := cli.Connect(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Cards.Get(cfg.Context, "c1", "name"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Cards.AttachFile(cfg.Context, "c1", file); err != nil {
+			t.Fatalf("%s: %v", strings.Join(args, " "), err)
+		}
+		return cfg
+	}
+
+	attach("--record", dir)
+	// Each upload gets a new multipart boundary; the replay still matches.
+	srv.Close()
+	cfg := attach("--replay", dir)
+	cache, err := cli.CacheDir(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(cache); len(entries) > 0 {
+		t.Errorf("replay wrote to the cache: %v", entries)
+	}
+}
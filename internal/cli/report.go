@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ReportBoardCard struct {
+	Card
+	DueComplete bool     `json:"dueComplete"`
+	IDMembers   []string `json:"idMembers"`
+}
+
+// ParseSince reads a period start: a date, or an age before now such as
+// 1w, 7d, or 36h.
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := ParseDate(s); err == nil {
+		return t, nil
+	}
+	d, err := ParseAge(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return now.Add(-d), nil
+}
+
+// ParseAge reads a duration that may also count days (d) and weeks (w).
+func ParseAge(s string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	default:
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return 0, fmt.Errorf("%q is not a date or an age such as 1w, 7d, or 36h", s)
+		}
+		return d, nil
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%q is not a date or an age such as 1w, 7d, or 36h", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// ParseDate reads a date (in UTC) or an RFC 3339 time.
+func ParseDate(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
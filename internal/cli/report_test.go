@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 2, 9, 12, 0, 0, 0, time.UTC)
+	for in, want := range map[string]time.Time{
+		"1w":         now.AddDate(0, 0, -7),
+		"3d":         now.AddDate(0, 0, -3),
+		"36h":        now.Add(-36 * time.Hour),
+		"2026-02-01": time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		if got, err := ParseSince(in, now); err != nil || !got.Equal(want) {
+			t.Errorf("parseSince(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "w", "-1d", "soon"} {
+		if _, err := ParseSince(in, now); err == nil {
+			t.Errorf("parseSince(%q) succeeded", in)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"trelli/pkg/trello"
+)
+
+// retryable is trello.Retryable, except that a request missing from --replay
+// fixtures will not appear on a second try either.
+func retryable(method string, err error) bool {
+	return trello.Retryable(method, err) && !errors.Is(err, errNoRecording)
+}
+
+// timeoutHint points at --timeout when a request ran out of time.
+func timeoutHint(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w (raise --timeout)", err)
+	}
+	return err
+}
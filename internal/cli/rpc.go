@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"trelli/pkg/trello"
+)
+
+// JSON-RPC 2.0 error codes; rpcCodeTrello is ours, from the range the
+// spec reserves for implementations.
+const (
+	rpcCodeParse          = -32700
+	rpcCodeInvalidRequest = -32600
+	rpcCodeNoMethod       = -32601
+	rpcCodeInvalidParams  = -32602
+	rpcCodeTrello         = -32000
+)
+
+const maxRPCMessage = 1 << 20
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+type RPCError struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Data    *errorPayload `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string { return e.Message }
+
+// RPCMethod runs one method with its raw params.
+type RPCMethod func(ctx context.Context, client *Client, cfg Config, params json.RawMessage) (any, error)
+
+func RPCListBoards(ctx context.Context, client *Client, _ Config, params json.RawMessage) (any, error) {
+	var p struct {
+		Filter string `json:"filter"`
+	}
+	if err := DecodeRPCParams(params, &p); err != nil {
+		return nil, err
+	}
+	boards, err := client.Boards.List(ctx, trello.ListBoardsOptions{Filter: p.Filter})
+	return NonNil(boards), err
+}
+
+func RPCSearchCards(ctx context.Context, client *Client, cfg Config, params json.RawMessage) (any, error) {
+	var p struct {
+		Query string `json:"query"`
+		Board string `json:"board"`
+		Limit int    `json:"limit"`
+	}
+	if err := DecodeRPCParams(params, &p); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(p.Query) == "" {
+		return nil, InvalidRPCParams(`"query" is required`)
+	}
+	opts := trello.SearchCardsOptions{Limit: p.Limit}
+	if p.Board != "" {
+		opts.BoardIDs = []string{cfg.File.ResolveBoardAlias(p.Board)}
+	}
+	cards, err := client.Cards.Search(ctx, p.Query, opts)
+	return NonNil(cards), err
+}
+
+func RPCCreateCard(ctx context.Context, client *Client, cfg Config, params json.RawMessage) (any, error) {
+	var d CardDraft
+	if err := DecodeRPCParams(params, &d); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(d.Name) == "" {
+		return nil, InvalidRPCParams(`"name" is required`)
+	}
+	if d.IDList == "" && d.ListName == "" {
+		return nil, InvalidRPCParams(`"idList" or "listName" is required`)
+	}
+	return CreateCard(ctx, client, cfg, d)
+}
+
+func RPCAddComment(ctx context.Context, client *Client, cfg Config, params json.RawMessage) (any, error) {
+	var p struct {
+		Card string `json:"card"`
+		Text string `json:"text"`
+	}
+	if err := DecodeRPCParams(params, &p); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(p.Card) == "" || strings.TrimSpace(p.Text) == "" {
+		return nil, InvalidRPCParams(`"card" and "text" are required`)
+	}
+	return AddComment(ctx, client, cfg, p.Card, p.Text)
+}
+
+// ServeRPC answers the requests read from in, one per line, with methods
+// on out until in is exhausted or ctx ends.
+func ServeRPC(ctx context.Context, client *Client, cfg Config, methods map[string]RPCMethod, in io.Reader, out io.Writer) error {
+	sc := bufio.NewScanner(in)
+	sc.Buffer(make([]byte, 0, 64*1024), maxRPCMessage)
+	enc := json.NewEncoder(out)
+	for sc.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if resp, ok := handleRPC(ctx, client, cfg, methods, line); ok {
+			if err := enc.Encode(resp); err != nil {
+				return err
+			}
+		}
+	}
+	return sc.Err()
+}
+
+// handleRPC runs one request line. ok is false for notifications, which
+// get no response.
+func handleRPC(ctx context.Context, client *Client, cfg Config, methods map[string]RPCMethod, line []byte) (resp rpcResponse, ok bool) {
+	resp = rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null")}
+	var req rpcRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		resp.Error = &RPCError{Code: rpcCodeParse, Message: "parse error: " + err.Error()}
+		return resp, true
+	}
+	if req.ID != nil {
+		resp.ID = req.ID
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		resp.Error = &RPCError{Code: rpcCodeInvalidRequest, Message: `invalid request: want "jsonrpc": "2.0" and a method`}
+		return resp, true
+	}
+	method, known := methods[req.Method]
+	var result any
+	var err error
+	if known {
+		result, err = method(ctx, client, cfg, req.Params)
+	} else {
+		err = &RPCError{Code: rpcCodeNoMethod, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+	if req.ID == nil {
+		return resp, false
+	}
+	var rerr *RPCError
+	switch {
+	case errors.As(err, &rerr):
+		resp.Error = rerr
+	case err != nil:
+		payload := NewErrorPayload(err)
+		resp.Error = &RPCError{Code: rpcCodeTrello, Message: payload.Message, Data: &payload}
+	default:
+		resp.Result = result
+	}
+	return resp, true
+}
+
+// DecodeRPCParams decodes by-name params into v; absent params leave v
+// zero.
+func DecodeRPCParams(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return InvalidRPCParams("invalid params: " + err.Error())
+	}
+	return nil
+}
+
+func InvalidRPCParams(msg string) error {
+	return &RPCError{Code: rpcCodeInvalidParams, Message: msg}
+}
+
+// NonNil makes empty results encode as [] rather than null.
+func NonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// GuardLocal rejects requests a web page could make: those naming another
+// host (DNS rebinding) and those carrying an Origin header (cross-site
+// fetches and form posts).
+func GuardLocal(next http.Handler, host string) http.Handler {
+	_, port, _ := net.SplitHostPort(host)
+	allowed := map[string]bool{host: true, "localhost:" + port: true, "127.0.0.1:" + port: true, "[::1]:" + port: true}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if host != "" && !allowed[r.Host] {
+			ServeError(w, http.StatusForbidden, fmt.Errorf("host %q not allowed", r.Host))
+			return
+		}
+		if r.Header.Get("Origin") != "" {
+			ServeError(w, http.StatusForbidden, errors.New("cross-origin requests are not allowed"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ServeError writes err in the shape --json errors use.
+func ServeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	WriteError(w, err, true)
+}
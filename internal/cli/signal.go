@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// InterruptContext returns a context cancelled by the first SIGINT or
+// SIGTERM, aborting in-flight requests and loops. Signal handling is then
+// reset so a second Ctrl-C terminates immediately.
+func InterruptContext() context.Context {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx
+}
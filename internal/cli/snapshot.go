@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// snapshotStore keeps the last successful response of every GET request on
+// disk, with its ETag, so --offline can replay read commands without the
+// network and online requests can be revalidated with If-None-Match.
+type snapshotStore struct {
+	dir string
+	// warned ensures the staleness notice is logged once per invocation.
+	warned sync.Once
+}
+
+type snapshotEntry struct {
+	Fetched time.Time       `json:"fetched"`
+	Path    string          `json:"path"`
+	ETag    string          `json:"etag,omitempty"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// newSnapshotStore returns a store scoped to cfg's profile under the cache
+// dir, or nil when no cache dir is available.
+func newSnapshotStore(cfg Config) *snapshotStore {
+	dir, err := CacheDir(cfg)
+	if err != nil {
+		return nil
+	}
+	return &snapshotStore{
+		dir: filepath.Join(dir, "snapshots", FirstNonEmpty(cfg.Profile, "default")),
+	}
+}
+
+// snapshotKey identifies a request independently of the credentials in its
+// query string.
+func snapshotKey(p string, query url.Values) string {
+	q := url.Values{}
+	for k, v := range query {
+		if k != "key" && k != "token" {
+			q[k] = v
+		}
+	}
+	sum := sha256.Sum256([]byte(p + "?" + q.Encode()))
+	return hex.EncodeToString(sum[:12])
+}
+
+func (s *snapshotStore) save(p string, query url.Values, data []byte, etag string) {
+	if s == nil || !json.Valid(data) {
+		return
+	}
+	raw, err := json.Marshal(snapshotEntry{Fetched: time.Now().UTC(), Path: p, ETag: etag, Data: data})
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(s.dir, 0o700) != nil {
+		return
+	}
+	// Snapshots are best effort; a failed write must not fail the command.
+	file := filepath.Join(s.dir, snapshotKey(p, query)+".json")
+	tmp := file + ".tmp"
+	if os.WriteFile(tmp, raw, 0o600) == nil {
+		_ = os.Rename(tmp, file)
+	}
+}
+
+// entry returns the stored snapshot for the request, if any.
+func (s *snapshotStore) entry(p string, query url.Values) (snapshotEntry, bool) {
+	var entry snapshotEntry
+	if s == nil {
+		return entry, false
+	}
+	raw, err := os.ReadFile(filepath.Join(s.dir, snapshotKey(p, query)+".json"))
+	if err != nil || json.Unmarshal(raw, &entry) != nil {
+		return entry, false
+	}
+	return entry, true
+}
+
+// load decodes the snapshot for the request into out and reports how old it
+// is on stderr.
+func (s *snapshotStore) load(p string, query url.Values, out any) error {
+	if s == nil {
+		return errors.New("offline: no cache directory available")
+	}
+	raw, err := os.ReadFile(filepath.Join(s.dir, snapshotKey(p, query)+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("offline: no cached data for %s (run the command online once to cache it)", p)
+	}
+	if err != nil {
+		return err
+	}
+	var entry snapshotEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return fmt.Errorf("offline: corrupt cache entry for %s: %w", p, err)
+	}
+	s.warned.Do(func() {
+		age := time.Since(entry.Fetched).Round(time.Minute)
+		slog.Info(fmt.Sprintf("offline: showing cached data from %s (%s old)", entry.Fetched.Local().Format("2006-01-02 15:04"), age),
+			"fetched", entry.Fetched, "age", age)
+	})
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(entry.Data, out)
+}
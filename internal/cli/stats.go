@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// apiStats counts the API traffic of one invocation for --stats. A nil
+// *apiStats is a valid no-op.
+type apiStats struct {
+	calls     atomic.Int64
+	sent      atomic.Int64
+	received  atomic.Int64
+	retries   atomic.Int64
+	CacheHits atomic.Int64
+	latency   atomic.Int64 // nanoseconds
+}
+
+// call records one HTTP round trip that sent n body bytes and took d to
+// return response headers.
+func (s *apiStats) call(n int64, d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.calls.Add(1)
+	s.sent.Add(n)
+	s.latency.Add(int64(d))
+}
+
+func (s *apiStats) retry() {
+	if s != nil {
+		s.retries.Add(1)
+	}
+}
+
+// hit records a request answered without downloading it: an in-process
+// repeat, a 304 revalidation, a name cache hit, or an offline snapshot.
+func (s *apiStats) hit() {
+	if s != nil {
+		s.CacheHits.Add(1)
+	}
+}
+
+// body wraps a response body so the bytes read from it are counted.
+func (s *apiStats) body(rc io.ReadCloser) io.ReadCloser {
+	if s == nil {
+		return rc
+	}
+	return &countingBody{ReadCloser: rc, n: &s.received}
+}
+
+type countingBody struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
+
+// Print writes the totals to w as one line, or as a JSON object when
+// asJSON is set.
+func (s *apiStats) Print(w io.Writer, asJSON bool) {
+	if s == nil {
+		return
+	}
+	latency := time.Duration(s.latency.Load())
+	if asJSON {
+		_ = json.NewEncoder(w).Encode(map[string]any{"stats": map[string]any{
+			"calls":         s.calls.Load(),
+			"bytesSent":     s.sent.Load(),
+			"bytesReceived": s.received.Load(),
+			"retries":       s.retries.Load(),
+			"cacheHits":     s.CacheHits.Load(),
+			"latencyMs":     latency.Milliseconds(),
+		}})
+		return
+	}
+	fmt.Fprintf(w, "stats: %d API calls, %s sent, %s received, %d retries, %d cache hits, %s total latency\n",
+		s.calls.Load(), FormatBytes(s.sent.Load()), FormatBytes(s.received.Load()),
+		s.retries.Load(), s.CacheHits.Load(), latency.Round(time.Millisecond))
+}
+
+func FormatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// meterTransport reports each round trip to the progress indicator and
+// --stats.
+type meterTransport struct {
+	next     http.RoundTripper
+	progress *progress
+	stats    *apiStats
+}
+
+func (t *meterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.progress.Begin()
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	t.stats.call(req.ContentLength, time.Since(start))
+	t.progress.End()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = t.stats.body(resp.Body)
+	return resp, nil
+}
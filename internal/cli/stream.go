@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"trelli/pkg/trello"
+)
+
+// StreamArray GETs a JSON array and calls each for every element as it is
+// decoded, so large listings are never held in memory as a whole. Failed
+// attempts are retried by the API client's layers before the response
+// arrives; a response that breaks off is not, since elements have already
+// been handled. Streamed responses are not snapshotted for --offline, which
+// falls back to a cached copy of the full response if one exists.
+func StreamArray[T any](ctx context.Context, c *Client, p string, query url.Values, each func(T) error) error {
+	if c.Offline {
+		var items []T
+		if err := c.Do(ctx, http.MethodGet, p, query, nil, &items); err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := each(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	p, query, _, err := c.resolveBoardRefs(ctx, p, query, nil)
+	if err != nil {
+		return err
+	}
+	u, err := c.API.Endpoint(p, query)
+	if err != nil {
+		return err
+	}
+	return timeoutHint(streamOnce(ctx, c, u, each))
+}
+
+func streamOnce[T any](ctx context.Context, c *Client, u *url.URL, each func(T) error) error {
+	resp, err := c.API.Open(ctx, http.MethodGet, u, nil, "")
+	if resp == nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("expected a JSON array from %s", trello.RedactURL(u))
+	}
+	for dec.More() {
+		var item T
+		if err := dec.Decode(&item); err != nil {
+			return err
+		}
+		c.Redact.learnValue(item)
+		if err := each(item); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// jsonArrayWriter prints a JSON array one element at a time, matching
+// JSONRenderer's layout, and redacts the elements with --redact.
+type jsonArrayWriter struct {
+	w      io.Writer
+	redact *redactor
+	n      int
+}
+
+func (a *jsonArrayWriter) Write(v any) error {
+	raw, err := json.MarshalIndent(v, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if a.n == 0 {
+		sep = "[\n  "
+	}
+	a.n++
+	_, err = fmt.Fprintf(a.w, "%s%s", sep, a.redact.RedactJSON(raw))
+	return err
+}
+
+func (a *jsonArrayWriter) Close() error {
+	if a.n == 0 {
+		_, err := fmt.Fprintln(a.w, "[]")
+		return err
+	}
+	_, err := fmt.Fprint(a.w, "\n]\n")
+	return err
+}
+
+// PrintStream streams the array at p to stdout: element by element as JSON,
+// or collected and rendered in the output format.
+func PrintStream[T any](ctx context.Context, client *Client, cfg Config, p string, query url.Values, table func([]T) Table) error {
+	client.Progress.SetTask("Reading cards", 0)
+	defer client.Progress.EndTask()
+	if cfg.JSON {
+		out := &jsonArrayWriter{w: os.Stdout, redact: cfg.Redact}
+		if err := StreamArray(ctx, client, p, query, func(item T) error {
+			client.Progress.Advance(1)
+			return out.Write(item)
+		}); err != nil {
+			return err
+		}
+		return out.Close()
+	}
+	var items []T
+	if err := StreamArray(ctx, client, p, query, func(item T) error {
+		client.Progress.Advance(1)
+		items = append(items, item)
+		return nil
+	}); err != nil {
+		return err
+	}
+	return Render(cfg, items, table(items))
+}
+
+// CommentPageSize is the largest page Trello serves for card actions.
+const CommentPageSize = 1000
+
+// PrintAllComments pages backwards through a card's comments with before=,
+// streaming each page with --json and otherwise passing them all to show.
+func PrintAllComments(ctx context.Context, client *Client, cfg Config, cardID string, show func([]CommentAction) error) error {
+	out := &jsonArrayWriter{w: os.Stdout, redact: cfg.Redact}
+	client.Progress.SetTask("Reading comments", 0)
+	defer client.Progress.EndTask()
+	var collected []CommentAction
+	before := ""
+	for {
+		req := CommentsRequest(cardID, CommentPageSize, nil)
+		if before != "" {
+			req.Query.Set("before", before)
+		}
+		n := 0
+		err := StreamArray(ctx, client, req.Path, req.Query, func(a CommentAction) error {
+			n++
+			client.Progress.Advance(1)
+			before = a.ID
+			if cfg.JSON {
+				return out.Write(a)
+			}
+			collected = append(collected, a)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if n < CommentPageSize {
+			break
+		}
+	}
+	if cfg.JSON {
+		return out.Close()
+	}
+	return show(collected)
+}
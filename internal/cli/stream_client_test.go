@@ -0,0 +1,94 @@
+package cli_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+	"trelli/pkg/trello"
+)
+
+// streamClient connects to srv with retries enabled, so the tests can tell
+// what the transport layers retry.
+func streamClient(t *testing.T, srv *httptest.Server) *cli.Client {
+	t.Helper()
+	cfg, _, err := clitest.TestConfig(t, srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.MaxRetries = 2
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func streamIDs(t *testing.T, client *cli.Client) ([]string, error) {
+	t.Helper()
+	var ids []string
+	err := cli.StreamArray(context.Background(), client, "/1/lists/l1/cards", nil, func(c trello.Card) error {
+		ids = append(ids, c.ID)
+		return nil
+	})
+	return ids, err
+}
+
+func TestStreamArrayRetriesBeforeFirstItem(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`[{"id": "c1"}, {"id": "c2"}]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	ids, err := streamIDs(t, streamClient(t, srv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ids, ",") != "c1,c2" || calls.Load() != 2 {
+		t.Errorf("streamed %q in %d calls, want c1,c2 in 2", ids, calls.Load())
+	}
+}
+
+func TestStreamArrayTruncatedBody(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		// Promise more than is sent, so the body breaks off mid-element.
+		body := `[{"id": "c1"}, {"id": "c2"}, {"id": "c`
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	ids, err := streamIDs(t, streamClient(t, srv))
+	if err == nil {
+		t.Fatal("truncated body streamed without an error")
+	}
+	// Delivered elements stay delivered, and nothing is sent again.
+	if strings.Join(ids, ",") != "c1,c2" || calls.Load() != 1 {
+		t.Errorf("streamed %q in %d calls, want c1,c2 in 1", ids, calls.Load())
+	}
+}
+
+func TestStreamArrayNotAnArray(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "c1"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	_, err := streamIDs(t, streamClient(t, srv))
+	if err == nil || !strings.Contains(err.Error(), "expected a JSON array") || strings.Contains(err.Error(), "test-token") {
+		t.Errorf("streamArray = %v", err)
+	}
+}
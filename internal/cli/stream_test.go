@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONArrayWriter(t *testing.T) {
+	var empty strings.Builder
+	if err := (&jsonArrayWriter{w: &empty}).Close(); err != nil || empty.String() != "[]\n" {
+		t.Errorf("empty array = %q, %v", empty.String(), err)
+	}
+
+	var out strings.Builder
+	a := &jsonArrayWriter{w: &out}
+	items := []map[string]string{{"id": "c1", "name": "One"}, {"id": "c2", "name": "Two"}}
+	for _, item := range items {
+		if err := a.Write(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The streamed layout matches rendering the whole slice at once.
+	var whole strings.Builder
+	if err := (JSONRenderer{}).Render(&whole, items); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != whole.String() {
+		t.Errorf("streamed:\n%s\nrendered:\n%s", out.String(), whole.String())
+	}
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// ActionPageSize is the largest page Trello serves for board actions.
+const ActionPageSize = 1000
+
+func BoardRequest(boardID string, out *Board) GetRequest {
+	query := url.Values{}
+	query.Set("fields", "id,name,shortLink,url,closed")
+	return GetRequest{Path: "/1/boards/" + url.PathEscape(boardID), Query: query, Out: out}
+}
+
+// WriteJSONFile atomically replaces file with v as indented JSON.
+func WriteJSONFile(file string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(file); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"trelli/pkg/trello"
+)
+
+// verbosity is a repeatable boolean flag: each -v/--verbose raises the level.
+type verbosity int
+
+func (v *verbosity) String() string   { return strconv.Itoa(int(*v)) }
+func (v *verbosity) IsBoolFlag() bool { return true }
+
+func (v *verbosity) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		n, nerr := strconv.Atoi(s)
+		if nerr != nil {
+			return fmt.Errorf("invalid verbosity %q", s)
+		}
+		*v = verbosity(n)
+		return nil
+	}
+	if b {
+		*v++
+	}
+	return nil
+}
+
+// traceTransport logs requests and responses at debug level. Level 1 logs
+// method, redacted URL, status, and latency; level 2 also logs bodies.
+type traceTransport struct {
+	next  http.RoundTripper
+	log   *slog.Logger
+	level int
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	u := trello.RedactURL(req.URL)
+	t.log.DebugContext(ctx, fmt.Sprintf("> %s %s", req.Method, u), "method", req.Method, "url", u)
+	if t.level >= 2 && req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			raw, _ := io.ReadAll(body)
+			body.Close()
+			if len(raw) > 0 {
+				t.log.DebugContext(ctx, fmt.Sprintf("> %s", raw), "method", req.Method, "url", u, "requestBody", string(raw))
+			}
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		t.log.DebugContext(ctx, fmt.Sprintf("< error after %s: %v", elapsed, err), "method", req.Method, "url", u, "error", err.Error(), "elapsedMs", elapsed.Milliseconds())
+		return nil, err
+	}
+	t.log.DebugContext(ctx, fmt.Sprintf("< %s (%s)", resp.Status, elapsed), "method", req.Method, "url", u, "status", resp.StatusCode, "elapsedMs", elapsed.Milliseconds())
+	if t.level >= 2 {
+		raw, rerr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(raw))
+		if rerr != nil {
+			return nil, rerr
+		}
+		if len(raw) > 0 {
+			t.log.DebugContext(ctx, fmt.Sprintf("< %s", raw), "method", req.Method, "url", u, "responseBody", string(raw))
+		}
+	}
+	return resp, nil
+}
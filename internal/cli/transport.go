@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"trelli/pkg/trello"
+)
+
+// apiSender is the part of trello.Client that Client builds on: endpoint
+// URLs and requests, retried and paced by its layers, plus file uploads.
+type apiSender interface {
+	Endpoint(p string, query url.Values) (*url.URL, error)
+	Send(ctx context.Context, method string, u *url.URL, form url.Values, etag string) ([]byte, http.Header, error)
+	Open(ctx context.Context, method string, u *url.URL, form url.Values, etag string) (*http.Response, error)
+	trello.Uploader
+}
+
+// NewTransport builds the base HTTP transport from proxy and TLS settings.
+// Without --proxy, HTTPS_PROXY/HTTP_PROXY/NO_PROXY from the environment apply.
+//
+// Connections to the API host are kept alive for every concurrent worker
+// and negotiated as HTTP/2 where possible. Accept-Encoding is left to the
+// transport, which then requests gzip and decompresses it transparently;
+// setting the header by hand would disable that.
+func NewTransport(cfg Config) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.ForceAttemptHTTP2 = true
+	transport.DisableCompression = false
+	transport.MaxIdleConnsPerHost = max(cfg.Concurrency, defaultConcurrency)
+	transport.IdleConnTimeout = 90 * time.Second
+	transport.TLSHandshakeTimeout = 10 * time.Second
+
+	if p := strings.TrimSpace(cfg.Proxy); p != "" {
+		proxyURL, err := url.Parse(p)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid --proxy %q: want a URL such as http://proxy.example:3128", p)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if cfg.CACert == "" && !cfg.InsecureSkipVerify {
+		return transport, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CACert != "" {
+		pem, err := os.ReadFile(cfg.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("reading --ca-cert: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("--ca-cert contains no PEM certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.InsecureSkipVerify {
+		slog.Warn("TLS certificate verification is disabled (--insecure-skip-verify)")
+		tlsConfig.InsecureSkipVerify = true
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
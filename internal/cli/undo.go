@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// JournalEntry records one mutation with enough state to reverse it.
+type JournalEntry struct {
+	Time    time.Time `json:"time"`
+	Profile string    `json:"profile"`
+	Action  string    `json:"action"`
+	// Target is the card, comment action, checklist, or check item created
+	// or changed; Parent is the checklist of a check item or the label or
+	// member put on or taken off a card; From is the list a moved card came
+	// from.
+	Target  string `json:"target"`
+	Parent  string `json:"parent,omitempty"`
+	From    string `json:"from,omitempty"`
+	Summary string `json:"summary"`
+}
+
+func JournalPath() (string, error) {
+	p, err := configPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(p), "journal.jsonl"), nil
+}
+
+func ReadJournal() ([]JournalEntry, error) {
+	p, err := JournalPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []JournalEntry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e JournalEntry
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, sc.Err()
+}
+
+// RecordUndo appends e to the journal. Each entry is a single append of one
+// line, so concurrent mutations, in this process (trelli serve) or another,
+// do not lose each other's entries. Failures only warn: the mutation has
+// already happened.
+func RecordUndo(cfg Config, e JournalEntry) {
+	e.Time = time.Now().UTC()
+	e.Profile = FirstNonEmpty(cfg.Profile, "default")
+	if err := appendJournal(e); err != nil {
+		slog.Warn(fmt.Sprintf("could not record undo entry: %v", err), "action", e.Action, "target", e.Target)
+	}
+}
+
+func appendJournal(e JournalEntry) error {
+	p, err := JournalPath()
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Reverse undoes e through the API.
+func (e JournalEntry) Reverse(ctx context.Context, client *Client) error {
+	var err error
+	switch e.Action {
+	case "cards.create":
+		_, err = client.Cards.Archive(ctx, e.Target)
+	case "cards.move":
+		_, err = client.Cards.Move(ctx, e.Target, e.From)
+	case "cards.archive":
+		_, err = client.Cards.Unarchive(ctx, e.Target)
+	case "cards.label-add":
+		err = client.Cards.RemoveLabel(ctx, e.Target, e.Parent)
+	case "cards.label-remove":
+		err = client.Cards.AddLabel(ctx, e.Target, e.Parent)
+	case "cards.assign":
+		err = client.Cards.RemoveMember(ctx, e.Target, e.Parent)
+	case "cards.unassign":
+		err = client.Cards.AddMember(ctx, e.Target, e.Parent)
+	case "comments.add":
+		err = client.Comments.Delete(ctx, e.Target)
+	case "checklists.create":
+		err = client.Checklists.Delete(ctx, e.Target)
+	case "checklists.add-item":
+		err = client.Checklists.DeleteItem(ctx, e.Parent, e.Target)
+	default:
+		return fmt.Errorf("cannot undo %s", e.Action)
+	}
+	return err
+}
+
+// UndoDescription says what reversing e will do.
+func (e JournalEntry) UndoDescription() string {
+	switch e.Action {
+	case "cards.create":
+		return "archive created card"
+	case "cards.move":
+		return "move card back to list " + e.From
+	case "cards.archive":
+		return "unarchive card"
+	case "cards.label-add":
+		return "remove label"
+	case "cards.label-remove":
+		return "add label back"
+	case "cards.assign":
+		return "unassign member"
+	case "cards.unassign":
+		return "assign member back"
+	case "comments.add":
+		return "delete comment"
+	case "checklists.create":
+		return "delete checklist"
+	case "checklists.add-item":
+		return "delete checklist item"
+	}
+	return "unsupported"
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// VaultCard is a card with the fields its note shows.
+type VaultCard struct {
+	Card
+	IDLabels   []string        `json:"idLabels"`
+	Checklists []Checklist     `json:"-"`
+	Comments   []CommentAction `json:"-"`
+}
+
+// WriteChecklistMarkdown writes a checklist as a heading of level (e.g.
+// "###") and a task list in item order.
+func WriteChecklistMarkdown(b *bytes.Buffer, level string, cl Checklist) {
+	fmt.Fprintf(b, "%s %s\n\n", level, cl.Name)
+	items := SortedCheckItems(cl)
+	for _, item := range items {
+		mark := " "
+		if item.State == "complete" {
+			mark = "x"
+		}
+		fmt.Fprintf(b, "- [%s] %s\n", mark, item.Name)
+	}
+	if len(items) > 0 {
+		b.WriteString("\n")
+	}
+}
+
+// SortedCheckItems returns the items of a checklist in their order on the
+// card.
+func SortedCheckItems(cl Checklist) []ChecklistItem {
+	items := slices.Clone(cl.CheckItems)
+	slices.SortStableFunc(items, func(x, y ChecklistItem) int { return cmp.Compare(x.Pos, y.Pos) })
+	return items
+}
+
+// WriteCommentsMarkdown writes comments oldest first, each as its author
+// and time followed by the text quoted.
+func WriteCommentsMarkdown(b *bytes.Buffer, comments []CommentAction) {
+	// Trello returns comments newest first.
+	for j := len(comments) - 1; j >= 0; j-- {
+		cm := comments[j]
+		who := FirstNonEmpty(cm.MemberCreator.FullName, cm.MemberCreator.Username)
+		fmt.Fprintf(b, "**%s** (%s)\n\n", who, NoteTime(cm.Date))
+		for _, line := range strings.Split(strings.TrimSpace(cm.Data.Text), "\n") {
+			b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+		b.WriteString("\n")
+	}
+}
+
+// ShortLinkOf returns the shortLink of a card from its short URL.
+func ShortLinkOf(c Card) string {
+	if i := strings.LastIndexByte(c.ShortURL, '/'); i >= 0 && i < len(c.ShortURL)-1 {
+		return c.ShortURL[i+1:]
+	}
+	return c.ID
+}
+
+// NoteTime formats a Trello timestamp as "2006-01-02 15:04" UTC.
+func NoteTime(s string) string {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return s
+	}
+	return t.UTC().Format("2006-01-02 15:04")
+}
+
+func DateOnly(s string) string {
+	if len(s) >= 10 {
+		return s[:10]
+	}
+	return s
+}
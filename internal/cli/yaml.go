@@ -0,0 +1,562 @@
+package cli
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecodeYAML decodes the subset of YAML that rule and board files need
+// into v, a pointer: block mappings and sequences, "- key: value" items,
+// plain, quoted, and block (| and >) scalars, flow [a, b] and {k: v}
+// values, and # comments. Anchors, tags, and multiple documents are not
+// supported. Fields match mapping keys by their json tag, and a key that
+// matches no field is an error, so typos do not go unnoticed.
+func DecodeYAML(data []byte, v any) error {
+	node, err := parseYAML(data)
+	if err != nil {
+		return err
+	}
+	return yamlAssign(reflect.ValueOf(v).Elem(), node, "")
+}
+
+// yamlPlain is an unquoted scalar; its type is that of the field it is
+// decoded into, so that 5 is a number in one place and a name in another.
+type yamlPlain string
+
+type yamlLine struct {
+	num    int
+	indent int
+	text   string // without indentation and comment
+	raw    string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func parseYAML(data []byte) (any, error) {
+	p := &yamlParser{}
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimLeft(raw, " ")
+		text := strings.TrimSpace(stripYAMLComment(trimmed))
+		if text != "" && strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		if i == 0 && text == "---" {
+			text = ""
+		}
+		p.lines = append(p.lines, yamlLine{num: i + 1, indent: len(raw) - len(trimmed), text: text, raw: raw})
+	}
+	p.skipBlank()
+	if p.pos == len(p.lines) {
+		return nil, nil
+	}
+	node, err := p.parseNode(p.lines[p.pos].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.skipBlank(); p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].num)
+	}
+	return node, nil
+}
+
+// stripYAMLComment cuts a # comment, which starts a line or follows a
+// space outside quotes.
+func stripYAMLComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+func (p *yamlParser) skipBlank() {
+	for p.pos < len(p.lines) && p.lines[p.pos].text == "" {
+		p.pos++
+	}
+}
+
+func isYAMLSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *yamlParser) parseNode(indent int) (any, error) {
+	if isYAMLSeqItem(p.lines[p.pos].text) {
+		return p.parseSeq(indent)
+	}
+	return p.parseMap(indent)
+}
+
+// parseChild parses the block value of a key or item with an empty
+// value on its own line, if the next line is indented deeper.
+func (p *yamlParser) parseChild(indent int) (any, error) {
+	if p.skipBlank(); p.pos == len(p.lines) || p.lines[p.pos].indent <= indent {
+		return nil, nil
+	}
+	return p.parseNode(p.lines[p.pos].indent)
+}
+
+func (p *yamlParser) parseSeq(indent int) (any, error) {
+	seq := []any{}
+	for p.skipBlank(); p.pos < len(p.lines); p.skipBlank() {
+		l := p.lines[p.pos]
+		if l.indent < indent || !isYAMLSeqItem(l.text) {
+			break
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", l.num)
+		}
+		rest := strings.TrimSpace(l.text[1:])
+		var item any
+		var err error
+		switch {
+		case rest == "":
+			p.pos++
+			item, err = p.parseChild(indent)
+		case isYAMLKey(rest):
+			// "- key: value" starts a mapping indented to its first key.
+			col := l.indent + len(l.text) - len(rest)
+			p.lines[p.pos].indent, p.lines[p.pos].text = col, rest
+			item, err = p.parseMap(col)
+		default:
+			p.pos++
+			item, err = p.parseValue(rest, indent, l.num)
+		}
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, item)
+	}
+	return seq, nil
+}
+
+func (p *yamlParser) parseMap(indent int) (any, error) {
+	m := map[string]any{}
+	for p.skipBlank(); p.pos < len(p.lines); p.skipBlank() {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", l.num)
+		}
+		key, rest, ok := splitYAMLKey(l.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", l.num)
+		}
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", l.num, key)
+		}
+		p.pos++
+		var v any
+		var err error
+		if rest != "" {
+			v, err = p.parseValue(rest, indent, l.num)
+		} else if v, err = p.parseChild(indent); err == nil && v == nil && p.pos < len(p.lines) {
+			// A sequence may sit at the indentation of its key.
+			if next := p.lines[p.pos]; next.indent == indent && isYAMLSeqItem(next.text) {
+				v, err = p.parseSeq(indent)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+func isYAMLKey(text string) bool {
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return false
+	}
+	_, _, ok := splitYAMLKey(text)
+	return ok
+}
+
+// splitYAMLKey splits "key: value" into the key and the value, which is
+// empty when it follows on the next lines.
+func splitYAMLKey(text string) (key, rest string, ok bool) {
+	if text != "" && (text[0] == '"' || text[0] == '\'') {
+		k, after, err := yamlQuoted(text)
+		if err != nil || (after != ":" && !strings.HasPrefix(after, ": ")) {
+			return "", "", false
+		}
+		return k, strings.TrimSpace(after[1:]), true
+	}
+	i := strings.Index(text, ": ")
+	if i < 0 {
+		if !strings.HasSuffix(text, ":") {
+			return "", "", false
+		}
+		i = len(text) - 1
+	}
+	key = strings.TrimSpace(text[:i])
+	return key, strings.TrimSpace(text[i+1:]), key != ""
+}
+
+// parseValue parses the value after "key:" or "-" on the same line.
+func (p *yamlParser) parseValue(s string, indent, num int) (any, error) {
+	if s[0] == '|' || s[0] == '>' {
+		return p.parseBlockScalar(s, indent, num)
+	}
+	v, rest, err := yamlFlow(s, false)
+	if err == nil && strings.TrimSpace(rest) != "" {
+		err = fmt.Errorf("unexpected %q", strings.TrimSpace(rest))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", num, err)
+	}
+	return v, nil
+}
+
+// parseBlockScalar reads the lines of a | (literal) or > (folded) block
+// scalar indented deeper than its key. A - after the indicator drops the
+// final newline.
+func (p *yamlParser) parseBlockScalar(header string, indent, num int) (any, error) {
+	style, chomp := header[0], strings.TrimSpace(header[1:])
+	if chomp != "" && chomp != "-" {
+		return nil, fmt.Errorf("line %d: unsupported block scalar header %q", num, header)
+	}
+	var body []string
+	blockIndent := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		l := p.lines[p.pos]
+		if strings.TrimSpace(l.raw) == "" {
+			body = append(body, "")
+			continue
+		}
+		if l.indent <= indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = l.indent
+		}
+		if l.indent < blockIndent {
+			return nil, fmt.Errorf("line %d: less indented than the block above", l.num)
+		}
+		body = append(body, l.raw[blockIndent:])
+	}
+	for len(body) > 0 && body[len(body)-1] == "" {
+		body = body[:len(body)-1]
+	}
+	var b strings.Builder
+	if style == '|' {
+		b.WriteString(strings.Join(body, "\n"))
+	} else {
+		// Folding joins lines with spaces; a blank line is a line break.
+		for i, line := range body {
+			switch {
+			case line == "":
+				b.WriteByte('\n')
+				continue
+			case i > 0 && body[i-1] != "":
+				b.WriteByte(' ')
+			}
+			b.WriteString(line)
+		}
+	}
+	if chomp == "" && len(body) > 0 {
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
+
+// yamlFlow parses the value at the start of s and returns what follows
+// it. Inside [ ] and { }, plain scalars end at a comma or bracket.
+func yamlFlow(s string, inFlow bool) (any, string, error) {
+	s = strings.TrimLeft(s, " ")
+	switch {
+	case s == "":
+		return nil, "", nil
+	case s[0] == '[':
+		seq := []any{}
+		s = s[1:]
+		for {
+			if s = strings.TrimLeft(s, " "); s == "" {
+				return nil, "", errors.New("unterminated [ ]")
+			} else if s[0] == ']' {
+				return seq, s[1:], nil
+			}
+			v, rest, err := yamlFlow(s, true)
+			if err != nil {
+				return nil, "", err
+			}
+			seq = append(seq, v)
+			if s = strings.TrimLeft(rest, " "); strings.HasPrefix(s, ",") {
+				s = s[1:]
+			} else if s != "" && s[0] != ']' {
+				return nil, "", errors.New("expected , or ] in [ ]")
+			}
+		}
+	case s[0] == '{':
+		m := map[string]any{}
+		s = s[1:]
+		for {
+			if s = strings.TrimLeft(s, " "); s == "" {
+				return nil, "", errors.New("unterminated { }")
+			} else if s[0] == '}' {
+				return m, s[1:], nil
+			}
+			var key string
+			if s[0] == '"' || s[0] == '\'' {
+				k, rest, err := yamlQuoted(s)
+				if err != nil {
+					return nil, "", err
+				}
+				key, s = k, strings.TrimLeft(rest, " ")
+			} else {
+				i := strings.IndexAny(s, ":,}")
+				if i < 0 {
+					return nil, "", errors.New("unterminated { }")
+				}
+				key, s = strings.TrimSpace(s[:i]), s[i:]
+			}
+			if !strings.HasPrefix(s, ":") {
+				return nil, "", fmt.Errorf("expected : after %q in { }", key)
+			}
+			v, rest, err := yamlFlow(s[1:], true)
+			if err != nil {
+				return nil, "", err
+			}
+			m[key] = v
+			if s = strings.TrimLeft(rest, " "); strings.HasPrefix(s, ",") {
+				s = s[1:]
+			} else if s != "" && s[0] != '}' {
+				return nil, "", errors.New("expected , or } in { }")
+			}
+		}
+	case s[0] == '"' || s[0] == '\'':
+		return yamlQuoted(s)
+	}
+	end := len(s)
+	if inFlow {
+		if i := strings.IndexAny(s, ",]}"); i >= 0 {
+			end = i
+		}
+	}
+	switch v := strings.TrimSpace(s[:end]); v {
+	case "", "~", "null", "Null", "NULL":
+		return nil, s[end:], nil
+	default:
+		return yamlPlain(v), s[end:], nil
+	}
+}
+
+// yamlQuoted parses the quoted string at the start of s: "..." with
+// backslash escapes, or '...' where ” is a quote.
+func yamlQuoted(s string) (string, string, error) {
+	if s[0] == '"' {
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				v, err := strconv.Unquote(s[:i+1])
+				if err != nil {
+					return "", "", fmt.Errorf("invalid string %s", s[:i+1])
+				}
+				return v, s[i+1:], nil
+			}
+		}
+		return "", "", errors.New("unterminated \"")
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] != '\'' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '\'' {
+			b.WriteByte('\'')
+			i++
+			continue
+		}
+		return b.String(), s[i+1:], nil
+	}
+	return "", "", errors.New("unterminated '")
+}
+
+// yamlAssign stores a parsed node in dst, converting plain scalars to
+// dst's type. path names the node in errors.
+func yamlAssign(dst reflect.Value, node any, path string) error {
+	if node == nil {
+		return nil
+	}
+	where := cmp.Or(path, "document")
+	plain, isPlain := node.(yamlPlain)
+	switch dst.Kind() {
+	case reflect.Pointer:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return yamlAssign(dst.Elem(), node, path)
+	case reflect.Interface:
+		dst.Set(reflect.ValueOf(yamlInterface(node)))
+		return nil
+	case reflect.Struct:
+		m, ok := node.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: want a mapping", where)
+		}
+		fields := map[string]int{}
+		for i := 0; i < dst.NumField(); i++ {
+			f := dst.Type().Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			fields[cmp.Or(name, f.Name)] = i
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			i, ok := fields[k]
+			if !ok {
+				return fmt.Errorf("%s: unknown field %q", where, k)
+			}
+			if err := yamlAssign(dst.Field(i), m[k], yamlPath(path, k)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		m, ok := node.(map[string]any)
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("%s: want a mapping", where)
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(dst.Type()))
+		}
+		for k, v := range m {
+			e := reflect.New(dst.Type().Elem()).Elem()
+			if err := yamlAssign(e, v, yamlPath(path, k)); err != nil {
+				return err
+			}
+			dst.SetMapIndex(reflect.ValueOf(k).Convert(dst.Type().Key()), e)
+		}
+		return nil
+	case reflect.Slice:
+		seq, ok := node.([]any)
+		if !ok {
+			return fmt.Errorf("%s: want a sequence", where)
+		}
+		out := reflect.MakeSlice(dst.Type(), len(seq), len(seq))
+		for i, v := range seq {
+			if err := yamlAssign(out.Index(i), v, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(out)
+		return nil
+	case reflect.String:
+		switch v := node.(type) {
+		case string:
+			dst.SetString(v)
+		case yamlPlain:
+			dst.SetString(string(v))
+		default:
+			return fmt.Errorf("%s: want a string", where)
+		}
+		return nil
+	case reflect.Bool:
+		if b, ok := yamlBool(plain); isPlain && ok {
+			dst.SetBool(b)
+			return nil
+		}
+		return fmt.Errorf("%s: want true or false", where)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if dst.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(string(plain))
+			if !isPlain || err != nil {
+				return fmt.Errorf("%s: want a duration such as 90s or 5m", where)
+			}
+			dst.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(string(plain), 10, dst.Type().Bits())
+		if !isPlain || err != nil {
+			return fmt.Errorf("%s: want a whole number", where)
+		}
+		dst.SetInt(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(string(plain), dst.Type().Bits())
+		if !isPlain || err != nil {
+			return fmt.Errorf("%s: want a number", where)
+		}
+		dst.SetFloat(f)
+		return nil
+	}
+	return fmt.Errorf("%s: cannot decode into %s", where, dst.Type())
+}
+
+// yamlInterface converts a node for an untyped field, guessing the types
+// of plain scalars as YAML does.
+func yamlInterface(node any) any {
+	switch v := node.(type) {
+	case yamlPlain:
+		if b, ok := yamlBool(v); ok {
+			return b
+		}
+		if n, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return n
+		}
+		if f, err := strconv.ParseFloat(string(v), 64); err == nil {
+			return f
+		}
+		return string(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = yamlInterface(e)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = yamlInterface(e)
+		}
+		return out
+	}
+	return node
+}
+
+func yamlBool(s yamlPlain) (bool, bool) {
+	switch strings.ToLower(string(s)) {
+	case "true", "yes", "on":
+		return true, true
+	case "false", "no", "off":
+		return false, true
+	}
+	return false, false
+}
+
+func yamlPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	type item struct {
+		Name  string   `json:"name"`
+		Count int      `json:"count"`
+		Tags  []string `json:"tags"`
+	}
+	type doc struct {
+		Board    string         `json:"board"`
+		Enabled  bool           `json:"enabled"`
+		Interval time.Duration  `json:"interval"`
+		Items    []item         `json:"items"`
+		Limits   map[string]int `json:"limits"`
+		Desc     string         `json:"desc"`
+		Folded   string         `json:"folded"`
+		Extra    any            `json:"extra"`
+	}
+	src := `---
+# A comment
+board: "EnGi #1"   # trailing comment
+enabled: yes
+interval: 5m
+items:
+- name: 2026     # a number as a name
+  count: 3
+  tags: [a, 'b, c', "d"]
+- name: It''s
+  tags:
+    - x
+limits: {Doing: 5, "In Review": 2}
+desc: |
+  Line one
+
+  Line # three
+folded: >-
+  one
+  two
+
+  three
+extra: {n: 1, ok: true, s: text}
+`
+	var got doc
+	if err := DecodeYAML([]byte(src), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := doc{
+		Board:    "EnGi #1",
+		Enabled:  true,
+		Interval: 5 * time.Minute,
+		Items:    []item{{Name: "2026", Count: 3, Tags: []string{"a", "b, c", "d"}}, {Name: "It''s", Tags: []string{"x"}}},
+		Limits:   map[string]int{"Doing": 5, "In Review": 2},
+		Desc:     "Line one\n\nLine # three\n",
+		Folded:   "one two\nthree",
+		Extra:    map[string]any{"n": int64(1), "ok": true, "s": "text"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeYAML =\n%#v\nwant\n%#v", got, want)
+	}
+
+	for src, wantErr := range map[string]string{
+		"board: a\nbord: b":                `document: unknown field "bord"`,
+		"board: a\nboard: b":               `line 2: duplicate key "board"`,
+		"items:\n- name: a\n  count: many": "items[0].count: want a whole number",
+		"enabled: \"true\"":                "enabled: want true or false",
+		"board: a\n    b: c":               "line 2: unexpected indentation",
+		"limits: {Doing: 5":                "line 1: unterminated { }",
+		"board:\n\t- a":                    "line 2: tabs are not allowed for indentation",
+		"items: [a":                        "line 1: unterminated [ ]",
+		"items: [a; b}":                    "line 1: expected , or ] in [ ]",
+		"limits: {":                        "line 1: unterminated { }",
+	} {
+		var d doc
+		if err := DecodeYAML([]byte(src), &d); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("decodeYAML(%q) error = %v, want %q", src, err, wantErr)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Package clitest runs trelli commands in tests against a stub of the
+// Trello API and compares their output with golden files.
+package clitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata/golden")
+
+// stubRoutes are the Trello responses served by the test stub, by path.
+// Unknown paths get Trello's 404.
+var stubRoutes = map[string]string{
+	"/1/members/me": `{"id": "m1", "username": "ada", "fullName": "Ada Lovelace"}`,
+	"/1/members/me/boards": `[
+		{"id": "b2", "name": "Roadmap", "shortLink": "RdMp", "url": "https://trello.com/b/RdMp/roadmap", "closed": false},
+		{"id": "b1", "name": "Engineering", "shortLink": "EnGi", "url": "https://trello.com/b/EnGi/engineering", "closed": false}
+	]`,
+	"/1/boards/b1":         `{"id": "b1", "name": "Engineering", "shortLink": "EnGi", "url": "https://trello.com/b/EnGi/engineering"}`,
+	"/1/boards/b1/lists":   `[{"id": "l1", "name": "To Do", "closed": false}, {"id": "l2", "name": "Done", "closed": false}]`,
+	"/1/boards/b1/members": `[{"id": "m1", "username": "ada", "fullName": "Ada Lovelace"}, {"id": "m2", "username": "grace", "fullName": "Grace Hopper"}]`,
+	"/1/boards/b1/labels":  `[{"id": "lb1", "name": "Bug", "color": "red"}, {"id": "lb2", "name": "Feature", "color": "green"}]`,
+	"/1/boards/b1/cards": `[
+		{"id": "c1", "name": "Fix login, again", "desc": "Users are logged out after 5 minutes.", "idList": "l1", "idLabels": ["lb1"], "due": "2026-03-01T12:00:00.000Z", "shortUrl": "https://trello.com/c/AbCd", "closed": false, "dateLastActivity": "2026-02-10T09:30:00.000Z", "attachments": [{"id": "at1", "url": "https://github.com/acme/app/issues/1"}]},
+		{"id": "c2", "name": "Write \"release\" notes", "idList": "l1", "idLabels": ["lb1", "lb2"], "shortUrl": "https://trello.com/c/EfGh", "closed": false, "dateLastActivity": "2026-02-01T00:00:00.000Z", "attachments": [{"id": "at2", "url": "https://github.com/acme/app/issues/4"}, {"id": "at4", "url": "https://acme.atlassian.net/browse/PROJ-1"}]},
+		{"id": "c3", "name": "Old idea", "idList": "l2", "shortUrl": "https://trello.com/c/IjKl", "closed": true, "dateLastActivity": "2026-03-01T00:00:00.000Z", "attachments": [{"id": "at3", "url": "https://github.com/acme/app/issues/5"}]}
+	]`,
+	"/1/boards/b2":       `{"id": "b2", "name": "Management", "shortLink": "MgMt", "url": "https://trello.com/b/MgMt/management"}`,
+	"/1/boards/b2/lists": `[{"id": "l3", "name": "to do", "closed": false}]`,
+	"/1/boards/b2/cards": `[
+		{"id": "c4", "name": "Fix login, again", "idList": "l3", "closed": false, "dateLastActivity": "2026-02-20T00:00:00.000Z"},
+		{"id": "c5", "name": "Budget review", "idList": "l3", "closed": false, "dateLastActivity": "2026-02-15T00:00:00.000Z"}
+	]`,
+	"/1/lists/l1/cards": `[
+		{"id": "c1", "name": "Fix login, again", "idList": "l1", "due": "2026-03-01T12:00:00.000Z", "shortUrl": "https://trello.com/c/AbCd", "closed": false, "dateLastActivity": "2026-02-10T09:30:00.000Z"},
+		{"id": "c2", "name": "Write \"release\" notes", "idList": "l1", "shortUrl": "https://trello.com/c/EfGh", "closed": false, "dateLastActivity": "2026-02-01T00:00:00.000Z"}
+	]`,
+	"/1/lists/l2/cards": `[]`,
+	"/1/cards/c1":       `{"id": "c1", "idShort": 12, "idBoard": "b1", "name": "Fix login, again", "desc": "Users are logged out after 5 minutes.", "idList": "l1", "due": "2026-03-01T12:00:00.000Z", "shortUrl": "https://trello.com/c/AbCd", "closed": false, "dueReminder": 1440}`,
+	"/1/cards/c1/checklists": `[
+		{"id": "k1", "name": "Steps", "checkItems": [{"id": "i1", "name": "Reproduce", "state": "complete"}, {"id": "i2", "name": "Fix", "state": "incomplete"}]},
+		{"id": "k2", "name": "Empty", "checkItems": []}
+	]`,
+	"/1/cards/c2/checklists": `[]`,
+	"/1/cards/c2/actions":    `[]`,
+	"/1/cards/c3/checklists": `[]`,
+	"/1/cards/c3/actions":    `[]`,
+	"/1/search":              `{"cards": [{"id": "c1", "name": "Fix login, again", "idList": "l1", "shortUrl": "https://trello.com/c/AbCd", "closed": false}]}`,
+	"/1/cards/c1/actions":    `[{"id": "a1", "type": "commentCard", "date": "2026-02-10T09:30:00.000Z", "data": {"text": "Seen on staging too."}, "memberCreator": {"username": "ada", "fullName": "Ada Lovelace"}}]`,
+}
+
+// NewStub serves stubRoutes, including /1/batch, and answers writes with a
+// created card.
+func NewStub(t *testing.T) *httptest.Server {
+	t.Helper()
+	route := func(p string) (string, int) {
+		if body, ok := stubRoutes[p]; ok {
+			return body, http.StatusOK
+		}
+		return `{"message": "The requested resource was not found."}`, http.StatusNotFound
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("token") != "test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"message": "invalid token"}`)
+			return
+		}
+		switch {
+		case r.Method != http.MethodGet:
+			io.WriteString(w, `{"id": "c9", "name": "Created", "idList": "l1", "shortUrl": "https://trello.com/c/NeWc"}`)
+		case r.URL.Path == "/1/batch":
+			var results []map[string]json.RawMessage
+			for _, u := range strings.Split(r.URL.Query().Get("urls"), ",") {
+				p, _, _ := strings.Cut(u, "?")
+				body, status := route("/1" + p)
+				if status != http.StatusOK {
+					results = append(results, map[string]json.RawMessage{"statusCode": json.RawMessage("404"), "message": json.RawMessage(`"not found"`)})
+					continue
+				}
+				results = append(results, map[string]json.RawMessage{"200": json.RawMessage(body)})
+			}
+			json.NewEncoder(w).Encode(results)
+		default:
+			body, status := route(r.URL.Path)
+			w.WriteHeader(status)
+			io.WriteString(w, body)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// RunCLI runs trelli with args against srv the way main does and returns
+// what it wrote to stdout, followed by the error main would print.
+func RunCLI(t *testing.T, srv *httptest.Server, args ...string) string {
+	t.Helper()
+	return RunCLIWithConfig(t, srv, nil, args...)
+}
+
+// RunCLIWithConfig is RunCLI with fc as the config file, when it is not nil.
+func RunCLIWithConfig(t *testing.T, srv *httptest.Server, fc cli.FileConfig, args ...string) string {
+	t.Helper()
+	stdout := CaptureStdout(t)
+	var cfg cli.Config
+	err := func() error {
+		var rest []string
+		var err error
+		cfg, rest, err = TestConfig(t, srv, args...)
+		if err != nil {
+			return err
+		}
+		if fc != nil {
+			cfg.File = fc
+		}
+		rest = cli.ResolveAliases(rest)
+		spec, ok := cli.FindCommand(rest[0])
+		if !ok {
+			t.Fatalf("unknown command %q", rest[0])
+		}
+		remaining := cfg.File.WithCommandDefaults(spec.Name, rest[1:])
+		client, err := cli.Connect(cfg)
+		if err != nil {
+			return err
+		}
+		return spec.Run(client, cfg, remaining)
+	}()
+	out := stdout()
+	if err != nil && !errors.Is(err, cli.ErrHelpDisplayed) && !errors.Is(err, cli.ErrDryRun) {
+		var buf bytes.Buffer
+		cli.WriteError(&buf, err, cfg.JSON || cli.HasJSONFlag(args))
+		out += "--- error\n" + buf.String()
+	}
+	return out
+}
+
+// TestConfig isolates config, cache, and credentials in a temporary
+// directory and parses args after global flags that point at srv.
+func TestConfig(t *testing.T, srv *httptest.Server, args ...string) (cli.Config, []string, error) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("TRELLI_CONFIG", filepath.Join(dir, "config.json"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	t.Setenv("HOME", dir)
+	t.Setenv("TRELLO_API_KEY", "test-key")
+	t.Setenv("TRELLO_TOKEN", "test-token")
+	t.Setenv("TRELLO_BOARD_ID", "b1")
+	t.Setenv("TRELLI_PROFILE", "")
+
+	global := append([]string{"--base-url", srv.URL, "--no-progress", "--no-retry", "--rate", "0"}, args...)
+	cfg, rest, _, err := cli.ParseGlobal(global)
+	cfg.Context = context.Background()
+	return cfg, rest, err
+}
+
+// CaptureStdout redirects os.Stdout until the returned function is called,
+// which restores it and returns everything written.
+func CaptureStdout(t *testing.T) func() string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		done <- buf.String()
+	}()
+	return func() string {
+		os.Stdout = orig
+		w.Close()
+		return <-done
+	}
+}
+
+// CheckGolden compares got with testdata/golden/<name>.golden, rewriting
+// the file instead under -update.
+func CheckGolden(t *testing.T, name, got string) {
+	t.Helper()
+	file := filepath.Join("testdata", "golden", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s (run go test -update to accept):\n--- got\n%s--- want\n%s", file, got, want)
+	}
+}
+
+// WithStubRoutes adds or replaces stub routes for the rest of the test.
+func WithStubRoutes(t *testing.T, routes map[string]string) {
+	t.Helper()
+	for p, body := range routes {
+		old, ok := stubRoutes[p]
+		stubRoutes[p] = body
+		t.Cleanup(func() {
+			if ok {
+				stubRoutes[p] = old
+			} else {
+				delete(stubRoutes, p)
+			}
+		})
+	}
+}
+
+// WriteRecorder serves stub and records the method and path of its writes.
+func WriteRecorder(t *testing.T, stub *httptest.Server) (*httptest.Server, *[]string) {
+	var writes []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writes = append(writes, r.Method+" "+r.URL.Path)
+		}
+		stub.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &writes
+}
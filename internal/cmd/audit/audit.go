@@ -0,0 +1,331 @@
+// Package audit implements "trelli audit": review who has access to boards.
+package audit
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "audit",
+	Summary: "Review who has access to boards",
+	Description: `access lists the members of a board with their role (admin, normal, or
+observer) and the date of their last action on the board, for periodic
+access reviews. Members are flagged when they are observers, external
+(not in the board's Workspace), invited but unconfirmed, deactivated, or
+inactive: without an action in the last --inactive (default 90d). Only
+that much of the board's history is read, so an inactive member has no
+last activity date.
+
+With --org, access lists the members of a Workspace instead, with the
+guests of its open boards as external members and the boards they are on.
+Their last activity is their last action in the Workspace.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "access", Usage: []string{
+			"access [[--board] <boardIdOrShortLink>] [--inactive <age>]",
+			"access --org <orgIdOrName> [--inactive <age>]",
+		}, Flags: []cli.Flag{
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "org", Arg: "id", Desc: "Workspace id or name, instead of a board"},
+			{Name: "inactive", Arg: "age", Desc: "Flag members without an action for this long, e.g. 30d or 26w (default 90d)"},
+		}},
+	},
+	Options: []cli.Flag{cli.JSONOption},
+	Run:     runAudit,
+}
+
+// accessMember is one member in audit access.
+type accessMember struct {
+	ID         string   `json:"id"`
+	Username   string   `json:"username"`
+	Name       string   `json:"name"`
+	Role       string   `json:"role"`
+	External   bool     `json:"external"`
+	Boards     []string `json:"boards,omitempty"`
+	LastActive string   `json:"lastActive,omitempty"`
+	Flags      []string `json:"flags"`
+}
+
+// trelloMembership is a member's membership of a board or Workspace.
+type trelloMembership struct {
+	IDMember    string     `json:"idMember"`
+	MemberType  string     `json:"memberType"`
+	Unconfirmed bool       `json:"unconfirmed"`
+	Deactivated bool       `json:"deactivated"`
+	Member      cli.Member `json:"member"`
+}
+
+// accessRoleOrder sorts admins before normal members before observers.
+var accessRoleOrder = []string{"admin", "normal", "observer"}
+
+func runAudit(client *cli.Client, cfg cli.Config, args []string) error {
+	if len(args) == 0 {
+		cli.PrintCommandHelp("audit")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("audit")
+		return nil
+	case "access":
+		return runAuditAccess(cfg.Context, client, cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown audit subcommand %q", args[0])
+	}
+}
+
+func runAuditAccess(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("audit access", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boardID := cfg.BoardID
+	var orgID string
+	inactive := "90d"
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&orgID, "org", "", "Workspace id or name")
+	fs.StringVar(&inactive, "inactive", inactive, "Flag members without an action for this long")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("audit")); err != nil {
+		return err
+	}
+	var positionalBoard string
+	if err := cli.TakePositional(fs, &positionalBoard); err != nil {
+		return err
+	}
+	age, err := cli.ParseAge(inactive)
+	if err != nil {
+		return fmt.Errorf("invalid --inactive: %w", err)
+	}
+	since := time.Now().UTC().Add(-age)
+
+	var members []accessMember
+	if orgID != "" {
+		if positionalBoard != "" {
+			return errors.New("--org and a board cannot be combined")
+		}
+		members, err = auditOrgAccess(ctx, client, cfg, orgID, since)
+	} else {
+		boardID = cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(positionalBoard, boardID))
+		if strings.TrimSpace(boardID) == "" {
+			return cli.ErrNoBoard
+		}
+		members, err = auditBoardAccess(ctx, client, boardID, since)
+	}
+	if err != nil {
+		return err
+	}
+	slices.SortStableFunc(members, func(a, b accessMember) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(accessRoleOrder, a.Role), slices.Index(accessRoleOrder, b.Role)),
+			strings.Compare(strings.ToLower(a.Username), strings.ToLower(b.Username)),
+		)
+	})
+	return cli.Render(cfg, cli.NonNil(members), accessTable(members))
+}
+
+// auditBoardAccess lists the board's members, flagging those outside its
+// Workspace.
+func auditBoardAccess(ctx context.Context, client *cli.Client, boardID string, since time.Time) ([]accessMember, error) {
+	var board struct {
+		ID             string `json:"id"`
+		IDOrganization string `json:"idOrganization"`
+	}
+	var memberships []trelloMembership
+	query := url.Values{}
+	query.Set("fields", "id,name,idOrganization")
+	if err := client.GetAll(ctx,
+		cli.GetRequest{Path: "/1/boards/" + url.PathEscape(boardID), Query: query, Out: &board},
+		membershipsRequest("/1/boards/"+url.PathEscape(boardID), &memberships),
+	); err != nil {
+		return nil, err
+	}
+	// A board outside any Workspace has no external members to tell apart.
+	var inOrg map[string]bool
+	if board.IDOrganization != "" {
+		var org []cli.Member
+		query := url.Values{}
+		query.Set("fields", "id")
+		if err := client.Do(ctx, http.MethodGet, "/1/organizations/"+url.PathEscape(board.IDOrganization)+"/members", query, nil, &org); err != nil {
+			return nil, err
+		}
+		inOrg = map[string]bool{}
+		for _, m := range org {
+			inOrg[m.ID] = true
+		}
+	}
+	members := make([]accessMember, len(memberships))
+	for i, ms := range memberships {
+		members[i] = newAccessMember(ms)
+		members[i].External = inOrg != nil && !inOrg[ms.IDMember]
+	}
+	last, err := fetchLastActive(ctx, client, "/1/boards/"+url.PathEscape(cli.FirstNonEmpty(board.ID, boardID))+"/actions", since, len(members))
+	if err != nil {
+		return nil, err
+	}
+	return flagAccess(members, last), nil
+}
+
+// auditOrgAccess lists the Workspace's members and the guests of its open
+// boards.
+func auditOrgAccess(ctx context.Context, client *cli.Client, cfg cli.Config, orgID string, since time.Time) ([]accessMember, error) {
+	var memberships []trelloMembership
+	var boards []cli.Board
+	query := url.Values{}
+	query.Set("filter", "open")
+	query.Set("fields", "id,name")
+	if err := client.GetAll(ctx,
+		membershipsRequest("/1/organizations/"+url.PathEscape(orgID), &memberships),
+		cli.GetRequest{Path: "/1/organizations/" + url.PathEscape(orgID) + "/boards", Query: query, Out: &boards},
+	); err != nil {
+		return nil, err
+	}
+	var members []accessMember
+	index := map[string]int{}
+	for _, ms := range memberships {
+		index[ms.IDMember] = len(members)
+		members = append(members, newAccessMember(ms))
+	}
+
+	boardMemberships := make([][]trelloMembership, len(boards))
+	tasks := make([]func() error, len(boards))
+	for i, b := range boards {
+		tasks[i] = func() error {
+			return client.GetAll(ctx, membershipsRequest("/1/boards/"+url.PathEscape(b.ID), &boardMemberships[i]))
+		}
+	}
+	if err := cli.Parallel(cfg.Concurrency, tasks...); err != nil {
+		return nil, err
+	}
+	for i, b := range boards {
+		for _, ms := range boardMemberships[i] {
+			j, ok := index[ms.IDMember]
+			if !ok {
+				j = len(members)
+				index[ms.IDMember] = j
+				guest := newAccessMember(ms)
+				guest.External = true
+				members = append(members, guest)
+			}
+			if members[j].External {
+				members[j].Boards = append(members[j].Boards, b.Name)
+				// A guest's role is the most powerful one on any board.
+				if slices.Index(accessRoleOrder, ms.MemberType) < slices.Index(accessRoleOrder, members[j].Role) {
+					members[j].Role = ms.MemberType
+				}
+			}
+		}
+	}
+	last, err := fetchLastActive(ctx, client, "/1/organizations/"+url.PathEscape(orgID)+"/actions", since, len(members))
+	if err != nil {
+		return nil, err
+	}
+	return flagAccess(members, last), nil
+}
+
+// membershipsRequest gets the memberships of a board or Workspace at path,
+// with the members' names.
+func membershipsRequest(path string, out *[]trelloMembership) cli.GetRequest {
+	query := url.Values{}
+	query.Set("filter", "all")
+	query.Set("member", "true")
+	query.Set("member_fields", "username,fullName")
+	return cli.GetRequest{Path: path + "/memberships", Query: query, Out: out}
+}
+
+func newAccessMember(ms trelloMembership) accessMember {
+	m := accessMember{ID: ms.IDMember, Username: ms.Member.Username, Name: ms.Member.FullName, Role: ms.MemberType}
+	if ms.Unconfirmed {
+		m.Flags = append(m.Flags, "unconfirmed")
+	}
+	if ms.Deactivated {
+		m.Flags = append(m.Flags, "deactivated")
+	}
+	return m
+}
+
+// flagAccess sets the last activity of the members and the flags that
+// depend on it and on their role.
+func flagAccess(members []accessMember, last map[string]string) []accessMember {
+	for i := range members {
+		m := &members[i]
+		m.LastActive = last[m.ID]
+		var flags []string
+		if m.Role == "observer" {
+			flags = append(flags, "observer")
+		}
+		if m.External {
+			flags = append(flags, "external")
+		}
+		flags = append(flags, m.Flags...)
+		if m.LastActive == "" {
+			flags = append(flags, "inactive")
+		}
+		m.Flags = cli.NonNil(flags)
+	}
+	return members
+}
+
+// fetchLastActive pages through the actions at path since the cutoff,
+// newest first, and returns the date of each member's latest action. It
+// stops once it has found want members.
+func fetchLastActive(ctx context.Context, client *cli.Client, path string, since time.Time, want int) (map[string]string, error) {
+	last := map[string]string{}
+	before := ""
+	for len(last) < want {
+		query := url.Values{}
+		query.Set("filter", "all")
+		query.Set("limit", fmt.Sprint(cli.ActionPageSize))
+		query.Set("fields", "id,date,idMemberCreator")
+		query.Set("memberCreator", "false")
+		query.Set("since", since.Format(time.RFC3339))
+		if before != "" {
+			query.Set("before", before)
+		}
+		var page []struct {
+			ID              string `json:"id"`
+			Date            string `json:"date"`
+			IDMemberCreator string `json:"idMemberCreator"`
+		}
+		if err := client.Do(ctx, http.MethodGet, path, query, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, a := range page {
+			if _, ok := last[a.IDMemberCreator]; !ok {
+				last[a.IDMemberCreator] = a.Date
+			}
+		}
+		if len(page) < cli.ActionPageSize {
+			break
+		}
+		before = page[len(page)-1].ID
+	}
+	return last, nil
+}
+
+// accessTable has a BOARDS column only when there are guests to show it
+// for.
+func accessTable(members []accessMember) cli.Table {
+	guests := slices.ContainsFunc(members, func(m accessMember) bool { return len(m.Boards) > 0 })
+	t := cli.Table{Columns: []string{"MEMBER", "NAME", "ROLE", "LAST_ACTIVE", "FLAGS"}, Empty: "No members."}
+	if guests {
+		t.Columns = append(t.Columns, "BOARDS")
+	}
+	for _, m := range members {
+		row := []string{"@" + m.Username, m.Name, m.Role, cli.DateOnly(m.LastActive), strings.Join(m.Flags, ",")}
+		if guests {
+			row = append(row, strings.Join(m.Boards, ", "))
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
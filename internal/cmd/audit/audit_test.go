@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// TestMain registers the command as main does, for the tests that run it
+// through the CLI.
+func TestMain(m *testing.M) {
+	cli.Register(Command)
+	os.Exit(m.Run())
+}
+
+var auditRoutes = map[string]string{
+	"/1/boards/b1": `{"id": "b1", "name": "Engineering", "idOrganization": "o1"}`,
+	"/1/boards/b1/memberships": `[
+		{"id": "ms1", "idMember": "m1", "memberType": "admin", "member": {"id": "m1", "username": "ada", "fullName": "Ada Lovelace"}},
+		{"id": "ms2", "idMember": "m2", "memberType": "normal", "member": {"id": "m2", "username": "grace", "fullName": "Grace Hopper"}},
+		{"id": "ms3", "idMember": "m3", "memberType": "observer", "unconfirmed": true, "member": {"id": "m3", "username": "auditor", "fullName": "Ext Auditor"}}
+	]`,
+	"/1/boards/b2/memberships": `[
+		{"id": "ms4", "idMember": "m1", "memberType": "admin", "member": {"id": "m1", "username": "ada", "fullName": "Ada Lovelace"}},
+		{"id": "ms5", "idMember": "m3", "memberType": "normal", "member": {"id": "m3", "username": "auditor", "fullName": "Ext Auditor"}}
+	]`,
+	"/1/organizations/o1/members": `[{"id": "m1"}, {"id": "m2"}]`,
+	"/1/organizations/o1/memberships": `[
+		{"id": "om1", "idMember": "m1", "memberType": "admin", "member": {"id": "m1", "username": "ada", "fullName": "Ada Lovelace"}},
+		{"id": "om2", "idMember": "m2", "memberType": "normal", "deactivated": true, "member": {"id": "m2", "username": "grace", "fullName": "Grace Hopper"}}
+	]`,
+	"/1/organizations/o1/boards": `[{"id": "b1", "name": "Engineering"}, {"id": "b2", "name": "Roadmap"}]`,
+	"/1/boards/b1/actions": `[
+		{"id": "a2", "date": "2026-02-12T10:00:00.000Z", "idMemberCreator": "m1"},
+		{"id": "a1", "date": "2026-02-05T10:00:00.000Z", "idMemberCreator": "m2"}
+	]`,
+	"/1/organizations/o1/actions": `[{"id": "a3", "date": "2026-02-20T10:00:00.000Z", "idMemberCreator": "m3"}]`,
+}
+
+func TestAuditAccess(t *testing.T) {
+	clitest.WithStubRoutes(t, auditRoutes)
+	stub := clitest.NewStub(t)
+	clitest.CheckGolden(t, "audit_access", clitest.RunCLI(t, stub, "audit", "access", "b1"))
+	clitest.CheckGolden(t, "audit_access_org", clitest.RunCLI(t, stub, "audit", "access", "--org", "o1"))
+	clitest.CheckGolden(t, "audit_access_json", clitest.RunCLI(t, stub, "--json", "audit", "access", "--board", "b1"))
+	if got := clitest.RunCLI(t, stub, "audit", "access", "b1", "--inactive", "soon"); !strings.Contains(got, "invalid --inactive") {
+		t.Errorf("bad --inactive: %s", got)
+	}
+}
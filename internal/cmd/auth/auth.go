@@ -0,0 +1,160 @@
+// Package auth implements "trelli auth": store credentials in the OS keychain.
+package auth
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "auth",
+	Summary: "Store credentials in the OS keychain",
+	Description: `Store Trello credentials in the OS keychain (macOS Keychain, Windows
+Credential Manager, or Secret Service via secret-tool on Linux).
+login prompts for the API key and token when not passed, verifies them,
+and stores them for the selected --profile (or "default").
+Stored credentials are used when --key/--token, the profile, and
+TRELLO_API_KEY/TRELLO_TOKEN leave them unset, and after the
+credentials.exec command. Set config credentials.store to "none" to
+disable keychain lookups.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "login", Usage: []string{"login [--key <key>] [--token <token>]"}, Flags: []cli.Flag{
+			{Name: "key", Arg: "key", Desc: "Trello API key (login)"},
+			{Name: "token", Arg: "token", Desc: "Trello token (login)"},
+		}},
+		{Name: "logout", Usage: []string{"logout"}},
+		{Name: "status", Usage: []string{"status"}},
+	},
+	Options: []cli.Flag{cli.JSONOption},
+	Run:     cli.Local(runAuth),
+	Mode:    cli.ModeOnline,
+}
+
+func runAuth(cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) == 0 {
+		cli.PrintCommandHelp("auth")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("auth")
+		return nil
+	case "login":
+		fs := flag.NewFlagSet("auth login", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		var key, token string
+		fs.StringVar(&key, "key", "", "Trello API key (prompted when omitted)")
+		fs.StringVar(&token, "token", "", "Trello token (prompted when omitted)")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("auth")); err != nil {
+			return err
+		}
+		store, err := cli.NewCredentialStore()
+		if err != nil {
+			return err
+		}
+
+		in := bufio.NewReader(os.Stdin)
+		key = strings.TrimSpace(key)
+		if key == "" {
+			fmt.Fprintf(os.Stderr, "Get your API key from %s\n", cli.TrelloAppKeyURL)
+			if key, err = cli.Prompt(in, "Trello API key: ", false); err != nil {
+				return err
+			}
+		}
+		if key == "" {
+			return errors.New("auth login requires an API key")
+		}
+		token = strings.TrimSpace(token)
+		if token == "" {
+			fmt.Fprintf(os.Stderr, "Authorize trelli and copy the token from:\n  %s\n", cli.AuthorizeURL(key))
+			if token, err = cli.Prompt(in, "Trello token: ", true); err != nil {
+				return err
+			}
+		}
+		if token == "" {
+			return errors.New("auth login requires a token")
+		}
+
+		cfg.APIKey, cfg.Token = key, token
+		client, err := cli.NewClient(cfg)
+		if err != nil {
+			return err
+		}
+		me, err := cli.FetchMe(ctx, client)
+		if err != nil {
+			return fmt.Errorf("verifying credentials: %w", err)
+		}
+		if err := store.Set(cli.CredentialAccount(cfg.Profile, "key"), key); err != nil {
+			return err
+		}
+		if err := store.Set(cli.CredentialAccount(cfg.Profile, "token"), token); err != nil {
+			return err
+		}
+		if cfg.Structured() {
+			return cli.Render(cfg, me)
+		}
+		fmt.Printf("Logged in as @%s; credentials stored in %s (profile %s).\n", me.Username, store.Name(), cli.FirstNonEmpty(cfg.Profile, "default"))
+		return nil
+
+	case "logout":
+		fs := flag.NewFlagSet("auth logout", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("auth")); err != nil {
+			return err
+		}
+		store, err := cli.NewCredentialStore()
+		if err != nil {
+			return err
+		}
+		removed := false
+		for _, field := range []string{"key", "token"} {
+			err := store.Delete(cli.CredentialAccount(cfg.Profile, field))
+			if err == nil {
+				removed = true
+				continue
+			}
+			if !errors.Is(err, cli.ErrCredentialNotFound) {
+				return err
+			}
+		}
+		if !removed {
+			return fmt.Errorf("no stored credentials for profile %s", cli.FirstNonEmpty(cfg.Profile, "default"))
+		}
+		fmt.Printf("Removed credentials for profile %s from %s.\n", cli.FirstNonEmpty(cfg.Profile, "default"), store.Name())
+		return nil
+
+	case "status":
+		fs := flag.NewFlagSet("auth status", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("auth")); err != nil {
+			return err
+		}
+		if err := cli.LoadStoredCredentials(&cfg); err != nil {
+			return err
+		}
+		client, err := cli.NewClient(cfg)
+		if err != nil {
+			return err
+		}
+		me, err := cli.FetchMe(ctx, client)
+		if err != nil {
+			return err
+		}
+		if cfg.Structured() {
+			return cli.Render(cfg, me)
+		}
+		fmt.Printf("Logged in as %s (%s), profile %s.\n", cfg.Redact.RedactText("@"+me.Username), cfg.Redact.RedactText(me.FullName), cli.FirstNonEmpty(cfg.Profile, "default"))
+		return nil
+	default:
+		return fmt.Errorf("unknown auth subcommand %q", args[0])
+	}
+}
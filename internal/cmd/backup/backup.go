@@ -0,0 +1,247 @@
+// Package backup implements "trelli backup": write rotated board backups, e.g. from cron.
+package backup
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+	"trelli/pkg/trello"
+)
+
+// TimeLayout stamps backup file names; it sorts like the time.
+const TimeLayout = "2006-01-02T150405Z"
+
+var Command = cli.Command{
+	Name:    "backup",
+	Summary: "Write rotated board backups, e.g. from cron",
+	Description: `Write a timestamped backup of each board to --dir, in the format of
+boards export (lists, labels, and every card with its checklists and
+comments), then delete all but the newest --keep backups of each backed up
+board. Files are named <shortLink>-<UTC time>.json and only appear once
+complete. --boards takes board ids, shortLinks, or aliases, and "mine" for
+every open board you can see.
+
+A board that fails does not stop the others, and its older backups are
+kept; backup then exits non-zero after listing what failed, so cron can
+report it.
+
+diff compares two backups, or with --against-live a backup and the board
+now (the backed up board unless --board names another): cards removed,
+added, moved between lists, and renamed, and changes to descriptions and
+checklists. Cards match by id and lists by name. Removals come first, to
+spot accidental bulk deletions.`,
+	Usage: []string{
+		"[--boards <ids|mine>] [--dir <path>] [--keep <n>]",
+		"diff <older.json> <newer.json>",
+		"diff --against-live [--board <id>] <backup.json>",
+	},
+	Options: []cli.Flag{
+		{Name: "boards", Arg: "ids", Desc: `Comma-separated boards, or "mine" for all your open boards (default: the default board)`},
+		{Name: "dir", Arg: "path", Desc: "Directory for backups (default ./backups)"},
+		{Name: "keep", Arg: "n", Desc: "Backups to keep per board; 0 keeps all (default 14)"},
+		{Name: "against-live", Desc: "diff: compare the backup with the live board"},
+		{Name: "board", Arg: "id", Desc: "diff: board id, shortLink, or alias for --against-live (default: the backed up board)"},
+		cli.JSONOption,
+	},
+	Sections: []cli.HelpSection{{Title: "Cron", Body: `# Every night at 02:30, keeping two weeks.
+30 2 * * * trelli backup --boards mine --dir $HOME/trello-backups --keep 14`},
+		{Title: "Reviewing changes", Body: `trelli backup diff backups/EnGi-2026-01-30T023000Z.json backups/EnGi-2026-01-31T023000Z.json
+trelli backup diff --against-live backups/EnGi-2026-01-31T023000Z.json`}},
+	Run:          cli.Local(runBackup),
+	Mode:         cli.ModeOnline,
+	BareIsAction: true,
+}
+
+// backupResult is the outcome for one board.
+type backupResult struct {
+	Board  string   `json:"board"`
+	Name   string   `json:"name,omitempty"`
+	File   string   `json:"file,omitempty"`
+	Lists  int      `json:"lists"`
+	Cards  int      `json:"cards"`
+	Pruned []string `json:"pruned,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+func runBackup(cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help" || args[0] == "help") {
+		cli.PrintCommandHelp("backup")
+		return nil
+	}
+	if len(args) > 0 && args[0] == "diff" {
+		return runBackupDiff(ctx, cfg, args[1:])
+	}
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boards := cfg.BoardID
+	dir := "backups"
+	keep := 14
+	fs.StringVar(&boards, "boards", boards, "Comma-separated boards, or mine")
+	fs.StringVar(&dir, "dir", dir, "Directory for backups")
+	fs.IntVar(&keep, "keep", keep, "Backups to keep per board")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("backup")); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if keep < 0 {
+		return fmt.Errorf("invalid --keep %d", keep)
+	}
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		return err
+	}
+	targets, results, err := backupBoards(ctx, client, cfg, cli.SplitIDs(boards))
+	if err != nil {
+		return err
+	}
+	if len(targets)+len(results) == 0 {
+		return errors.New("missing --boards and no default board configured")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	stamp := time.Now().UTC().Format(TimeLayout)
+	for _, b := range targets {
+		results = append(results, backupBoard(ctx, client, b, dir, stamp, keep))
+	}
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+			slog.Warn(fmt.Sprintf("backing up %s: %s", r.Board, r.Error), "board", r.Board)
+		}
+	}
+	if err := cli.Render(cfg, cli.NonNil(results), backupTable(results)); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("backup failed for %d of %d boards", failed, len(results))
+	}
+	return nil
+}
+
+// backupBoards resolves --boards to boards with their shortLinks, which
+// name the backup files. "mine" expands to every open board of the member.
+// Boards that cannot be read are returned as failed results.
+func backupBoards(ctx context.Context, client *cli.Client, cfg cli.Config, ids []string) ([]cli.Board, []backupResult, error) {
+	var failed []backupResult
+	var boards []cli.Board
+	seen := map[string]bool{}
+	add := func(b cli.Board) {
+		if !seen[b.ID] {
+			seen[b.ID] = true
+			boards = append(boards, b)
+		}
+	}
+	if slices.Contains(ids, "mine") {
+		mine, err := client.Boards.List(ctx, trello.ListBoardsOptions{Filter: "open", Fields: "id,name,shortLink,url,closed"})
+		if err != nil {
+			return nil, nil, err
+		}
+		slices.SortFunc(mine, func(a, b cli.Board) int { return strings.Compare(a.Name, b.Name) })
+		for _, b := range mine {
+			add(b)
+		}
+	}
+	for _, id := range ids {
+		if id == "mine" {
+			continue
+		}
+		id = cfg.File.ResolveBoardAlias(id)
+		b, err := client.Boards.Get(ctx, id, "id,name,shortLink,url,closed")
+		if err != nil {
+			failed = append(failed, backupResult{Board: id, Error: err.Error()})
+			continue
+		}
+		add(b)
+	}
+	return boards, failed, nil
+}
+
+// backupBoard exports b to dir and prunes its older backups. Failures are
+// reported in the result; a failed backup leaves no file behind.
+func backupBoard(ctx context.Context, client *cli.Client, b cli.Board, dir, stamp string, keep int) backupResult {
+	prefix := cli.FirstNonEmpty(b.ShortLink, b.ID)
+	r := backupResult{Board: prefix, Name: b.Name}
+	out := filepath.Join(dir, prefix+"-"+stamp+".json")
+	export, err := cli.ExportBoard(ctx, client, b.ID, out, false)
+	if err != nil {
+		// A backup does not resume; the next run starts over.
+		os.Remove(cli.ExportCheckpointPath(out))
+		r.Error = err.Error()
+		return r
+	}
+	r.File, r.Lists, r.Cards = out, len(export.Lists), len(export.Cards)
+	if keep == 0 {
+		return r
+	}
+	pruned, err := pruneBackups(dir, prefix, keep)
+	r.Pruned = pruned
+	if err != nil {
+		r.Error = "pruning: " + err.Error()
+	}
+	return r
+}
+
+// pruneBackups deletes all but the newest keep backups of a board from
+// dir and returns the files deleted. Other files are left alone.
+func pruneBackups(dir, prefix string, keep int) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if isBackupFile(e.Name(), prefix) && e.Type().IsRegular() {
+			files = append(files, e.Name())
+		}
+	}
+	// The time stamps sort as they read.
+	slices.Sort(files)
+	var pruned []string
+	for _, name := range files[:max(0, len(files)-keep)] {
+		file := filepath.Join(dir, name)
+		if err := os.Remove(file); err != nil {
+			return pruned, err
+		}
+		pruned = append(pruned, file)
+	}
+	return pruned, nil
+}
+
+// isBackupFile reports whether name is a backup of the board prefix.
+func isBackupFile(name, prefix string) bool {
+	stamp, ok := strings.CutPrefix(name, prefix+"-")
+	if !ok {
+		return false
+	}
+	stamp, ok = strings.CutSuffix(stamp, ".json")
+	if !ok {
+		return false
+	}
+	_, err := time.Parse(TimeLayout, stamp)
+	return err == nil
+}
+
+func backupTable(results []backupResult) cli.Table {
+	t := cli.Table{Columns: []string{"BOARD", "NAME", "FILE", "LISTS", "CARDS", "PRUNED", "ERROR"}, Empty: "No boards backed up."}
+	for _, r := range results {
+		t.Rows = append(t.Rows, []string{r.Board, r.Name, r.File, strconv.Itoa(r.Lists), strconv.Itoa(r.Cards), strconv.Itoa(len(r.Pruned)), r.Error})
+	}
+	return t
+}
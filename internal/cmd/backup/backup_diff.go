@@ -0,0 +1,222 @@
+package backup
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"trelli/internal/cli"
+	"trelli/internal/cmd/restore"
+)
+
+// backupChange is one difference between two states of a board.
+type backupChange struct {
+	Change string `json:"change"`
+	Card   string `json:"card"`
+	Name   string `json:"name"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// backupChangeOrder ranks changes in output, the most destructive first.
+var backupChangeOrder = []string{"removed", "added", "moved", "renamed", "description", "checklists"}
+
+// runBackupDiff compares two backups, or a backup and the live board. Only
+// the live board needs credentials.
+func runBackupDiff(ctx context.Context, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("backup diff", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var live bool
+	var boardID, older, newer string
+	fs.BoolVar(&live, "against-live", false, "Compare with the live board")
+	fs.StringVar(&boardID, "board", "", "Board id, shortLink, or alias for --against-live")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("backup")); err != nil {
+		return err
+	}
+	dsts := []*string{&older, &newer}
+	if live {
+		dsts = dsts[:1]
+	}
+	if err := cli.TakePositional(fs, dsts...); err != nil {
+		return err
+	}
+	switch {
+	case older == "":
+		return errors.New("backup diff needs two backup files, or one with --against-live")
+	case newer == "" && !live:
+		return errors.New("backup diff needs a second backup file, or --against-live")
+	case boardID != "" && !live:
+		return errors.New("--board needs --against-live")
+	}
+
+	a, err := restore.ReadBackup(older)
+	if err != nil {
+		return err
+	}
+	var b cli.BoardExport
+	if live {
+		boardID = cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(boardID, a.Board.ID))
+		if strings.TrimSpace(boardID) == "" {
+			return errors.New("missing --board and the backup names no board")
+		}
+		client, err := cli.Connect(cfg)
+		if err != nil {
+			return err
+		}
+		if b, err = fetchBoardExport(ctx, client, boardID); err != nil {
+			return err
+		}
+	} else if b, err = restore.ReadBackup(newer); err != nil {
+		return err
+	}
+	changes := diffBackups(a, b)
+	return cli.Render(cfg, cli.NonNil(changes), backupDiffTable(changes))
+}
+
+// diffBackups lists the cards removed from, added to, and changed between
+// two states of a board. Cards are matched by id and lists by name, so
+// recreating a list with the same name is no change.
+func diffBackups(a, b cli.BoardExport) []backupChange {
+	listsA, listsB := exportListNames(a), exportListNames(b)
+	cardsA := map[string]cli.ExportedCard{}
+	for _, c := range a.Cards {
+		cardsA[c.ID] = c
+	}
+	cardsB := map[string]bool{}
+	for _, c := range b.Cards {
+		cardsB[c.ID] = true
+	}
+
+	var changes []backupChange
+	for _, c := range a.Cards {
+		if !cardsB[c.ID] {
+			changes = append(changes, backupChange{Change: "removed", Card: cli.ShortLinkOf(c.Card), Name: c.Name, Detail: "from " + listsA[c.IDList]})
+		}
+	}
+	for _, c := range b.Cards {
+		old, ok := cardsA[c.ID]
+		if !ok {
+			changes = append(changes, backupChange{Change: "added", Card: cli.ShortLinkOf(c.Card), Name: c.Name, Detail: "in " + listsB[c.IDList]})
+			continue
+		}
+		change := func(kind, detail string) {
+			changes = append(changes, backupChange{Change: kind, Card: cli.ShortLinkOf(c.Card), Name: c.Name, Detail: detail})
+		}
+		if from, to := listsA[old.IDList], listsB[c.IDList]; from != to {
+			change("moved", from+" -> "+to)
+		}
+		if old.Name != c.Name {
+			change("renamed", fmt.Sprintf("from %q", old.Name))
+		}
+		if old.Desc != c.Desc {
+			change("description", describeTextChange(old.Desc, c.Desc))
+		}
+		if d := diffChecklists(old.Checklists, c.Checklists); len(d) > 0 {
+			change("checklists", strings.Join(d, "; "))
+		}
+	}
+	slices.SortStableFunc(changes, func(x, y backupChange) int {
+		return cmp.Compare(slices.Index(backupChangeOrder, x.Change), slices.Index(backupChangeOrder, y.Change))
+	})
+	return changes
+}
+
+func exportListNames(e cli.BoardExport) map[string]string {
+	names := map[string]string{}
+	for _, l := range e.Lists {
+		names[l.ID] = l.Name
+	}
+	return names
+}
+
+// describeTextChange summarizes an edit of a description by lines.
+func describeTextChange(old, new string) string {
+	switch {
+	case old == "":
+		return "added"
+	case new == "":
+		return fmt.Sprintf("cleared (%d lines)", len(strings.Split(old, "\n")))
+	}
+	// Count lines by content: a line moved within the text is unchanged.
+	count := map[string]int{}
+	for _, line := range strings.Split(old, "\n") {
+		count[line]++
+	}
+	added := 0
+	for _, line := range strings.Split(new, "\n") {
+		if count[line] > 0 {
+			count[line]--
+		} else {
+			added++
+		}
+	}
+	removed := 0
+	for _, n := range count {
+		removed += n
+	}
+	return fmt.Sprintf("+%d/-%d lines", added, removed)
+}
+
+// diffChecklists describes how the checklists of a card changed:
+// checklists added and removed, and within a checklist items added,
+// removed, checked, and unchecked. Checklists and items match by name.
+func diffChecklists(old, new []cli.Checklist) []string {
+	var d []string
+	find := func(cls []cli.Checklist, name string) (cli.Checklist, bool) {
+		i := slices.IndexFunc(cls, func(cl cli.Checklist) bool { return cl.Name == name })
+		if i < 0 {
+			return cli.Checklist{}, false
+		}
+		return cls[i], true
+	}
+	for _, cl := range old {
+		if _, ok := find(new, cl.Name); !ok {
+			d = append(d, fmt.Sprintf("removed %q (%d items)", cl.Name, len(cl.CheckItems)))
+		}
+	}
+	for _, cl := range new {
+		before, ok := find(old, cl.Name)
+		if !ok {
+			d = append(d, fmt.Sprintf("added %q (%d items)", cl.Name, len(cl.CheckItems)))
+			continue
+		}
+		states := map[string]string{}
+		for _, it := range before.CheckItems {
+			states[it.Name] = it.State
+		}
+		var items []string
+		for _, it := range cl.CheckItems {
+			state, ok := states[it.Name]
+			delete(states, it.Name)
+			switch {
+			case !ok:
+				items = append(items, fmt.Sprintf("added %q", it.Name))
+			case state != it.State && it.State == "complete":
+				items = append(items, fmt.Sprintf("checked %q", it.Name))
+			case state != it.State:
+				items = append(items, fmt.Sprintf("unchecked %q", it.Name))
+			}
+		}
+		for _, it := range before.CheckItems {
+			if _, ok := states[it.Name]; ok {
+				items = append(items, fmt.Sprintf("removed %q", it.Name))
+			}
+		}
+		if len(items) > 0 {
+			d = append(d, fmt.Sprintf("%s: %s", cl.Name, strings.Join(items, ", ")))
+		}
+	}
+	return d
+}
+
+func backupDiffTable(changes []backupChange) cli.Table {
+	t := cli.Table{Columns: []string{"CHANGE", "CARD", "NAME", "DETAIL"}, Empty: "No differences."}
+	for _, c := range changes {
+		t.Rows = append(t.Rows, []string{c.Change, c.Card, c.Name, c.Detail})
+	}
+	return t
+}
@@ -0,0 +1,62 @@
+package backup
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// TestMain registers the command as main does, for the tests that run it
+// through the CLI.
+func TestMain(m *testing.M) {
+	cli.Register(Command)
+	os.Exit(m.Run())
+}
+
+func TestBackupPartialFailure(t *testing.T) {
+	stub := clitest.NewStub(t)
+	dir := t.TempDir()
+	for _, name := range []string{"EnGi-2026-01-01T020000Z.json", "EnGi-2026-01-02T020000Z.json", "EnGi-notes.json", "RdMp-2026-01-01T020000Z.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The stub has no labels for Roadmap (b2), so its backup fails.
+	out := clitest.RunCLI(t, stub, "--json", "backup", "--boards", "mine", "--dir", dir, "--keep", "2")
+	body, errOut, ok := strings.Cut(out, "--- error\n")
+	if !ok || !strings.Contains(errOut, "backup failed for 1 of 2 boards") {
+		t.Fatalf("want a partial failure, got:\n%s", out)
+	}
+	var results []backupResult
+	if err := json.Unmarshal([]byte(body), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Board != "EnGi" || results[0].Error != "" || results[0].Cards != 3 || results[1].Board != "RdMp" || results[1].Error == "" {
+		t.Fatalf("results = %+v", results)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	// Only the newest two EnGi backups remain; the failed board keeps its
+	// backup and leaves no checkpoint.
+	want := []string{"EnGi-2026-01-02T020000Z.json", filepath.Base(results[0].File), "EnGi-notes.json", "RdMp-2026-01-01T020000Z.json"}
+	if !slices.Equal(names, want) {
+		t.Errorf("files = %q, want %q", names, want)
+	}
+	if len(results[0].Pruned) != 1 || filepath.Base(results[0].Pruned[0]) != "EnGi-2026-01-01T020000Z.json" {
+		t.Errorf("pruned = %q", results[0].Pruned)
+	}
+}
@@ -0,0 +1,31 @@
+package backup
+
+import (
+	"context"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+// fetchBoardExport reads a board as boards export writes it, without
+// checkpoints.
+func fetchBoardExport(ctx context.Context, client *cli.Client, boardID string) (cli.BoardExport, error) {
+	state, err := cli.StartExport(ctx, client, boardID)
+	if err != nil {
+		return cli.BoardExport{}, err
+	}
+	client.Progress.SetTask("Exporting cards", len(state.Pending))
+	defer client.Progress.EndTask()
+	for pending := state.Pending; len(pending) > 0; {
+		window := pending[:min(cli.ExportWindow, len(pending))]
+		cards, err := cli.FetchCardDetails(ctx, client, window)
+		if err != nil {
+			return cli.BoardExport{}, err
+		}
+		state.Export.Cards = append(state.Export.Cards, cards...)
+		pending = pending[len(window):]
+		client.Progress.Advance(len(cards))
+	}
+	state.Export.Exported = time.Now().UTC()
+	return state.Export, nil
+}
@@ -0,0 +1,184 @@
+// Package boards implements "trelli boards": board-level commands.
+package boards
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"trelli/internal/cli"
+	"trelli/pkg/trello"
+)
+
+var Command = cli.Command{
+	Name:    "boards",
+	Aliases: []string{"board", "b"},
+	Summary: "Board-level commands",
+	Description: `List boards visible to the authenticated user, show one, or export a board
+with every card's checklists and comments.
+
+apply makes a board match a definition file, like kubectl apply: it
+creates the lists, labels, seed cards, checklists, and check items the
+file names and the board lacks, puts the lists in the file's order, and
+updates label colors and the list, description, due date, and labels of
+seed cards. Lists, labels, and cards match by name, case-insensitively;
+nothing is removed. --create makes a new board from the file instead. The
+changes are listed for confirmation before any is made (--yes skips the
+prompt; --force proceeds when stdin is not a terminal), and printed once
+made; the global --dry-run only prints them. When a change fails, the
+changes made before it are printed with the error.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "list", Aliases: []string{"ls"}, Usage: []string{"list [--filter <name-substring>]"}, Flags: []cli.Flag{
+			{Name: "filter", Arg: "text", Desc: "Case-insensitive board name filter"},
+		}},
+		{Name: "show", Usage: []string{"show [[--board] <boardIdOrShortLink>] [--copy | --copy-id]"}, Flags: []cli.Flag{
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias"},
+			{Name: "copy", Desc: "Copy the board's short URL to the clipboard (show)"},
+			{Name: "copy-id", Desc: "Copy the board id to the clipboard (show)"},
+		}},
+		{Name: "export", Usage: []string{"export [[--board] <boardIdOrShortLink>] [--out <file>] [--resume]"}, Flags: []cli.Flag{
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias"},
+			{Name: "out", Arg: "file", Desc: "Output file (default trelli-export-<board>.json)"},
+			{Name: "resume", Desc: "Continue an interrupted export from <file>.partial"},
+		}},
+		{Name: "apply", Usage: []string{"apply [--file] <board.yaml> [--board <id> | --create <name>] [--yes|--force]"}, Flags: []cli.Flag{
+			{Name: "file", Arg: "path", Desc: "Board definition file"},
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the file's board, then the default board)"},
+			{Name: "create", Arg: "name", Desc: "Create a board with this name and apply the file to it"},
+			cli.YesFlag, cli.ForceFlag,
+		}},
+	},
+	Options: []cli.Flag{cli.JSONOption},
+	Sections: []cli.HelpSection{{Title: "Board file", Body: `lists: [Backlog, Doing, Review, Done]
+labels:
+  - {name: Bug, color: red}
+  - {name: Feature, color: green}
+cards:
+  - name: Kick-off meeting
+    list: Backlog
+    due: 2026-03-02
+    labels: [Feature]
+    desc: |
+      Agree on scope and owners.
+    checklists:
+      - name: Prepare
+        items: [Book a room, Send the agenda]`}},
+	Run: runBoards,
+}
+
+func runBoards(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) == 0 {
+		cli.PrintCommandHelp("boards")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("boards")
+		return nil
+	case "list":
+		fs := flag.NewFlagSet("boards list", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		var filter string
+		fs.StringVar(&filter, "filter", "", "Case-insensitive substring filter on board name")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("boards")); err != nil {
+			return err
+		}
+
+		boards, err := client.Boards.List(ctx, trello.ListBoardsOptions{})
+		if err != nil {
+			return err
+		}
+
+		if filter != "" {
+			needle := strings.ToLower(strings.TrimSpace(filter))
+			filtered := make([]cli.Board, 0, len(boards))
+			for _, b := range boards {
+				if strings.Contains(strings.ToLower(b.Name), needle) {
+					filtered = append(filtered, b)
+				}
+			}
+			boards = filtered
+		}
+
+		sort.Slice(boards, func(i, j int) bool { return boards[i].Name < boards[j].Name })
+		return cli.Render(cfg, boards, boardsTable(boards))
+	case "show":
+		fs := flag.NewFlagSet("boards show", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		boardID := cfg.BoardID
+		var clip cli.CopyFlags
+		fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+		cli.AddCopyFlags(fs, &clip)
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("boards")); err != nil {
+			return err
+		}
+		var positionalBoard string
+		if err := cli.TakePositional(fs, &positionalBoard); err != nil {
+			return err
+		}
+		boardID = cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(positionalBoard, boardID))
+		if strings.TrimSpace(boardID) == "" {
+			return cli.ErrNoBoard
+		}
+
+		board, err := client.Boards.Get(ctx, boardID, "")
+		if err != nil {
+			return err
+		}
+		shortURL := board.URL
+		if board.ShortLink != "" {
+			shortURL = "https://trello.com/b/" + board.ShortLink
+		}
+		defer clip.Apply(board.ID, shortURL)
+		return cli.Render(cfg, board, boardsTable([]cli.Board{board}))
+	case "export":
+		fs := flag.NewFlagSet("boards export", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		boardID := cfg.BoardID
+		var out string
+		var resume bool
+		fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+		fs.StringVar(&out, "out", "", "Output file (default trelli-export-<board>.json)")
+		fs.BoolVar(&resume, "resume", false, "Continue an interrupted export")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("boards")); err != nil {
+			return err
+		}
+		if err := cli.TakePositional(fs, &boardID); err != nil {
+			return err
+		}
+		boardID = cfg.File.ResolveBoardAlias(boardID)
+		if strings.TrimSpace(boardID) == "" {
+			return cli.ErrNoBoard
+		}
+		if out == "" {
+			out = "trelli-export-" + boardID + ".json"
+		}
+
+		export, err := cli.ExportBoard(ctx, client, boardID, out, resume)
+		if err != nil {
+			return err
+		}
+		if cfg.Structured() {
+			return cli.Render(cfg, map[string]any{"board": boardID, "file": out, "lists": len(export.Lists), "cards": len(export.Cards)})
+		}
+		fmt.Printf("Exported %s: %d lists, %d cards -> %s\n", export.Board.Name, len(export.Lists), len(export.Cards), out)
+		return nil
+	case "apply":
+		return runBoardsApply(ctx, client, cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown boards subcommand %q", args[0])
+	}
+}
+
+func boardsTable(boards []cli.Board) cli.Table {
+	t := cli.Table{Columns: []string{"ID", "NAME", "CLOSED", "URL"}, Empty: "No boards found."}
+	for _, b := range boards {
+		t.Rows = append(t.Rows, []string{b.ID, b.Name, strconv.FormatBool(b.Closed), b.URL})
+	}
+	return t
+}
@@ -0,0 +1,457 @@
+package boards
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+// boardSpec is a board definition file for boards apply.
+type boardSpec struct {
+	Board  string          `json:"board"`
+	Lists  []string        `json:"lists"`
+	Labels []cli.Label     `json:"labels"`
+	Cards  []boardSpecCard `json:"cards"`
+}
+
+type boardSpecCard struct {
+	Name       string               `json:"name"`
+	List       string               `json:"list"`
+	Desc       string               `json:"desc"`
+	Labels     []string             `json:"labels"`
+	Due        string               `json:"due"`
+	Checklists []boardSpecChecklist `json:"checklists"`
+}
+
+type boardSpecChecklist struct {
+	Name  string   `json:"name"`
+	Items []string `json:"items"`
+}
+
+// applyChange is one change boards apply makes to bring a board in line
+// with its file. do makes it, using the ids of the changes before it.
+type applyChange struct {
+	Change string `json:"change"`
+	Name   string `json:"name"`
+	Detail string `json:"detail,omitempty"`
+	do     func(context.Context) error
+}
+
+// applyCard is a board card as boards apply compares it.
+type applyCard struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	IDList   string   `json:"idList"`
+	Desc     string   `json:"desc"`
+	Due      string   `json:"due"`
+	IDLabels []string `json:"idLabels"`
+}
+
+// applyChecklist is a board checklist with the card it is on.
+type applyChecklist struct {
+	cli.Checklist
+	IDCard string `json:"idCard"`
+}
+
+// boardState is what boards apply reads of a board.
+type boardState struct {
+	Lists      []cli.TrelloList
+	Labels     []cli.Label
+	Cards      []applyCard
+	Checklists []applyChecklist
+}
+
+func runBoardsApply(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("boards apply", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var file, boardID, create string
+	fs.StringVar(&file, "file", "", "Board definition file")
+	fs.StringVar(&boardID, "board", "", "Board id, shortLink, or alias")
+	fs.StringVar(&create, "create", "", "Create a board with this name")
+	cli.AddConfirmFlags(fs, &cfg)
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("boards")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &file); err != nil {
+		return err
+	}
+	if file == "" {
+		return errors.New("missing --file")
+	}
+	spec, err := loadBoardSpec(file)
+	if err != nil {
+		return err
+	}
+
+	var changes []applyChange
+	var state boardState
+	if create != "" {
+		if boardID != "" {
+			return errors.New("--create and --board are exclusive")
+		}
+		// The board is new, so everything in the file is created on it.
+		changes = append(changes, applyChange{Change: "create board", Name: create, do: func(ctx context.Context) error {
+			board, err := client.Boards.Create(ctx, create)
+			boardID = board.ID
+			return err
+		}})
+	} else {
+		boardID = cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(boardID, spec.Board, cfg.BoardID))
+		if strings.TrimSpace(boardID) == "" {
+			return errors.New("missing --board and no board in the file or default board configured")
+		}
+		if state, err = fetchBoardState(ctx, client, boardID); err != nil {
+			return err
+		}
+	}
+	changes = append(changes, planBoardApply(client, cfg, &boardID, spec, state)...)
+	if len(changes) == 0 || cfg.DryRun {
+		return cli.Render(cfg, cli.NonNil(changes), applyTable(changes))
+	}
+
+	targets := make([]string, len(changes))
+	for i, c := range changes {
+		targets[i] = strings.TrimSpace(c.Change + "  " + c.Name + "  " + c.Detail)
+	}
+	board := "board " + boardID
+	if create != "" {
+		board = "new board " + create
+	}
+	if err := cli.Confirm(cfg, fmt.Sprintf("apply %d changes to %s", len(changes), board), targets); err != nil {
+		return err
+	}
+	for i, c := range changes {
+		if err := c.do(ctx); err != nil {
+			// Show what was changed before the failure, so the file
+			// can be fixed and applied again.
+			if i > 0 {
+				if err := cli.Render(cfg, changes[:i], applyTable(changes[:i])); err != nil {
+					return err
+				}
+			}
+			return fmt.Errorf("%s %q: %w (%d of %d changes made before it)", c.Change, c.Name, err, i, len(changes))
+		}
+	}
+	return cli.Render(cfg, changes, applyTable(changes))
+}
+
+func loadBoardSpec(file string) (boardSpec, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return boardSpec{}, err
+	}
+	var spec boardSpec
+	if err := cli.DecodeYAML(data, &spec); err != nil {
+		return boardSpec{}, fmt.Errorf("%s: %w", file, err)
+	}
+	if err := spec.validate(); err != nil {
+		return boardSpec{}, fmt.Errorf("%s: %w", file, err)
+	}
+	return spec, nil
+}
+
+// validate checks the file and normalizes it: due dates become RFC 3339,
+// descriptions lose the final newline of block scalars, and the lists of
+// cards are added to the lists when missing.
+func (s *boardSpec) validate() error {
+	for i, name := range s.Lists {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("lists[%d]: empty name", i)
+		}
+		if slices.ContainsFunc(s.Lists[:i], func(l string) bool { return strings.EqualFold(l, name) }) {
+			return fmt.Errorf("list %q: named twice", name)
+		}
+	}
+	for i, l := range s.Labels {
+		if strings.TrimSpace(l.Name) == "" {
+			return fmt.Errorf("labels[%d]: empty name", i)
+		}
+	}
+	for i := range s.Cards {
+		c := &s.Cards[i]
+		if strings.TrimSpace(c.Name) == "" {
+			return fmt.Errorf("cards[%d]: empty name", i)
+		}
+		if strings.TrimSpace(c.List) == "" {
+			return fmt.Errorf("card %q: missing list", c.Name)
+		}
+		if !slices.ContainsFunc(s.Lists, func(l string) bool { return strings.EqualFold(l, c.List) }) {
+			s.Lists = append(s.Lists, c.List)
+		}
+		c.Desc = strings.TrimRight(c.Desc, "\n")
+		if c.Due != "" {
+			due, err := cli.ParseDate(c.Due)
+			if err != nil {
+				return fmt.Errorf("card %q: due %q is not a date (2006-01-02) or RFC 3339 time", c.Name, c.Due)
+			}
+			c.Due = due.UTC().Format("2006-01-02T15:04:05.000Z")
+		}
+	}
+	return nil
+}
+
+func fetchBoardState(ctx context.Context, client *cli.Client, boardID string) (boardState, error) {
+	var s boardState
+	cards := url.Values{}
+	cards.Set("fields", "id,name,idList,desc,due,idLabels")
+	checklists := url.Values{}
+	checklists.Set("fields", "name,idCard")
+	checklists.Set("checkItem_fields", "name,state")
+	err := client.GetAll(ctx,
+		cli.BoardListsRequest(boardID, &s.Lists),
+		cli.BoardLabelsRequest(boardID, &s.Labels),
+		cli.GetRequest{Path: "/1/boards/" + url.PathEscape(boardID) + "/cards", Query: cards, Out: &s.Cards},
+		cli.GetRequest{Path: "/1/boards/" + url.PathEscape(boardID) + "/checklists", Query: checklists, Out: &s.Checklists},
+	)
+	slices.SortStableFunc(s.Lists, func(a, b cli.TrelloList) int { return cmp.Compare(a.Pos, b.Pos) })
+	return s, err
+}
+
+// planBoardApply lists the changes that make a board match spec: missing
+// lists, labels, cards, checklists, and check items are created, lists
+// are put in the order of the file, label colors and the list,
+// description, and due date of cards are updated, and missing labels are
+// added to cards. Nothing is removed. Lists, labels, and cards match by
+// name, case-insensitively. boardID is read when the changes are made, as
+// the board may be created first.
+func planBoardApply(client *cli.Client, cfg cli.Config, boardID *string, spec boardSpec, state boardState) []applyChange {
+	var changes []applyChange
+	add := func(change, name, detail string, do func(context.Context) error) {
+		changes = append(changes, applyChange{Change: change, Name: name, Detail: detail, do: do})
+	}
+
+	// ids by lower-case name, filled in as lists and labels are created.
+	listIDs := map[string]string{}
+	listNames := map[string]string{}
+	listPos := map[string]float64{}
+	for _, l := range state.Lists {
+		if _, ok := listIDs[strings.ToLower(l.Name)]; !ok {
+			listIDs[strings.ToLower(l.Name)] = l.ID
+			listPos[strings.ToLower(l.Name)] = l.Pos
+		}
+		listNames[l.ID] = l.Name
+	}
+	// Lists are created at the bottom; a list out of order moves there
+	// too, and so must every list after it.
+	last := math.Inf(-1)
+	for _, name := range spec.Lists {
+		key := strings.ToLower(name)
+		id, ok := listIDs[key]
+		switch {
+		case !ok:
+			listIDs[key] = ""
+			last = math.Inf(1)
+			add("create list", name, "", func(ctx context.Context) error {
+				l, err := client.Lists.Create(ctx, *boardID, name)
+				listIDs[key] = l.ID
+				return err
+			})
+		case listPos[key] > last:
+			last = listPos[key]
+		default:
+			last = math.Inf(1)
+			add("move list", name, "to the end", func(ctx context.Context) error {
+				_, err := client.Lists.Update(ctx, id, url.Values{"pos": {"bottom"}})
+				return err
+			})
+		}
+	}
+
+	labels := map[string]cli.Label{}
+	labelNames := map[string]string{}
+	for _, l := range state.Labels {
+		labelNames[l.ID] = l.Name
+		if _, ok := labels[strings.ToLower(l.Name)]; !ok && l.Name != "" {
+			labels[strings.ToLower(l.Name)] = l
+		}
+	}
+	labelIDs := map[string]string{}
+	for k, l := range labels {
+		labelIDs[k] = l.ID
+	}
+	createLabel := func(name, color string) {
+		key := strings.ToLower(name)
+		labelIDs[key] = ""
+		add("create label", name, color, func(ctx context.Context) error {
+			l, err := client.Boards.CreateLabel(ctx, *boardID, name, color)
+			labelIDs[key] = l.ID
+			return err
+		})
+	}
+	for _, l := range spec.Labels {
+		existing, ok := labels[strings.ToLower(l.Name)]
+		switch {
+		case !ok:
+			createLabel(l.Name, l.Color)
+		case l.Color != "" && l.Color != existing.Color:
+			color := l.Color
+			add("recolor label", existing.Name, cli.FirstNonEmpty(existing.Color, "none")+" -> "+color, func(ctx context.Context) error {
+				_, err := client.Boards.UpdateLabel(ctx, existing.ID, url.Values{"color": {color}})
+				return err
+			})
+		}
+	}
+
+	cards := map[string]applyCard{}
+	for _, c := range state.Cards {
+		if _, ok := cards[strings.ToLower(c.Name)]; !ok {
+			cards[strings.ToLower(c.Name)] = c
+		}
+	}
+	checklists := map[string][]cli.Checklist{}
+	for _, cl := range state.Checklists {
+		checklists[cl.IDCard] = append(checklists[cl.IDCard], cl.Checklist)
+	}
+	for _, sc := range spec.Cards {
+		for _, name := range sc.Labels {
+			if _, ok := labelIDs[strings.ToLower(name)]; !ok {
+				createLabel(name, "")
+			}
+		}
+		labelIDsOf := func(names []string) []string {
+			var ids []string
+			for _, name := range names {
+				ids = append(ids, labelIDs[strings.ToLower(name)])
+			}
+			return ids
+		}
+
+		existing, ok := cards[strings.ToLower(sc.Name)]
+		if !ok {
+			detail := "in " + sc.List
+			if len(sc.Checklists) > 0 {
+				var names []string
+				for _, cl := range sc.Checklists {
+					names = append(names, cl.Name)
+				}
+				detail += "; checklists " + strings.Join(names, ", ")
+			}
+			add("create card", sc.Name, detail, func(ctx context.Context) error {
+				card, err := cli.CreateCard(ctx, client, cfg, cli.CardDraft{
+					IDList:   listIDs[strings.ToLower(sc.List)],
+					Name:     sc.Name,
+					Desc:     sc.Desc,
+					Due:      sc.Due,
+					IDLabels: labelIDsOf(sc.Labels),
+				})
+				if err != nil {
+					return err
+				}
+				return addSpecChecklists(ctx, client, card.ID, sc.Checklists)
+			})
+			continue
+		}
+
+		var fields []string
+		var names []string
+		move := !strings.EqualFold(listNames[existing.IDList], sc.List)
+		if move {
+			fields = append(fields, fmt.Sprintf("list %s -> %s", cli.FirstNonEmpty(listNames[existing.IDList], existing.IDList), sc.List))
+		}
+		desc := sc.Desc != "" && sc.Desc != existing.Desc
+		if desc {
+			fields = append(fields, "description")
+		}
+		due := sc.Due != "" && !sameTime(sc.Due, existing.Due)
+		if due {
+			fields = append(fields, "due "+sc.Due[:10])
+		}
+		for _, name := range sc.Labels {
+			if !slices.ContainsFunc(existing.IDLabels, func(id string) bool { return strings.EqualFold(labelNames[id], name) }) {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			fields = append(fields, "labels +"+strings.Join(names, " +"))
+		}
+		if len(fields) > 0 {
+			add("update card", existing.Name, strings.Join(fields, "; "), func(ctx context.Context) error {
+				form := url.Values{}
+				if move {
+					form.Set("idList", listIDs[strings.ToLower(sc.List)])
+				}
+				if desc {
+					form.Set("desc", sc.Desc)
+				}
+				if due {
+					form.Set("due", sc.Due)
+				}
+				if len(names) > 0 {
+					form.Set("idLabels", strings.Join(append(slices.Clone(existing.IDLabels), labelIDsOf(names)...), ","))
+				}
+				_, err := client.Cards.Update(ctx, existing.ID, form)
+				return err
+			})
+		}
+
+		for _, scl := range sc.Checklists {
+			have := checklists[existing.ID]
+			i := slices.IndexFunc(have, func(cl cli.Checklist) bool { return strings.EqualFold(cl.Name, scl.Name) })
+			if i < 0 {
+				add("add checklist", existing.Name, scl.Name, func(ctx context.Context) error {
+					return addSpecChecklists(ctx, client, existing.ID, []boardSpecChecklist{scl})
+				})
+				continue
+			}
+			var missing []string
+			for _, item := range scl.Items {
+				if !slices.ContainsFunc(have[i].CheckItems, func(it cli.ChecklistItem) bool { return strings.EqualFold(it.Name, item) }) {
+					missing = append(missing, item)
+				}
+			}
+			if len(missing) > 0 {
+				checklistID := have[i].ID
+				add("add items", existing.Name, have[i].Name+": "+strings.Join(missing, ", "), func(ctx context.Context) error {
+					for _, item := range missing {
+						if _, err := client.Checklists.AddItem(ctx, checklistID, item, false); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+			}
+		}
+	}
+	return changes
+}
+
+func addSpecChecklists(ctx context.Context, client *cli.Client, cardID string, checklists []boardSpecChecklist) error {
+	for _, cl := range checklists {
+		checklist, err := client.Checklists.Create(ctx, cardID, cl.Name)
+		if err != nil {
+			return err
+		}
+		for _, item := range cl.Items {
+			if _, err := client.Checklists.AddItem(ctx, checklist.ID, item, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sameTime reports whether two RFC 3339 times are the same instant.
+func sameTime(a, b string) bool {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	return errA == nil && errB == nil && ta.Equal(tb)
+}
+
+func applyTable(changes []applyChange) cli.Table {
+	t := cli.Table{Columns: []string{"CHANGE", "NAME", "DETAIL"}, Empty: "The board matches the file."}
+	for _, c := range changes {
+		t.Rows = append(t.Rows, []string{c.Change, c.Name, c.Detail})
+	}
+	return t
+}
@@ -0,0 +1,95 @@
+package boards
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// TestMain registers the command as main does, for the tests that run it
+// through the CLI.
+func TestMain(m *testing.M) {
+	cli.Register(Command)
+	os.Exit(m.Run())
+}
+
+var boardsApplyRoutes = map[string]string{
+	"/1/boards/b1/checklists": `[{"id": "k1", "name": "Steps", "idCard": "c1", "checkItems": [{"id": "i1", "name": "Reproduce", "state": "complete"}, {"id": "i2", "name": "Fix", "state": "incomplete"}]}]`,
+}
+
+func TestBoardsApply(t *testing.T) {
+	clitest.WithStubRoutes(t, boardsApplyRoutes)
+	stub := clitest.NewStub(t)
+	clitest.CheckGolden(t, "boards_apply_dry_run", clitest.RunCLI(t, stub, "--dry-run", "boards", "apply", "testdata/boards-apply/board.yaml"))
+
+	var writes []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			r.ParseForm()
+			var fields []string
+			for k, v := range r.Form {
+				if k != "key" && k != "token" {
+					fields = append(fields, k+"="+strings.Join(v, ","))
+				}
+			}
+			slices.Sort(fields)
+			writes = append(writes, fmt.Sprintf("%s %s %s", r.Method, r.URL.Path, strings.Join(fields, " ")))
+		}
+		stub.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	if got := clitest.RunCLI(t, srv, "boards", "apply", "testdata/boards-apply/board.yaml"); !strings.Contains(got, "needs confirmation") || len(writes) > 0 {
+		t.Errorf("boards apply without --yes = %q, writes %q", got, writes)
+	}
+	clitest.RunCLI(t, srv, "boards", "apply", "--file", "testdata/boards-apply/board.yaml", "--board", "b1", "--yes")
+	clitest.CheckGolden(t, "boards_apply_writes", strings.Join(writes, "\n")+"\n")
+}
+
+func TestBoardsApplyCreate(t *testing.T) {
+	stub := clitest.NewStub(t)
+	file := filepath.Join(t.TempDir(), "board.yaml")
+	if err := os.WriteFile(file, []byte("lists: [Backlog, Done]\ncards:\n  - {name: First, list: Backlog}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want := `CHANGE        NAME     DETAIL
+create board  Project  
+create list   Backlog  
+create list   Done     
+create card   First    in Backlog
+`
+	if got := clitest.RunCLI(t, stub, "--dry-run", "boards", "apply", file, "--create", "Project"); got != want {
+		t.Errorf("boards apply --create =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestBoardsApplyFailure(t *testing.T) {
+	clitest.WithStubRoutes(t, boardsApplyRoutes)
+	stub := clitest.NewStub(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.URL.Path == "/1/labels/lb2" {
+			http.Error(w, `{"message": "invalid color"}`, http.StatusBadRequest)
+			return
+		}
+		stub.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	got := clitest.RunCLI(t, srv, "boards", "apply", "testdata/boards-apply/board.yaml", "--yes")
+	want := `CHANGE       NAME     DETAIL
+create list  Backlog  
+move list    To Do    to the end
+move list    Done     to the end
+--- error
+recolor label "Feature": trello API error (400): invalid color (3 of 9 changes made before it)
+`
+	if got != want {
+		t.Errorf("boards apply with a failing change =\n%s\nwant\n%s", got, want)
+	}
+}
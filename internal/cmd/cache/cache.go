@@ -0,0 +1,103 @@
+// Package cache implements "trelli cache": manage the local name cache.
+package cache
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "cache",
+	Summary: "Manage the local name cache",
+	Description: `Board, list, label, and member listings used to resolve names such as
+--list-name are cached under <user cache dir>/trelli for cache.ttl
+(default 1h). refresh re-fetches boards and the lists, labels, and
+members of a board; clear deletes the cache directory, including
+--offline snapshots.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "refresh", Usage: []string{"refresh [[--board] <boardIdOrShortLink>]"}, Flags: []cli.Flag{
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+		}},
+		{Name: "clear", Usage: []string{"clear"}},
+	},
+	Options: []cli.Flag{cli.JSONOption},
+	Run:     cli.Local(runCache),
+	Mode:    cli.ModeOnline,
+}
+
+func runCache(cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) == 0 {
+		cli.PrintCommandHelp("cache")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("cache")
+		return nil
+	case "refresh":
+		fs := flag.NewFlagSet("cache refresh", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		boardID := cfg.BoardID
+		fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("cache")); err != nil {
+			return err
+		}
+		if err := cli.TakePositional(fs, &boardID); err != nil {
+			return err
+		}
+		boardID = cfg.File.ResolveBoardAlias(boardID)
+		if strings.TrimSpace(boardID) == "" {
+			return cli.ErrNoBoard
+		}
+		client, err := cli.Connect(cfg)
+		if err != nil {
+			return err
+		}
+		var boards []cli.Board
+		var lists []cli.TrelloList
+		var labels []cli.Label
+		var members []cli.Member
+		err = client.GetAll(ctx,
+			cli.BoardsRequest(&boards),
+			cli.BoardListsRequest(boardID, &lists),
+			cli.BoardLabelsRequest(boardID, &labels),
+			cli.BoardMembersRequest(boardID, &members),
+		)
+		if err != nil {
+			return err
+		}
+		client.Names.Put("boards", boards)
+		client.Names.Put("lists:"+boardID, lists)
+		client.Names.Put("labels:"+boardID, labels)
+		client.Names.Put("members:"+boardID, members)
+		counts := map[string]int{"boards": len(boards), "lists": len(lists), "labels": len(labels), "members": len(members)}
+		if cfg.Structured() {
+			return cli.Render(cfg, map[string]any{"board": boardID, "cached": counts})
+		}
+		fmt.Printf("Cached %d boards; %d lists, %d labels, %d members for board %s\n",
+			counts["boards"], counts["lists"], counts["labels"], counts["members"], boardID)
+		return nil
+	case "clear":
+		dir, err := cli.CacheDir(cfg)
+		if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		if cfg.Structured() {
+			return cli.Render(cfg, map[string]any{"cleared": dir})
+		}
+		fmt.Printf("Cleared %s\n", dir)
+		return nil
+	default:
+		return fmt.Errorf("unknown cache subcommand %q", args[0])
+	}
+}
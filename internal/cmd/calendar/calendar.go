@@ -0,0 +1,302 @@
+// Package calendar implements "trelli calendar": serve card due dates as a calendar feed.
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "calendar",
+	Summary: "Serve card due dates as a calendar feed",
+	Description: `serve publishes the due dates of the open cards of one or more boards as
+an iCalendar (ICS) feed on 127.0.0.1, for calendar apps to subscribe to.
+The feed is rebuilt every --refresh; if a refresh fails, the last feed
+keeps being served. Cards with a completed due date are marked done.
+Ctrl-C stops the server.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "serve", Usage: []string{"serve [--boards <ids>] [--port <n>] [--addr <host>] [--refresh <duration>]"}, Flags: []cli.Flag{
+			{Name: "boards", Arg: "ids", Desc: "Comma-separated board ids, shortLinks, or aliases (default: the default board)"},
+			{Name: "port", Arg: "n", Desc: "Port to listen on (default 8091)"},
+			{Name: "addr", Arg: "host", Desc: "Address to bind (default 127.0.0.1; anything else exposes the boards' cards)"},
+			{Name: "refresh", Arg: "duration", Desc: "Time between rebuilds of the feed (default 5m)"},
+		}},
+	},
+	Sections: []cli.HelpSection{{Title: "Subscribing", Body: `trelli calendar serve --boards EnGi,ops
+Then subscribe to http://127.0.0.1:8091/calendar.ics in the calendar app.`}},
+	Run:  cli.Local(runCalendar),
+	Mode: cli.ModeOnline,
+}
+
+const (
+	defaultCalendarPort    = 8091
+	defaultCalendarRefresh = 5 * time.Minute
+)
+
+func runCalendar(cfg cli.Config, args []string) error {
+	if len(args) == 0 {
+		cli.PrintCommandHelp("calendar")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("calendar")
+		return nil
+	case "serve":
+		return runCalendarServe(cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown calendar subcommand %q", args[0])
+	}
+}
+
+func runCalendarServe(cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	fs := flag.NewFlagSet("calendar serve", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boards := cfg.BoardID
+	var addr string
+	var port int
+	var refresh time.Duration
+	fs.StringVar(&boards, "boards", boards, "Comma-separated board ids")
+	fs.StringVar(&addr, "addr", "127.0.0.1", "Address to bind")
+	fs.IntVar(&port, "port", defaultCalendarPort, "Port to listen on")
+	fs.DurationVar(&refresh, "refresh", defaultCalendarRefresh, "Time between rebuilds of the feed")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("calendar")); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	var boardIDs []string
+	for _, id := range cli.SplitIDs(boards) {
+		boardIDs = append(boardIDs, cfg.File.ResolveBoardAlias(id))
+	}
+	if len(boardIDs) == 0 {
+		return errors.New("missing --boards and no default board configured")
+	}
+	if port < 0 || port > 65535 {
+		return fmt.Errorf("invalid --port %d", port)
+	}
+	if refresh < time.Minute {
+		return errors.New("--refresh must be at least 1m")
+	}
+
+	cfg.NoProgress = true
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		return err
+	}
+	client.LongRunning()
+	feed := &calendarFeed{client: client, boards: boardIDs, refresh: refresh}
+	if err := feed.update(ctx); err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", net.JoinHostPort(addr, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	host := ln.Addr().String()
+	if ip := net.ParseIP(addr); addr != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		slog.Warn(fmt.Sprintf("serving on %s; anyone who can reach it can read the cards in the feed", ln.Addr()), "addr", ln.Addr().String())
+		host = ""
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /calendar.ics", feed)
+	mux.Handle("GET /{$}", feed)
+	srv := &http.Server{
+		Handler:           cli.GuardLocal(mux, host),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	slog.Info(fmt.Sprintf("Serving the calendar at http://%s/calendar.ics (Ctrl-C to stop)", ln.Addr()), "addr", ln.Addr().String())
+
+	go func() {
+		tick := time.NewTicker(refresh)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+			}
+			if err := feed.update(ctx); err != nil && ctx.Err() == nil {
+				slog.Warn(fmt.Sprintf("refreshing the calendar: %v; serving the previous feed", err))
+			}
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}()
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	client.Stats.Print(os.Stderr, cfg.JSON)
+	return nil
+}
+
+// calendarFeed serves the latest ICS feed built from boards.
+type calendarFeed struct {
+	client  *cli.Client
+	boards  []string
+	refresh time.Duration
+
+	mu       sync.RWMutex
+	body     []byte
+	etag     string
+	modified time.Time
+}
+
+// calendarCard is a card as the feed needs it.
+type calendarCard struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	Desc             string `json:"desc"`
+	Due              string `json:"due"`
+	DueComplete      bool   `json:"dueComplete"`
+	ShortURL         string `json:"shortUrl"`
+	Closed           bool   `json:"closed"`
+	DateLastActivity string `json:"dateLastActivity"`
+}
+
+// update rebuilds the feed, keeping the previous one on failure. The
+// modification time only moves when the feed changed.
+func (f *calendarFeed) update(ctx context.Context) error {
+	body, err := buildCalendar(ctx, f.client, f.boards, f.refresh)
+	if err != nil {
+		return err
+	}
+	etag := fmt.Sprintf(`"%x"`, sha1.Sum(body))
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if etag != f.etag {
+		f.body, f.etag, f.modified = body, etag, time.Now()
+	}
+	return nil
+}
+
+func (f *calendarFeed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.RLock()
+	body, etag, modified := f.body, f.etag, f.modified
+	f.mu.RUnlock()
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	// ServeContent answers conditional requests and HEAD.
+	http.ServeContent(w, r, "calendar.ics", modified, bytes.NewReader(body))
+}
+
+// buildCalendar returns an ICS calendar with an event at the due date of
+// every open card of boards.
+func buildCalendar(ctx context.Context, client *cli.Client, boardIDs []string, refresh time.Duration) ([]byte, error) {
+	var b bytes.Buffer
+	var names []string
+	var events bytes.Buffer
+	for _, boardID := range boardIDs {
+		var board cli.Board
+		var cards []calendarCard
+		query := url.Values{}
+		query.Set("filter", "open")
+		query.Set("fields", "id,name,desc,due,dueComplete,shortUrl,closed,dateLastActivity")
+		cardsReq := cli.GetRequest{Path: "/1/boards/" + url.PathEscape(boardID) + "/cards", Query: query, Out: &cards}
+		if err := client.GetAll(ctx, cli.BoardRequest(boardID, &board), cardsReq); err != nil {
+			return nil, fmt.Errorf("board %s: %w", boardID, err)
+		}
+		names = append(names, board.Name)
+		for _, c := range cards {
+			due, err := time.Parse(time.RFC3339, c.Due)
+			if err != nil || c.Closed {
+				continue
+			}
+			writeCalendarEvent(&events, c, board.Name, due)
+		}
+	}
+
+	icsLine(&b, "BEGIN:VCALENDAR")
+	icsLine(&b, "VERSION:2.0")
+	icsLine(&b, "PRODID:-//trelli//calendar//EN")
+	icsLine(&b, "CALSCALE:GREGORIAN")
+	icsLine(&b, "METHOD:PUBLISH")
+	icsLine(&b, "X-WR-CALNAME:"+icsText("Trello: "+strings.Join(names, ", ")))
+	icsLine(&b, fmt.Sprintf("REFRESH-INTERVAL;VALUE=DURATION:PT%dM", int(refresh.Minutes())))
+	icsLine(&b, fmt.Sprintf("X-PUBLISHED-TTL:PT%dM", int(refresh.Minutes())))
+	b.Write(events.Bytes())
+	icsLine(&b, "END:VCALENDAR")
+	return b.Bytes(), nil
+}
+
+// icsTime is the UTC date-time format of iCalendar.
+const icsTime = "20060102T150405Z"
+
+// writeCalendarEvent writes a card as an event without duration at its
+// due time.
+func writeCalendarEvent(b *bytes.Buffer, c calendarCard, board string, due time.Time) {
+	summary := c.Name
+	if c.DueComplete {
+		summary = "✓ " + summary
+	}
+	stamp := due
+	if t, err := time.Parse(time.RFC3339, c.DateLastActivity); err == nil {
+		stamp = t
+	}
+	desc := strings.TrimSpace(strings.Join([]string{c.ShortURL, strings.TrimSpace(c.Desc)}, "\n\n"))
+	icsLine(b, "BEGIN:VEVENT")
+	icsLine(b, "UID:"+c.ID+"@trelli")
+	icsLine(b, "DTSTAMP:"+stamp.UTC().Format(icsTime))
+	icsLine(b, "DTSTART:"+due.UTC().Format(icsTime))
+	icsLine(b, "SUMMARY:"+icsText(summary))
+	if desc != "" {
+		icsLine(b, "DESCRIPTION:"+icsText(desc))
+	}
+	if c.ShortURL != "" {
+		icsLine(b, "URL:"+c.ShortURL)
+	}
+	icsLine(b, "CATEGORIES:"+icsText(board))
+	icsLine(b, "TRANSP:TRANSPARENT")
+	icsLine(b, "END:VEVENT")
+}
+
+// icsText escapes a TEXT value.
+func icsText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// icsLine writes a content line, folded at 75 octets without splitting a
+// UTF-8 sequence, and ends it with CRLF.
+func icsLine(b *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with the space.
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,67 @@
+package calendar
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+func TestCalendarFeed(t *testing.T) {
+	stub := clitest.NewStub(t)
+	cfg, _, err := clitest.TestConfig(t, stub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed := &calendarFeed{client: client, boards: []string{"b1"}, refresh: 5 * time.Minute}
+	if err := feed.update(cfg.Context); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(feed)
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/calendar.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	clitest.CheckGolden(t, "calendar_feed", string(body))
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/calendar.ics", nil)
+	req.Header.Set("If-None-Match", resp.Header.Get("ETag"))
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("conditional GET = %d, want 304", resp.StatusCode)
+	}
+}
+
+func TestICSLineFolding(t *testing.T) {
+	var b bytes.Buffer
+	icsLine(&b, "SUMMARY:"+strings.Repeat("é", 60))
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		if len(line) > 75 || !strings.HasPrefix(line, "SUMMARY:") && !strings.HasPrefix(line, " ") {
+			t.Errorf("bad folded line %q", line)
+		}
+	}
+	if got := strings.ReplaceAll(b.String(), "\r\n ", ""); got != "SUMMARY:"+strings.Repeat("é", 60)+"\r\n" {
+		t.Errorf("unfolded = %q", got)
+	}
+}
@@ -0,0 +1,67 @@
+package cards
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"trelli/internal/cli"
+	"trelli/internal/cmd/find"
+)
+
+// boardCard is a card with its board, for cards list --boards.
+type boardCard struct {
+	Board   string `json:"board"`
+	BoardID string `json:"boardId"`
+	cli.Card
+}
+
+// listBoardsCards returns the cards of the list named listName on each of
+// the boards, in the order of the boards. A board whose list cannot be
+// read is reported and skipped, so it does not hide the others.
+func listBoardsCards(ctx context.Context, client *cli.Client, cfg cli.Config, scope []string, listName string, opts listCardsOptions) ([]boardCard, error) {
+	boards, err := find.Boards(cfg, client, scope)
+	if err != nil {
+		return nil, err
+	}
+	found := make([][]cli.Card, len(boards))
+	failed := make([]error, len(boards))
+	tasks := make([]func() error, len(boards))
+	for i, b := range boards {
+		tasks[i] = func() error {
+			listID, err := cli.ResolveListID(ctx, client, b.ID, "", listName)
+			if err == nil {
+				found[i], err = listCards(ctx, client, listID, opts)
+			}
+			failed[i] = err
+			return nil
+		}
+	}
+	_ = cli.Parallel(cfg.Concurrency, tasks...)
+
+	var cards []boardCard
+	bad := 0
+	for i, b := range boards {
+		if failed[i] != nil {
+			bad++
+			slog.Warn(fmt.Sprintf("board %s: %s", cli.FirstNonEmpty(b.Name, b.ID), failed[i]), "board", b.ID)
+			continue
+		}
+		for _, c := range found[i] {
+			cards = append(cards, boardCard{Board: b.Name, BoardID: b.ID, Card: c})
+		}
+	}
+	if bad > 0 && bad == len(boards) {
+		return nil, fmt.Errorf("could not list %q on any of the %d boards", listName, bad)
+	}
+	return cards, nil
+}
+
+func boardCardsTable(cards []boardCard) cli.Table {
+	t := cli.Table{Columns: []string{"BOARD", "ID", "NAME", "DUE", "CLOSED", "URL"}, Empty: "No cards found."}
+	for _, c := range cards {
+		t.Rows = append(t.Rows, []string{c.Board, c.ID, c.Name, c.Due, strconv.FormatBool(c.Closed), cli.FirstNonEmpty(c.ShortURL, c.URL)})
+	}
+	return t
+}
@@ -0,0 +1,41 @@
+package cards
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// TestMain registers the command as main does, for the tests that run it
+// through the CLI.
+func TestMain(m *testing.M) {
+	cli.Register(Command)
+	os.Exit(m.Run())
+}
+
+func TestCardsListBoards(t *testing.T) {
+	clitest.WithStubRoutes(t, map[string]string{
+		"/1/lists/l3/cards":  `[{"id": "c4", "name": "Budget review", "idList": "l3", "shortUrl": "https://trello.com/c/BuDg", "closed": false}]`,
+		"/1/boards/b3":       `{"id": "b3", "name": "Support"}`,
+		"/1/boards/b3/lists": `[{"id": "l9", "name": "Backlog"}]`,
+	})
+	stub := clitest.NewStub(t)
+	clitest.CheckGolden(t, "cards_list_boards", clitest.RunCLI(t, stub, "cards", "list", "--boards", "b1,b2,b3", "--list-name", "To Do"))
+
+	got := clitest.RunCLI(t, stub, "--json", "cards", "list", "--boards", "b2", "--list-name", "to do")
+	if !strings.Contains(got, `"board": "Management"`) || !strings.Contains(got, `"boardId": "b2"`) || !strings.Contains(got, `"id": "c4"`) {
+		t.Errorf("cards list --boards --json:\n%s", got)
+	}
+	if got := clitest.RunCLI(t, stub, "cards", "list", "--boards", "b1,b2", "--list-name", "To Do", "--count"); got != "3\n" {
+		t.Errorf("cards list --boards --count = %q", got)
+	}
+	if got := clitest.RunCLI(t, stub, "cards", "list", "--boards", "b3", "--list-name", "To Do"); !strings.Contains(got, `could not list "To Do" on any of the 1 boards`) {
+		t.Errorf("cards list --boards b3:\n%s", got)
+	}
+	if got := clitest.RunCLI(t, stub, "cards", "list", "--boards", "b1", "--list", "l1"); !strings.Contains(got, "--boards requires --list-name") {
+		t.Errorf("cards list --boards --list:\n%s", got)
+	}
+}
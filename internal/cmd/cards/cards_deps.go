@@ -0,0 +1,334 @@
+package cards
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+// depsAttachmentPrefix starts the name of the card-link attachment that
+// records a dependency. It is put on the blocking card and links the card
+// it blocks, so the link shows on the blocker in Trello.
+const depsAttachmentPrefix = "Blocks: "
+
+// cardLinkPattern finds the shortLink in a Trello card URL.
+var cardLinkPattern = regexp.MustCompile(`^https://trello\.com/c/([A-Za-z0-9]+)`)
+
+// depsCard is a board card with the attachments that may record
+// dependencies.
+type depsCard struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	IDList      string           `json:"idList"`
+	ShortURL    string           `json:"shortUrl"`
+	Attachments []cli.Attachment `json:"attachments"`
+}
+
+// depsGraph is a board's dependencies: Edges[i] is [blocker, blocked] by
+// card id, or by URL for a blocked card that is archived or on another
+// board. Names holds the names of those, as the attachments give them.
+type depsGraph struct {
+	Board  cli.Board
+	Cards  map[string]depsCard
+	Lists  map[string]string
+	Names  map[string]string
+	Order  []string
+	Edges  [][2]string
+	linkID map[string]string
+}
+
+// depsEdge is one dependency as show and graph --json print it.
+type depsEdge struct {
+	Relation string `json:"relation,omitempty"`
+	Card     string `json:"card"`
+	Name     string `json:"name"`
+	List     string `json:"list,omitempty"`
+	URL      string `json:"url"`
+}
+
+func runCardsDeps(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("cards deps requires add, show, or graph")
+	}
+	switch args[0] {
+	case "add":
+		return runCardsDepsAdd(ctx, client, cfg, args[1:])
+	case "show":
+		return runCardsDepsShow(ctx, client, cfg, args[1:])
+	case "graph":
+		return runCardsDepsGraph(ctx, client, cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown cards deps subcommand %q", args[0])
+	}
+}
+
+func runCardsDepsAdd(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("cards deps add", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var cardID, blocks, blockedBy string
+	fs.StringVar(&cardID, "card", "", "Card id")
+	fs.StringVar(&blocks, "blocks", "", "Card that cannot start before this one is done")
+	fs.StringVar(&blockedBy, "blocked-by", "", "Card that must be done before this one")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("cards")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &cardID); err != nil {
+		return err
+	}
+	if strings.TrimSpace(cardID) == "" {
+		return errors.New("cards deps add requires --card")
+	}
+	if (blocks == "") == (blockedBy == "") {
+		return errors.New("cards deps add requires one of --blocks or --blocked-by")
+	}
+	blocker, blocked := cardID, blocks
+	if blockedBy != "" {
+		blocker, blocked = blockedBy, cardID
+	}
+
+	from, err := client.Cards.Get(ctx, blocker, "id,name,shortUrl")
+	if err != nil {
+		return err
+	}
+	to, err := client.Cards.Get(ctx, blocked, "id,name,shortUrl")
+	if err != nil {
+		return err
+	}
+	if from.ID == to.ID {
+		return errors.New("a card cannot block itself")
+	}
+	boardID, err := cardBoardID(ctx, client, from.ID)
+	if err != nil {
+		return err
+	}
+	g, err := loadDepsGraph(ctx, client, boardID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case slices.Contains(g.Edges, [2]string{from.ID, to.ID}):
+		fmt.Printf("Card %s already blocks card %s\n", from.ID, to.ID)
+		return nil
+	case g.reaches(to.ID, from.ID):
+		return fmt.Errorf("card %s already depends on card %s; adding this would make a cycle", to.ID, from.ID)
+	}
+
+	link := cli.FirstNonEmpty(to.ShortURL, to.URL)
+	attachment, err := client.Cards.AttachURL(ctx, from.ID, link, depsAttachmentPrefix+to.Name)
+	if err != nil {
+		return err
+	}
+	if cfg.Structured() {
+		return cli.Render(cfg, attachment)
+	}
+	fmt.Printf("Card %s (%s) now blocks card %s (%s)\n", from.ID, cfg.Redact.RedactText(from.Name), to.ID, cfg.Redact.RedactText(to.Name))
+	return nil
+}
+
+func runCardsDepsShow(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("cards deps show", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var cardID string
+	fs.StringVar(&cardID, "card", "", "Card id")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("cards")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &cardID); err != nil {
+		return err
+	}
+	if strings.TrimSpace(cardID) == "" {
+		return errors.New("cards deps show requires --card")
+	}
+	card, err := client.Cards.Get(ctx, cardID, "id")
+	if err != nil {
+		return err
+	}
+	boardID, err := cardBoardID(ctx, client, card.ID)
+	if err != nil {
+		return err
+	}
+	g, err := loadDepsGraph(ctx, client, boardID)
+	if err != nil {
+		return err
+	}
+	edges := []depsEdge{}
+	for _, e := range g.Edges {
+		switch card.ID {
+		case e[1]:
+			edges = append(edges, g.edge("blocked by", e[0]))
+		case e[0]:
+			edges = append(edges, g.edge("blocks", e[1]))
+		}
+	}
+	t := cli.Table{Columns: []string{"RELATION", "CARD", "NAME", "LIST", "URL"}, Empty: "No dependencies."}
+	for _, e := range edges {
+		t.Rows = append(t.Rows, []string{e.Relation, e.Card, e.Name, e.List, e.URL})
+	}
+	return cli.Render(cfg, edges, t)
+}
+
+func runCardsDepsGraph(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("cards deps graph", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boardID := cfg.BoardID
+	format := "dot"
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&format, "format", format, "Graph format")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("cards")); err != nil {
+		return err
+	}
+	var positionalBoard string
+	if err := cli.TakePositional(fs, &positionalBoard); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(positionalBoard, boardID))
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+	if format != "dot" {
+		return fmt.Errorf("invalid --format %q: only dot is supported", format)
+	}
+	g, err := loadDepsGraph(ctx, client, boardID)
+	if err != nil {
+		return err
+	}
+	if cfg.Structured() {
+		type jsonEdge struct {
+			Blocker depsEdge `json:"blocker"`
+			Blocked depsEdge `json:"blocked"`
+		}
+		edges := []jsonEdge{}
+		for _, e := range g.Edges {
+			edges = append(edges, jsonEdge{Blocker: g.edge("", e[0]), Blocked: g.edge("", e[1])})
+		}
+		return cli.Render(cfg, edges)
+	}
+	return writeDepsDot(os.Stdout, g)
+}
+
+// loadDepsGraph reads the dependencies recorded on the board's open cards.
+func loadDepsGraph(ctx context.Context, client *cli.Client, boardID string) (*depsGraph, error) {
+	var board cli.Board
+	var lists []cli.TrelloList
+	var cards []depsCard
+	query := url.Values{}
+	query.Set("filter", "open")
+	query.Set("fields", "id,name,idList,shortUrl")
+	query.Set("attachments", "true")
+	query.Set("attachment_fields", "name,url")
+	if err := client.GetAll(ctx,
+		cli.BoardRequest(boardID, &board),
+		cli.BoardListsRequest(boardID, &lists),
+		cli.GetRequest{Path: "/1/boards/" + url.PathEscape(boardID) + "/cards", Query: query, Out: &cards},
+	); err != nil {
+		return nil, err
+	}
+	g := &depsGraph{Board: board, Cards: map[string]depsCard{}, Lists: map[string]string{}, Names: map[string]string{}, linkID: map[string]string{}}
+	for _, l := range lists {
+		g.Lists[l.ID] = l.Name
+	}
+	for _, c := range cards {
+		g.Cards[c.ID] = c
+		g.Order = append(g.Order, c.ID)
+		if m := cardLinkPattern.FindStringSubmatch(c.ShortURL); m != nil {
+			g.linkID[m[1]] = c.ID
+		}
+	}
+	for _, c := range cards {
+		for _, a := range c.Attachments {
+			if !strings.HasPrefix(a.Name, depsAttachmentPrefix) {
+				continue
+			}
+			m := cardLinkPattern.FindStringSubmatch(a.URL)
+			if m == nil {
+				continue
+			}
+			// Cards that are archived or on other boards are kept by URL.
+			blocked := g.linkID[m[1]]
+			if blocked == "" {
+				blocked = a.URL
+				g.Names[a.URL] = strings.TrimPrefix(a.Name, depsAttachmentPrefix)
+			}
+			if !slices.Contains(g.Edges, [2]string{c.ID, blocked}) {
+				g.Edges = append(g.Edges, [2]string{c.ID, blocked})
+			}
+		}
+	}
+	return g, nil
+}
+
+// reaches reports whether to can be reached from from along dependencies.
+func (g *depsGraph) reaches(from, to string) bool {
+	seen := map[string]bool{}
+	next := []string{from}
+	for len(next) > 0 {
+		id := next[len(next)-1]
+		next = next[:len(next)-1]
+		if id == to {
+			return true
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		for _, e := range g.Edges {
+			if e[0] == id {
+				next = append(next, e[1])
+			}
+		}
+	}
+	return false
+}
+
+// edge describes the card with id, which is a URL for cards not on the
+// board or archived.
+func (g *depsGraph) edge(relation, id string) depsEdge {
+	c, ok := g.Cards[id]
+	if !ok {
+		return depsEdge{Relation: relation, Card: id, Name: g.Names[id], URL: id}
+	}
+	return depsEdge{Relation: relation, Card: c.ID, Name: c.Name, List: g.Lists[c.IDList], URL: c.ShortURL}
+}
+
+// writeDepsDot writes the graph in Graphviz DOT, with an edge from each
+// card to the cards it blocks. Cards without dependencies are left out.
+func writeDepsDot(w io.Writer, g *depsGraph) error {
+	used := map[string]bool{}
+	for _, e := range g.Edges {
+		used[e[0]], used[e[1]] = true, true
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(cli.FirstNonEmpty(g.Board.Name, g.Board.ID)))
+	b.WriteString("  rankdir=LR;\n  node [shape=box];\n")
+	for _, id := range g.Order {
+		if used[id] {
+			c := g.Cards[id]
+			fmt.Fprintf(&b, "  %s [label=%s];\n", dotQuote(id), dotQuote(c.Name+"\n"+g.Lists[c.IDList]))
+		}
+	}
+	for _, e := range g.Edges {
+		if _, ok := g.Cards[e[1]]; !ok {
+			fmt.Fprintf(&b, "  %s [label=%s, style=dashed];\n", dotQuote(e[1]), dotQuote(cli.FirstNonEmpty(g.Names[e[1]], e[1])))
+		}
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(e[0]), dotQuote(e[1]))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote quotes s as a DOT string.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
@@ -0,0 +1,41 @@
+package cards
+
+import (
+	"strings"
+	"testing"
+
+	"trelli/internal/clitest"
+)
+
+var depsRoutes = map[string]string{
+	"/1/cards/c2": `{"id": "c2", "idBoard": "b1", "name": "Write \"release\" notes", "idList": "l1", "shortUrl": "https://trello.com/c/EfGh", "closed": false}`,
+	"/1/cards/c6": `{"id": "c6", "idBoard": "b1", "name": "Unrelated", "idList": "l2", "shortUrl": "https://trello.com/c/MnOp", "closed": false}`,
+	"/1/boards/b1/cards": `[
+		{"id": "c1", "name": "Fix login, again", "idList": "l1", "shortUrl": "https://trello.com/c/AbCd", "attachments": [
+			{"id": "at1", "name": "Blocks: Write \"release\" notes", "url": "https://trello.com/c/EfGh"},
+			{"id": "at2", "name": "Logs", "url": "https://trello.com/c/IjKl"}
+		]},
+		{"id": "c2", "name": "Write \"release\" notes", "idList": "l1", "shortUrl": "https://trello.com/c/EfGh", "attachments": [
+			{"id": "at3", "name": "Blocks: Budget review", "url": "https://trello.com/c/BdGt/7-budget-review"}
+		]},
+		{"id": "c6", "name": "Unrelated", "idList": "l2", "shortUrl": "https://trello.com/c/MnOp", "attachments": []}
+	]`,
+}
+
+func TestCardsDeps(t *testing.T) {
+	clitest.WithStubRoutes(t, depsRoutes)
+	stub := clitest.NewStub(t)
+	clitest.CheckGolden(t, "cards_deps_show", clitest.RunCLI(t, stub, "cards", "deps", "show", "c2"))
+	clitest.CheckGolden(t, "cards_deps_graph", clitest.RunCLI(t, stub, "cards", "deps", "graph", "--board", "b1"))
+	clitest.CheckGolden(t, "cards_deps_graph_json", clitest.RunCLI(t, stub, "--json", "cards", "deps", "graph", "b1"))
+	clitest.CheckGolden(t, "cards_deps_add_dry_run", clitest.RunCLI(t, stub, "--dry-run", "cards", "deps", "add", "c1", "--blocked-by", "c6"))
+	if got := clitest.RunCLI(t, stub, "cards", "deps", "add", "--card", "c1", "--blocks", "c2"); !strings.Contains(got, "already blocks") {
+		t.Errorf("existing dependency: %s", got)
+	}
+	if got := clitest.RunCLI(t, stub, "cards", "deps", "add", "--card", "c2", "--blocks", "c1"); !strings.Contains(got, "would make a cycle") {
+		t.Errorf("cycle: %s", got)
+	}
+	if got := clitest.RunCLI(t, stub, "cards", "deps", "graph", "b1", "--format", "mermaid"); !strings.Contains(got, `invalid --format "mermaid"`) {
+		t.Errorf("format: %s", got)
+	}
+}
@@ -0,0 +1,94 @@
+package cards
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"trelli/internal/cli"
+	"trelli/internal/cmd/importcmd"
+)
+
+// maxDescLength is the longest description Trello accepts, in characters.
+const maxDescLength = 16384
+
+func runCardsDesc(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	if len(args) == 0 || (args[0] != "append" && args[0] != "prepend") {
+		return errors.New("cards desc requires append or prepend")
+	}
+	action := args[0]
+	fs := flag.NewFlagSet("cards desc "+action, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var cardID, text, textFile string
+	fs.StringVar(&cardID, "card", "", "Card id")
+	fs.StringVar(&text, "text", "", "Text to add")
+	fs.StringVar(&textFile, "text-file", "", "File with the text to add, or - for stdin")
+	if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("cards")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &cardID, &text); err != nil {
+		return err
+	}
+	if strings.TrimSpace(cardID) == "" {
+		return fmt.Errorf("cards desc %s requires --card", action)
+	}
+	if textFile != "" {
+		if text != "" {
+			return errors.New("--text and --text-file cannot be combined")
+		}
+		f, err := importcmd.OpenFile(textFile)
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", textFile, err)
+		}
+		text = string(data)
+	}
+	text = strings.TrimRight(text, "\r\n")
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("cards desc %s requires --text or --text-file", action)
+	}
+
+	// The description is read and written back right away; Trello has no
+	// conditional update, so an edit landing in between would be lost.
+	current, err := client.Cards.Get(ctx, cardID, "desc")
+	if err != nil {
+		return err
+	}
+	desc := joinDesc(current.Desc, text, action == "prepend")
+	if n := utf8.RuneCountInString(desc); n > maxDescLength {
+		return fmt.Errorf("the description of card %s would be %d characters, over Trello's limit of %d", cardID, n, maxDescLength)
+	}
+	card, err := client.Cards.Update(ctx, cardID, url.Values{"desc": {desc}})
+	if err != nil {
+		return err
+	}
+	if cfg.Structured() {
+		return cli.Render(cfg, card)
+	}
+	where := "to the end of"
+	if action == "prepend" {
+		where = "to the start of"
+	}
+	fmt.Printf("Added %d characters %s the description of card %s\n", utf8.RuneCountInString(text), where, cardID)
+	return nil
+}
+
+// joinDesc adds text on its own line before or after desc.
+func joinDesc(desc, text string, prepend bool) string {
+	switch {
+	case strings.TrimSpace(desc) == "":
+		return text
+	case prepend:
+		return text + "\n" + strings.TrimLeft(desc, "\r\n")
+	}
+	return strings.TrimRight(desc, "\r\n") + "\n" + text
+}
@@ -0,0 +1,38 @@
+package cards
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"trelli/internal/clitest"
+)
+
+func TestJoinDesc(t *testing.T) {
+	for _, tc := range []struct {
+		desc, text string
+		prepend    bool
+		want       string
+	}{
+		{"", "log", false, "log"},
+		{"  \n", "log", true, "log"},
+		{"Notes\n\n", "log", false, "Notes\nlog"},
+		{"\nNotes", "log", true, "log\nNotes"},
+	} {
+		if got := joinDesc(tc.desc, tc.text, tc.prepend); got != tc.want {
+			t.Errorf("joinDesc(%q, %q, %v) = %q, want %q", tc.desc, tc.text, tc.prepend, got, tc.want)
+		}
+	}
+}
+
+func TestCardsDescTextFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "entry.md")
+	if err := os.WriteFile(file, []byte("- ran the nightly build\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got := clitest.RunCLI(t, clitest.NewStub(t), "--dry-run", "cards", "desc", "append", "c1", "--text-file", file)
+	if !strings.Contains(got, "desc=Users are logged out after 5 minutes.\n- ran the nightly build") {
+		t.Errorf("dry run =\n%s", got)
+	}
+}
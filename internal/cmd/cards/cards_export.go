@@ -0,0 +1,328 @@
+package cards
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+// cardExportParts are the sections --include can name.
+var cardExportParts = []string{"checklists", "attachments", "comments"}
+
+// cardDocument is a card with everything cards export writes about it.
+type cardDocument struct {
+	cli.Card
+	DueComplete bool                `json:"dueComplete"`
+	Board       *cli.Board          `json:"board,omitempty"`
+	List        *cli.TrelloList     `json:"list,omitempty"`
+	Labels      []cli.Label         `json:"labels"`
+	Members     []cli.Member        `json:"members"`
+	Attachments []cardFile          `json:"attachments,omitempty"`
+	Checklists  []cli.Checklist     `json:"checklists,omitempty"`
+	Comments    []cli.CommentAction `json:"comments,omitempty"`
+}
+
+// cardFile is an attachment as cards export lists it.
+type cardFile struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Bytes    int64  `json:"bytes"`
+	MimeType string `json:"mimeType"`
+	Date     string `json:"date"`
+}
+
+func runCardsExport(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("cards export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var cards, out, dir string
+	format := "markdown"
+	include := strings.Join(cardExportParts, ",")
+	fs.StringVar(&cards, "card", "", "Comma-separated card ids")
+	fs.StringVar(&format, "format", format, "markdown or html")
+	fs.StringVar(&include, "include", include, "Sections to include")
+	fs.StringVar(&out, "out", "", "Write all cards to this file")
+	fs.StringVar(&dir, "dir", "", "Write one file per card to this folder")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("cards")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &cards); err != nil {
+		return err
+	}
+	ids := cli.SplitIDs(cards)
+	if len(ids) == 0 {
+		return errors.New("cards export requires --card")
+	}
+	if format != "markdown" && format != "md" && format != "html" {
+		return fmt.Errorf("invalid --format %q: want markdown or html", format)
+	}
+	if out != "" && dir != "" {
+		return errors.New("--out and --dir cannot be combined")
+	}
+	parts := map[string]bool{}
+	for _, p := range cli.SplitIDs(include) {
+		if !slices.Contains(cardExportParts, p) {
+			return fmt.Errorf("invalid --include %q: want any of %s", p, strings.Join(cardExportParts, ", "))
+		}
+		parts[p] = true
+	}
+
+	docs, err := fetchCardDocuments(ctx, client, ids, parts)
+	if err != nil {
+		return err
+	}
+	if cfg.Structured() && out == "" && dir == "" {
+		return cli.Render(cfg, docs)
+	}
+	write := writeCardsMarkdown
+	ext := ".md"
+	if format == "html" {
+		write, ext = writeCardsHTML, ".html"
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		var files []string
+		for _, d := range docs {
+			var b bytes.Buffer
+			if err := write(&b, []cardDocument{d}); err != nil {
+				return err
+			}
+			file := filepath.Join(dir, cli.ShortLinkOf(d.Card)+ext)
+			if err := os.WriteFile(file, b.Bytes(), 0o644); err != nil {
+				return err
+			}
+			files = append(files, file)
+		}
+		if cfg.Structured() {
+			return cli.Render(cfg, files)
+		}
+		fmt.Printf("Exported %d cards -> %s\n", len(files), dir)
+		return nil
+	}
+	var b bytes.Buffer
+	if err := write(&b, docs); err != nil {
+		return err
+	}
+	if out == "" || out == "-" {
+		_, err := os.Stdout.Write(b.Bytes())
+		return err
+	}
+	if err := os.WriteFile(out, b.Bytes(), 0o644); err != nil {
+		return err
+	}
+	if cfg.Structured() {
+		return cli.Render(cfg, []string{out})
+	}
+	fmt.Printf("Exported %d cards -> %s\n", len(docs), out)
+	return nil
+}
+
+// fetchCardDocuments reads the cards with their board, list, labels, and
+// members, and the parts asked for, in as few batched requests as
+// possible.
+func fetchCardDocuments(ctx context.Context, client *cli.Client, ids []string, parts map[string]bool) ([]cardDocument, error) {
+	docs := make([]cardDocument, len(ids))
+	query := url.Values{}
+	query.Set("fields", cli.CardFields(cli.Config{})+",dueComplete,labels")
+	query.Set("board", "true")
+	query.Set("board_fields", "name,url")
+	query.Set("list", "true")
+	query.Set("list_fields", "name")
+	query.Set("members", "true")
+	query.Set("member_fields", "fullName,username")
+	if parts["attachments"] {
+		query.Set("attachments", "true")
+		query.Set("attachment_fields", "name,url,bytes,mimeType,date")
+	}
+	var reqs []cli.GetRequest
+	for i, id := range ids {
+		reqs = append(reqs, cli.GetRequest{Path: "/1/cards/" + url.PathEscape(id), Query: query, Out: &docs[i]})
+		if parts["checklists"] {
+			reqs = append(reqs, cli.ChecklistsRequest(id, &docs[i].Checklists))
+		}
+		if parts["comments"] {
+			reqs = append(reqs, cli.CommentsRequest(id, cli.CommentPageSize, &docs[i].Comments))
+		}
+	}
+	if err := client.GetAll(ctx, reqs...); err != nil {
+		return nil, err
+	}
+	for i := range docs {
+		docs[i].Labels = cli.NonNil(docs[i].Labels)
+		docs[i].Members = cli.NonNil(docs[i].Members)
+	}
+	return docs, nil
+}
+
+// cardFacts are the label/value rows at the top of an exported card.
+func cardFacts(d cardDocument) [][2]string {
+	var facts [][2]string
+	if d.Board != nil {
+		facts = append(facts, [2]string{"Board", d.Board.Name})
+	}
+	if d.List != nil {
+		facts = append(facts, [2]string{"List", d.List.Name})
+	}
+	if len(d.Labels) > 0 {
+		names := make([]string, len(d.Labels))
+		for i, l := range d.Labels {
+			names[i] = cli.FirstNonEmpty(l.Name, l.Color)
+		}
+		facts = append(facts, [2]string{"Labels", strings.Join(names, ", ")})
+	}
+	if len(d.Members) > 0 {
+		names := make([]string, len(d.Members))
+		for i, m := range d.Members {
+			names[i] = cli.FirstNonEmpty(m.FullName, m.Username)
+		}
+		facts = append(facts, [2]string{"Members", strings.Join(names, ", ")})
+	}
+	if d.Due != "" {
+		due := cli.NoteTime(d.Due)
+		if d.DueComplete {
+			due += " (complete)"
+		}
+		facts = append(facts, [2]string{"Due", due})
+	}
+	if d.Closed {
+		facts = append(facts, [2]string{"Status", "Archived"})
+	}
+	if d.DateLastActivity != "" {
+		facts = append(facts, [2]string{"Last activity", cli.NoteTime(d.DateLastActivity)})
+	}
+	facts = append(facts, [2]string{"Link", cli.FirstNonEmpty(d.ShortURL, d.URL)})
+	return facts
+}
+
+// writeCardsMarkdown writes the cards as one Markdown document, separated
+// by rules.
+func writeCardsMarkdown(w io.Writer, docs []cardDocument) error {
+	var b bytes.Buffer
+	for i, d := range docs {
+		if i > 0 {
+			b.WriteString("---\n\n")
+		}
+		fmt.Fprintf(&b, "# %s\n\n", d.Name)
+		b.WriteString("| | |\n|---|---|\n")
+		for _, f := range cardFacts(d) {
+			fmt.Fprintf(&b, "| **%s** | %s |\n", f[0], strings.ReplaceAll(f[1], "|", `\|`))
+		}
+		b.WriteString("\n")
+		if desc := strings.TrimSpace(d.Desc); desc != "" {
+			b.WriteString("## Description\n\n" + desc + "\n\n")
+		}
+		if len(d.Checklists) > 0 {
+			b.WriteString("## Checklists\n\n")
+			for _, cl := range d.Checklists {
+				cli.WriteChecklistMarkdown(&b, "###", cl)
+			}
+		}
+		if len(d.Attachments) > 0 {
+			b.WriteString("## Attachments\n\n")
+			for _, a := range d.Attachments {
+				fmt.Fprintf(&b, "- [%s](%s)%s\n", cli.FirstNonEmpty(a.Name, a.URL), a.URL, attachmentNote(a))
+			}
+			b.WriteString("\n")
+		}
+		if len(d.Comments) > 0 {
+			b.WriteString("## Comments\n\n")
+			cli.WriteCommentsMarkdown(&b, d.Comments)
+		}
+	}
+	_, err := w.Write(append(bytes.TrimRight(b.Bytes(), "\n"), '\n'))
+	return err
+}
+
+// attachmentNote describes an uploaded file's size and date; links have
+// neither.
+func attachmentNote(a cardFile) string {
+	var notes []string
+	if a.Bytes > 0 {
+		notes = append(notes, cli.FormatBytes(a.Bytes))
+	}
+	if a.Date != "" {
+		notes = append(notes, cli.DateOnly(a.Date))
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(notes, ", ") + ")"
+}
+
+// cardsHTML is a standalone, print-friendly page; each card starts on a
+// new page when printed to PDF.
+var cardsHTML = template.Must(template.New("cards").Funcs(template.FuncMap{
+	"facts":     cardFacts,
+	"time":      cli.NoteTime,
+	"note":      attachmentNote,
+	"complete":  func(item cli.ChecklistItem) bool { return item.State == "complete" },
+	"checklist": cli.SortedCheckItems,
+	"oldestFirst": func(c []cli.CommentAction) []cli.CommentAction {
+		c = slices.Clone(c)
+		slices.Reverse(c)
+		return c
+	},
+	"author": func(c cli.CommentAction) string {
+		return cli.FirstNonEmpty(c.MemberCreator.FullName, c.MemberCreator.Username)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{if eq (len .) 1}}{{(index . 0).Name}}{{else}}{{len .}} cards{{end}}</title>
+<style>
+body { font: 11pt/1.5 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #172b4d; max-width: 48em; margin: 2em auto; padding: 0 1em; }
+article + article { break-before: page; margin-top: 3em; }
+h1 { font-size: 1.6em; margin-bottom: .5em; }
+h2 { font-size: 1.15em; border-bottom: 1px solid #dfe1e6; padding-bottom: .2em; margin-top: 1.5em; }
+h3 { font-size: 1em; }
+table.facts td { padding: .1em 1em .1em 0; vertical-align: top; }
+table.facts td:first-child { font-weight: 600; }
+.desc, blockquote { white-space: pre-wrap; }
+ul.checklist { list-style: none; padding-left: 0; }
+blockquote { margin: .3em 0 1em; padding-left: 1em; border-left: 3px solid #dfe1e6; }
+a { color: #0c66e4; }
+@media print { body { margin: 0; max-width: none; } a { color: inherit; } }
+</style>
+</head>
+<body>
+{{range .}}<article>
+<h1>{{.Name}}</h1>
+<table class="facts">
+{{range facts .}}<tr><td>{{index . 0}}</td><td>{{if eq (index . 0) "Link"}}<a href="{{index . 1}}">{{index . 1}}</a>{{else}}{{index . 1}}{{end}}</td></tr>
+{{end}}</table>
+{{with .Desc}}<h2>Description</h2>
+<div class="desc">{{.}}</div>
+{{end}}{{with .Checklists}}<h2>Checklists</h2>
+{{range .}}<h3>{{.Name}}</h3>
+<ul class="checklist">
+{{range checklist .}}<li>{{if complete .}}☑{{else}}☐{{end}} {{.Name}}</li>
+{{end}}</ul>
+{{end}}{{end}}{{with .Attachments}}<h2>Attachments</h2>
+<ul>
+{{range .}}<li><a href="{{.URL}}">{{or .Name .URL}}</a>{{note .}}</li>
+{{end}}</ul>
+{{end}}{{with .Comments}}<h2>Comments</h2>
+{{range oldestFirst .}}<p><strong>{{author .}}</strong> ({{time .Date}})</p>
+<blockquote>{{.Data.Text}}</blockquote>
+{{end}}{{end}}</article>
+{{end}}</body>
+</html>
+`))
+
+func writeCardsHTML(w io.Writer, docs []cardDocument) error {
+	return cardsHTML.Execute(w, docs)
+}
@@ -0,0 +1,40 @@
+package cards
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"trelli/internal/clitest"
+)
+
+var cardsExportRoutes = map[string]string{
+	"/1/cards/c1": `{"id": "c1", "name": "Fix login, again", "desc": "Users are logged out after 5 minutes.\n\nSee the | session | table.", "idList": "l1", "due": "2026-03-01T12:00:00.000Z", "dueComplete": true, "shortUrl": "https://trello.com/c/AbCd", "closed": false, "dateLastActivity": "2026-02-10T09:30:00.000Z",
+		"board": {"id": "b1", "name": "Engineering", "url": "https://trello.com/b/EnGi/engineering"},
+		"list": {"id": "l1", "name": "To Do"},
+		"labels": [{"id": "lb1", "name": "Bug", "color": "red"}, {"id": "lb3", "name": "", "color": "blue"}],
+		"members": [{"id": "m1", "username": "ada", "fullName": "Ada Lovelace"}],
+		"attachments": [{"id": "at1", "name": "trace.log", "url": "https://trello.com/1/cards/c1/attachments/at1/download/trace.log", "bytes": 2048, "mimeType": "text/plain", "date": "2026-02-09T08:00:00.000Z"}, {"id": "at2", "name": "", "url": "https://github.com/acme/app/issues/1", "bytes": 0}]}`,
+}
+
+func TestCardsExport(t *testing.T) {
+	clitest.WithStubRoutes(t, cardsExportRoutes)
+	stub := clitest.NewStub(t)
+	clitest.CheckGolden(t, "cards_export_markdown", clitest.RunCLI(t, stub, "cards", "export", "c1"))
+	clitest.CheckGolden(t, "cards_export_html", clitest.RunCLI(t, stub, "cards", "export", "--card", "c1", "--format", "html", "--include", "checklists"))
+
+	dir := t.TempDir()
+	clitest.RunCLI(t, stub, "cards", "export", "c1", "--dir", dir, "--include", "")
+	data, err := os.ReadFile(filepath.Join(dir, "AbCd.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "## Comments") || !strings.HasPrefix(string(data), "# Fix login, again\n") {
+		t.Errorf("exported file =\n%s", data)
+	}
+
+	if got := clitest.RunCLI(t, stub, "cards", "export", "c1", "--include", "history"); !strings.Contains(got, `invalid --include "history"`) {
+		t.Errorf("unknown part: %s", got)
+	}
+}
@@ -0,0 +1,119 @@
+package cards
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+// noReminder is Trello's dueReminder for a due date without a reminder.
+const noReminder = -1
+
+func runCardsUpdate(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("cards update", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var cardID, name, desc, due, reminder string
+	fs.StringVar(&cardID, "card", "", "Card id")
+	fs.StringVar(&name, "name", "", "New card title")
+	fs.StringVar(&desc, "desc", "", "New description")
+	fs.StringVar(&due, "due", "", "Due date/time (ISO-8601), or none")
+	fs.StringVar(&reminder, "reminder", "", "Minutes before the due date to remind, or none")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("cards")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &cardID); err != nil {
+		return err
+	}
+	if strings.TrimSpace(cardID) == "" {
+		return errors.New("cards update requires --card")
+	}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	form := url.Values{}
+	if set["name"] {
+		if strings.TrimSpace(name) == "" {
+			return errors.New("--name cannot be empty")
+		}
+		form.Set("name", name)
+	}
+	if set["desc"] {
+		form.Set("desc", desc)
+	}
+	if set["due"] {
+		if strings.EqualFold(due, "none") {
+			due = ""
+		}
+		form.Set("due", due)
+	}
+	if set["reminder"] {
+		minutes, err := parseReminder(reminder)
+		if err != nil {
+			return err
+		}
+		form.Set("dueReminder", strconv.Itoa(minutes))
+	}
+	if len(form) == 0 {
+		return errors.New("cards update needs at least one of --name, --desc, --due, or --reminder")
+	}
+	card, err := client.Cards.Update(ctx, cardID, form)
+	if err != nil {
+		return err
+	}
+	return cli.Render(cfg, card, cli.CardsTable([]cli.Card{card}))
+}
+
+// parseReminder reads --reminder: minutes before the due date, an age
+// such as 2h or 1d, or none.
+func parseReminder(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "none") {
+		return noReminder, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+		return n, nil
+	}
+	if d, err := cli.ParseAge(s); err == nil && d%time.Minute == 0 {
+		return int(d / time.Minute), nil
+	}
+	return 0, fmt.Errorf("invalid --reminder %q: want minutes before the due date (e.g. 60), an age such as 2h or 1d, or none", s)
+}
+
+// formatReminder describes a dueReminder for people.
+func formatReminder(minutes *int) string {
+	switch {
+	case minutes == nil || *minutes < 0:
+		return "none"
+	case *minutes == 0:
+		return "at due time"
+	case *minutes%(24*60) == 0:
+		return plural(*minutes/(24*60), "day") + " before"
+	case *minutes%60 == 0:
+		return plural(*minutes/60, "hour") + " before"
+	}
+	return plural(*minutes, "minute") + " before"
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return strconv.Itoa(n) + " " + unit + "s"
+}
+
+// dueReminderTable shows a card's due date and reminder in cards show --full.
+func dueReminderTable(card cli.Card) cli.Table {
+	t := cli.Table{Columns: []string{"DUE", "REMINDER"}, Empty: "No due date."}
+	if card.Due != "" {
+		t.Rows = append(t.Rows, []string{card.Due, formatReminder(card.DueReminder)})
+	}
+	return t
+}
@@ -0,0 +1,45 @@
+package cards
+
+import (
+	"testing"
+)
+
+func TestParseReminder(t *testing.T) {
+	for in, want := range map[string]int{
+		"60":   60,
+		"0":    0,
+		"2h":   120,
+		"1d":   1440,
+		"none": noReminder,
+		"None": noReminder,
+	} {
+		if got, err := parseReminder(in); err != nil || got != want {
+			t.Errorf("parseReminder(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "-5", "soon", "90s"} {
+		if got, err := parseReminder(in); err == nil {
+			t.Errorf("parseReminder(%q) = %d, want an error", in, got)
+		}
+	}
+}
+
+func TestFormatReminder(t *testing.T) {
+	n := func(i int) *int { return &i }
+	for _, tc := range []struct {
+		in   *int
+		want string
+	}{
+		{nil, "none"},
+		{n(-1), "none"},
+		{n(0), "at due time"},
+		{n(15), "15 minutes before"},
+		{n(60), "1 hour before"},
+		{n(90), "90 minutes before"},
+		{n(2880), "2 days before"},
+	} {
+		if got := formatReminder(tc.in); got != tc.want {
+			t.Errorf("formatReminder(%v) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
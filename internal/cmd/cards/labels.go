@@ -0,0 +1,136 @@
+package cards
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+// resolveLabelIDs turns label names and colors into the ids of the board's
+// labels; ids pass through. A name matches case-insensitively. A color
+// matches when no label has that name; if several labels share the
+// color, the one without a name wins, and otherwise it is ambiguous. The
+// board's labels come from the name cache and are fetched again when it
+// lacks one of them.
+func resolveLabelIDs(ctx context.Context, client *cli.Client, boardID string, labels []string) ([]string, error) {
+	if !cli.HasNames(labels) {
+		return labels, nil
+	}
+	if strings.TrimSpace(boardID) == "" {
+		return nil, errors.New("label names need a board: pass --board or configure a default board")
+	}
+	fetch := func(ctx context.Context, c *cli.Client) ([]cli.Label, error) {
+		return cli.FetchBoardLabels(ctx, c, boardID)
+	}
+	board, cached, err := cli.CachedLookup(ctx, client, "labels:"+boardID, fetch)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(labels))
+	for _, l := range labels {
+		id, err := cli.MatchLabel(board, l)
+		if err != nil && cached {
+			// The label may have been created or renamed since it was cached.
+			if board, err = fetch(ctx, client); err != nil {
+				return nil, err
+			}
+			cached = false
+			id, err = cli.MatchLabel(board, l)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w on board %s", err, boardID)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// cardBoardID returns the board a card is on.
+func cardBoardID(ctx context.Context, client *cli.Client, cardID string) (string, error) {
+	card, err := client.Cards.Get(ctx, cardID, "idBoard")
+	return card.IDBoard, err
+}
+
+// listBoardID returns the board a list is on.
+func listBoardID(ctx context.Context, client *cli.Client, listID string) (string, error) {
+	list, err := client.Lists.Get(ctx, listID, "idBoard")
+	return list.IDBoard, err
+}
+
+// cardLabelChange is the result of cards label add and remove.
+type cardLabelChange struct {
+	Card   string   `json:"card"`
+	Action string   `json:"action"`
+	Labels []string `json:"labels"`
+}
+
+func runCardsLabel(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	if len(args) == 0 || (args[0] != "add" && args[0] != "remove") {
+		return errors.New("cards label requires add or remove")
+	}
+	action := args[0]
+	fs := flag.NewFlagSet("cards label "+action, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var cardID, labels string
+	fs.StringVar(&cardID, "card", "", "Card id")
+	fs.StringVar(&labels, "label", "", "Label names, colors, or ids")
+	fs.StringVar(&labels, "labels", "", "Label names, colors, or ids")
+	if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("cards")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &cardID, &labels); err != nil {
+		return err
+	}
+	if strings.TrimSpace(cardID) == "" {
+		return fmt.Errorf("cards label %s requires --card", action)
+	}
+	names := cli.SplitIDs(labels)
+	if len(names) == 0 {
+		return fmt.Errorf("cards label %s requires --label", action)
+	}
+	ids := names
+	if cli.HasNames(names) {
+		boardID, err := cardBoardID(ctx, client, cardID)
+		if err != nil {
+			return err
+		}
+		if ids, err = resolveLabelIDs(ctx, client, boardID, names); err != nil {
+			return err
+		}
+	}
+	for i, id := range ids {
+		var err error
+		if action == "add" {
+			err = client.Cards.AddLabel(ctx, cardID, id)
+		} else {
+			err = client.Cards.RemoveLabel(ctx, cardID, id)
+		}
+		switch {
+		case errors.Is(err, cli.ErrDryRun):
+		case err != nil:
+			return err
+		case action == "add":
+			cli.RecordUndo(cfg, cli.JournalEntry{Action: "cards.label-add", Target: cardID, Parent: id, Summary: "add label " + names[i] + " to card " + cardID})
+		default:
+			cli.RecordUndo(cfg, cli.JournalEntry{Action: "cards.label-remove", Target: cardID, Parent: id, Summary: "remove label " + names[i] + " from card " + cardID})
+		}
+	}
+	if cfg.DryRun {
+		return cli.ErrDryRun
+	}
+	change := cardLabelChange{Card: cardID, Action: action, Labels: ids}
+	if cfg.Structured() {
+		return cli.Render(cfg, change)
+	}
+	if action == "add" {
+		fmt.Printf("Added %s to card %s\n", strings.Join(names, ", "), cardID)
+	} else {
+		fmt.Printf("Removed %s from card %s\n", strings.Join(names, ", "), cardID)
+	}
+	return nil
+}
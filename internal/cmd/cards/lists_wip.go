@@ -0,0 +1,46 @@
+package cards
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+// checkMoveWIP returns the WIP status of listID as it would be with the
+// card moved into it, and whether the move would put it over its limit.
+// Without a limit for the list, the status is empty.
+func checkMoveWIP(ctx context.Context, client *cli.Client, cfg cli.Config, cardID, listID string) (cli.WipStatus, bool, error) {
+	limits, err := cfg.File.WipLimits()
+	if err != nil {
+		return cli.WipStatus{}, false, err
+	}
+	if len(limits) == 0 {
+		return cli.WipStatus{}, false, nil
+	}
+	var list cli.TrelloList
+	var cards []cli.Card
+	listQuery := url.Values{}
+	listQuery.Set("fields", "id,name")
+	cardQuery := url.Values{}
+	cardQuery.Set("fields", "id")
+	if err := client.GetAll(ctx,
+		cli.GetRequest{Path: "/1/lists/" + url.PathEscape(listID), Query: listQuery, Out: &list},
+		cli.GetRequest{Path: "/1/lists/" + url.PathEscape(listID) + "/cards", Query: cardQuery, Out: &cards},
+	); err != nil {
+		return cli.WipStatus{}, false, err
+	}
+	limit, ok := limits[strings.ToLower(list.Name)]
+	if !ok {
+		return cli.WipStatus{}, false, nil
+	}
+	// A card that is already in the list does not change its count.
+	if slices.ContainsFunc(cards, func(c cli.Card) bool { return c.ID == cardID }) {
+		return cli.WipStatus{ID: list.ID, Name: list.Name, Cards: len(cards), Limit: limit, Over: len(cards) > limit}, false, nil
+	}
+	s := cli.WipStatus{ID: list.ID, Name: list.Name, Cards: len(cards) + 1, Limit: limit}
+	s.Over = s.Cards > limit
+	return s, s.Over, nil
+}
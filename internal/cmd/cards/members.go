@@ -0,0 +1,85 @@
+package cards
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+// cardMemberChange is the result of cards assign.
+type cardMemberChange struct {
+	Card    string   `json:"card"`
+	Action  string   `json:"action"`
+	Members []string `json:"members"`
+}
+
+func runCardsAssign(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("cards assign", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var cardID, members string
+	var remove bool
+	fs.StringVar(&cardID, "card", "", "Card id")
+	fs.StringVar(&members, "members", "", "@usernames or member ids")
+	fs.BoolVar(&remove, "remove", false, "Take the members off the card")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("cards")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &cardID, &members); err != nil {
+		return err
+	}
+	if strings.TrimSpace(cardID) == "" {
+		return errors.New("cards assign requires --card")
+	}
+	names := cli.SplitIDs(members)
+	if len(names) == 0 {
+		return errors.New("cards assign requires --members")
+	}
+	ids := names
+	if cli.HasNames(names) {
+		boardID, err := cardBoardID(ctx, client, cardID)
+		if err != nil {
+			return err
+		}
+		if ids, err = cli.ResolveMemberIDs(ctx, client, boardID, names); err != nil {
+			return err
+		}
+	}
+	for i, id := range ids {
+		var err error
+		if remove {
+			err = client.Cards.RemoveMember(ctx, cardID, id)
+		} else {
+			err = client.Cards.AddMember(ctx, cardID, id)
+		}
+		switch {
+		case errors.Is(err, cli.ErrDryRun):
+		case err != nil:
+			return err
+		case remove:
+			cli.RecordUndo(cfg, cli.JournalEntry{Action: "cards.unassign", Target: cardID, Parent: id, Summary: "remove " + names[i] + " from card " + cardID})
+		default:
+			cli.RecordUndo(cfg, cli.JournalEntry{Action: "cards.assign", Target: cardID, Parent: id, Summary: "assign " + names[i] + " to card " + cardID})
+		}
+	}
+	if cfg.DryRun {
+		return cli.ErrDryRun
+	}
+	change := cardMemberChange{Card: cardID, Action: "add", Members: ids}
+	if remove {
+		change.Action = "remove"
+	}
+	if cfg.Structured() {
+		return cli.Render(cfg, change)
+	}
+	if remove {
+		fmt.Printf("Removed %s from card %s\n", cfg.Redact.RedactText(strings.Join(names, ", ")), cardID)
+	} else {
+		fmt.Printf("Assigned %s to card %s\n", cfg.Redact.RedactText(strings.Join(names, ", ")), cardID)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package cards
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+	"trelli/pkg/trello"
+)
+
+// fakeAPI answers requests from canned bodies by method and path, without
+// HTTP, and records the writes.
+type fakeAPI struct {
+	t      *testing.T
+	bodies map[string]string
+	writes []string
+}
+
+func (f *fakeAPI) Endpoint(p string, query url.Values) (*url.URL, error) {
+	return &url.URL{Path: p, RawQuery: query.Encode()}, nil
+}
+
+func (f *fakeAPI) Send(ctx context.Context, method string, u *url.URL, form url.Values, etag string) ([]byte, http.Header, error) {
+	if method != http.MethodGet {
+		f.writes = append(f.writes, method+" "+u.Path+" "+form.Encode())
+	}
+	body, ok := f.bodies[method+" "+u.Path]
+	if !ok {
+		return nil, nil, trello.NewAPIError(http.StatusNotFound, "no fake for "+method+" "+u.Path)
+	}
+	return []byte(body), http.Header{}, nil
+}
+
+func (f *fakeAPI) Open(ctx context.Context, method string, u *url.URL, form url.Values, etag string) (*http.Response, error) {
+	raw, header, err := f.Send(ctx, method, u, form, etag)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(bytes.NewReader(raw))}, nil
+}
+
+func (f *fakeAPI) Upload(ctx context.Context, p string, form url.Values, file trello.File, out any) error {
+	f.t.Errorf("unexpected upload to %s", p)
+	return nil
+}
+
+func TestCommandsAgainstFakeAPI(t *testing.T) {
+	network := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request reached the network: %s %s", r.Method, r.URL.Path)
+	}))
+	t.Cleanup(network.Close)
+	fake := &fakeAPI{t: t, bodies: map[string]string{
+		"GET /1/lists/l1/cards": `[{"id": "c1", "name": "Fix login", "idList": "l1"}]`,
+		"PUT /1/cards/c1":       `{"id": "c1", "name": "Fix login", "idList": "l1", "closed": true}`,
+	}}
+	run := func(args ...string) string {
+		t.Helper()
+		cfg, rest, err := clitest.TestConfig(t, network, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, err := cli.Connect(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.API = fake
+		spec, _ := cli.FindCommand(rest[0])
+		stdout := clitest.CaptureStdout(t)
+		err = spec.Run(client, cfg, rest[1:])
+		out := stdout()
+		if err != nil {
+			t.Fatalf("%s: %v", strings.Join(args, " "), err)
+		}
+		return out
+	}
+
+	if got := run("cards", "list", "--list", "l1"); !strings.Contains(got, "c1") || !strings.Contains(got, "Fix login") {
+		t.Errorf("cards list:\n%s", got)
+	}
+	run("cards", "archive", "c1", "--yes")
+	if len(fake.writes) != 1 || fake.writes[0] != "PUT /1/cards/c1 closed=true" {
+		t.Errorf("writes = %q", fake.writes)
+	}
+}
@@ -0,0 +1,173 @@
+// Package check implements "trelli check": check boards from cron or CI.
+package check
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "check",
+	Summary: "Check boards from cron or CI",
+	Description: `Checks print what they find and exit with status 2 when they find
+something, 0 when they do not, and 1 when they cannot check, so cron
+and CI can alert on the exit status alone.
+
+overdue lists the open cards of a board whose due date has passed and is
+not marked complete, most overdue first, followed by a summary line.
+--member keeps the cards of one member: me, an @username, or a member
+id.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "overdue", Usage: []string{"overdue [[--board] <boardIdOrShortLink>] [--member me|<@user>]"}, Flags: []cli.Flag{
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "member", Arg: "member", Desc: "Only cards of this member: me, an @username, or a member id"},
+		}},
+	},
+	Options: []cli.Flag{cli.JSONOption},
+	Run:     runCheck,
+}
+
+// overdueCard is a card check overdue found.
+type overdueCard struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	List    string   `json:"list"`
+	Due     string   `json:"due"`
+	Days    int      `json:"days"`
+	Members []string `json:"members"`
+	URL     string   `json:"url"`
+}
+
+func runCheck(client *cli.Client, cfg cli.Config, args []string) error {
+	if len(args) == 0 {
+		cli.PrintCommandHelp("check")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("check")
+		return nil
+	case "overdue":
+		return runCheckOverdue(cfg.Context, client, cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown check subcommand %q", args[0])
+	}
+}
+
+func runCheckOverdue(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("check overdue", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boardID := cfg.BoardID
+	var member string
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&member, "member", "", "me, an @username, or a member id")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("check")); err != nil {
+		return err
+	}
+	var positionalBoard string
+	if err := cli.TakePositional(fs, &positionalBoard); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(positionalBoard, boardID))
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+	var memberID string
+	switch {
+	case member == "":
+	case member == "me":
+		me, err := cli.FetchMe(ctx, client)
+		if err != nil {
+			return err
+		}
+		memberID = me.ID
+	default:
+		ids, err := cli.ResolveMemberIDs(ctx, client, boardID, []string{member})
+		if err != nil {
+			return err
+		}
+		memberID = ids[0]
+	}
+
+	cards, err := fetchOverdueCards(ctx, client, boardID, memberID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if err := cli.Render(cfg, cards, overdueTable(cards)); err != nil {
+		return err
+	}
+	if len(cards) == 0 {
+		return nil
+	}
+	summary := fmt.Sprintf("%d overdue cards on board %s", len(cards), boardID)
+	if len(cards) == 1 {
+		summary = fmt.Sprintf("1 overdue card on board %s", boardID)
+	}
+	if !cfg.Structured() {
+		fmt.Printf("\n%s; the oldest was due %s.\n", summary, cli.DateOnly(cards[0].Due))
+	}
+	return &cli.CheckFailedError{Msg: summary}
+}
+
+// fetchOverdueCards returns the board's open cards that were due before
+// now and are not complete, of memberID when it is set, most overdue
+// first.
+func fetchOverdueCards(ctx context.Context, client *cli.Client, boardID, memberID string, now time.Time) ([]overdueCard, error) {
+	var cards []cli.ReportBoardCard
+	var lists []cli.TrelloList
+	var members []cli.Member
+	query := url.Values{}
+	query.Set("filter", "open")
+	query.Set("fields", "id,name,idList,due,dueComplete,idMembers,shortUrl,closed")
+	if err := client.GetAll(ctx,
+		cli.GetRequest{Path: "/1/boards/" + url.PathEscape(boardID) + "/cards", Query: query, Out: &cards},
+		cli.BoardListsRequest(boardID, &lists),
+		cli.BoardMembersRequest(boardID, &members),
+	); err != nil {
+		return nil, err
+	}
+	listNames := map[string]string{}
+	for _, l := range lists {
+		listNames[l.ID] = l.Name
+	}
+	usernames := map[string]string{}
+	for _, m := range members {
+		usernames[m.ID] = m.Username
+	}
+	overdue := []overdueCard{}
+	for _, c := range cards {
+		due, err := time.Parse(time.RFC3339, c.Due)
+		if err != nil || c.Closed || c.DueComplete || !due.Before(now) {
+			continue
+		}
+		if memberID != "" && !slices.Contains(c.IDMembers, memberID) {
+			continue
+		}
+		oc := overdueCard{ID: c.ID, Name: c.Name, List: cli.FirstNonEmpty(listNames[c.IDList], c.IDList), Due: c.Due, Days: int(now.Sub(due).Hours() / 24), Members: []string{}, URL: cli.FirstNonEmpty(c.ShortURL, c.URL)}
+		for _, id := range c.IDMembers {
+			if name := usernames[id]; name != "" {
+				oc.Members = append(oc.Members, "@"+name)
+			}
+		}
+		overdue = append(overdue, oc)
+	}
+	slices.SortStableFunc(overdue, func(a, b overdueCard) int { return strings.Compare(a.Due, b.Due) })
+	return overdue, nil
+}
+
+func overdueTable(cards []overdueCard) cli.Table {
+	t := cli.Table{Columns: []string{"ID", "NAME", "LIST", "DUE", "DAYS", "MEMBERS", "URL"}, Empty: "No overdue cards."}
+	for _, c := range cards {
+		t.Rows = append(t.Rows, []string{c.ID, c.Name, c.List, cli.DateOnly(c.Due), fmt.Sprint(c.Days), strings.Join(c.Members, ","), c.URL})
+	}
+	return t
+}
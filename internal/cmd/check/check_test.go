@@ -0,0 +1,64 @@
+package check
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// TestMain registers the command as main does, for the tests that run it
+// through the CLI.
+func TestMain(m *testing.M) {
+	cli.Register(Command)
+	os.Exit(m.Run())
+}
+
+func TestCheckOverdue(t *testing.T) {
+	clitest.WithStubRoutes(t, map[string]string{
+		"/1/boards/b2/members": `[]`,
+		"/1/boards/b1/cards": `[
+			{"id": "c1", "name": "Fix login, again", "idList": "l1", "due": "2026-03-01T12:00:00.000Z", "idMembers": ["m1"], "shortUrl": "https://trello.com/c/AbCd"},
+			{"id": "c2", "name": "Write \"release\" notes", "idList": "l1", "due": "2026-02-20T12:00:00.000Z", "idMembers": ["m1", "m2"], "shortUrl": "https://trello.com/c/EfGh"},
+			{"id": "c6", "name": "Ship it", "idList": "l2", "due": "2026-02-01T12:00:00.000Z", "dueComplete": true, "shortUrl": "https://trello.com/c/MnOp"},
+			{"id": "c7", "name": "Later", "idList": "l1", "due": "2999-01-01T12:00:00.000Z", "shortUrl": "https://trello.com/c/QrSt"}
+		]`,
+	})
+	stub := clitest.NewStub(t)
+	cfg, _, err := clitest.TestConfig(t, stub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := cli.NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+	cards, err := fetchOverdueCards(context.Background(), client, "b1", "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := (cli.TableRenderer{}).Render(&b, cards, overdueTable(cards)); err != nil {
+		t.Fatal(err)
+	}
+	clitest.CheckGolden(t, "check_overdue", b.String())
+	if mine, _ := fetchOverdueCards(context.Background(), client, "b1", "m2", now); len(mine) != 1 || mine[0].ID != "c2" {
+		t.Errorf("cards of m2 = %+v", mine)
+	}
+
+	got := clitest.RunCLI(t, stub, "check", "overdue", "--member", "@grace")
+	if !strings.Contains(got, "1 overdue card on board b1; the oldest was due 2026-02-20.") || !strings.Contains(got, "--- error\n1 overdue card on board b1") {
+		t.Errorf("check overdue --member @grace:\n%s", got)
+	}
+	if got := clitest.RunCLI(t, stub, "check", "overdue", "--member", "me"); !strings.Contains(got, "--- error\n2 overdue cards on board b1") {
+		t.Errorf("check overdue --member me:\n%s", got)
+	}
+	if got := clitest.RunCLI(t, stub, "check", "overdue", "b2"); got != "No overdue cards.\n" {
+		t.Errorf("check overdue b2:\n%s", got)
+	}
+}
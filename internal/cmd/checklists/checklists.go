@@ -0,0 +1,164 @@
+// Package checklists implements "trelli checklists": card checklist commands.
+package checklists
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:        "checklists",
+	Aliases:     []string{"checklist", "cl"},
+	Summary:     "Card checklist commands",
+	Description: "Manage card checklists and items.\nIdentifiers shown as [--flag] <value> may be passed positionally.",
+	Subcommands: []cli.Subcommand{
+		{Name: "list", Aliases: []string{"ls"}, Usage: []string{"list [--card] <cardId>"}, Flags: []cli.Flag{cli.CardFlag}},
+		{Name: "create", Usage: []string{"create [--card] <cardId> [--name] <checklistName>"}, Flags: []cli.Flag{cli.CardFlag,
+			{Name: "name", Arg: "text", Desc: "Checklist or item name"},
+		}},
+		{Name: "add-item", Usage: []string{"add-item [--checklist] <checklistId> [--name] <itemName> [--checked]"}, Flags: []cli.Flag{
+			{Name: "checklist", Arg: "id", Desc: "Checklist id"},
+			{Name: "name", Arg: "text", Desc: "Checklist or item name"},
+			{Name: "checked", Desc: "Create item as checked"},
+		}},
+		{Name: "set-item", Usage: []string{"set-item [--card] <cardId> [--item] <itemId> [--state] <complete|incomplete>"}, Flags: []cli.Flag{cli.CardFlag,
+			{Name: "item", Arg: "id", Desc: "Checklist item id"},
+			{Name: "state", Arg: "state", Desc: "complete|incomplete"},
+		}},
+	},
+	Options: []cli.Flag{cli.JSONOption},
+	Run:     runChecklists,
+}
+
+func runChecklists(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) == 0 {
+		cli.PrintCommandHelp("checklists")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("checklists")
+		return nil
+	case "list":
+		fs := flag.NewFlagSet("checklists list", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		var cardID string
+		fs.StringVar(&cardID, "card", "", "Card id")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("checklists")); err != nil {
+			return err
+		}
+		if err := cli.TakePositional(fs, &cardID); err != nil {
+			return err
+		}
+		if strings.TrimSpace(cardID) == "" {
+			return errors.New("checklists list requires --card")
+		}
+
+		checklists, err := fetchChecklists(ctx, client, cardID)
+		if err != nil {
+			return err
+		}
+		return cli.Render(cfg, checklists, cli.ChecklistsTable(checklists))
+
+	case "create":
+		fs := flag.NewFlagSet("checklists create", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		var cardID, name string
+		fs.StringVar(&cardID, "card", "", "Card id")
+		fs.StringVar(&name, "name", "", "Checklist name")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("checklists")); err != nil {
+			return err
+		}
+		if err := cli.TakePositional(fs, &cardID, &name); err != nil {
+			return err
+		}
+		if strings.TrimSpace(cardID) == "" || strings.TrimSpace(name) == "" {
+			return errors.New("checklists create requires --card and --name")
+		}
+
+		checklist, err := client.Checklists.Create(ctx, cardID, name)
+		if err != nil {
+			return err
+		}
+		cli.RecordUndo(cfg, cli.JournalEntry{Action: "checklists.create", Target: checklist.ID, Summary: "create checklist " + checklist.Name})
+		return cli.Render(cfg, checklist, cli.ChecklistsTable([]cli.Checklist{checklist}))
+
+	case "add-item":
+		fs := flag.NewFlagSet("checklists add-item", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		var checklistID, name string
+		var checked bool
+		fs.StringVar(&checklistID, "checklist", "", "Checklist id")
+		fs.StringVar(&name, "name", "", "Item name")
+		fs.BoolVar(&checked, "checked", false, "Create item as checked")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("checklists")); err != nil {
+			return err
+		}
+		if err := cli.TakePositional(fs, &checklistID, &name); err != nil {
+			return err
+		}
+		if strings.TrimSpace(checklistID) == "" || strings.TrimSpace(name) == "" {
+			return errors.New("checklists add-item requires --checklist and --name")
+		}
+
+		item, err := client.Checklists.AddItem(ctx, checklistID, name, checked)
+		if err != nil {
+			return err
+		}
+		cli.RecordUndo(cfg, cli.JournalEntry{Action: "checklists.add-item", Target: item.ID, Parent: checklistID, Summary: "add checklist item " + item.Name})
+		return cli.Render(cfg, item, checklistItemsTable([]cli.ChecklistItem{item}))
+
+	case "set-item":
+		fs := flag.NewFlagSet("checklists set-item", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		var cardID, itemID, state string
+		fs.StringVar(&cardID, "card", "", "Card id")
+		fs.StringVar(&itemID, "item", "", "Checklist item id")
+		fs.StringVar(&state, "state", "", "State: complete|incomplete")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("checklists")); err != nil {
+			return err
+		}
+		if err := cli.TakePositional(fs, &cardID, &itemID, &state); err != nil {
+			return err
+		}
+		state = strings.TrimSpace(strings.ToLower(state))
+		if strings.TrimSpace(cardID) == "" || strings.TrimSpace(itemID) == "" || state == "" {
+			return errors.New("checklists set-item requires --card, --item, and --state")
+		}
+		if state != "complete" && state != "incomplete" {
+			return errors.New("--state must be complete or incomplete")
+		}
+
+		updated, err := client.Checklists.SetItemState(ctx, cardID, itemID, state)
+		if err != nil {
+			return err
+		}
+		return cli.Render(cfg, updated, checklistItemsTable([]cli.ChecklistItem{updated}))
+	default:
+		return fmt.Errorf("unknown checklists subcommand %q", args[0])
+	}
+}
+
+func fetchChecklists(ctx context.Context, client *cli.Client, cardID string) ([]cli.Checklist, error) {
+	var checklists []cli.Checklist
+	if err := client.GetAll(ctx, cli.ChecklistsRequest(cardID, &checklists)); err != nil {
+		return nil, err
+	}
+	return checklists, nil
+}
+
+func checklistItemsTable(items []cli.ChecklistItem) cli.Table {
+	t := cli.Table{Columns: []string{"ITEM_ID", "STATE", "NAME"}, Empty: "No checklist items found."}
+	for _, item := range items {
+		t.Rows = append(t.Rows, []string{item.ID, item.State, item.Name})
+	}
+	return t
+}
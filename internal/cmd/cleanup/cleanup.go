@@ -0,0 +1,218 @@
+// Package cleanup implements "trelli cleanup": prune old archived cards and tidy up boards.
+package cleanup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+	"trelli/internal/cmd/backup"
+)
+
+var Command = cli.Command{
+	Name:    "cleanup",
+	Summary: "Prune old archived cards and tidy up boards",
+	Description: `archived lists the archived cards of a board whose last activity is
+older than --older-than (default 180d); archiving counts as activity, so
+this is at least how long they have been archived. --delete removes them
+permanently after writing a manifest: a JSON file with every deleted
+card as Trello returned it, with its checklists and attachments (not
+comments). Deleting asks for confirmation; --dry-run prints the requests
+and writes no manifest.
+
+wizard walks through a board's stale cards (no activity in --stale,
+default 30d), empty checklists, unused labels, and overdue cards, and
+asks what to do with each: archive it or comment on it, mentioning its
+members (stale), delete it (checklists and labels), or clear the due
+date, comment, or archive it (overdue). An upper-case answer applies the
+choice to the rest of the step; Enter skips, q quits. Each choice is
+carried out right away; --dry-run prints the requests instead.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "archived", Usage: []string{"archived [[--board] <boardIdOrShortLink>] [--older-than <age>] [--delete [--manifest <file>] [--yes|--force]]"}, Flags: []cli.Flag{
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "older-than", Arg: "age", Desc: "Minimum age of the last activity, e.g. 90d or 26w (default 180d)"},
+			{Name: "delete", Desc: "Delete the cards permanently instead of listing them"},
+			{Name: "manifest", Arg: "file", Desc: "Where to write the manifest (default trelli-archived-<board>-<time>.json)"},
+			{Name: "yes", Short: "y", Desc: "Skip the confirmation prompt (--delete)"},
+			{Name: "force", Desc: "Proceed without a prompt when stdin is not a terminal (--delete)"},
+		}},
+		{Name: "wizard", Usage: []string{"wizard [[--board] <boardIdOrShortLink>] [--stale <age>]"}, Flags: []cli.Flag{
+			{Name: "stale", Arg: "age", Desc: "Cards without activity for this long are stale, e.g. 14d or 8w (default 30d)"},
+		}},
+	},
+	Options: []cli.Flag{cli.JSONOption},
+	Run:     runCleanup,
+}
+
+// archivedManifest records the cards cleanup archived --delete removed.
+type archivedManifest struct {
+	Board     string            `json:"board"`
+	OlderThan string            `json:"olderThan"`
+	Deleted   time.Time         `json:"deleted"`
+	Cards     []json.RawMessage `json:"cards"`
+}
+
+// archivedCleanup is the result of cleanup archived --delete.
+type archivedCleanup struct {
+	Board    string   `json:"board"`
+	Deleted  []string `json:"deleted"`
+	Failed   []string `json:"failed,omitempty"`
+	Manifest string   `json:"manifest"`
+}
+
+func runCleanup(client *cli.Client, cfg cli.Config, args []string) error {
+	if len(args) == 0 {
+		cli.PrintCommandHelp("cleanup")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("cleanup")
+		return nil
+	case "archived":
+		return runCleanupArchived(cfg.Context, client, cfg, args[1:])
+	case "wizard":
+		return runCleanupWizard(cfg.Context, client, cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown cleanup subcommand %q", args[0])
+	}
+}
+
+func runCleanupArchived(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("cleanup archived", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boardID := cfg.BoardID
+	olderThan := "180d"
+	var manifest string
+	var del bool
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&olderThan, "older-than", olderThan, "Minimum age of the last activity")
+	fs.BoolVar(&del, "delete", false, "Delete the cards permanently")
+	fs.StringVar(&manifest, "manifest", "", "Manifest file")
+	cli.AddConfirmFlags(fs, &cfg)
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("cleanup")); err != nil {
+		return err
+	}
+	var positionalBoard string
+	if err := cli.TakePositional(fs, &positionalBoard); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(positionalBoard, boardID))
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+	age, err := cli.ParseAge(olderThan)
+	if err != nil {
+		return fmt.Errorf("invalid --older-than: %w", err)
+	}
+
+	now := time.Now().UTC()
+	cards, raw, err := fetchOldArchivedCards(ctx, client, boardID, now.Add(-age))
+	if err != nil {
+		return err
+	}
+	if !del {
+		if len(cards) > 0 && !cfg.Structured() {
+			slog.Info("Pass --delete to remove them permanently; a manifest is written first.")
+		}
+		return cli.Render(cfg, cli.NonNil(cards), archivedTable(cards))
+	}
+	if len(cards) == 0 {
+		return cli.Render(cfg, archivedCleanup{Board: boardID, Deleted: []string{}}, archivedTable(nil))
+	}
+
+	targets := make([]string, len(cards))
+	for i, c := range cards {
+		targets[i] = fmt.Sprintf("%s  %s (last activity %s)", c.ID, c.Name, cli.DateOnly(c.DateLastActivity))
+	}
+	if err := cli.Confirm(cfg, fmt.Sprintf("permanently delete %d archived cards", len(cards)), targets); err != nil {
+		return err
+	}
+	result := archivedCleanup{Board: boardID, Deleted: []string{}}
+	if !cfg.DryRun {
+		// The manifest is the only copy once the cards are gone, so it is
+		// written before anything is deleted.
+		result.Manifest = cli.FirstNonEmpty(manifest, fmt.Sprintf("trelli-archived-%s-%s.json", boardID, now.Format(backup.TimeLayout)))
+		if err := cli.WriteJSONFile(result.Manifest, archivedManifest{Board: boardID, OlderThan: olderThan, Deleted: now, Cards: raw}); err != nil {
+			return fmt.Errorf("writing the manifest: %w", err)
+		}
+	}
+	client.Progress.SetTask("Deleting cards", len(cards))
+	defer client.Progress.EndTask()
+	for _, c := range cards {
+		err := client.Cards.Delete(ctx, c.ID)
+		client.Progress.Advance(1)
+		switch {
+		case errors.Is(err, cli.ErrDryRun):
+		case err != nil:
+			slog.Warn(fmt.Sprintf("deleting card %s: %s", c.ID, err), "card", c.ID)
+			result.Failed = append(result.Failed, c.ID)
+		default:
+			result.Deleted = append(result.Deleted, c.ID)
+		}
+	}
+	if cfg.DryRun {
+		return cli.ErrDryRun
+	}
+	if cfg.Structured() {
+		if err := cli.Render(cfg, result); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("Deleted %d archived cards from board %s; manifest: %s\n", len(result.Deleted), boardID, result.Manifest)
+	}
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("could not delete %d of %d cards", len(result.Failed), len(cards))
+	}
+	return nil
+}
+
+// fetchOldArchivedCards returns the board's archived cards without
+// activity since cutoff, both decoded and as Trello returned them.
+func fetchOldArchivedCards(ctx context.Context, client *cli.Client, boardID string, cutoff time.Time) ([]cli.Card, []json.RawMessage, error) {
+	query := url.Values{}
+	query.Set("filter", "closed")
+	query.Set("checklists", "all")
+	query.Set("attachments", "true")
+	var all []json.RawMessage
+	if err := client.Do(ctx, http.MethodGet, "/1/boards/"+url.PathEscape(boardID)+"/cards", query, nil, &all); err != nil {
+		return nil, nil, err
+	}
+	var cards []cli.Card
+	var raw []json.RawMessage
+	for _, data := range all {
+		var c cli.Card
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, nil, err
+		}
+		if !c.Closed {
+			continue
+		}
+		// A card whose date cannot be read is kept rather than deleted.
+		t, err := time.Parse(time.RFC3339, c.DateLastActivity)
+		if err != nil || !t.Before(cutoff) {
+			continue
+		}
+		cards = append(cards, c)
+		raw = append(raw, data)
+	}
+	return cards, raw, nil
+}
+
+func archivedTable(cards []cli.Card) cli.Table {
+	t := cli.Table{Columns: []string{"ID", "NAME", "LIST", "LAST_ACTIVITY", "URL"}, Empty: "No archived cards that old."}
+	for _, c := range cards {
+		t.Rows = append(t.Rows, []string{c.ID, c.Name, c.IDList, cli.DateOnly(c.DateLastActivity), cli.FirstNonEmpty(c.ShortURL, c.URL)})
+	}
+	return t
+}
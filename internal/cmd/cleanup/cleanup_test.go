@@ -0,0 +1,83 @@
+package cleanup
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// TestMain registers the command as main does, for the tests that run it
+// through the CLI.
+func TestMain(m *testing.M) {
+	cli.Register(Command)
+	os.Exit(m.Run())
+}
+
+func TestCleanupArchived(t *testing.T) {
+	stub := clitest.NewStub(t)
+	clitest.CheckGolden(t, "cleanup_archived", clitest.RunCLI(t, stub, "cleanup", "archived", "b1", "--older-than", "30d"))
+	clitest.CheckGolden(t, "cleanup_archived_dry_run", clitest.RunCLI(t, stub, "--dry-run", "cleanup", "archived", "--older-than", "30d", "--delete"))
+	clitest.CheckGolden(t, "cleanup_archived_none", clitest.RunCLI(t, stub, "cleanup", "archived", "--older-than", "520w", "--delete"))
+	if got := clitest.RunCLI(t, stub, "cleanup", "archived", "--older-than", "30d", "--delete"); !strings.Contains(got, "needs confirmation") {
+		t.Errorf("delete without --yes: %s", got)
+	}
+
+	manifest := filepath.Join(t.TempDir(), "removed.json")
+	got := clitest.RunCLI(t, stub, "cleanup", "archived", "--older-than", "30d", "--delete", "--yes", "--manifest", manifest)
+	if !strings.Contains(got, "Deleted 1 archived cards from board b1") {
+		t.Errorf("delete: %s", got)
+	}
+	data, err := os.ReadFile(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m archivedManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Board != "b1" || len(m.Cards) != 1 || !strings.Contains(string(m.Cards[0]), `"id": "c3"`) {
+		t.Errorf("manifest =\n%s", data)
+	}
+}
+
+func TestCleanupWizard(t *testing.T) {
+	clitest.WithStubRoutes(t, map[string]string{
+		"/1/boards/b1/labels": `[{"id": "lb1", "name": "Bug", "color": "red"}, {"id": "lb2", "name": "Feature", "color": "green"}, {"id": "lb3", "name": "", "color": "purple"}]`,
+		"/1/boards/b1/checklists": `[
+			{"id": "k1", "name": "Steps", "idCard": "c1", "checkItems": [{"id": "i1", "name": "Reproduce", "state": "complete"}]},
+			{"id": "k2", "name": "Todo", "idCard": "c2", "checkItems": []},
+			{"id": "k3", "name": "Ideas", "idCard": "c3", "checkItems": []}
+		]`,
+	})
+	stub := clitest.NewStub(t)
+	cfg, _, err := clitest.TestConfig(t, stub, "--dry-run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := cli.NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	steps, err := wizardSteps(cfg.Context, client, cfg, "b1", now, "30d", 30*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Comment on c1, archive c2 (which drops its checklist), skip all
+	// labels, and give an unknown answer before clearing c1's due date.
+	var shown, requests strings.Builder
+	client.DryRunOut = &requests
+	results, err := runWizard(cfg.Context, bufio.NewReader(strings.NewReader("c\na\nS\nz\nd\n")), &shown, steps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clitest.CheckGolden(t, "cleanup_wizard", fmt.Sprintf("%s\n%s\n%s\n", shown.String(), requests.String(), wizardSummary(results)))
+}
@@ -0,0 +1,328 @@
+package cleanup
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+// wizardCard is a board card as cleanup wizard reviews it.
+type wizardCard struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	IDList           string   `json:"idList"`
+	Due              string   `json:"due"`
+	DueComplete      bool     `json:"dueComplete"`
+	DateLastActivity string   `json:"dateLastActivity"`
+	IDMembers        []string `json:"idMembers"`
+	IDLabels         []string `json:"idLabels"`
+	Closed           bool     `json:"closed"`
+}
+
+// wizardItem is one finding of cleanup wizard.
+type wizardItem struct {
+	ID   string
+	Text string
+	Card wizardCard
+}
+
+// wizardAction is a fix cleanup wizard offers, chosen by its key.
+type wizardAction struct {
+	Key  string
+	Name string
+	Do   func(ctx context.Context, item wizardItem) error
+}
+
+// wizardStep is a kind of finding with the fixes that apply to it.
+type wizardStep struct {
+	Kind    string
+	Title   string
+	Items   []wizardItem
+	Actions []wizardAction
+}
+
+// wizardResult is what cleanup wizard did with one finding.
+type wizardResult struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+func runCleanupWizard(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("cleanup wizard", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boardID := cfg.BoardID
+	stale := "30d"
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&stale, "stale", stale, "Age without activity that makes a card stale")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("cleanup")); err != nil {
+		return err
+	}
+	var positionalBoard string
+	if err := cli.TakePositional(fs, &positionalBoard); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(positionalBoard, boardID))
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+	age, err := cli.ParseAge(stale)
+	if err != nil {
+		return fmt.Errorf("invalid --stale: %w", err)
+	}
+	if !cli.CanPrompt(cfg) {
+		return errors.New("trelli cleanup wizard is interactive; use cleanup archived, cards archive, or labels merge in scripts")
+	}
+
+	steps, err := wizardSteps(ctx, client, cfg, boardID, time.Now().UTC(), stale, age)
+	if err != nil {
+		return err
+	}
+	results, err := runWizard(ctx, bufio.NewReader(os.Stdin), os.Stderr, steps)
+	if err != nil {
+		return err
+	}
+	if cfg.DryRun {
+		return cli.ErrDryRun
+	}
+	if cfg.Structured() {
+		return cli.Render(cfg, cli.NonNil(results))
+	}
+	fmt.Println(wizardSummary(results))
+	return nil
+}
+
+// wizardSteps reads the board and collects the stale cards, empty
+// checklists, unused labels, and overdue cards, each with its fixes.
+func wizardSteps(ctx context.Context, client *cli.Client, cfg cli.Config, boardID string, now time.Time, staleFlag string, stale time.Duration) ([]wizardStep, error) {
+	var cards []wizardCard
+	var lists []cli.TrelloList
+	var labels []cli.Label
+	var members []cli.Member
+	var checklists []cli.GrepChecklist
+	cardQuery := url.Values{}
+	cardQuery.Set("filter", "all")
+	cardQuery.Set("fields", "id,name,idList,due,dueComplete,dateLastActivity,idMembers,idLabels,closed")
+	checklistQuery := url.Values{}
+	checklistQuery.Set("fields", "id,name,idCard")
+	checklistQuery.Set("checkItem_fields", "name")
+	if err := client.GetAll(ctx,
+		cli.GetRequest{Path: "/1/boards/" + url.PathEscape(boardID) + "/cards", Query: cardQuery, Out: &cards},
+		cli.BoardListsRequest(boardID, &lists),
+		cli.BoardLabelsRequest(boardID, &labels),
+		cli.BoardMembersRequest(boardID, &members),
+		cli.GetRequest{Path: "/1/boards/" + url.PathEscape(boardID) + "/checklists", Query: checklistQuery, Out: &checklists},
+	); err != nil {
+		return nil, err
+	}
+
+	listNames := map[string]string{}
+	for _, l := range lists {
+		listNames[l.ID] = l.Name
+	}
+	usernames := map[string]string{}
+	for _, m := range members {
+		usernames[m.ID] = m.Username
+	}
+	open := map[string]wizardCard{}
+	used := map[string]bool{}
+	for _, c := range cards {
+		if !c.Closed {
+			open[c.ID] = c
+		}
+		for _, id := range c.IDLabels {
+			used[id] = true
+		}
+	}
+	cardText := func(c wizardCard, detail string) string {
+		return fmt.Sprintf("%s  %s  (%s)  %s", c.ID, c.Name, cli.FirstNonEmpty(listNames[c.IDList], c.IDList), detail)
+	}
+	// ping comments on the card, mentioning its members so Trello
+	// notifies them.
+	ping := func(text func(wizardCard) string) func(context.Context, wizardItem) error {
+		return func(ctx context.Context, item wizardItem) error {
+			var mentions []string
+			for _, id := range item.Card.IDMembers {
+				if name := usernames[id]; name != "" {
+					mentions = append(mentions, "@"+name)
+				}
+			}
+			_, err := cli.AddComment(ctx, client, cfg, item.Card.ID, strings.TrimSpace(strings.Join(mentions, " ")+" "+text(item.Card)))
+			return err
+		}
+	}
+	archive := wizardAction{Key: "a", Name: "archive", Do: func(ctx context.Context, item wizardItem) error {
+		card, err := client.Cards.Archive(ctx, item.Card.ID)
+		if err == nil {
+			cli.RecordUndo(cfg, cli.JournalEntry{Action: "cards.archive", Target: card.ID, Summary: "archive card " + item.Card.Name})
+		}
+		return err
+	}}
+
+	staleStep := wizardStep{Kind: "stale", Title: fmt.Sprintf("Stale cards (no activity in %s)", staleFlag), Actions: []wizardAction{
+		archive,
+		{Key: "c", Name: "comment", Do: ping(func(c wizardCard) string {
+			return fmt.Sprintf("Is this card still needed? It has had no activity since %s.", cli.DateOnly(c.DateLastActivity))
+		})},
+	}}
+	overdueStep := wizardStep{Kind: "overdue", Title: "Overdue cards", Actions: []wizardAction{
+		{Key: "d", Name: "clear due", Do: func(ctx context.Context, item wizardItem) error {
+			_, err := client.Cards.Update(ctx, item.Card.ID, url.Values{"due": {""}})
+			return err
+		}},
+		{Key: "c", Name: "comment", Do: ping(func(c wizardCard) string {
+			return fmt.Sprintf("This card was due %s. Is it done, or does it need a new due date?", cli.DateOnly(c.Due))
+		})},
+		archive,
+	}}
+	checklistStep := wizardStep{Kind: "checklist", Title: "Empty checklists", Actions: []wizardAction{
+		{Key: "x", Name: "delete", Do: func(ctx context.Context, item wizardItem) error {
+			return client.Checklists.Delete(ctx, item.ID)
+		}},
+	}}
+	labelStep := wizardStep{Kind: "label", Title: "Unused labels", Actions: []wizardAction{
+		{Key: "x", Name: "delete", Do: func(ctx context.Context, item wizardItem) error {
+			return client.Boards.DeleteLabel(ctx, item.ID)
+		}},
+	}}
+
+	cutoff := now.Add(-stale)
+	for _, c := range cards {
+		if c.Closed {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, c.DateLastActivity); err == nil && t.Before(cutoff) {
+			staleStep.Items = append(staleStep.Items, wizardItem{ID: c.ID, Card: c, Text: cardText(c, "last activity "+cli.DateOnly(c.DateLastActivity))})
+		}
+		if t, err := time.Parse(time.RFC3339, c.Due); err == nil && !c.DueComplete && t.Before(now) {
+			overdueStep.Items = append(overdueStep.Items, wizardItem{ID: c.ID, Card: c, Text: cardText(c, "due "+cli.DateOnly(c.Due))})
+		}
+	}
+	for _, cl := range checklists {
+		c, ok := open[cl.IDCard]
+		if ok && len(cl.CheckItems) == 0 {
+			checklistStep.Items = append(checklistStep.Items, wizardItem{ID: cl.ID, Card: c, Text: fmt.Sprintf("%s  %q on card %s  %s", cl.ID, cl.Name, c.ID, c.Name)})
+		}
+	}
+	for _, l := range labels {
+		if !used[l.ID] {
+			text := l.ID + "  " + cli.LabelName(l)
+			if l.Name != "" && l.Color != "" {
+				text += "  " + l.Color
+			}
+			labelStep.Items = append(labelStep.Items, wizardItem{ID: l.ID, Text: text})
+		}
+	}
+	return []wizardStep{staleStep, checklistStep, labelStep, overdueStep}, nil
+}
+
+// runWizard asks what to do with each finding and does it right away. An
+// upper-case key applies the action to the rest of the step as well.
+// Actions that fail are reported and the wizard goes on.
+func runWizard(ctx context.Context, in *bufio.Reader, out io.Writer, steps []wizardStep) ([]wizardResult, error) {
+	var results []wizardResult
+	// Cards archived in one step are not offered again in a later one.
+	archived := map[string]bool{}
+	for _, step := range steps {
+		items := slices.DeleteFunc(slices.Clone(step.Items), func(item wizardItem) bool { return archived[item.Card.ID] })
+		if len(items) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "\n%s: %d\n", step.Title, len(items))
+		choices := make([]string, 0, len(step.Actions)+2)
+		for _, a := range step.Actions {
+			choices = append(choices, fmt.Sprintf("%s (%s)", a.Name, a.Key))
+		}
+		choices = append(choices, "skip (s)", "quit (q)")
+		label := fmt.Sprintf("%s; upper case for all remaining [s]: ", strings.Join(choices, ", "))
+
+		var all *wizardAction
+		for i, item := range items {
+			fmt.Fprintf(out, "  [%d/%d] %s\n", i+1, len(items), item.Text)
+			action := all
+			for action == nil {
+				answer, err := cli.Prompt(in, "  "+label, false)
+				if errors.Is(err, io.EOF) {
+					answer, err = "q", nil
+				}
+				if err != nil {
+					return results, err
+				}
+				key := cli.FirstNonEmpty(answer, "s")
+				switch strings.ToLower(key) {
+				case "q":
+					return results, nil
+				case "s":
+					action = &wizardAction{Key: "s", Name: "skip"}
+				default:
+					if j := slices.IndexFunc(step.Actions, func(a wizardAction) bool { return a.Key == strings.ToLower(key) }); j >= 0 {
+						action = &step.Actions[j]
+					} else {
+						fmt.Fprintf(out, "  Unknown choice %q.\n", answer)
+						continue
+					}
+				}
+				if key != strings.ToLower(key) {
+					all = action
+				}
+			}
+			r := wizardResult{Kind: step.Kind, ID: item.ID, Action: action.Name}
+			if action.Do != nil {
+				err := action.Do(ctx, item)
+				switch {
+				case err == nil || errors.Is(err, cli.ErrDryRun):
+					if action.Name == "archive" {
+						archived[item.Card.ID] = true
+					}
+				case ctx.Err() != nil:
+					return results, err
+				default:
+					slog.Warn(fmt.Sprintf("%s %s: %s", action.Name, item.ID, err), "id", item.ID)
+					r.Error = err.Error()
+				}
+			}
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+// wizardSummary counts what the wizard did, e.g. "archive 2, skip 1".
+func wizardSummary(results []wizardResult) string {
+	var names []string
+	counts := map[string]int{}
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+			continue
+		}
+		if counts[r.Action] == 0 {
+			names = append(names, r.Action)
+		}
+		counts[r.Action]++
+	}
+	if len(names) == 0 && failed == 0 {
+		return "Nothing to clean up."
+	}
+	parts := make([]string, 0, len(names)+1)
+	for _, n := range names {
+		parts = append(parts, fmt.Sprintf("%s %d", n, counts[n]))
+	}
+	if failed > 0 {
+		parts = append(parts, fmt.Sprintf("failed %d", failed))
+	}
+	return "Done: " + strings.Join(parts, ", ") + "."
+}
@@ -0,0 +1,116 @@
+// Package comments implements "trelli comments": card comment commands.
+package comments
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:        "comments",
+	Aliases:     []string{"comment"},
+	Summary:     "Card comment commands",
+	Description: "Read or add comments on a card.\nlist --pretty renders each comment's Markdown, styled on a terminal unless NO_COLOR is set; --raw prints it as written.\nIdentifiers shown as [--flag] <value> may be passed positionally, e.g. trelli comments add <cardId> \"text\".",
+	Subcommands: []cli.Subcommand{
+		{Name: "list", Aliases: []string{"ls"}, Usage: []string{"list [--card] <cardId> [--limit <n> | --all] [--pretty [--raw]]"}, Flags: []cli.Flag{cli.CardFlag,
+			{Name: "all", Desc: "Return every comment, paging and streaming (list)"},
+			{Name: "limit", Arg: "n", Desc: "Number of comments to fetch (default 100)"},
+			{Name: "pretty", Desc: "Show each comment as a block with its Markdown rendered (list)"},
+			cli.RawMarkdownFlag,
+		}},
+		{Name: "add", Usage: []string{"add [--card] <cardId> [--text] <comment>"}, Flags: []cli.Flag{cli.CardFlag,
+			{Name: "text", Arg: "text", Desc: "Comment body"},
+		}},
+	},
+	Options: []cli.Flag{cli.JSONOption},
+	Run:     runComments,
+}
+
+func runComments(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) == 0 {
+		cli.PrintCommandHelp("comments")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("comments")
+		return nil
+	case "list":
+		fs := flag.NewFlagSet("comments list", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		var cardID string
+		limit := 100
+		var all, pretty, raw bool
+		fs.StringVar(&cardID, "card", "", "Card id")
+		fs.IntVar(&limit, "limit", limit, "Max comments to return")
+		fs.BoolVar(&all, "all", false, "Return every comment, paging through the history")
+		fs.BoolVar(&pretty, "pretty", false, "Render comments as Markdown blocks")
+		fs.BoolVar(&raw, "raw", false, "With --pretty, print comments as written")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("comments")); err != nil {
+			return err
+		}
+		if err := cli.TakePositional(fs, &cardID); err != nil {
+			return err
+		}
+		if strings.TrimSpace(cardID) == "" {
+			return errors.New("comments list requires --card")
+		}
+
+		show := func(actions []cli.CommentAction) error {
+			if pretty && !cfg.Structured() {
+				cli.PrintComments(os.Stdout, actions, cli.TerminalMarkdown(raw), cfg.Redact)
+				return nil
+			}
+			return cli.Render(cfg, actions, cli.CommentsTable(actions))
+		}
+		if all {
+			return cli.PrintAllComments(ctx, client, cfg, cardID, show)
+		}
+		actions, err := fetchComments(ctx, client, cardID, limit)
+		if err != nil {
+			return err
+		}
+		return show(actions)
+
+	case "add":
+		fs := flag.NewFlagSet("comments add", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		var cardID, text string
+		fs.StringVar(&cardID, "card", "", "Card id")
+		fs.StringVar(&text, "text", "", "Comment text")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("comments")); err != nil {
+			return err
+		}
+		if err := cli.TakePositional(fs, &cardID, &text); err != nil {
+			return err
+		}
+		if strings.TrimSpace(cardID) == "" || strings.TrimSpace(text) == "" {
+			return errors.New("comments add requires --card and --text")
+		}
+
+		created, err := cli.AddComment(ctx, client, cfg, cardID, text)
+		if err != nil {
+			return err
+		}
+		return cli.Render(cfg, created, cli.CommentsTable([]cli.CommentAction{created}))
+	default:
+		return fmt.Errorf("unknown comments subcommand %q", args[0])
+	}
+}
+
+func fetchComments(ctx context.Context, client *cli.Client, cardID string, limit int) ([]cli.CommentAction, error) {
+	var actions []cli.CommentAction
+	if err := client.GetAll(ctx, cli.CommentsRequest(cardID, limit, &actions)); err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
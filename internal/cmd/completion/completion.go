@@ -0,0 +1,335 @@
+// Package completion implements "trelli completion": print shell completion script.
+package completion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "completion",
+	Summary: "Print shell completion script",
+	Description: `Print a shell completion script covering commands, subcommands, and flags.
+Values for --board, --list-name, and --labels complete against board
+aliases and live Trello data (from the name cache) when credentials
+are configured.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "bash", Usage: []string{"bash|zsh|fish|powershell"}},
+		{Name: "zsh"},
+		{Name: "fish"},
+		{Name: "powershell"},
+	},
+	Sections: []cli.HelpSection{{Title: "Setup", Body: `bash:        source <(trelli completion bash)
+zsh:         trelli completion zsh > "${fpath[1]}/_trelli"
+fish:        trelli completion fish > ~/.config/fish/completions/trelli.fish
+powershell:  trelli completion powershell | Out-String | Invoke-Expression`}},
+	Run:  func(_ *cli.Client, _ cli.Config, args []string) error { return runCompletion(args) },
+	Mode: cli.ModeOffline,
+}
+
+// completeArgs returns candidates for cur given the words before it
+// (excluding the program name). Flag values are completed by values, which
+// may be nil; candidates may carry a tab-separated description.
+func completeArgs(words []string, cur string, values func(flag string, seen map[string]string, cur string) []string) []string {
+	var command, sub, pending string
+	flags := cli.GlobalFlags
+	seen := map[string]string{}
+	for _, w := range words {
+		if pending != "" {
+			seen[pending] = w
+			pending = ""
+			continue
+		}
+		if strings.HasPrefix(w, "-") {
+			name, value, hasValue := strings.Cut(strings.TrimLeft(w, "-"), "=")
+			if f, ok := cli.FindFlagSpec(flags, name); ok && f.TakesValue() {
+				if hasValue {
+					seen[name] = value
+				} else {
+					pending = name
+				}
+			}
+			continue
+		}
+		switch {
+		case command == "":
+			command = w
+		case sub == "":
+			sub = w
+			if spec, ok := cli.FindCommand(command); ok {
+				if s, ok := spec.Subcommand(sub); ok {
+					flags = append(append([]cli.Flag{}, s.Flags...), cli.GlobalFlags...)
+				}
+			}
+		}
+	}
+	if pending != "" {
+		if values == nil {
+			return nil
+		}
+		return values(pending, seen, cur)
+	}
+
+	var candidates []string
+	switch {
+	case strings.HasPrefix(cur, "-"):
+		for _, f := range flags {
+			candidates = append(candidates, "--"+f.Name)
+		}
+	case command == "":
+		for _, c := range cli.Commands() {
+			candidates = append(candidates, c.Name)
+		}
+	case command == "help" && sub == "":
+		for _, c := range cli.Commands() {
+			if len(c.Subcommands) > 0 {
+				candidates = append(candidates, c.Name)
+			}
+		}
+	case sub == "":
+		if spec, ok := cli.FindCommand(command); ok {
+			for _, s := range spec.Subcommands {
+				candidates = append(candidates, s.Name)
+			}
+		}
+	}
+	return filterPrefix(candidates, cur)
+}
+
+// filterPrefix keeps candidates whose value (before any tab-separated
+// description) starts with prefix.
+func filterPrefix(candidates []string, prefix string) []string {
+	out := make([]string, 0, len(candidates))
+	seen := map[string]bool{}
+	for _, c := range candidates {
+		value, _, _ := strings.Cut(c, "\t")
+		if strings.HasPrefix(strings.ToLower(value), strings.ToLower(prefix)) && !seen[value] {
+			seen[value] = true
+			out = append(out, c)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Complete implements the hidden `trelli __complete --cur=<word> -- <words...>`
+// callback used by the generated completion scripts.
+func Complete(cfg cli.Config, args []string) error {
+	var cur string
+	var words []string
+	for i, a := range args {
+		if strings.HasPrefix(a, "--cur=") {
+			cur = strings.TrimPrefix(a, "--cur=")
+			continue
+		}
+		if a == "--" {
+			words = args[i+1:]
+			break
+		}
+	}
+	values := func(flag string, seen map[string]string, cur string) []string {
+		return completeFlagValue(cfg, flag, seen, cur)
+	}
+	for _, c := range completeArgs(words, cur, values) {
+		fmt.Println(c)
+	}
+	return nil
+}
+
+// completeFlagValue completes --output and --log-format values, and --board, --list-name,
+// --labels/--label, and --members values from config aliases and the Trello API. Failures yield
+// no candidates.
+func completeFlagValue(cfg cli.Config, flag string, seen map[string]string, cur string) []string {
+	var candidates []string
+	switch flag {
+	case "output", "o":
+		candidates = []string{cli.OutputTable, cli.OutputJSON, cli.OutputCSV, cli.OutputTemplate}
+	case "log-format":
+		candidates = []string{cli.LogFormatPlain, cli.LogFormatText, cli.LogFormatJSON}
+	case "board":
+		if m, ok := cfg.File.Lookup("boards.aliases"); ok {
+			if aliases, ok := m.(map[string]any); ok {
+				for alias, id := range aliases {
+					candidates = append(candidates, alias+"\talias for "+cli.FormatConfigValue(id))
+				}
+			}
+		}
+		boards, _ := cachedCompletion(cfg, "boards", cli.FetchBoards)
+		for _, b := range boards {
+			candidates = append(candidates, cli.FirstNonEmpty(b.ShortLink, b.ID)+"\t"+b.Name)
+		}
+	case "list-name":
+		boardID := completionBoard(cfg, seen)
+		if boardID == "" {
+			break
+		}
+		lists, _ := cachedCompletion(cfg, "lists:"+boardID, func(ctx context.Context, client *cli.Client) ([]cli.TrelloList, error) {
+			return cli.FetchBoardLists(ctx, client, boardID)
+		})
+		for _, l := range lists {
+			if !l.Closed {
+				candidates = append(candidates, l.Name)
+			}
+		}
+	case "labels", "label":
+		boardID := completionBoard(cfg, seen)
+		if boardID == "" {
+			break
+		}
+		labels, _ := cachedCompletion(cfg, "labels:"+boardID, func(ctx context.Context, client *cli.Client) ([]cli.Label, error) {
+			return cli.FetchBoardLabels(ctx, client, boardID)
+		})
+		// Complete the last element of a comma-separated list.
+		done := ""
+		if i := strings.LastIndex(cur, ","); i >= 0 {
+			done, cur = cur[:i+1], cur[i+1:]
+		}
+		for _, l := range labelCompletionValues(labels) {
+			candidates = append(candidates, done+l)
+		}
+		cur = done + cur
+	case "members":
+		boardID := completionBoard(cfg, seen)
+		if boardID == "" {
+			break
+		}
+		members, _ := cachedCompletion(cfg, "members:"+boardID, func(ctx context.Context, client *cli.Client) ([]cli.Member, error) {
+			return cli.FetchBoardMembers(ctx, client, boardID)
+		})
+		done := ""
+		if i := strings.LastIndex(cur, ","); i >= 0 {
+			done, cur = cur[:i+1], cur[i+1:]
+		}
+		for _, m := range members {
+			candidates = append(candidates, done+"@"+m.Username+"\t"+m.FullName)
+		}
+		cur = done + cur
+	}
+	return filterPrefix(candidates, cur)
+}
+
+// labelCompletionValues offers labels by name, or by color when unnamed,
+// as the label resolver accepts them. A label whose name holds a comma,
+// which separates labels, or that another label shares is offered by id.
+func labelCompletionValues(labels []cli.Label) []string {
+	count := map[string]int{}
+	for _, l := range labels {
+		count[strings.ToLower(cli.FirstNonEmpty(l.Name, l.Color))]++
+	}
+	values := make([]string, 0, len(labels))
+	for _, l := range labels {
+		value := cli.FirstNonEmpty(l.Name, l.Color)
+		if value == "" || strings.Contains(value, ",") || count[strings.ToLower(value)] > 1 {
+			value = l.ID
+		}
+		values = append(values, value+"\t"+strings.TrimSpace(l.Name+" ("+l.Color+")"))
+	}
+	return values
+}
+
+// completionBoard returns the board whose lists, labels, or members are
+// offered, or "" when there is none to ask.
+func completionBoard(cfg cli.Config, seen map[string]string) string {
+	if b := strings.TrimSpace(seen["board"]); b != "" {
+		return cfg.File.ResolveBoardAlias(b)
+	}
+	return cfg.BoardID
+}
+
+// cachedCompletion returns the cached listing for key, connecting only when
+// the name cache has no fresh copy so repeated tab presses stay fast.
+func cachedCompletion[T any](cfg cli.Config, key string, fetch func(context.Context, *cli.Client) ([]T, error)) ([]T, error) {
+	var items []T
+	if cli.NewNameCache(cfg).Get(key, &items) {
+		return items, nil
+	}
+	cfg.NoProgress = true
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// A slow network must not hang the shell.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return fetch(ctx, client)
+}
+
+func runCompletion(args []string) error {
+	if len(args) == 0 {
+		cli.PrintCommandHelp("completion")
+		return nil
+	}
+	if args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		cli.PrintCommandHelp("completion")
+		return nil
+	}
+	if len(args) > 1 {
+		return errors.New("completion takes exactly one shell name")
+	}
+	switch args[0] {
+	case "bash":
+		fmt.Print(bashCompletion)
+	case "zsh":
+		fmt.Print(zshCompletion)
+	case "fish":
+		fmt.Print(fishCompletion)
+	case "powershell":
+		fmt.Print(powershellCompletion)
+	default:
+		return fmt.Errorf("unsupported shell %q (want bash, zsh, fish, or powershell)", args[0])
+	}
+	return nil
+}
+
+const bashCompletion = `# bash completion for trelli
+_trelli() {
+  local cur="${COMP_WORDS[COMP_CWORD]}" line
+  COMPREPLY=()
+  while IFS= read -r line; do
+    line="${line%%$'\t'*}"
+    COMPREPLY+=("${line// /\\ }")
+  done < <(trelli __complete "--cur=${cur}" -- "${COMP_WORDS[@]:1:COMP_CWORD-1}" 2>/dev/null)
+}
+complete -o default -F _trelli trelli
+`
+
+const zshCompletion = `#compdef trelli
+_trelli() {
+  local -a completions
+  local line
+  for line in "${(@f)$(trelli __complete "--cur=${words[CURRENT]}" -- "${(@)words[2,CURRENT-1]}" 2>/dev/null)}"; do
+    [[ -z "$line" ]] && continue
+    if [[ "$line" == *$'\t'* ]]; then
+      completions+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+    else
+      completions+=("${line//:/\\:}")
+    fi
+  done
+  _describe 'trelli' completions
+}
+compdef _trelli trelli
+`
+
+const fishCompletion = `# fish completion for trelli
+complete -c trelli -f -a '(trelli __complete "--cur="(commandline -ct) -- (commandline -opc)[2..-1] 2>/dev/null)'
+`
+
+const powershellCompletion = `# PowerShell completion for trelli
+Register-ArgumentCompleter -Native -CommandName trelli -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $words = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+    if ($wordToComplete -ne '' -and $words.Count -gt 0) { $words = @($words | Select-Object -SkipLast 1) }
+    trelli __complete "--cur=$wordToComplete" -- @words 2>$null | ForEach-Object {
+        $value, $desc = $_ -split "` + "`" + `t", 2
+        if (-not $desc) { $desc = $value }
+        $text = if ($value -match '\s') { "'" + ($value -replace "'", "''") + "'" } else { $value }
+        [System.Management.Automation.CompletionResult]::new($text, $value, 'ParameterValue', $desc)
+    }
+}
+`
@@ -0,0 +1,64 @@
+package completion
+
+import (
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+	"trelli/internal/cmd/cards"
+)
+
+// TestMain registers the cards command, whose flags the tests complete,
+// as main does.
+func TestMain(m *testing.M) {
+	cli.Register(cards.Command)
+	os.Exit(m.Run())
+}
+
+func TestCompleteLabelNames(t *testing.T) {
+	stub := clitest.NewStub(t)
+	cfg, _, err := clitest.TestConfig(t, stub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := func(flag string, seen map[string]string, cur string) []string {
+		return completeFlagValue(cfg, flag, seen, cur)
+	}
+	for _, tc := range []struct {
+		words []string
+		cur   string
+		want  []string
+	}{
+		{[]string{"cards", "label", "add", "c1", "--label"}, "bu", []string{"Bug\tBug (red)"}},
+		{[]string{"cards", "create", "--board", "b1", "--labels"}, "Bug,fe", []string{"Bug,Feature\tFeature (green)"}},
+		{[]string{"cards", "create", "--labels"}, "", []string{"Bug\tBug (red)", "Feature\tFeature (green)"}},
+	} {
+		if got := completeArgs(tc.words, tc.cur, values); !slices.Equal(got, tc.want) {
+			t.Errorf("complete %v %q = %q, want %q", tc.words, tc.cur, got, tc.want)
+		}
+	}
+
+	got := labelCompletionValues([]cli.Label{
+		{ID: "lb1", Name: "In Progress", Color: "blue"},
+		{ID: "lb2", Color: "purple"},
+		{ID: "lb3", Name: "P1, urgent", Color: "red"},
+		{ID: "lb4", Name: "Bug", Color: "red"},
+		{ID: "lb5", Name: "bug", Color: "orange"},
+	})
+	want := []string{"In Progress\tIn Progress (blue)", "purple\t(purple)", "lb3\tP1, urgent (red)", "lb4\tBug (red)", "lb5\tbug (orange)"}
+	if !slices.Equal(got, want) {
+		t.Errorf("labelCompletionValues = %q, want %q", got, want)
+	}
+
+	if bash, err := exec.LookPath("bash"); err == nil {
+		cmd := exec.Command(bash, "-n")
+		cmd.Stdin = strings.NewReader(bashCompletion)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("bash completion script: %v\n%s", err, out)
+		}
+	}
+}
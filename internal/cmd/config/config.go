@@ -0,0 +1,261 @@
+// Package config implements "trelli config": manage the config file.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "config",
+	Summary: "Manage the config file",
+	Description: `Manage the trelli config file (JSON). Location: TRELLI_CONFIG or
+<user config dir>/trelli/config.json. Flags and environment variables
+take precedence over config values.
+
+Keys containing <name> take any name, e.g. profiles.work.token.
+Board aliases (boards.aliases.<name>) are accepted wherever --board is.
+<command>.<subcommand>.<flag> keys set default flag values, e.g.
+cards.list.limit 500; flags given on the command line still win.
+config edit opens a copy in $VISUAL/$EDITOR and saves it only if it is
+valid JSON with known keys.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "get", Usage: []string{"get <key>"}},
+		{Name: "set", Usage: []string{"set <key> <value>"}},
+		{Name: "unset", Usage: []string{"unset <key>"}},
+		{Name: "list", Aliases: []string{"ls"}, Usage: []string{"list"}},
+		{Name: "edit", Usage: []string{"edit"}},
+		{Name: "path", Usage: []string{"path"}},
+		{Name: "alias", Usage: []string{
+			"alias board add <alias> <boardId>",
+			"alias board remove <alias>",
+			"alias board list",
+		}},
+	},
+	Sections: []cli.HelpSection{{Title: "Keys", Body: cli.ConfigKeysHelp()}},
+	Options:  []cli.Flag{{Name: "json", Desc: "Output raw JSON (get, list)"}},
+	Run:      cli.Local(runConfig),
+	Mode:     cli.ModeOffline,
+}
+
+func runConfig(cfg cli.Config, args []string) error {
+	if len(args) == 0 {
+		cli.PrintCommandHelp("config")
+		return nil
+	}
+	if args[0] != "path" && args[0] != "edit" && cfg.ConfigErr != nil {
+		return cfg.ConfigErr
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("config")
+		return nil
+	case "path":
+		fmt.Println(cfg.ConfigPath)
+		return nil
+	case "get":
+		fs := flag.NewFlagSet("config get", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("config")); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New("config get requires <key>")
+		}
+		key := fs.Arg(0)
+		if _, ok := cli.LookupConfigKey(key); !ok {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		v, ok := cfg.File.Get(key)
+		if !ok {
+			return fmt.Errorf("config key %q is not set", key)
+		}
+		if cfg.Structured() {
+			return cli.Render(cfg, v)
+		}
+		fmt.Println(cli.FormatConfigValue(v))
+		return nil
+
+	case "set":
+		fs := flag.NewFlagSet("config set", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("config")); err != nil {
+			return err
+		}
+		if fs.NArg() != 2 {
+			return errors.New("config set requires <key> <value>")
+		}
+		key := fs.Arg(0)
+		k, ok := cli.LookupConfigKey(key)
+		if !ok {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		v, err := cli.CoerceConfigValue(k, fs.Arg(1))
+		if err != nil {
+			return fmt.Errorf("config key %q: %w", key, err)
+		}
+		cfg.File.Set(key, v)
+		return cli.SaveConfigFile(cfg.ConfigPath, cfg.File)
+
+	case "unset":
+		fs := flag.NewFlagSet("config unset", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("config")); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New("config unset requires <key>")
+		}
+		if !cfg.File.Unset(fs.Arg(0)) {
+			return fmt.Errorf("config key %q is not set", fs.Arg(0))
+		}
+		return cli.SaveConfigFile(cfg.ConfigPath, cfg.File)
+
+	case "list":
+		fs := flag.NewFlagSet("config list", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("config")); err != nil {
+			return err
+		}
+		values := cfg.File.Flatten()
+		return cli.Render(cfg, values, configTable(values))
+
+	case "alias":
+		return runConfigAlias(cfg, args[1:])
+
+	case "edit":
+		fs := flag.NewFlagSet("config edit", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("config")); err != nil {
+			return err
+		}
+		return editConfigFile(cfg.ConfigPath)
+	default:
+		return fmt.Errorf("unknown config subcommand %q", args[0])
+	}
+}
+
+func runConfigAlias(cfg cli.Config, args []string) error {
+	if len(args) < 2 || args[0] != "board" {
+		return errors.New("usage: trelli config alias board add <alias> <boardId> | remove <alias> | list")
+	}
+	switch args[1] {
+	case "add":
+		if len(args) != 4 {
+			return errors.New("config alias board add requires <alias> <boardId>")
+		}
+		alias, boardID := strings.TrimSpace(args[2]), strings.TrimSpace(args[3])
+		if alias == "" || strings.ContainsAny(alias, ". ") {
+			return fmt.Errorf("invalid alias %q: must be non-empty without dots or spaces", alias)
+		}
+		if boardID == "" {
+			return errors.New("config alias board add requires a board id")
+		}
+		cfg.File.Set("boards.aliases."+alias, boardID)
+		return cli.SaveConfigFile(cfg.ConfigPath, cfg.File)
+	case "remove":
+		if len(args) != 3 {
+			return errors.New("config alias board remove requires <alias>")
+		}
+		if !cfg.File.Unset("boards.aliases." + args[2]) {
+			return fmt.Errorf("board alias %q is not defined", args[2])
+		}
+		return cli.SaveConfigFile(cfg.ConfigPath, cfg.File)
+	case "list":
+		aliases := map[string]any{}
+		if m, ok := cfg.File.Lookup("boards.aliases"); ok {
+			if mm, ok := m.(map[string]any); ok {
+				aliases = mm
+			}
+		}
+		return cli.Render(cfg, aliases, configTable(aliases))
+	default:
+		return fmt.Errorf("unknown config alias board subcommand %q", args[1])
+	}
+}
+
+// editConfigFile opens a copy of the config in $VISUAL/$EDITOR and only
+// replaces the real file once the edited copy validates.
+func editConfigFile(path string) error {
+	original, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if len(bytes.TrimSpace(original)) == 0 {
+		original = []byte("{}\n")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	draft, err := os.CreateTemp(filepath.Dir(path), "config-edit-*.json")
+	if err != nil {
+		return err
+	}
+	draftPath := draft.Name()
+	if _, err := draft.Write(original); err != nil {
+		draft.Close()
+		return err
+	}
+	if err := draft.Close(); err != nil {
+		return err
+	}
+
+	if err := runEditor(draftPath); err != nil {
+		return err
+	}
+	edited, err := os.ReadFile(draftPath)
+	if err != nil {
+		return err
+	}
+	fc, err := cli.ParseConfigFile(edited)
+	if err != nil {
+		return fmt.Errorf("config not saved: %w (your edits are kept in %s)", err, draftPath)
+	}
+	os.Remove(draftPath)
+	return cli.SaveConfigFile(path, fc)
+}
+
+func runEditor(path string) error {
+	editor := cli.FirstNonEmpty(os.Getenv("VISUAL"), os.Getenv("EDITOR"))
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return nil
+}
+
+func configTable(values map[string]any) cli.Table {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	t := cli.Table{Columns: []string{"KEY", "VALUE"}, Empty: "No config values set."}
+	for _, k := range keys {
+		t.Rows = append(t.Rows, []string{k, cli.FormatConfigValue(values[k])})
+	}
+	return t
+}
@@ -0,0 +1,209 @@
+// Package docs implements "trelli docs": generate man pages and markdown reference.
+package docs
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:        "docs",
+	Summary:     "Generate man pages and markdown reference",
+	Description: "Generate reference documentation from the command definitions.",
+	Subcommands: []cli.Subcommand{
+		{Name: "man", Usage: []string{"man [--out <dir>]"}, Flags: []cli.Flag{
+			{Name: "out", Arg: "dir", Desc: "Output directory (default: current directory)"},
+		}},
+		{Name: "markdown", Usage: []string{"markdown [--out <dir>]"}, Flags: []cli.Flag{
+			{Name: "out", Arg: "dir", Desc: "Output directory (default: current directory)"},
+		}},
+	},
+	Run:  func(_ *cli.Client, _ cli.Config, args []string) error { return runDocs(args) },
+	Mode: cli.ModeOffline,
+}
+
+func runDocs(args []string) error {
+	if len(args) == 0 {
+		cli.PrintCommandHelp("docs")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("docs")
+		return nil
+	case "man", "markdown":
+		fs := flag.NewFlagSet("docs "+args[0], flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		out := "."
+		fs.StringVar(&out, "out", out, "Output directory")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("docs")); err != nil {
+			return err
+		}
+		if strings.TrimSpace(out) == "" {
+			return errors.New("--out must not be empty")
+		}
+		if err := os.MkdirAll(out, 0o755); err != nil {
+			return err
+		}
+		render, ext := writeMarkdownPage, ".md"
+		if args[0] == "man" {
+			render, ext = writeManPage, ".1"
+		}
+		files := []string{}
+		pages := append([]cli.Command{{Name: ""}}, documentedCommands()...)
+		for _, c := range pages {
+			name := "trelli"
+			if c.Name != "" {
+				name += "-" + c.Name
+			}
+			var buf bytes.Buffer
+			render(&buf, c)
+			p := filepath.Join(out, name+ext)
+			if err := os.WriteFile(p, buf.Bytes(), 0o644); err != nil {
+				return err
+			}
+			files = append(files, p)
+		}
+		for _, f := range files {
+			fmt.Println(f)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown docs subcommand %q", args[0])
+	}
+}
+
+// documentedCommands returns the commands that get their own page.
+func documentedCommands() []cli.Command {
+	var out []cli.Command
+	for _, c := range cli.Commands() {
+		if c.Documented() {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// roff escapes text for use in a man page.
+func roff(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, ".") || strings.HasPrefix(l, "'") {
+			lines[i] = `\&` + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func writeManFlags(w io.Writer, flags []cli.Flag) {
+	for _, f := range flags {
+		label := `\fB` + roff(strings.TrimSuffix(f.Label(), " <"+f.Arg+">")) + `\fR`
+		if f.Arg != "" {
+			label += ` \fI` + roff(f.Arg) + `\fR`
+		}
+		fmt.Fprintf(w, ".TP\n%s\n%s\n", label, roff(f.Desc))
+	}
+}
+
+// writeManPage renders c as a section 1 man page; the zero Name renders the
+// top-level trelli(1) page.
+func writeManPage(w io.Writer, c cli.Command) {
+	if c.Name == "" {
+		fmt.Fprintf(w, ".TH TRELLI 1 \"\" \"trelli %s\" \"trelli manual\"\n", roff(cli.Version))
+		fmt.Fprint(w, ".SH NAME\ntrelli \\- efficient Trello CLI\n")
+		fmt.Fprint(w, ".SH SYNOPSIS\n.nf\ntrelli [global options] <command> <subcommand> [options]\ntrelli help [command]\ntrelli version\n.fi\n")
+		fmt.Fprint(w, ".SH GLOBAL OPTIONS\n")
+		writeManFlags(w, cli.GlobalFlags)
+		fmt.Fprint(w, ".SH COMMANDS\n")
+		for _, cmd := range cli.Commands() {
+			fmt.Fprintf(w, ".TP\n\\fB%s\\fR\n%s\n", roff(cmd.Name), roff(cmd.Summary))
+		}
+		fmt.Fprint(w, ".SH EXAMPLES\n.nf\n")
+		for _, e := range cli.RootExamples {
+			fmt.Fprintln(w, roff(e))
+		}
+		fmt.Fprint(w, ".fi\n.SH SEE ALSO\n")
+		var refs []string
+		for _, cmd := range documentedCommands() {
+			refs = append(refs, `\fBtrelli\-`+roff(cmd.Name)+`\fR(1)`)
+		}
+		fmt.Fprintln(w, strings.Join(refs, ",\n"))
+		return
+	}
+
+	fmt.Fprintf(w, ".TH TRELLI-%s 1 \"\" \"trelli %s\" \"trelli manual\"\n", strings.ToUpper(roff(c.Name)), roff(cli.Version))
+	fmt.Fprintf(w, ".SH NAME\ntrelli\\-%s \\- %s\n", roff(c.Name), roff(c.Summary))
+	fmt.Fprint(w, ".SH SYNOPSIS\n.nf\n")
+	for _, l := range c.UsageLines() {
+		fmt.Fprintln(w, roff(l))
+	}
+	fmt.Fprint(w, ".fi\n")
+	if c.Description != "" {
+		fmt.Fprintf(w, ".SH DESCRIPTION\n%s\n", roff(c.Description))
+	}
+	for _, s := range c.Sections {
+		fmt.Fprintf(w, ".SH %s\n.nf\n%s\n.fi\n", strings.ToUpper(roff(s.Title)), roff(s.Body))
+	}
+	if opts := c.Flags(); len(opts) > 0 {
+		fmt.Fprint(w, ".SH OPTIONS\n")
+		writeManFlags(w, opts)
+	}
+	fmt.Fprint(w, ".SH SEE ALSO\n\\fBtrelli\\fR(1)\n")
+}
+
+func writeMarkdownFlags(w io.Writer, flags []cli.Flag) {
+	for _, f := range flags {
+		fmt.Fprintf(w, "- `%s`: %s\n", f.Label(), f.Desc)
+	}
+}
+
+// writeMarkdownPage renders c as a markdown reference page; the zero Name
+// renders the index page.
+func writeMarkdownPage(w io.Writer, c cli.Command) {
+	if c.Name == "" {
+		fmt.Fprint(w, "# trelli\n\nEfficient Trello CLI.\n\n## Usage\n\n```\ntrelli [global options] <command> <subcommand> [options]\ntrelli help [command]\ntrelli version\n```\n\n## Global Options\n\n")
+		writeMarkdownFlags(w, cli.GlobalFlags)
+		fmt.Fprint(w, "\n## Commands\n\n")
+		for _, cmd := range cli.Commands() {
+			if cmd.Documented() {
+				fmt.Fprintf(w, "- [`%s`](trelli-%s.md): %s\n", cmd.Name, cmd.Name, cmd.Summary)
+			} else {
+				fmt.Fprintf(w, "- `%s`: %s\n", cmd.Name, cmd.Summary)
+			}
+		}
+		fmt.Fprint(w, "\n## Examples\n\n```bash\n")
+		for _, e := range cli.RootExamples {
+			fmt.Fprintln(w, e)
+		}
+		fmt.Fprint(w, "```\n")
+		return
+	}
+
+	fmt.Fprintf(w, "# trelli %s\n\n%s.\n\n## Usage\n\n```\n", c.Name, c.Summary)
+	for _, l := range c.UsageLines() {
+		fmt.Fprintln(w, l)
+	}
+	fmt.Fprint(w, "```\n")
+	if c.Description != "" {
+		fmt.Fprintf(w, "\n## Description\n\n%s\n", strings.NewReplacer("<", "&lt;", ">", "&gt;").Replace(c.Description))
+	}
+	for _, s := range c.Sections {
+		fmt.Fprintf(w, "\n## %s\n\n```\n%s\n```\n", s.Title, s.Body)
+	}
+	if opts := c.Flags(); len(opts) > 0 {
+		fmt.Fprint(w, "\n## Options\n\n")
+		writeMarkdownFlags(w, opts)
+	}
+	fmt.Fprint(w, "\nSee also: [trelli](trelli.md)\n")
+}
@@ -0,0 +1,218 @@
+// Package exec implements "trelli exec": run many commands from a script in one process.
+package exec
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "exec",
+	Summary: "Run many commands from a script in one process",
+	Description: `Run the commands in a script, one per line, sharing one client: the
+HTTP connections, caches, and rate limiter carry over between commands,
+which is much faster than starting trelli once per command. Lines hold a
+command and its arguments as typed after "trelli" (a leading "trelli" is
+optional), with shell-style quoting; blank lines and lines starting with
+# are skipped. Global options apply to every command and go before exec.
+
+Each command is logged as ok, or as an error when it fails, like other
+diagnostics (--quiet keeps only the failures; one JSON object per command
+with --json); command output goes to stdout as usual. exec fails if any
+command failed.
+
+A script read from stdin leaves no way to answer confirmation prompts, so
+commands that ask first, such as cards archive, fail unless --yes or
+--force is given, before exec or on the line. Interactive commands such as
+cleanup wizard cannot run then.`,
+	Usage: []string{"[--file <script>] [--stop-on-error]"},
+	Options: []cli.Flag{
+		{Name: "file", Arg: "path", Desc: "Script to run (default: stdin; - also means stdin)"},
+		{Name: "stop-on-error", Desc: "Stop at the first failing command"},
+	},
+	Sections: []cli.HelpSection{{Title: "Example script", Body: `# triage.trelli
+cards create --list-name "To Do" --name "Rotate API keys"
+cards move c1 --list-name Doing
+comments add c1 "Picked up"`}},
+	Run:          runExec,
+	BareIsAction: true,
+}
+
+// execUnscriptable are client commands that take over stdin/stdout or run
+// scripts themselves, so they cannot be script lines.
+var execUnscriptable = map[string]bool{"exec": true, "rpc": true, "mcp": true}
+
+// execResult reports one script line.
+type execResult struct {
+	Line    int    `json:"line"`
+	Command string `json:"command"`
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+	Ms      int64  `json:"ms"`
+}
+
+func runExec(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help" || args[0] == "help") {
+		cli.PrintCommandHelp("exec")
+		return nil
+	}
+	fs := flag.NewFlagSet("exec", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var file string
+	var stopOnError bool
+	fs.StringVar(&file, "file", "", "Script to run")
+	fs.BoolVar(&stopOnError, "stop-on-error", false, "Stop at the first failing command")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("exec")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &file); err != nil {
+		return err
+	}
+
+	var in io.Reader = os.Stdin
+	cfg.StdinBusy = true
+	if file != "" && file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+		cfg.StdinBusy = false
+	}
+
+	sc := bufio.NewScanner(in)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	var run, failed int
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		start := time.Now()
+		err := execLine(client, cfg, line)
+		if errors.Is(err, cli.ErrHelpDisplayed) || errors.Is(err, cli.ErrDryRun) {
+			err = nil
+		}
+		run++
+		res := execResult{Line: lineNo, Command: line, OK: err == nil, Ms: time.Since(start).Milliseconds()}
+		if err != nil {
+			failed++
+			res.Error = cli.NewErrorPayload(err).Message
+		}
+		reportExecResult(cfg, res)
+		if err != nil && (stopOnError || errors.Is(err, context.Canceled)) {
+			break
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d commands failed", failed, run)
+	}
+	return nil
+}
+
+// execLine runs one script line with the shared client.
+func execLine(client *cli.Client, cfg cli.Config, line string) error {
+	args, err := splitCommandLine(line)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 && args[0] == "trelli" {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return errors.New("missing command")
+	}
+	if strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("global option %s must be given to exec, not on a script line", args[0])
+	}
+	args = cli.ResolveAliases(args)
+	spec, ok := cli.FindCommand(args[0])
+	switch {
+	case !ok:
+		return fmt.Errorf("unknown command %q", args[0])
+	case spec.Run == nil || spec.Mode != cli.ModeClient || execUnscriptable[spec.Name]:
+		return fmt.Errorf("%s cannot run in a script", spec.Name)
+	}
+	return spec.Run(client, cfg, cfg.File.WithCommandDefaults(spec.Name, args[1:]))
+}
+
+func reportExecResult(cfg cli.Config, res execResult) {
+	if cfg.JSON {
+		_ = json.NewEncoder(os.Stderr).Encode(res)
+		return
+	}
+	if res.OK {
+		slog.Info(fmt.Sprintf("ok   line %d: %s (%dms)", res.Line, res.Command, res.Ms), "line", res.Line, "command", res.Command, "ms", res.Ms)
+		return
+	}
+	slog.Error(fmt.Sprintf("line %d: %s: %s", res.Line, res.Command, res.Error), "line", res.Line, "command", res.Command, "ms", res.Ms, "error", res.Error)
+}
+
+// splitCommandLine splits a script line into arguments the way a POSIX
+// shell would for plain words: whitespace separates, single quotes keep
+// everything literal, and double quotes and backslashes escape.
+func splitCommandLine(line string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errors.New("unterminated quote or escape")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
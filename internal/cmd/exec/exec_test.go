@@ -0,0 +1,105 @@
+package exec
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+	"trelli/internal/cmd/cards"
+)
+
+// TestMain registers the command, and the cards command its scripts run,
+// as main does.
+func TestMain(m *testing.M) {
+	cli.Register(Command, cards.Command)
+	os.Exit(m.Run())
+}
+
+// withStdin replaces os.Stdin with a file holding input for the test.
+func withStdin(t *testing.T, input string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, []byte(input), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() {
+		os.Stdin = orig
+		f.Close()
+	})
+}
+
+// withStderr replaces os.Stderr, which the logger writes to, with a file
+// and returns a function that reads what was written.
+func withStderr(t *testing.T) func() string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stderr")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stderr
+	os.Stderr = f
+	t.Cleanup(func() {
+		os.Stderr = orig
+		f.Close()
+	})
+	return func() string {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+}
+
+func TestExecResultsAreLogged(t *testing.T) {
+	srv := clitest.NewStub(t)
+	script := "cards list l1\nbogus\n"
+
+	withStdin(t, script)
+	stderr := withStderr(t)
+	clitest.RunCLI(t, srv, "--log-format", "json", "exec")
+	got := stderr()
+	if !strings.Contains(got, `"level":"INFO"`) || !strings.Contains(got, `"line":1,"command":"cards list l1","ms":`) {
+		t.Errorf("--log-format json, ok line:\n%s", got)
+	}
+	if !strings.Contains(got, `"level":"ERROR"`) || !strings.Contains(got, `"line":2,"command":"bogus","ms":`) || !strings.Contains(got, `"error":"unknown command \"bogus\""`) {
+		t.Errorf("--log-format json, failed line:\n%s", got)
+	}
+
+	withStdin(t, script)
+	stderr = withStderr(t)
+	clitest.RunCLI(t, srv, "--quiet", "exec")
+	if got := stderr(); strings.Contains(got, "ok   line 1") || !strings.Contains(got, `error: line 2: bogus: unknown command "bogus"`) {
+		t.Errorf("--quiet:\n%s", got)
+	}
+}
+
+func TestExecStdinScriptConfirmation(t *testing.T) {
+	srv := clitest.NewStub(t)
+	withStdin(t, "cards archive c1\ncards archive c1 --yes\n")
+	got := clitest.RunCLI(t, srv, "--json", "exec")
+	if !strings.Contains(got, "1 of 2 commands failed") {
+		t.Errorf("exec from stdin:\n%s", got)
+	}
+
+	// The prompt message names the way out for a script on stdin.
+	cfg := cli.Config{StdinBusy: true}
+	err := cli.Confirm(cfg, "archive card c1", nil)
+	if err == nil || !strings.Contains(err.Error(), "read from stdin: pass --yes or --force") {
+		t.Errorf("confirm with a busy stdin = %v", err)
+	}
+	cfg.Force = true
+	if err := cli.Confirm(cfg, "archive card c1", nil); err != nil {
+		t.Errorf("confirm --force with a busy stdin = %v", err)
+	}
+}
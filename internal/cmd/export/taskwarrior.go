@@ -0,0 +1,160 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+// runExportTaskwarrior writes the cards of a board as tasks, one JSON
+// object per line, for task import.
+func runExportTaskwarrior(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("export taskwarrior", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boardID := cfg.BoardID
+	var out string
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&out, "out", "", "Output file (default: stdout)")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("export")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &boardID); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(boardID)
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+
+	var lists []cli.TrelloList
+	var labels []cli.Label
+	if err := client.GetAll(ctx, cli.BoardListsRequest(boardID, &lists), cli.BoardLabelsRequest(boardID, &labels)); err != nil {
+		return err
+	}
+	listNames := map[string]string{}
+	for _, l := range lists {
+		listNames[l.ID] = l.Name
+	}
+	labelNames := map[string]string{}
+	for _, l := range labels {
+		labelNames[l.ID] = cli.FirstNonEmpty(l.Name, l.Color)
+	}
+
+	var w io.Writer = os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	query := url.Values{}
+	query.Set("fields", cli.CardFields(cli.Config{})+",idLabels")
+	err := cli.StreamArray(ctx, client, "/1/boards/"+url.PathEscape(boardID)+"/cards", query, func(c cli.VaultCard) error {
+		return enc.Encode(cardTask(c, listNames, labelNames))
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// cardTask maps a card to a task, pending or, once archived, completed:
+// its list becomes the project and its labels tags. The UUID derives from the card id, so exporting again
+// updates the tasks imported before instead of duplicating them.
+func cardTask(c cli.VaultCard, listNames, labelNames map[string]string) TaskwarriorTask {
+	t := TaskwarriorTask{
+		UUID:        cardUUID(c.ID),
+		Description: c.Name,
+		Status:      "pending",
+		Project:     listNames[c.IDList],
+		TrelloID:    c.ID,
+		TrelloURL:   c.ShortURL,
+	}
+	if c.Closed {
+		t.Status = "completed"
+		if end, err := time.Parse(time.RFC3339, c.DateLastActivity); err == nil {
+			t.End = end.UTC().Format(TaskwarriorTime)
+		}
+	}
+	// Ids of cards created through the API start with their creation time.
+	if created, ok := trelloIDTime(c.ID); ok {
+		t.Entry = created.Format(TaskwarriorTime)
+	} else if active, err := time.Parse(time.RFC3339, c.DateLastActivity); err == nil {
+		t.Entry = active.UTC().Format(TaskwarriorTime)
+	}
+	if due, err := time.Parse(time.RFC3339, c.Due); err == nil {
+		t.Due = due.UTC().Format(TaskwarriorTime)
+	}
+	for _, id := range c.IDLabels {
+		if name := labelNames[id]; name != "" {
+			// Tags are single words.
+			t.Tags = append(t.Tags, strings.Join(strings.Fields(name), "_"))
+		}
+	}
+	if desc := strings.TrimSpace(c.Desc); desc != "" {
+		t.Annotations = []TaskwarriorAnnotation{{Entry: t.Entry, Description: desc}}
+	}
+	return t
+}
+
+// cardUUID returns a name-based (version 5 style) UUID for a card id.
+func cardUUID(cardID string) string {
+	sum := sha1.Sum([]byte("trelli:card:" + cardID))
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// trelloIDTime returns the creation time encoded in the first four bytes
+// of a Trello id.
+func trelloIDTime(id string) (time.Time, bool) {
+	if len(id) < 8 {
+		return time.Time{}, false
+	}
+	secs, err := strconv.ParseInt(id[:8], 16, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(secs, 0).UTC(), true
+}
+
+// TaskwarriorTime is Taskwarrior's date format in JSON.
+const TaskwarriorTime = "20060102T150405Z"
+
+// TaskwarriorTask is a task in Taskwarrior's JSON import/export format.
+// trelloid and trellourl are user-defined attributes; Taskwarrior keeps
+// them even when they are not configured.
+type TaskwarriorTask struct {
+	UUID        string                  `json:"uuid,omitempty"`
+	Description string                  `json:"description"`
+	Status      string                  `json:"status"`
+	Entry       string                  `json:"entry,omitempty"`
+	End         string                  `json:"end,omitempty"`
+	Due         string                  `json:"due,omitempty"`
+	Project     string                  `json:"project,omitempty"`
+	Tags        []string                `json:"tags,omitempty"`
+	Annotations []TaskwarriorAnnotation `json:"annotations,omitempty"`
+	TrelloID    string                  `json:"trelloid,omitempty"`
+	TrelloURL   string                  `json:"trellourl,omitempty"`
+}
+
+type TaskwarriorAnnotation struct {
+	Entry       string `json:"entry"`
+	Description string `json:"description"`
+}
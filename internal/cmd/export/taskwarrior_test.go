@@ -0,0 +1,27 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCardUUID(t *testing.T) {
+	a, b := cardUUID("5f0c1e2d3a4b5c6d7e8f9012"), cardUUID("5f0c1e2d3a4b5c6d7e8f9013")
+	if a != cardUUID("5f0c1e2d3a4b5c6d7e8f9012") || a == b {
+		t.Errorf("cardUUID is not a stable per-card id: %s, %s", a, b)
+	}
+	if len(a) != 36 || a[14] != '5' || !strings.ContainsRune("89ab", rune(a[19])) {
+		t.Errorf("cardUUID = %s, want a version 5 UUID", a)
+	}
+}
+
+func TestTrelloIDTime(t *testing.T) {
+	got, ok := trelloIDTime("5f0c1e2d3a4b5c6d7e8f9012")
+	if want := time.Date(2020, 7, 13, 8, 41, 17, 0, time.UTC); !ok || !got.Equal(want) {
+		t.Errorf("trelloIDTime = %v, %v; want %v", got, ok, want)
+	}
+	if _, ok := trelloIDTime("c1"); ok {
+		t.Error("trelloIDTime accepted a short id")
+	}
+}
@@ -0,0 +1,375 @@
+// Package export implements "trelli export": export a board to other formats.
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "export",
+	Summary: "Export a board to other formats",
+	Description: `taskwarrior writes the cards of a board in Taskwarrior's import format,
+one task per line, for task import: lists become projects, labels become
+tags (spaces turned into underscores), the description an annotation,
+and archived cards completed tasks. Task UUIDs derive from card ids, so
+importing a later export updates the same tasks.
+
+vault writes a board as a folder of Markdown notes for Obsidian and
+similar note-taking tools: one note per open card under cards/, with
+YAML frontmatter (ids, list, labels, due) and a body of the description,
+checklists, and comments; an index note per list under lists/ linking its
+cards; and a board note linking the lists. Exporting again updates the
+notes in place and deletes notes of cards and lists no longer on the
+board. Other files in the folder are left alone.
+
+xlsx writes a board as an Excel workbook: a summary sheet counting the
+cards of each open list, linked to a sheet per list with a row per open
+card (name linked to the card, description, labels, due date, and last
+activity).`,
+	Subcommands: []cli.Subcommand{
+		{Name: "taskwarrior", Usage: []string{"taskwarrior [[--board] <boardIdOrShortLink>] [--out <file>]"}, Flags: []cli.Flag{
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "out", Arg: "file", Desc: "Output file (default: stdout for taskwarrior, trelli-<board>.xlsx for xlsx)"},
+		}},
+		{Name: "vault", Usage: []string{"vault [[--board] <boardIdOrShortLink>] [--dir <path>]"}, Flags: []cli.Flag{
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "dir", Arg: "path", Desc: "Vault folder (default trelli-vault-<board>)"},
+		}},
+		{Name: "xlsx", Usage: []string{"xlsx [[--board] <boardIdOrShortLink>] [--out <file>]"}, Flags: []cli.Flag{
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "out", Arg: "file", Desc: "Output file (default: stdout for taskwarrior, trelli-<board>.xlsx for xlsx)"},
+		}},
+	},
+	Sections: []cli.HelpSection{{Title: "Taskwarrior", Body: `trelli export taskwarrior --board EnGi | task import`}},
+	Options:  []cli.Flag{cli.JSONOption},
+	Run:      runExport,
+}
+
+func runExport(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) == 0 {
+		cli.PrintCommandHelp("export")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("export")
+		return nil
+	case "taskwarrior":
+		return runExportTaskwarrior(ctx, client, cfg, args[1:])
+	case "vault":
+		fs := flag.NewFlagSet("export vault", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		boardID := cfg.BoardID
+		var dir string
+		fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+		fs.StringVar(&dir, "dir", "", "Vault folder (default trelli-vault-<board>)")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("export")); err != nil {
+			return err
+		}
+		if err := cli.TakePositional(fs, &boardID); err != nil {
+			return err
+		}
+		boardID = cfg.File.ResolveBoardAlias(boardID)
+		if strings.TrimSpace(boardID) == "" {
+			return cli.ErrNoBoard
+		}
+		if dir == "" {
+			dir = "trelli-vault-" + boardID
+		}
+
+		v, err := fetchVault(ctx, client, boardID)
+		if err != nil {
+			return err
+		}
+		summary, err := writeVault(dir, v)
+		if err != nil {
+			return err
+		}
+		if cfg.Structured() {
+			return cli.Render(cfg, summary)
+		}
+		fmt.Printf("Exported %s: %d lists, %d cards -> %s (%d notes written, %d removed)\n", v.Board.Name, summary.Lists, summary.Cards, dir, summary.Written, summary.Removed)
+		return nil
+	case "xlsx":
+		return runExportXLSX(ctx, client, cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown export subcommand %q", args[0])
+	}
+}
+
+type vault struct {
+	Board  cli.Board
+	Lists  []cli.TrelloList
+	Labels []cli.Label
+	Cards  []cli.VaultCard
+}
+
+type vaultSummary struct {
+	Board   string `json:"board"`
+	Dir     string `json:"dir"`
+	Lists   int    `json:"lists"`
+	Cards   int    `json:"cards"`
+	Written int    `json:"written"`
+	Removed int    `json:"removed"`
+}
+
+// fetchVault reads the board with every open card's checklists and
+// comments, cli.ExportWindow cards at a time.
+func fetchVault(ctx context.Context, client *cli.Client, boardID string) (vault, error) {
+	var v vault
+	if err := client.GetAll(ctx,
+		cli.BoardRequest(boardID, &v.Board),
+		cli.BoardListsRequest(boardID, &v.Lists),
+		cli.BoardLabelsRequest(boardID, &v.Labels),
+	); err != nil {
+		return v, err
+	}
+	query := url.Values{}
+	query.Set("fields", cli.CardFields(cli.Config{})+",idLabels")
+	if err := cli.StreamArray(ctx, client, "/1/boards/"+url.PathEscape(boardID)+"/cards", query, func(c cli.VaultCard) error {
+		v.Cards = append(v.Cards, c)
+		return nil
+	}); err != nil {
+		return v, err
+	}
+	for start := 0; start < len(v.Cards); start += cli.ExportWindow {
+		window := v.Cards[start:min(start+cli.ExportWindow, len(v.Cards))]
+		reqs := make([]cli.GetRequest, 0, 2*len(window))
+		for i := range window {
+			reqs = append(reqs,
+				cli.ChecklistsRequest(window[i].ID, &window[i].Checklists),
+				cli.CommentsRequest(window[i].ID, cli.CommentPageSize, &window[i].Comments))
+		}
+		if err := client.GetAll(ctx, reqs...); err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
+
+// vaultMarker is the frontmatter line that identifies notes written by
+// export vault; only such notes are ever deleted.
+const vaultMarker = "source: trelli"
+
+// writeVault writes the notes of v under dir, skipping notes whose content
+// is unchanged, and deletes stale notes trelli wrote before.
+func writeVault(dir string, v vault) (vaultSummary, error) {
+	summary := vaultSummary{Board: v.Board.ID, Dir: dir, Lists: len(v.Lists), Cards: len(v.Cards)}
+	notes := map[string][]byte{}
+
+	listNotes := map[string]string{}
+	listNames := map[string]string{}
+	used := map[string]bool{}
+	for _, l := range v.Lists {
+		name := uniqueNoteName(used, l.Name, l.ID)
+		listNotes[l.ID] = "lists/" + name
+		listNames[l.ID] = l.Name
+	}
+	labelNames := map[string]string{}
+	for _, l := range v.Labels {
+		labelNames[l.ID] = cli.FirstNonEmpty(l.Name, l.Color)
+	}
+	cardNotes := make([]string, len(v.Cards))
+	used = map[string]bool{}
+	for i, c := range v.Cards {
+		cardNotes[i] = "cards/" + uniqueNoteName(used, c.Name+" ("+cli.ShortLinkOf(c.Card)+")", c.ID)
+	}
+
+	// Board note.
+	var b bytes.Buffer
+	writeFrontmatter(&b, [][2]string{
+		{"id", yamlString(v.Board.ID)},
+		{"url", yamlString(v.Board.URL)},
+		{"exported", yamlString(time.Now().UTC().Format(time.RFC3339))},
+	})
+	fmt.Fprintf(&b, "# %s\n\n", v.Board.Name)
+	for _, l := range v.Lists {
+		n := 0
+		for _, c := range v.Cards {
+			if c.IDList == l.ID {
+				n++
+			}
+		}
+		fmt.Fprintf(&b, "- [[%s|%s]] (%d cards)\n", listNotes[l.ID], l.Name, n)
+	}
+	notes[uniqueNoteName(map[string]bool{}, v.Board.Name, v.Board.ID)] = b.Bytes()
+
+	// List notes.
+	for _, l := range v.Lists {
+		var b bytes.Buffer
+		writeFrontmatter(&b, [][2]string{
+			{"id", yamlString(l.ID)},
+			{"board", yamlString(v.Board.Name)},
+		})
+		fmt.Fprintf(&b, "# %s\n\n", l.Name)
+		for i, c := range v.Cards {
+			if c.IDList != l.ID {
+				continue
+			}
+			fmt.Fprintf(&b, "- [[%s|%s]]", cardNotes[i], c.Name)
+			if c.Due != "" {
+				fmt.Fprintf(&b, " (due %s)", cli.DateOnly(c.Due))
+			}
+			b.WriteString("\n")
+		}
+		notes[listNotes[l.ID]] = b.Bytes()
+	}
+
+	// Card notes.
+	for i, c := range v.Cards {
+		labels := make([]string, 0, len(c.IDLabels))
+		for _, id := range c.IDLabels {
+			if name := labelNames[id]; name != "" {
+				labels = append(labels, name)
+			}
+		}
+		var b bytes.Buffer
+		writeFrontmatter(&b, [][2]string{
+			{"id", yamlString(c.ID)},
+			{"url", yamlString(c.ShortURL)},
+			{"board", yamlString(v.Board.Name)},
+			{"list", yamlString(listNames[c.IDList])},
+			{"labels", yamlList(labels)},
+			{"due", yamlString(c.Due)},
+			{"updated", yamlString(c.DateLastActivity)},
+		})
+		fmt.Fprintf(&b, "# %s\n\n", c.Name)
+		if list, ok := listNotes[c.IDList]; ok {
+			fmt.Fprintf(&b, "List: [[%s|%s]]\n\n", list, listNames[c.IDList])
+		}
+		if desc := strings.TrimSpace(c.Desc); desc != "" {
+			b.WriteString(desc + "\n\n")
+		}
+		if len(c.Checklists) > 0 {
+			b.WriteString("## Checklists\n\n")
+			for _, cl := range c.Checklists {
+				cli.WriteChecklistMarkdown(&b, "###", cl)
+			}
+		}
+		if len(c.Comments) > 0 {
+			b.WriteString("## Comments\n\n")
+			cli.WriteCommentsMarkdown(&b, c.Comments)
+		}
+		notes[cardNotes[i]] = append(bytes.TrimRight(b.Bytes(), "\n"), '\n')
+	}
+
+	for name, data := range notes {
+		file := filepath.Join(dir, filepath.FromSlash(name)+".md")
+		if old, err := os.ReadFile(file); err == nil && bytes.Equal(stripExported(old), stripExported(data)) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return summary, err
+		}
+		if err := os.WriteFile(file, data, 0o644); err != nil {
+			return summary, err
+		}
+		summary.Written++
+	}
+
+	// Remove notes of cards and lists that are gone.
+	for _, sub := range []string{"cards", "lists"} {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return summary, err
+		}
+		for _, e := range entries {
+			name, ok := strings.CutSuffix(e.Name(), ".md")
+			if !ok || e.IsDir() {
+				continue
+			}
+			if _, current := notes[path.Join(sub, name)]; current {
+				continue
+			}
+			file := filepath.Join(dir, sub, e.Name())
+			data, err := os.ReadFile(file)
+			if err != nil || !bytes.Contains(data, []byte("\n"+vaultMarker+"\n")) {
+				continue
+			}
+			if err := os.Remove(file); err != nil {
+				return summary, err
+			}
+			summary.Removed++
+		}
+	}
+	return summary, nil
+}
+
+// stripExported drops the exported: line, so a board note that only
+// differs in export time is not rewritten.
+func stripExported(note []byte) []byte {
+	var out [][]byte
+	for _, line := range bytes.Split(note, []byte("\n")) {
+		if !bytes.HasPrefix(line, []byte("exported: ")) {
+			out = append(out, line)
+		}
+	}
+	return bytes.Join(out, []byte("\n"))
+}
+
+func writeFrontmatter(b *bytes.Buffer, fields [][2]string) {
+	b.WriteString("---\n")
+	for _, f := range fields {
+		if f[1] != `""` {
+			fmt.Fprintf(b, "%s: %s\n", f[0], f[1])
+		}
+	}
+	b.WriteString(vaultMarker + "\n---\n\n")
+}
+
+// yamlString quotes s as a JSON string, which YAML reads as is.
+func yamlString(s string) string {
+	raw, _ := json.Marshal(s)
+	return string(raw)
+}
+
+func yamlList(items []string) string {
+	if len(items) == 0 {
+		return "[]"
+	}
+	raw, _ := json.Marshal(items)
+	return strings.ReplaceAll(string(raw), `","`, `", "`)
+}
+
+// uniqueNoteName returns name as a file name safe on every platform and in
+// wiki links, falling back to id when it is empty or already used.
+func uniqueNoteName(used map[string]bool, name, id string) string {
+	clean := strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|#^[]`, r), r < ' ':
+			return '-'
+		}
+		return r
+	}, name)
+	clean = strings.Trim(strings.TrimSpace(clean), ".")
+	if len(clean) > 120 {
+		clean = strings.ToValidUTF8(clean[:120], "")
+	}
+	switch {
+	case clean == "":
+		clean = id
+	case used[strings.ToLower(clean)]:
+		clean += " " + id
+	}
+	used[strings.ToLower(clean)] = true
+	return clean
+}
@@ -0,0 +1,64 @@
+package export
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// TestMain registers the command as main does, for the tests that run it
+// through the CLI.
+func TestMain(m *testing.M) {
+	cli.Register(Command)
+	os.Exit(m.Run())
+}
+
+func TestExportVault(t *testing.T) {
+	stub := clitest.NewStub(t)
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "cards", "Deleted card (ZzZz).md")
+	mine := filepath.Join(dir, "cards", "My own note.md")
+	for file, content := range map[string]string{stale: "---\nid: \"c0\"\nsource: trelli\n---\n", mine: "# Mine\n"} {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if out := clitest.RunCLI(t, stub, "export", "vault", "--dir", dir); out != "Exported Engineering: 2 lists, 3 cards -> "+dir+" (6 notes written, 1 removed)\n" {
+		t.Errorf("first export printed %q", out)
+	}
+	var notes strings.Builder
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		notes.WriteString("=== " + filepath.ToSlash(rel) + "\n")
+		for _, line := range strings.SplitAfter(string(data), "\n") {
+			if !strings.HasPrefix(line, "exported: ") {
+				notes.WriteString(line)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clitest.CheckGolden(t, "export_vault", notes.String())
+
+	if out := clitest.RunCLI(t, stub, "export", "vault", "--dir", dir); !strings.HasSuffix(out, "(0 notes written, 0 removed)\n") {
+		t.Errorf("second export printed %q, want nothing rewritten", out)
+	}
+}
@@ -0,0 +1,376 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"trelli/internal/cli"
+)
+
+// xlsxCard is a card with the fields its spreadsheet row shows.
+type xlsxCard struct {
+	cli.Card
+	IDLabels    []string `json:"idLabels"`
+	DueComplete bool     `json:"dueComplete"`
+}
+
+type xlsxSummary struct {
+	Board string `json:"board"`
+	File  string `json:"file"`
+	Lists int    `json:"lists"`
+	Cards int    `json:"cards"`
+}
+
+func runExportXLSX(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("export xlsx", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boardID := cfg.BoardID
+	var out string
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&out, "out", "", "Workbook file (default trelli-<board>.xlsx)")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("export")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &boardID); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(boardID)
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+	if out == "" {
+		out = "trelli-" + boardID + ".xlsx"
+	}
+
+	var board cli.Board
+	var lists []cli.TrelloList
+	var labels []cli.Label
+	if err := client.GetAll(ctx, cli.BoardRequest(boardID, &board), cli.BoardListsRequest(boardID, &lists), cli.BoardLabelsRequest(boardID, &labels)); err != nil {
+		return err
+	}
+	var cards []xlsxCard
+	query := url.Values{}
+	query.Set("fields", cli.CardFields(cli.Config{})+",idLabels,dueComplete")
+	if err := cli.StreamArray(ctx, client, "/1/boards/"+url.PathEscape(boardID)+"/cards", query, func(c xlsxCard) error {
+		if !c.Closed {
+			cards = append(cards, c)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	wb := boardWorkbook(board, lists, labels, cards)
+	var buf bytes.Buffer
+	if err := wb.write(&buf); err != nil {
+		return err
+	}
+	if err := os.WriteFile(out, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	summary := xlsxSummary{Board: board.ID, File: out, Lists: len(wb.sheets) - 1, Cards: len(cards)}
+	if cfg.Structured() {
+		return cli.Render(cfg, summary)
+	}
+	fmt.Printf("Exported %s: %d lists, %d cards -> %s\n", board.Name, summary.Lists, summary.Cards, out)
+	return nil
+}
+
+// boardWorkbook lays out a board: a summary sheet with a row per open list
+// linking to its sheet, then a sheet per open list with a row per card.
+func boardWorkbook(board cli.Board, lists []cli.TrelloList, labels []cli.Label, cards []xlsxCard) *workbook {
+	labelNames := map[string]string{}
+	for _, l := range labels {
+		labelNames[l.ID] = cli.FirstNonEmpty(l.Name, l.Color)
+	}
+	byList := map[string][]xlsxCard{}
+	for _, c := range cards {
+		byList[c.IDList] = append(byList[c.IDList], c)
+	}
+
+	wb := &workbook{}
+	summary := wb.addSheet("Summary")
+	summary.widths = []float64{32, 10, 14, 14}
+	summary.rows = [][]xlsxCell{
+		{{text: "Board", style: styleBold}, {text: board.Name, link: board.URL}},
+		{},
+		{{text: "List", style: styleBold}, {text: "Cards", style: styleBold}, {text: "With due date", style: styleBold}, {text: "Due complete", style: styleBold}},
+	}
+	var total, totalDue, totalDone int
+	for _, l := range lists {
+		if l.Closed {
+			continue
+		}
+		sheet := wb.addSheet(l.Name)
+		sheet.widths = []float64{40, 60, 20, 18, 8, 18, 30}
+		sheet.frozen = true
+		sheet.rows = [][]xlsxCell{{
+			{text: "Card", style: styleBold}, {text: "Description", style: styleBold}, {text: "Labels", style: styleBold},
+			{text: "Due (UTC)", style: styleBold}, {text: "Done", style: styleBold}, {text: "Last activity (UTC)", style: styleBold},
+			{text: "URL", style: styleBold},
+		}}
+		var due, done int
+		for _, c := range byList[l.ID] {
+			var names []string
+			for _, id := range c.IDLabels {
+				if name := labelNames[id]; name != "" {
+					names = append(names, name)
+				}
+			}
+			link := cli.FirstNonEmpty(c.ShortURL, c.URL)
+			row := []xlsxCell{{text: c.Name, link: link}, {text: c.Desc}, {text: strings.Join(names, ", ")}, timeCell(c.Due), {}, timeCell(c.DateLastActivity), {text: link}}
+			if c.Due != "" {
+				due++
+				row[4] = xlsxCell{text: "no"}
+				if c.DueComplete {
+					done++
+					row[4] = xlsxCell{text: "yes"}
+				}
+			}
+			sheet.rows = append(sheet.rows, row)
+		}
+		n := len(byList[l.ID])
+		total, totalDue, totalDone = total+n, totalDue+due, totalDone+done
+		summary.rows = append(summary.rows, []xlsxCell{{text: l.Name, sheet: sheet.name}, numberCell(n), numberCell(due), numberCell(done)})
+	}
+	summary.rows = append(summary.rows, []xlsxCell{{text: "Total", style: styleBold}, numberCell(total), numberCell(totalDue), numberCell(totalDone)})
+	return wb
+}
+
+// Cell styles, indexes into the cellXfs of xlsxStyles.
+const (
+	styleDefault = iota
+	styleBold
+	styleDateTime
+	styleLink
+)
+
+// xlsxCell is a cell: text, or a number when isNumber; link makes the
+// text a hyperlink to a URL and sheet one to another sheet.
+type xlsxCell struct {
+	text     string
+	number   float64
+	isNumber bool
+	style    int
+	link     string
+	sheet    string
+}
+
+func numberCell(n int) xlsxCell {
+	return xlsxCell{number: float64(n), isNumber: true}
+}
+
+// timeCell returns an RFC 3339 time as a date-time cell, or an empty cell.
+func timeCell(s string) xlsxCell {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return xlsxCell{}
+	}
+	// Spreadsheets count days since 1899-12-30.
+	days := t.UTC().Sub(time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)).Hours() / 24
+	return xlsxCell{number: days, isNumber: true, style: styleDateTime}
+}
+
+type worksheet struct {
+	name   string
+	rows   [][]xlsxCell
+	widths []float64
+	// frozen keeps the first row in view and adds filters to it.
+	frozen bool
+}
+
+// workbook is a minimal Office Open XML spreadsheet writer: inline
+// strings, numbers, a few fixed styles, and hyperlinks.
+type workbook struct {
+	sheets []*worksheet
+	used   map[string]bool
+}
+
+// addSheet adds a sheet named after name, made valid and unique: at most
+// 31 characters, none of []:*?/\, and distinct ignoring case.
+func (wb *workbook) addSheet(name string) *worksheet {
+	if wb.used == nil {
+		wb.used = map[string]bool{}
+	}
+	clean := strings.Trim(strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) || r < ' ' {
+			return ' '
+		}
+		return r
+	}, name), " '")
+	if clean == "" || strings.EqualFold(clean, "History") {
+		clean = "Sheet" + strconv.Itoa(len(wb.sheets)+1)
+	}
+	unique := truncateRunes(clean, 31)
+	for n := 2; wb.used[strings.ToLower(unique)]; n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		unique = truncateRunes(clean, 31-len(suffix)) + suffix
+	}
+	wb.used[strings.ToLower(unique)] = true
+	s := &worksheet{name: unique}
+	wb.sheets = append(wb.sheets, s)
+	return s
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// write writes the workbook as an .xlsx (zip) file.
+func (wb *workbook) write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	add := func(name, content string) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(f, content)
+		return err
+	}
+
+	var types, sheets, rels strings.Builder
+	for i, s := range wb.sheets {
+		n := i + 1
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(s.name), n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+	}
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(wb.sheets)+1)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xmlHeader + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			types.String() + `</Types>`},
+		{"_rels/.rels", xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xmlHeader + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() + `</Relationships>`},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, p := range parts {
+		if err := add(p.name, p.content); err != nil {
+			return err
+		}
+	}
+	for i, s := range wb.sheets {
+		sheet, sheetRels := s.xml()
+		if err := add(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheet); err != nil {
+			return err
+		}
+		if sheetRels != "" {
+			if err := add(fmt.Sprintf("xl/worksheets/_rels/sheet%d.xml.rels", i+1), sheetRels); err != nil {
+				return err
+			}
+		}
+	}
+	return zw.Close()
+}
+
+// xml returns the sheet part and, when it links to URLs, its
+// relationships part.
+func (s *worksheet) xml() (sheet, rels string) {
+	var b, links, targets strings.Builder
+	b.WriteString(xmlHeader + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	if s.frozen {
+		b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	}
+	if len(s.widths) > 0 {
+		b.WriteString("<cols>")
+		for i, w := range s.widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%g" customWidth="1"/>`, i+1, i+1, w)
+		}
+		b.WriteString("</cols>")
+	}
+	b.WriteString("<sheetData>")
+	width, nrel := 0, 0
+	for r, row := range s.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			ref := cellRef(c, r)
+			width = max(width, c+1)
+			style := cell.style
+			switch {
+			case cell.link != "":
+				nrel++
+				fmt.Fprintf(&links, `<hyperlink ref="%s" r:id="rId%d"/>`, ref, nrel)
+				fmt.Fprintf(&targets, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="%s" TargetMode="External"/>`, nrel, xmlEscape(cell.link))
+				style = styleLink
+			case cell.sheet != "":
+				fmt.Fprintf(&links, `<hyperlink ref="%s" location="%s"/>`, ref, xmlEscape("'"+strings.ReplaceAll(cell.sheet, "'", "''")+"'!A1"))
+				style = styleLink
+			}
+			switch {
+			case cell.isNumber:
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(cell.number, 'f', -1, 64))
+			case cell.text != "":
+				// Cells hold at most 32767 characters.
+				fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, xmlEscape(truncateRunes(cell.text, 32767)))
+			}
+		}
+		b.WriteString("</row>")
+	}
+	b.WriteString("</sheetData>")
+	if s.frozen && width > 0 {
+		fmt.Fprintf(&b, `<autoFilter ref="A1:%s"/>`, cellRef(width-1, max(len(s.rows)-1, 0)))
+	}
+	if links.Len() > 0 {
+		b.WriteString("<hyperlinks>" + links.String() + "</hyperlinks>")
+	}
+	b.WriteString("</worksheet>")
+	if nrel > 0 {
+		rels = xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + targets.String() + `</Relationships>`
+	}
+	return b.String(), rels
+}
+
+// cellRef returns the A1-style reference of a zero-based column and row.
+func cellRef(col, row int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row+1)
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+// xlsxStyles defines the cell styles: default, bold, date-time, and
+// hyperlink, in the order of the style constants.
+const xlsxStyles = xmlHeader + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm"/></numFmts>` +
+	`<fonts count="3"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font>` +
+	`<font><u/><sz val="11"/><color rgb="FF0563C1"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="2" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`
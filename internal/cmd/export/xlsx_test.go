@@ -0,0 +1,69 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"trelli/internal/clitest"
+)
+
+func TestExportXLSX(t *testing.T) {
+	stub := clitest.NewStub(t)
+	out := filepath.Join(t.TempDir(), "board.xlsx")
+	if got := clitest.RunCLI(t, stub, "export", "xlsx", "--out", out); got != "Exported Engineering: 2 lists, 2 cards -> "+out+"\n" {
+		t.Errorf("export printed %q", got)
+	}
+
+	zr, err := zip.OpenReader(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var parts strings.Builder
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Every part must be well-formed XML.
+		dec := xml.NewDecoder(strings.NewReader(string(data)))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: %v", f.Name, err)
+			}
+		}
+		if strings.HasPrefix(f.Name, "xl/worksheets/") || f.Name == "xl/workbook.xml" {
+			parts.WriteString("=== " + f.Name + "\n" + strings.ReplaceAll(string(data), "><", ">\n<") + "\n")
+		}
+	}
+	clitest.CheckGolden(t, "export_xlsx", parts.String())
+}
+
+func TestWorkbookSheetNames(t *testing.T) {
+	wb := &workbook{}
+	for _, name := range []string{"Summary", "summary", "Q1/Q2: [plan]", "History", strings.Repeat("x", 40), strings.Repeat("x", 40)} {
+		wb.addSheet(name)
+	}
+	var got []string
+	for _, s := range wb.sheets {
+		got = append(got, s.name)
+	}
+	want := []string{"Summary", "summary (2)", "Q1 Q2   plan", "Sheet4", strings.Repeat("x", 31), strings.Repeat("x", 27) + " (2)"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("sheet names = %q, want %q", got, want)
+	}
+	if cellRef(0, 0) != "A1" || cellRef(25, 9) != "Z10" || cellRef(26, 0) != "AA1" || cellRef(701, 1) != "ZZ2" {
+		t.Error("cellRef is wrong")
+	}
+}
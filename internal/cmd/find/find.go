@@ -0,0 +1,172 @@
+// Package find implements "trelli find": search the cards of all your boards.
+package find
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "find",
+	Summary: "Search the cards of all your boards",
+	Description: `Search the open cards of every open board you can see for text and
+print the matches with their board and list, for when you know a card
+exists but not where. --scope takes board ids, shortLinks, or aliases
+instead of mine. Card names and descriptions are searched unless --in
+says otherwise; searching comments pages through each board's history.
+
+The boards are read --concurrency at a time. The list of your boards
+comes from the name cache, and the card downloads are revalidated
+against the local cache like grep's. A board that cannot be read is
+reported and skipped.`,
+	Usage: []string{"[--scope mine|<boards>] [--regex] [--case-sensitive] [--in <fields>] <text>"},
+	Options: []cli.Flag{
+		{Name: "scope", Arg: "boards", Desc: `"mine" for all your open boards (default), or comma-separated boards`},
+		{Name: "regex", Short: "E", Desc: "Treat the text as a Go regular expression"},
+		{Name: "case-sensitive", Desc: "Match case exactly"},
+		{Name: "in", Arg: "fields", Desc: "Comma-separated fields to search: name, desc, comments, checklists (default name,desc)"},
+		cli.JSONOption,
+	},
+	Run: runFind,
+}
+
+// findResult is a matching card with its board.
+type findResult struct {
+	Board   string `json:"board"`
+	BoardID string `json:"boardId"`
+	cli.GrepResult
+}
+
+func runFind(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help" || args[0] == "help") {
+		cli.PrintCommandHelp("find")
+		return nil
+	}
+	fs := flag.NewFlagSet("find", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var text string
+	scope, in := "mine", "name,desc"
+	var isRegex, caseSensitive bool
+	fs.StringVar(&scope, "scope", scope, "mine, or comma-separated boards")
+	fs.BoolVar(&isRegex, "regex", false, "Treat the text as a regular expression")
+	fs.BoolVar(&isRegex, "E", false, "Treat the text as a regular expression")
+	fs.BoolVar(&caseSensitive, "case-sensitive", false, "Match case exactly")
+	fs.StringVar(&in, "in", in, "Fields to search")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("find")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &text); err != nil {
+		return err
+	}
+	if text == "" {
+		return errors.New("find requires the text to search for")
+	}
+	fields, err := cli.ParseGrepFields(in)
+	if err != nil {
+		return err
+	}
+	re, err := cli.GrepPattern(text, isRegex, caseSensitive)
+	if err != nil {
+		return err
+	}
+
+	boards, err := Boards(cfg, client, cli.SplitIDs(scope))
+	if err != nil {
+		return err
+	}
+	found := make([][]cli.GrepResult, len(boards))
+	failed := make([]error, len(boards))
+	tasks := make([]func() error, len(boards))
+	for i, b := range boards {
+		tasks[i] = func() error {
+			// One unreadable board should not hide the matches on the others.
+			found[i], failed[i] = cli.GrepBoard(ctx, client, b.ID, re, fields)
+			return nil
+		}
+	}
+	_ = cli.Parallel(cfg.Concurrency, tasks...)
+
+	results := []findResult{}
+	bad := 0
+	for i, b := range boards {
+		if failed[i] != nil {
+			bad++
+			slog.Warn(fmt.Sprintf("searching board %s: %s", cli.FirstNonEmpty(b.Name, b.ID), failed[i]), "board", b.ID)
+			continue
+		}
+		for _, r := range found[i] {
+			results = append(results, findResult{Board: b.Name, BoardID: b.ID, GrepResult: r})
+		}
+	}
+	if bad > 0 && bad == len(boards) {
+		return fmt.Errorf("could not search any of the %d boards", bad)
+	}
+	if cfg.Structured() {
+		return cli.Render(cfg, results, findTable(results))
+	}
+	shown := make([]cli.GrepResult, len(results))
+	for i, r := range results {
+		shown[i] = r.GrepResult
+		shown[i].List = r.Board + " / " + r.List
+	}
+	cli.PrintGrepResults(os.Stdout, shown, re, cli.TerminalMarkdown(false))
+	return nil
+}
+
+func findTable(results []findResult) cli.Table {
+	t := cli.Table{Columns: []string{"BOARD", "CARD", "NAME", "LIST", "FIELD", "TEXT"}, Empty: "No matches."}
+	for _, r := range results {
+		for _, m := range r.Matches {
+			t.Rows = append(t.Rows, []string{r.Board, r.ID, r.Name, r.List, m.Field, m.Text})
+		}
+	}
+	return t
+}
+
+// Boards resolves --scope: "mine" is every open board of the member,
+// from the name cache, sorted by name; other entries are single boards.
+func Boards(cfg cli.Config, client *cli.Client, scope []string) ([]cli.Board, error) {
+	ctx := cfg.Context
+	if len(scope) == 0 {
+		return nil, errors.New("--scope needs mine or at least one board")
+	}
+	var boards []cli.Board
+	seen := map[string]bool{}
+	if slices.Contains(scope, "mine") {
+		mine, _, err := cli.CachedLookup(ctx, client, "boards", cli.FetchBoards)
+		if err != nil {
+			return nil, err
+		}
+		mine = slices.Clone(mine)
+		slices.SortFunc(mine, func(a, b cli.Board) int { return strings.Compare(a.Name, b.Name) })
+		for _, b := range mine {
+			if !b.Closed && !seen[b.ID] {
+				seen[b.ID] = true
+				boards = append(boards, b)
+			}
+		}
+	}
+	for _, id := range scope {
+		if id == "mine" {
+			continue
+		}
+		b, err := client.Boards.Get(ctx, cfg.File.ResolveBoardAlias(id), "id,name,shortLink,url,closed")
+		if err != nil {
+			return nil, fmt.Errorf("board %s: %w", id, err)
+		}
+		if !seen[b.ID] {
+			seen[b.ID] = true
+			boards = append(boards, b)
+		}
+	}
+	return boards, nil
+}
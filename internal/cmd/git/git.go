@@ -0,0 +1,238 @@
+// Package git implements "trelli git": branch names and commit
+// trailers from cards.
+package git
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "git",
+	Summary: "Git helpers: branch names and commit trailers from cards",
+	Description: `branch prints a branch name for a card, <prefix>/<number>-<title>
+(e.g. feat/123-fix-login-again, where 123 is the card number shown in
+Trello), and with --create switches to a new branch of that name.
+
+prepare-commit-msg prints a commit trailer linking the card, or adds it to
+a commit message file, as git's prepare-commit-msg hook does. Without
+--card it uses the card whose number the current branch name carries
+(resolved on --board); a branch without one is left alone.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "branch", Usage: []string{"branch [--card] <cardId> [--prefix <prefix>] [--create]"}, Flags: []cli.Flag{cli.CardFlag,
+			{Name: "prefix", Arg: "prefix", Desc: "Branch name prefix (default feat)"},
+			{Name: "create", Desc: "Create and switch to the branch (git switch -c)"},
+		}},
+		{Name: "prepare-commit-msg", Usage: []string{"prepare-commit-msg [--card <cardId>] [--board <id>] [<message-file>]"}, Flags: []cli.Flag{cli.CardFlag,
+			{Name: "board", Arg: "id", Desc: "Board of the branch's card number (default: the default board)"},
+		}},
+	},
+	Options: []cli.Flag{cli.JSONOption},
+	Sections: []cli.HelpSection{{Title: "Hook", Body: `# .git/hooks/prepare-commit-msg
+#!/bin/sh
+exec trelli git prepare-commit-msg "$1"`}},
+	Run: runGit,
+}
+
+// gitCardTrailer is the trailer key prepare-commit-msg adds.
+const gitCardTrailer = "Trello-Card"
+
+// gitCard is the card data branch names and trailers are made from.
+type gitCard struct {
+	ID       string `json:"id"`
+	IDShort  int    `json:"idShort"`
+	Name     string `json:"name"`
+	ShortURL string `json:"shortUrl"`
+}
+
+// branchCardNumber matches the card number of a branch made by git branch.
+var branchCardNumber = regexp.MustCompile(`^(?:[^/]+/)*([0-9]+)(?:-|$)`)
+
+func runGit(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) == 0 {
+		cli.PrintCommandHelp("git")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("git")
+		return nil
+	case "branch":
+		fs := flag.NewFlagSet("git branch", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		var cardID string
+		prefix := "feat"
+		var create bool
+		fs.StringVar(&cardID, "card", "", "Card id")
+		fs.StringVar(&prefix, "prefix", prefix, "Branch name prefix")
+		fs.BoolVar(&create, "create", false, "Create and switch to the branch")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("git")); err != nil {
+			return err
+		}
+		if err := cli.TakePositional(fs, &cardID); err != nil {
+			return err
+		}
+		if strings.TrimSpace(cardID) == "" {
+			return errors.New("git branch requires --card")
+		}
+
+		card, err := fetchGitCard(ctx, client, "/1/cards/"+url.PathEscape(cardID))
+		if err != nil {
+			return err
+		}
+		name := branchName(prefix, card)
+		if create {
+			if err := runGitCmd("switch", "-c", name); err != nil {
+				return err
+			}
+		}
+		if cfg.Structured() {
+			return cli.Render(cfg, map[string]any{"branch": name, "card": card.ID, "created": create})
+		}
+		fmt.Println(name)
+		return nil
+
+	case "prepare-commit-msg":
+		fs := flag.NewFlagSet("git prepare-commit-msg", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		var cardID, file string
+		boardID := cfg.BoardID
+		fs.StringVar(&cardID, "card", "", "Card id")
+		fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("git")); err != nil {
+			return err
+		}
+		if err := cli.TakePositional(fs, &file); err != nil {
+			return err
+		}
+
+		var path string
+		switch {
+		case strings.TrimSpace(cardID) != "":
+			path = "/1/cards/" + url.PathEscape(cardID)
+		default:
+			branch, err := gitOutput("rev-parse", "--abbrev-ref", "HEAD")
+			if err != nil {
+				return err
+			}
+			m := branchCardNumber.FindStringSubmatch(branch)
+			if m == nil {
+				if file != "" {
+					// A hook must not block commits on other branches.
+					return nil
+				}
+				return fmt.Errorf("branch %q carries no card number: pass --card", branch)
+			}
+			boardID = cfg.File.ResolveBoardAlias(boardID)
+			if strings.TrimSpace(boardID) == "" {
+				return cli.ErrNoBoard
+			}
+			path = "/1/boards/" + url.PathEscape(boardID) + "/cards/" + m[1]
+		}
+		card, err := fetchGitCard(ctx, client, path)
+		if err != nil {
+			return err
+		}
+		trailer := gitCardTrailer + ": " + card.ShortURL
+		if file != "" {
+			if err := runGitCmd("interpret-trailers", "--in-place", "--if-exists", "addIfDifferent", "--trailer", trailer, file); err != nil {
+				return err
+			}
+		}
+		if cfg.Structured() {
+			return cli.Render(cfg, map[string]string{"trailer": trailer, "card": card.ID, "url": card.ShortURL})
+		}
+		if file == "" {
+			fmt.Println(trailer)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown git subcommand %q", args[0])
+	}
+}
+
+func fetchGitCard(ctx context.Context, client *cli.Client, path string) (gitCard, error) {
+	query := url.Values{}
+	query.Set("fields", "id,idShort,name,shortUrl")
+	var card gitCard
+	err := client.Do(ctx, http.MethodGet, path, query, nil, &card)
+	return card, err
+}
+
+// maxBranchSlug bounds the title part of branch names.
+const maxBranchSlug = 40
+
+// branchName returns <prefix>/<number>-<slug>, the slug being the card
+// title in lower case with runs of anything but ASCII letters and digits
+// turned into single hyphens, cut at a hyphen to at most maxBranchSlug
+// characters.
+func branchName(prefix string, card gitCard) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(card.Name) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	slug := b.String()
+	if len(slug) > maxBranchSlug {
+		slug = slug[:maxBranchSlug]
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	name := strconv.Itoa(card.IDShort)
+	if slug != "" {
+		name += "-" + slug
+	}
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		name = prefix + "/" + name
+	}
+	return name
+}
+
+// runGitCmd runs git with the terminal's stdio, for commands whose output
+// the user should see.
+func runGitCmd(args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stderr, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return nil
+}
+
+// gitOutput runs git and returns its trimmed standard output.
+func gitOutput(args ...string) (string, error) {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
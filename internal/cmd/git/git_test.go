@@ -0,0 +1,61 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// TestMain registers the command as main does, for the tests that run it
+// through the CLI.
+func TestMain(m *testing.M) {
+	cli.Register(Command)
+	os.Exit(m.Run())
+}
+
+func TestBranchName(t *testing.T) {
+	tests := []struct {
+		prefix string
+		card   gitCard
+		want   string
+	}{
+		{"feat", gitCard{IDShort: 123, Name: "Fix login, again"}, "feat/123-fix-login-again"},
+		{"fix/", gitCard{IDShort: 7, Name: "  Crash: NPE in *Save*!  "}, "fix/7-crash-npe-in-save"},
+		{"", gitCard{IDShort: 8, Name: "Über größe"}, "8-ber-gr-e"},
+		{"feat", gitCard{IDShort: 9, Name: "日本語"}, "feat/9"},
+		{"feat", gitCard{IDShort: 10, Name: "Rotate the API keys of every staging environment before the audit"}, "feat/10-rotate-the-api-keys-of-every-staging"},
+	}
+	for _, tt := range tests {
+		if got := branchName(tt.prefix, tt.card); got != tt.want {
+			t.Errorf("branchName(%q, %q) = %q, want %q", tt.prefix, tt.card.Name, got, tt.want)
+		}
+	}
+}
+
+func TestGitPrepareCommitMsg(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	stub := clitest.NewStub(t)
+	file := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	if err := os.WriteFile(file, []byte("Fix session timeout\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Running the hook twice, as an amend does, adds the trailer once.
+	for range 2 {
+		if out := clitest.RunCLI(t, stub, "git", "prepare-commit-msg", "--card", "c1", file); out != "" {
+			t.Fatalf("prepare-commit-msg printed %q", out)
+		}
+	}
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Fix session timeout\n\nTrello-Card: https://trello.com/c/AbCd\n"; string(got) != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+}
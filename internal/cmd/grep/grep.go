@@ -0,0 +1,94 @@
+// Package grep implements "trelli grep": search the text of a board's cards.
+package grep
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "grep",
+	Summary: "Search the text of a board's cards",
+	Description: `Search the names, descriptions, comments, and checklists of a board's
+open cards for a pattern and print each matching card with the lines
+that match. The pattern is a literal, case-insensitive string, or a Go
+regular expression with --regex. Unlike Trello's search, every line of
+every description and comment is searched, not just indexed words.
+
+The board is downloaded in a few requests; responses are revalidated
+against the local cache instead of being downloaded again when they have
+not changed, and --offline searches the last download.`,
+	Usage: []string{"[--board <boardIdOrShortLink>] [--regex] [--case-sensitive] [--in <fields>] <pattern>"},
+	Options: []cli.Flag{
+		{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+		{Name: "regex", Short: "E", Desc: "Treat the pattern as a Go regular expression"},
+		{Name: "case-sensitive", Desc: "Match case exactly"},
+		{Name: "in", Arg: "fields", Desc: "Comma-separated fields to search: name, desc, comments, checklists (default all)"},
+		cli.JSONOption,
+	},
+	Run: runGrep,
+}
+
+func runGrep(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help" || args[0] == "help") {
+		cli.PrintCommandHelp("grep")
+		return nil
+	}
+	fs := flag.NewFlagSet("grep", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boardID := cfg.BoardID
+	var pattern, in string
+	var isRegex, caseSensitive bool
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.BoolVar(&isRegex, "regex", false, "Treat the pattern as a regular expression")
+	fs.BoolVar(&isRegex, "E", false, "Treat the pattern as a regular expression")
+	fs.BoolVar(&caseSensitive, "case-sensitive", false, "Match case exactly")
+	fs.StringVar(&in, "in", strings.Join(cli.GrepFields, ","), "Fields to search")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("grep")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &pattern); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(boardID)
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+	if pattern == "" {
+		return errors.New("grep requires a pattern")
+	}
+	fields, err := cli.ParseGrepFields(in)
+	if err != nil {
+		return err
+	}
+	re, err := cli.GrepPattern(pattern, isRegex, caseSensitive)
+	if err != nil {
+		return err
+	}
+
+	results, err := cli.GrepBoard(ctx, client, boardID, re, fields)
+	if err != nil {
+		return err
+	}
+	if cfg.Structured() {
+		return cli.Render(cfg, results, grepTable(results))
+	}
+	cli.PrintGrepResults(os.Stdout, results, re, cli.TerminalMarkdown(false))
+	return nil
+}
+
+func grepTable(results []cli.GrepResult) cli.Table {
+	t := cli.Table{Columns: []string{"CARD", "NAME", "LIST", "FIELD", "TEXT"}, Empty: "No matches."}
+	for _, r := range results {
+		for _, m := range r.Matches {
+			t.Rows = append(t.Rows, []string{r.ID, r.Name, r.List, m.Field, m.Text})
+		}
+	}
+	return t
+}
@@ -0,0 +1,39 @@
+package grep
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// TestMain registers the command as main does, for the tests that run it
+// through the CLI.
+func TestMain(m *testing.M) {
+	cli.Register(Command)
+	os.Exit(m.Run())
+}
+
+var grepRoutes = map[string]string{
+	"/1/boards/b1/checklists": `[{"id": "k1", "name": "Steps", "idCard": "c1", "checkItems": [{"id": "i1", "name": "Reproduce the logout", "state": "complete"}]}]`,
+	"/1/boards/b1/actions": `[
+		{"id": "a2", "type": "commentCard", "date": "2026-02-12T08:00:00.000Z", "data": {"text": "Release notes drafted.\nThe logout fix goes in too.", "card": {"id": "c2"}}, "memberCreator": {"username": "grace", "fullName": "Grace Hopper"}},
+		{"id": "a1", "type": "commentCard", "date": "2026-02-10T09:30:00.000Z", "data": {"text": "Seen on staging too.", "card": {"id": "c1"}}, "memberCreator": {"username": "ada", "fullName": "Ada Lovelace"}}
+	]`,
+}
+
+func TestGrep(t *testing.T) {
+	clitest.WithStubRoutes(t, grepRoutes)
+	stub := clitest.NewStub(t)
+	clitest.CheckGolden(t, "grep", clitest.RunCLI(t, stub, "grep", "LOG"))
+	clitest.CheckGolden(t, "grep_regex", clitest.RunCLI(t, stub, "--json", "grep", "--board", "b1", "-E", `log(ged)? ?out`, "--in", "comments,checklists"))
+	clitest.CheckGolden(t, "grep_no_matches", clitest.RunCLI(t, stub, "grep", "--case-sensitive", "staging", "--in", "name,desc"))
+	if got := clitest.RunCLI(t, stub, "grep", "x", "--in", "labels"); !strings.Contains(got, `invalid --in "labels"`) {
+		t.Errorf("unknown field: %s", got)
+	}
+	if got := clitest.RunCLI(t, stub, "grep", "--regex", "("); !strings.Contains(got, "invalid pattern") {
+		t.Errorf("bad regex: %s", got)
+	}
+}
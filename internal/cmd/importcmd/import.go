@@ -0,0 +1,122 @@
+// Package importcmd implements "trelli import": import issues from other trackers as cards.
+package importcmd
+
+import (
+	"fmt"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "import",
+	Summary: "Import issues from other trackers as cards",
+	Description: `jira reads issues from a Jira CSV export (--file) or the Jira REST API
+(--url with --jql or --project) and creates a card for each on the board:
+statuses become lists and components become labels, matched by name and
+created when missing. The columns or fields read, and the list and label
+each status and component maps to, come from the import.jira.* config
+keys. Issues are linked to their cards by an attachment when their URL
+is known (with --url), so importing again skips them.
+
+taskwarrior reads the output of task export (a file or stdin) and creates
+a card for each pending or waiting task: projects become lists and tags
+become labels (underscores turned into spaces), created when missing;
+annotations form the description. Tasks exported from Trello (with a
+trelloid attribute) are skipped.
+
+trello-export reads a board's JSON export (in Trello: Menu > Print,
+export, and share > Export as JSON) and recreates it on another board,
+for example one in another account: the open lists in order, the named
+labels with their colors, and the open cards of the open lists with their
+labels, due dates, checklists, and comments. Comments are posted by you,
+starting with their author and date; the export holds only the latest
+1000 actions, so older comments are missing. Each card links to the card
+it came from, so importing again skips it.
+
+markdown turns the top-level items of the Markdown lists in a file, such
+as meeting notes, into cards in one list (--list-name, default Backlog):
+items nested under one become checklist items, checked when their box is
+[x], and other indented text under it the description. Checked top-level
+items are skipped unless --include-done.
+
+todotxt reads a todo.txt file: each open task becomes a card in the list
+of its first +project (or --list-name), with its @contexts and priority
+("Priority A") as labels and due:YYYY-MM-DD as due date. Completed tasks
+(starting with "x ") are skipped.
+
+Markdown and todo.txt files carry no ids, so importing one again creates
+the cards again.
+
+With --dry-run nothing is written; the plan is printed instead.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "jira", Usage: []string{
+			"jira --file <export.csv> [--board <id>] [--url <jira-url>] [--list-name <name>]",
+			"jira --url <jira-url> (--jql <query> | --project <key>) [--jira-user <email>] [--jira-token <token>] [--board <id>] [--list-name <name>]",
+		}, Flags: []cli.Flag{
+			{Name: "file", Arg: "path", Desc: "Jira CSV export (Export > CSV (all fields))"},
+			{Name: "url", Arg: "url", Desc: "Jira base URL, e.g. https://acme.atlassian.net; links cards to issues"},
+			{Name: "jql", Arg: "query", Desc: "JQL selecting the issues to import over REST"},
+			{Name: "project", Arg: "key", Desc: "Import every issue of a project over REST (JQL project = <key>)"},
+			{Name: "jira-user", Arg: "email", Desc: "Jira Cloud account email for basic auth (default $JIRA_USER)"},
+			{Name: "jira-token", Arg: "token", Desc: "Jira API token, or personal access token without --jira-user (default $JIRA_API_TOKEN)"},
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "list-name", Arg: "name", Desc: "List for issues without a status (default Backlog)"},
+		}},
+		{Name: "taskwarrior", Usage: []string{"taskwarrior [[--file] <tasks.json>] [--board <id>] [--list-name <name>]"}, Flags: []cli.Flag{
+			{Name: "file", Arg: "path", Desc: "Output of task export (default: stdin)"},
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "list-name", Arg: "name", Desc: "List for tasks without a project (default Backlog)"},
+		}},
+		{Name: "markdown", Usage: []string{"markdown [[--file] <notes.md>] [--board <id>] [--list-name <name>] [--include-done]"}, Flags: []cli.Flag{
+			{Name: "file", Arg: "path", Desc: "Markdown file (default: stdin)"},
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "list-name", Arg: "name", Desc: "List for the cards, created when missing (default Backlog)"},
+			{Name: "include-done", Desc: "Also import checked top-level items"},
+		}},
+		{Name: "todotxt", Usage: []string{"todotxt [[--file] <todo.txt>] [--board <id>] [--list-name <name>]"}, Flags: []cli.Flag{
+			{Name: "file", Arg: "path", Desc: "todo.txt file (default: stdin)"},
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "list-name", Arg: "name", Desc: "List for tasks without a +project (default Backlog)"},
+		}},
+		{Name: "trello-export", Usage: []string{"trello-export [--file] <export.json> [--board <id>]"}, Flags: []cli.Flag{
+			{Name: "file", Arg: "path", Desc: "Trello board export (default: stdin)"},
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+		}},
+	},
+	Options: []cli.Flag{cli.JSONOption},
+	Sections: []cli.HelpSection{
+		{Title: "Field mapping", Body: `trelli config set import.jira.fields.due "Target end"
+trelli config set "import.jira.statuses.In Progress" Doing
+trelli config set import.jira.components.Backend API`},
+		{Title: "Taskwarrior", Body: `task export project:Work | trelli import taskwarrior --board EnGi`},
+		{Title: "Meeting notes", Body: `trelli import markdown --file notes.md --list-name "Backlog"`},
+		{Title: "Moving a board", Body: `trelli --dry-run import trello-export --file old-board.json --board NeWb
+trelli import trello-export --file old-board.json --board NeWb`},
+	},
+	Run: runImport,
+}
+
+func runImport(client *cli.Client, cfg cli.Config, args []string) error {
+	if len(args) == 0 {
+		cli.PrintCommandHelp("import")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("import")
+		return nil
+	case "jira":
+		return runImportJira(cfg.Context, client, cfg, args[1:])
+	case "taskwarrior":
+		return runImportTaskwarrior(cfg.Context, client, cfg, args[1:])
+	case "markdown":
+		return runImportMarkdown(cfg.Context, client, cfg, args[1:])
+	case "todotxt":
+		return runImportTodoTxt(cfg.Context, client, cfg, args[1:])
+	case "trello-export":
+		return runImportTrelloExport(cfg.Context, client, cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown import subcommand %q", args[0])
+	}
+}
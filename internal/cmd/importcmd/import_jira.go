@@ -0,0 +1,364 @@
+package importcmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+// jiraFieldNames are the card fields an import reads from Jira.
+var jiraFieldNames = []string{"key", "summary", "description", "status", "components", "due"}
+
+// Default Jira CSV columns and REST field ids, by card field; the
+// import.jira.fields.* config keys override them.
+var (
+	jiraCSVColumns = map[string]string{
+		"key":         "Issue key",
+		"summary":     "Summary",
+		"description": "Description",
+		"status":      "Status",
+		"components":  "Component/s",
+		"due":         "Due date",
+	}
+	jiraRESTFields = map[string]string{
+		"key":         "key",
+		"summary":     "summary",
+		"description": "description",
+		"status":      "status",
+		"components":  "components",
+		"due":         "duedate",
+	}
+)
+
+// jiraIssue holds the mapped fields of one issue; components may repeat.
+type jiraIssue map[string][]string
+
+func (i jiraIssue) get(field string) string {
+	if v := i[field]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// jiraDueLayouts are the date formats of REST responses and common CSV
+// export locales.
+var jiraDueLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02T15:04:05.000-0700",
+	"02/Jan/06 3:04 PM",
+	"2/Jan/06 3:04 PM",
+	"02/Jan/06",
+	"2/Jan/06",
+}
+
+func runImportJira(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("import jira", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var file, baseURL, jql, project string
+	user := os.Getenv("JIRA_USER")
+	token := os.Getenv("JIRA_API_TOKEN")
+	boardID := cfg.BoardID
+	listName := "Backlog"
+	fs.StringVar(&file, "file", "", "Jira CSV export")
+	fs.StringVar(&baseURL, "url", "", "Jira base URL")
+	fs.StringVar(&jql, "jql", "", "JQL selecting the issues")
+	fs.StringVar(&project, "project", "", "Jira project key")
+	fs.StringVar(&user, "jira-user", user, "Jira account email")
+	fs.StringVar(&token, "jira-token", token, "Jira API token")
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&listName, "list-name", listName, "List for issues without a status")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("import")); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	boardID = cfg.File.ResolveBoardAlias(boardID)
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+	baseURL = strings.TrimSuffix(strings.TrimSpace(baseURL), "/")
+	if jql == "" && project != "" {
+		jql = "project = " + strconv.Quote(project)
+	}
+
+	overrides := cfg.File.GetStringMap("import.jira.fields")
+	var issues []jiraIssue
+	switch {
+	case file != "":
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if issues, err = readJiraCSV(f, jiraFieldMap(jiraCSVColumns, overrides)); err != nil {
+			return fmt.Errorf("reading %s: %w", file, err)
+		}
+	case baseURL == "":
+		return errors.New("import jira requires --file or --url")
+	case jql == "":
+		return errors.New("import jira over REST requires --jql or --project")
+	default:
+		transport, err := cli.NewTransport(cfg)
+		if err != nil {
+			return err
+		}
+		jc := &jiraClient{base: baseURL, user: user, token: token, http: &http.Client{Transport: transport, Timeout: cfg.Timeout}}
+		if issues, err = jc.search(ctx, jql, jiraFieldMap(jiraRESTFields, overrides)); err != nil {
+			return err
+		}
+	}
+
+	items := jiraImportItems(issues, baseURL, listName, cfg.File.GetStringMap("import.jira.statuses"), cfg.File.GetStringMap("import.jira.components"))
+	steps, err := cli.ImportItems(ctx, client, cfg, boardID, cli.ImportLayout{}, items)
+	if err != nil {
+		return err
+	}
+	return cli.Render(cfg, cli.NonNil(steps), cli.ImportTable(steps))
+}
+
+// jiraFieldMap returns defaults with the configured overrides applied.
+func jiraFieldMap(defaults, overrides map[string]string) map[string]string {
+	m := map[string]string{}
+	for _, field := range jiraFieldNames {
+		m[field] = defaults[field]
+		for k, v := range overrides {
+			if strings.EqualFold(k, field) {
+				m[field] = v
+			}
+		}
+	}
+	return m
+}
+
+// jiraImportItems maps issues to cards: statuses to lists and components
+// to labels through the configured mappings (keys matched
+// case-insensitively), defaulting to the same name.
+func jiraImportItems(issues []jiraIssue, baseURL, defaultList string, statuses, components map[string]string) []cli.ImportItem {
+	mapped := func(m map[string]string, name string) string {
+		for k, v := range m {
+			if strings.EqualFold(k, name) {
+				return v
+			}
+		}
+		return name
+	}
+	items := make([]cli.ImportItem, 0, len(issues))
+	for _, issue := range issues {
+		key := issue.get("key")
+		item := cli.ImportItem{
+			Key:  key,
+			Name: cli.FirstNonEmpty(issue.get("summary"), key),
+			Desc: issue.get("description"),
+			List: cli.FirstNonEmpty(mapped(statuses, issue.get("status")), defaultList),
+		}
+		for _, c := range issue["components"] {
+			item.Labels = append(item.Labels, mapped(components, c))
+		}
+		if due := issue.get("due"); due != "" {
+			item.Due = parseJiraDue(due)
+			if item.Due == "" {
+				slog.Warn(fmt.Sprintf("%s: ignoring due date %q in an unknown format", cli.FirstNonEmpty(key, item.Name), due), "issue", key)
+			}
+		}
+		if baseURL != "" && key != "" {
+			item.Link = baseURL + "/browse/" + key
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func parseJiraDue(s string) string {
+	for _, layout := range jiraDueLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return ""
+}
+
+// readJiraCSV reads a Jira CSV export. Jira repeats a column for every
+// value of a multi-valued field, so all columns with a mapped header
+// contribute values.
+func readJiraCSV(r io.Reader, columns map[string]string) ([]jiraIssue, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	indexes := map[string][]int{}
+	for field, column := range columns {
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), column) {
+				indexes[field] = append(indexes[field], i)
+			}
+		}
+	}
+	if len(indexes["summary"]) == 0 && len(indexes["key"]) == 0 {
+		return nil, fmt.Errorf("no %q or %q column: is this a Jira CSV export?", columns["summary"], columns["key"])
+	}
+	var issues []jiraIssue
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return issues, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		issue := jiraIssue{}
+		for field, idx := range indexes {
+			for _, i := range idx {
+				if i < len(record) && strings.TrimSpace(record[i]) != "" {
+					issue[field] = append(issue[field], strings.TrimSpace(record[i]))
+				}
+			}
+		}
+		issues = append(issues, issue)
+	}
+}
+
+// jiraClient reads issues over the Jira REST API (v2, which Jira Cloud and
+// Data Center share). Credentials are only sent in the Authorization
+// header: basic auth with user and API token, or the token alone as a
+// personal access token.
+type jiraClient struct {
+	base  string
+	user  string
+	token string
+	http  *http.Client
+}
+
+// jiraPageSize is the most issues Jira returns per search page.
+const jiraPageSize = 100
+
+func (j *jiraClient) search(ctx context.Context, jql string, fields map[string]string) ([]jiraIssue, error) {
+	ids := make([]string, 0, len(fields))
+	for _, id := range fields {
+		if id != "key" {
+			ids = append(ids, id)
+		}
+	}
+	var issues []jiraIssue
+	for start := 0; ; {
+		query := url.Values{}
+		query.Set("jql", jql)
+		query.Set("fields", strings.Join(ids, ","))
+		query.Set("startAt", strconv.Itoa(start))
+		query.Set("maxResults", strconv.Itoa(jiraPageSize))
+		var page struct {
+			Total  int `json:"total"`
+			Issues []struct {
+				Key    string                     `json:"key"`
+				Fields map[string]json.RawMessage `json:"fields"`
+			} `json:"issues"`
+		}
+		if err := j.get(ctx, "/rest/api/2/search", query, &page); err != nil {
+			return nil, err
+		}
+		for _, raw := range page.Issues {
+			issue := jiraIssue{}
+			for field, id := range fields {
+				if id == "key" {
+					issue[field] = []string{raw.Key}
+					continue
+				}
+				issue[field] = jiraValues(raw.Fields[id])
+			}
+			issues = append(issues, issue)
+		}
+		start += len(page.Issues)
+		if len(page.Issues) == 0 || start >= page.Total {
+			return issues, nil
+		}
+	}
+}
+
+// jiraValues flattens a REST field value to strings: text as is, objects
+// such as statuses and components by name (or value, for select fields),
+// and arrays element by element.
+func jiraValues(raw json.RawMessage) []string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		if s = strings.TrimSpace(s); s != "" {
+			return []string{s}
+		}
+		return nil
+	}
+	var obj struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	if json.Unmarshal(raw, &obj) == nil {
+		if v := cli.FirstNonEmpty(obj.Name, obj.Value); v != "" {
+			return []string{v}
+		}
+		return nil
+	}
+	var arr []json.RawMessage
+	if json.Unmarshal(raw, &arr) != nil {
+		return nil
+	}
+	var out []string
+	for _, el := range arr {
+		out = append(out, jiraValues(el)...)
+	}
+	return out
+}
+
+func (j *jiraClient) get(ctx context.Context, p string, query url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.base+p+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case j.user != "":
+		req.SetBasicAuth(j.user, j.token)
+	case j.token != "":
+		req.Header.Set("Authorization", "Bearer "+j.token)
+	}
+	resp, err := j.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			ErrorMessages []string `json:"errorMessages"`
+		}
+		_ = json.Unmarshal(raw, &e)
+		return fmt.Errorf("jira: GET %s: %s (%d)", p, cli.FirstNonEmpty(strings.Join(e.ErrorMessages, "; "), http.StatusText(resp.StatusCode)), resp.StatusCode)
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("jira: decoding %s: %w", p, err)
+	}
+	return nil
+}
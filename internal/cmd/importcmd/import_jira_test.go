@@ -0,0 +1,74 @@
+package importcmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+func TestImportJiraREST(t *testing.T) {
+	stub := clitest.NewStub(t)
+	var queries []string
+	jira := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if user, token, ok := r.BasicAuth(); !ok || user != "ada@example.com" || token != "jira-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"errorMessages": ["You are not authenticated."]}`)
+			return
+		}
+		if r.URL.Path != "/rest/api/2/search" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		q := r.URL.Query()
+		queries = append(queries, q.Get("jql")+" @"+q.Get("startAt"))
+		// Two pages of one issue each.
+		if q.Get("startAt") == "0" {
+			io.WriteString(w, `{"total": 2, "issues": [{"key": "PROJ-1", "fields": {"summary": "Fix login", "status": {"name": "to do"}, "components": [{"name": "Backend"}], "duedate": "2026-03-01"}}]}`)
+			return
+		}
+		io.WriteString(w, `{"total": 2, "issues": [{"key": "PROJ-7", "fields": {"summary": "Dark mode", "description": "Please.", "status": {"name": "Selected"}, "components": [], "duedate": null}}]}`)
+	}))
+	t.Cleanup(jira.Close)
+
+	t.Setenv("JIRA_API_TOKEN", "jira-token")
+	cfg, _, err := clitest.TestConfig(t, stub, "--dry-run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.File = cli.FileConfig{"import": map[string]any{"jira": map[string]any{
+		"statuses":   map[string]any{"selected": "Done"},
+		"components": map[string]any{"backend": "Bug"},
+	}}}
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := clitest.CaptureStdout(t)
+	err = runImport(client, cfg, []string{"jira", "--url", jira.URL, "--project", "PROJ", "--jira-user", "ada@example.com"})
+	got := stdout()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clitest.CheckGolden(t, "import_jira_rest", got)
+	if want := []string{`project = "PROJ" @0`, `project = "PROJ" @1`}; len(queries) != 2 || queries[0] != want[0] || queries[1] != want[1] {
+		t.Errorf("searches = %q, want %q", queries, want)
+	}
+}
+
+func TestParseJiraDue(t *testing.T) {
+	for in, want := range map[string]string{
+		"2026-03-01":                   "2026-03-01T00:00:00Z",
+		"15/Mar/26 12:00 AM":           "2026-03-15T00:00:00Z",
+		"2026-03-01T10:00:00.000+0200": "2026-03-01T08:00:00Z",
+		"next week":                    "",
+	} {
+		if got := parseJiraDue(in); got != want {
+			t.Errorf("parseJiraDue(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package importcmd
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+// markdownItemPattern matches a list item: indentation, the bullet or
+// number, an optional task checkbox, and the text.
+var markdownItemPattern = regexp.MustCompile(`^(\s*)(?:[-*+]|\d+[.)])\s+(?:\[([ xX])\]\s+)?(.*)$`)
+
+// markdownCard is a top-level list item with what is nested under it.
+type markdownCard struct {
+	Line  int
+	Name  string
+	Done  bool
+	Desc  []string
+	Items []cli.ChecklistItem
+}
+
+func runImportMarkdown(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("import markdown", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var file string
+	var includeDone bool
+	boardID := cfg.BoardID
+	listName := "Backlog"
+	fs.StringVar(&file, "file", "", "Markdown file (default: stdin)")
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&listName, "list-name", listName, "List for the cards")
+	fs.BoolVar(&includeDone, "include-done", false, "Also import checked top-level items")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("import")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &file); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(boardID)
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+
+	in, err := OpenFile(file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	parsed, err := readMarkdownCards(in)
+	if err != nil {
+		return err
+	}
+	var items []cli.ImportItem
+	var skipped []cli.ImportStep
+	for _, c := range parsed {
+		key := fmt.Sprintf("line %d", c.Line)
+		if c.Done && !includeDone {
+			skipped = append(skipped, cli.ImportStep{Action: "skip (done)", Issue: key, Name: c.Name, List: listName})
+			continue
+		}
+		item := cli.ImportItem{Key: key, Name: c.Name, List: listName, Desc: strings.Join(c.Desc, "\n")}
+		if len(c.Items) > 0 {
+			item.Checklists = []cli.Checklist{{Name: "Checklist", CheckItems: c.Items}}
+		}
+		items = append(items, item)
+	}
+	steps, err := cli.ImportItems(ctx, client, cfg, boardID, cli.ImportLayout{}, items)
+	if err != nil {
+		return err
+	}
+	steps = append(steps, skipped...)
+	return cli.Render(cfg, cli.NonNil(steps), cli.ImportTable(steps))
+}
+
+// readMarkdownCards returns the top-level list items of a Markdown
+// document. Items nested under one become its checklist items, checked
+// when their box is; other indented text under it becomes its
+// description. Headings, paragraphs, and fenced code end an item.
+func readMarkdownCards(r io.Reader) ([]markdownCard, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	var cards []markdownCard
+	var current *markdownCard
+	indent := -1 // of the current top-level item
+	fenced := false
+	for n := 1; sc.Scan(); n++ {
+		line := strings.ReplaceAll(sc.Text(), "\t", "    ")
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			current = nil
+			continue
+		}
+		if fenced || trimmed == "" {
+			continue
+		}
+		if m := markdownItemPattern.FindStringSubmatch(line); m != nil {
+			depth := len(m[1])
+			text := strings.TrimSpace(m[3])
+			if current == nil || depth <= indent {
+				if text == "" {
+					current = nil
+					continue
+				}
+				cards = append(cards, markdownCard{Line: n, Name: text, Done: m[2] == "x" || m[2] == "X"})
+				current, indent = &cards[len(cards)-1], depth
+				continue
+			}
+			if text != "" {
+				state := "incomplete"
+				if m[2] == "x" || m[2] == "X" {
+					state = "complete"
+				}
+				current.Items = append(current.Items, cli.ChecklistItem{Name: text, State: state})
+			}
+			continue
+		}
+		if current != nil && len(line)-len(strings.TrimLeft(line, " ")) > indent {
+			current.Desc = append(current.Desc, trimmed)
+			continue
+		}
+		current = nil
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading markdown: %w", err)
+	}
+	return cards, nil
+}
+
+// todoTxtTask is one line of a todo.txt file.
+type todoTxtTask struct {
+	Line     int
+	Done     bool
+	Priority string
+	Text     string
+	Projects []string
+	Contexts []string
+	Due      string
+}
+
+var (
+	todoTxtPriorityPattern = regexp.MustCompile(`^\(([A-Z])\)\s+`)
+	todoTxtDatePattern     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}\s+`)
+)
+
+func runImportTodoTxt(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("import todotxt", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var file string
+	boardID := cfg.BoardID
+	listName := "Backlog"
+	fs.StringVar(&file, "file", "", "todo.txt file (default: stdin)")
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&listName, "list-name", listName, "List for tasks without a project")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("import")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &file); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(boardID)
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+
+	in, err := OpenFile(file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tasks, err := readTodoTxt(in)
+	if err != nil {
+		return err
+	}
+	var items []cli.ImportItem
+	for _, t := range tasks {
+		if t.Done {
+			continue
+		}
+		item := cli.ImportItem{Key: fmt.Sprintf("line %d", t.Line), Name: t.Text, List: listName, Due: t.Due}
+		if len(t.Projects) > 0 {
+			item.List = t.Projects[0]
+		}
+		if t.Priority != "" {
+			item.Labels = append(item.Labels, "Priority "+t.Priority)
+		}
+		item.Labels = append(item.Labels, t.Contexts...)
+		items = append(items, item)
+	}
+	steps, err := cli.ImportItems(ctx, client, cfg, boardID, cli.ImportLayout{}, items)
+	if err != nil {
+		return err
+	}
+	return cli.Render(cfg, cli.NonNil(steps), cli.ImportTable(steps))
+}
+
+// readTodoTxt parses todo.txt lines: "x " marks completed tasks, "(A) "
+// the priority, then an optional creation date. +project and @context
+// words are taken out of the text (underscores turned into spaces), and
+// so is due:YYYY-MM-DD; other key:value tags stay in the text.
+func readTodoTxt(r io.Reader) ([]todoTxtTask, error) {
+	sc := bufio.NewScanner(r)
+	var tasks []todoTxtTask
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		t := todoTxtTask{Line: n}
+		if rest, ok := strings.CutPrefix(line, "x "); ok {
+			t.Done = true
+			// A completion date precedes the creation date.
+			line = todoTxtDatePattern.ReplaceAllString(strings.TrimSpace(rest), "")
+		}
+		if m := todoTxtPriorityPattern.FindStringSubmatch(line); m != nil {
+			t.Priority = m[1]
+			line = line[len(m[0]):]
+		}
+		line = todoTxtDatePattern.ReplaceAllString(line, "")
+		var words []string
+		for _, w := range strings.Fields(line) {
+			switch {
+			case len(w) > 1 && w[0] == '+':
+				t.Projects = append(t.Projects, strings.ReplaceAll(w[1:], "_", " "))
+			case len(w) > 1 && w[0] == '@':
+				t.Contexts = append(t.Contexts, strings.ReplaceAll(w[1:], "_", " "))
+			case strings.HasPrefix(w, "due:"):
+				due, err := time.ParseInLocation("2006-01-02", strings.TrimPrefix(w, "due:"), time.Local)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid due date %q", n, strings.TrimPrefix(w, "due:"))
+				}
+				t.Due = due.Format(time.RFC3339)
+			default:
+				words = append(words, w)
+			}
+		}
+		t.Text = strings.Join(words, " ")
+		if t.Text == "" {
+			return nil, fmt.Errorf("line %d: task has no text", n)
+		}
+		tasks = append(tasks, t)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading todo.txt: %w", err)
+	}
+	return tasks, nil
+}
+
+// OpenFile opens file, or stdin when it is empty or -.
+func OpenFile(file string) (io.ReadCloser, error) {
+	if file == "" || file == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(file)
+}
@@ -0,0 +1,58 @@
+package importcmd
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+)
+
+func TestReadMarkdownCards(t *testing.T) {
+	f, err := os.Open("testdata/markdown/notes.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	cards, err := readMarkdownCards(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []markdownCard{
+		{Line: 7, Name: "Fix login timeout", Desc: []string{"Users are logged out after 5 minutes."}, Items: []cli.ChecklistItem{
+			{Name: "Reproduce on staging", State: "complete"},
+			{Name: "Raise session lifetime", State: "incomplete"},
+		}},
+		{Line: 11, Name: "Write release notes", Done: true},
+		{Line: 12, Name: "Plan the offsite", Items: []cli.ChecklistItem{
+			{Name: "Pick a date", State: "incomplete"},
+			{Name: "Book the venue", State: "incomplete"},
+			{Name: "ask for a projector", State: "incomplete"},
+		}},
+	}
+	if !reflect.DeepEqual(cards, want) {
+		t.Errorf("readMarkdownCards =\n%+v\nwant\n%+v", cards, want)
+	}
+}
+
+func TestReadTodoTxt(t *testing.T) {
+	tasks, err := readTodoTxt(strings.NewReader("x 2026-01-25 2026-01-20 Done +Home\n\n(A) 2026-01-20 Call +Office_Admin @phone due:2026-02-10 id:7\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("got %d tasks, want 2", len(tasks))
+	}
+	if d := tasks[0]; !d.Done || d.Text != "Done" {
+		t.Errorf("done task = %+v", d)
+	}
+	got := tasks[1]
+	if got.Line != 3 || got.Priority != "A" || got.Text != "Call id:7" || got.Due == "" ||
+		!reflect.DeepEqual(got.Projects, []string{"Office Admin"}) || !reflect.DeepEqual(got.Contexts, []string{"phone"}) {
+		t.Errorf("task = %+v", got)
+	}
+	if _, err := readTodoTxt(strings.NewReader("Pay rent due:soon\n")); err == nil {
+		t.Error("readTodoTxt accepted an invalid due date")
+	}
+}
@@ -0,0 +1,120 @@
+package importcmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+	"trelli/internal/cmd/export"
+)
+
+// runImportTaskwarrior creates cards from the pending tasks of a task
+// export: projects become lists and tags labels. Tasks that came from
+// Trello (with a trelloid) are skipped.
+func runImportTaskwarrior(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("import taskwarrior", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var file string
+	boardID := cfg.BoardID
+	listName := "Backlog"
+	fs.StringVar(&file, "file", "", "task export output (default: stdin)")
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&listName, "list-name", listName, "List for tasks without a project")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("import")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &file); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(boardID)
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+
+	var in io.Reader = os.Stdin
+	if file != "" && file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	tasks, err := readTaskwarrior(in)
+	if err != nil {
+		return err
+	}
+	var items []cli.ImportItem
+	for _, t := range tasks {
+		if t.TrelloID != "" || (t.Status != "pending" && t.Status != "waiting") {
+			continue
+		}
+		item := cli.ImportItem{
+			Key:  shortUUID(t.UUID),
+			Name: t.Description,
+			List: cli.FirstNonEmpty(t.Project, listName),
+		}
+		// Export joins the words of label names with underscores.
+		for _, tag := range t.Tags {
+			item.Labels = append(item.Labels, strings.ReplaceAll(tag, "_", " "))
+		}
+		if due, err := time.Parse(export.TaskwarriorTime, t.Due); err == nil {
+			item.Due = due.Format(time.RFC3339)
+		}
+		var notes []string
+		for _, a := range t.Annotations {
+			notes = append(notes, a.Description)
+		}
+		item.Desc = strings.Join(notes, "\n\n")
+		items = append(items, item)
+	}
+	steps, err := cli.ImportItems(ctx, client, cfg, boardID, cli.ImportLayout{}, items)
+	if err != nil {
+		return err
+	}
+	return cli.Render(cfg, cli.NonNil(steps), cli.ImportTable(steps))
+}
+
+// readTaskwarrior reads task export output: a JSON array, or one task
+// object per line as older versions and cardTask write.
+func readTaskwarrior(r io.Reader) ([]export.TaskwarriorTask, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var tasks []export.TaskwarriorTask
+	for {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			return tasks, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading tasks: %w", err)
+		}
+		if strings.HasPrefix(strings.TrimSpace(string(raw)), "[") {
+			var page []export.TaskwarriorTask
+			if err := json.Unmarshal(raw, &page); err != nil {
+				return nil, fmt.Errorf("reading tasks: %w", err)
+			}
+			tasks = append(tasks, page...)
+			continue
+		}
+		var t export.TaskwarriorTask
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return nil, fmt.Errorf("reading tasks: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+}
+
+// shortUUID returns the first block of a UUID, as task lists show it.
+func shortUUID(uuid string) string {
+	head, _, _ := strings.Cut(uuid, "-")
+	return head
+}
@@ -0,0 +1,13 @@
+package importcmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadTaskwarriorLines(t *testing.T) {
+	tasks, err := readTaskwarrior(strings.NewReader("{\"description\":\"a\",\"status\":\"pending\"}\n{\"description\":\"b\",\"status\":\"pending\"}\n"))
+	if err != nil || len(tasks) != 2 || tasks[1].Description != "b" {
+		t.Errorf("readTaskwarrior = %+v, %v", tasks, err)
+	}
+}
@@ -0,0 +1,197 @@
+package importcmd
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+// trelloExport is the part of a board's "Export as JSON" that import
+// trello-export reads.
+type trelloExport struct {
+	ID         string                  `json:"id"`
+	Name       string                  `json:"name"`
+	ShortLink  string                  `json:"shortLink"`
+	Lists      []cli.TrelloList        `json:"lists"`
+	Labels     []cli.Label             `json:"labels"`
+	Cards      []trelloExportCard      `json:"cards"`
+	Checklists []trelloExportChecklist `json:"checklists"`
+	Actions    []trelloExportAction    `json:"actions"`
+}
+
+type trelloExportCard struct {
+	cli.Card
+	ShortLink   string   `json:"shortLink"`
+	IDLabels    []string `json:"idLabels"`
+	DueComplete bool     `json:"dueComplete"`
+	Pos         float64  `json:"pos"`
+}
+
+type trelloExportChecklist struct {
+	cli.Checklist
+	IDCard string  `json:"idCard"`
+	Pos    float64 `json:"pos"`
+}
+
+type trelloExportAction struct {
+	Type string `json:"type"`
+	Date string `json:"date"`
+	Data struct {
+		Text string `json:"text"`
+		Card struct {
+			ID string `json:"id"`
+		} `json:"card"`
+	} `json:"data"`
+	MemberCreator cli.Member `json:"memberCreator"`
+}
+
+// runImportTrelloExport recreates the open lists and cards of a board
+// export, with their labels, checklists, and comments, on another board.
+func runImportTrelloExport(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("import trello-export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var file string
+	boardID := cfg.BoardID
+	fs.StringVar(&file, "file", "", "Board export (default: stdin)")
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("import")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &file); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(boardID)
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+
+	var in io.Reader = os.Stdin
+	if file != "" && file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	export, err := readTrelloExport(in)
+	if err != nil {
+		return err
+	}
+	if boardID == export.ID || boardID == export.ShortLink {
+		return fmt.Errorf("%s is the exported board itself; import into another board", boardID)
+	}
+	layout, items := trelloExportItems(export)
+	steps, err := cli.ImportItems(ctx, client, cfg, boardID, layout, items)
+	if err != nil {
+		return err
+	}
+	return cli.Render(cfg, cli.NonNil(steps), cli.ImportTable(steps))
+}
+
+func readTrelloExport(r io.Reader) (trelloExport, error) {
+	var export trelloExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return trelloExport{}, fmt.Errorf("reading board export: %w", err)
+	}
+	if export.ID == "" || export.Lists == nil || export.Cards == nil {
+		return trelloExport{}, errors.New("reading board export: not a Trello board export (Menu > Print, export, and share > Export as JSON)")
+	}
+	return export, nil
+}
+
+// trelloExportItems maps an export to the lists and labels to create, in
+// board order, and a card per open card of an open list. Labels without a
+// name are left out: imports match labels by name.
+func trelloExportItems(export trelloExport) (cli.ImportLayout, []cli.ImportItem) {
+	var layout cli.ImportLayout
+	lists := slices.Clone(export.Lists)
+	slices.SortStableFunc(lists, func(a, b cli.TrelloList) int { return cmp.Compare(a.Pos, b.Pos) })
+	listOrder := map[string]int{}
+	listNames := map[string]string{}
+	for _, l := range lists {
+		if l.Closed {
+			continue
+		}
+		listOrder[l.ID] = len(layout.Lists)
+		listNames[l.ID] = l.Name
+		layout.Lists = append(layout.Lists, l.Name)
+	}
+	labelNames := map[string]string{}
+	for _, l := range export.Labels {
+		if l.Name != "" {
+			labelNames[l.ID] = l.Name
+			layout.Labels = append(layout.Labels, l)
+		}
+	}
+
+	checklists := map[string][]trelloExportChecklist{}
+	for _, cl := range export.Checklists {
+		checklists[cl.IDCard] = append(checklists[cl.IDCard], cl)
+	}
+	// Actions are newest first; comments are added oldest first.
+	comments := map[string][]string{}
+	for i := len(export.Actions) - 1; i >= 0; i-- {
+		a := export.Actions[i]
+		if a.Type != "commentCard" {
+			continue
+		}
+		comments[a.Data.Card.ID] = append(comments[a.Data.Card.ID], ImportedComment(cli.FirstNonEmpty(a.MemberCreator.FullName, a.MemberCreator.Username), a.Date, a.Data.Text))
+	}
+
+	var cards []trelloExportCard
+	for _, c := range export.Cards {
+		if _, ok := listOrder[c.IDList]; ok && !c.Closed {
+			cards = append(cards, c)
+		}
+	}
+	slices.SortStableFunc(cards, func(a, b trelloExportCard) int {
+		return cmp.Or(cmp.Compare(listOrder[a.IDList], listOrder[b.IDList]), cmp.Compare(a.Pos, b.Pos))
+	})
+	var items []cli.ImportItem
+	for _, c := range cards {
+		item := cli.ImportItem{
+			Key:         cli.FirstNonEmpty(c.ShortLink, c.ID),
+			Name:        c.Name,
+			Desc:        c.Desc,
+			List:        listNames[c.IDList],
+			Due:         c.Due,
+			DueComplete: c.DueComplete,
+			Link:        cli.FirstNonEmpty(c.ShortURL, c.URL),
+			Comments:    comments[c.ID],
+		}
+		for _, id := range c.IDLabels {
+			if name, ok := labelNames[id]; ok {
+				item.Labels = append(item.Labels, name)
+			}
+		}
+		cls := checklists[c.ID]
+		slices.SortStableFunc(cls, func(a, b trelloExportChecklist) int { return cmp.Compare(a.Pos, b.Pos) })
+		for _, cl := range cls {
+			slices.SortStableFunc(cl.CheckItems, func(a, b cli.ChecklistItem) int { return cmp.Compare(a.Pos, b.Pos) })
+			item.Checklists = append(item.Checklists, cl.Checklist)
+		}
+		items = append(items, item)
+	}
+	return layout, items
+}
+
+// ImportedComment keeps the author and date of a comment, which is posted
+// again as whoever runs the import.
+func ImportedComment(author, date, text string) string {
+	when := date
+	if t, err := time.Parse(time.RFC3339, date); err == nil {
+		when = t.UTC().Format("2006-01-02 15:04 UTC")
+	}
+	return fmt.Sprintf("%s wrote on %s:\n\n%s", cli.FirstNonEmpty(author, "Someone"), when, text)
+}
@@ -0,0 +1,53 @@
+package importcmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// TestMain registers the command as main does, for the tests that run it
+// through the CLI.
+func TestMain(m *testing.M) {
+	cli.Register(Command)
+	os.Exit(m.Run())
+}
+
+func TestImportTrelloExport(t *testing.T) {
+	stub := clitest.NewStub(t)
+	var writes []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			r.ParseForm()
+			q := r.Form
+			writes = append(writes, fmt.Sprintf("%s %s %q", r.Method, r.URL.Path, cli.FirstNonEmpty(q.Get("name"), q.Get("text"), q.Get("url"), q.Get("dueComplete"))))
+		}
+		stub.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	clitest.RunCLI(t, srv, "import", "trello-export", "--file", "testdata/trello-export/board.json")
+	clitest.CheckGolden(t, "import_trello_export_writes", strings.Join(writes, "\n")+"\n")
+}
+
+func TestImportTrelloExportSameBoard(t *testing.T) {
+	stub := clitest.NewStub(t)
+	cfg, _, err := clitest.TestConfig(t, stub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = runImport(client, cfg, []string{"trello-export", "--file", "testdata/trello-export/board.json", "--board", "OlDe"})
+	if err == nil || !strings.Contains(err.Error(), "exported board itself") {
+		t.Errorf("import into the exported board: err = %v", err)
+	}
+}
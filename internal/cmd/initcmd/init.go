@@ -0,0 +1,165 @@
+// Package initcmd implements "trelli init": interactive first-run setup.
+package initcmd
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"trelli/internal/cli"
+	"trelli/pkg/trello"
+)
+
+var Command = cli.Command{
+	Name:    "init",
+	Summary: "Interactive first-run setup",
+	Description: `Walk through obtaining an API key and token, pick a default board from
+your boards, choose the output format, and write the config file.
+Credentials go to the OS keychain when available, otherwise to the
+profile in the config file.`,
+	Usage: []string{""},
+	Run:   cli.Local(runInit),
+	Mode:  cli.ModeOnline,
+}
+
+// runInit walks through first-run setup: credentials, default board, and
+// output format, then writes the config file.
+func runInit(cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("init")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs); err != nil {
+		return err
+	}
+	if !cli.IsTerminal(os.Stdin) {
+		return errors.New("trelli init is interactive; use `trelli config set` or `trelli auth login --key --token` in scripts")
+	}
+	_ = cli.LoadStoredCredentials(&cfg)
+
+	in := bufio.NewReader(os.Stdin)
+	profile := cli.FirstNonEmpty(cfg.Profile, "default")
+	fmt.Fprintf(os.Stderr, "Setting up trelli (profile %s, config %s).\n\n", profile, cfg.ConfigPath)
+
+	fmt.Fprintf(os.Stderr, "1. API key: get it from %s\n", cli.TrelloAppKeyURL)
+	key, err := promptDefault(in, "Trello API key", cfg.APIKey, true)
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		return errors.New("an API key is required")
+	}
+	token := cfg.Token
+	if key != cfg.APIKey || token == "" {
+		token = ""
+		fmt.Fprintf(os.Stderr, "\n2. Token: authorize trelli and copy the token from:\n   %s\n", cli.AuthorizeURL(key))
+	} else {
+		fmt.Fprintln(os.Stderr, "\n2. Token: press Enter to keep the current token.")
+	}
+	entered, err := cli.Prompt(in, "Trello token: ", true)
+	if err != nil {
+		return err
+	}
+	token = cli.FirstNonEmpty(entered, token)
+	if token == "" {
+		return errors.New("a token is required")
+	}
+
+	cfg.APIKey, cfg.Token = key, token
+	client, err := cli.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	me, err := cli.FetchMe(ctx, client)
+	if err != nil {
+		return fmt.Errorf("verifying credentials: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Authenticated as @%s.\n", me.Username)
+
+	fmt.Fprintln(os.Stderr, "\n3. Default board:")
+	boards, err := client.Boards.List(ctx, trello.ListBoardsOptions{Filter: "open", Fields: "id,name,shortLink"})
+	if err != nil {
+		return err
+	}
+	sort.Slice(boards, func(i, j int) bool { return boards[i].Name < boards[j].Name })
+	for i, b := range boards {
+		fmt.Fprintf(os.Stderr, "  %2d) %s (%s)\n", i+1, b.Name, b.ShortLink)
+	}
+	current := cfg.File.GetString("board.default")
+	choice, err := promptDefault(in, fmt.Sprintf("Board number [1-%d]", len(boards)), current, false)
+	if err != nil {
+		return err
+	}
+	if choice != "" && choice != current {
+		n, err := strconv.Atoi(choice)
+		if err != nil || n < 1 || n > len(boards) {
+			return fmt.Errorf("invalid board choice %q", choice)
+		}
+		cfg.File.Set("board.default", boards[n-1].ShortLink)
+	}
+
+	fmt.Fprintln(os.Stderr, "\n4. Output format:")
+	output, err := promptDefault(in, "Default output (table/json/csv)", cli.FirstNonEmpty(cfg.File.GetString("output"), "table"), false)
+	if err != nil {
+		return err
+	}
+	if output != cli.OutputTable && output != cli.OutputJSON && output != cli.OutputCSV {
+		return fmt.Errorf("invalid output format %q (want table, json, or csv)", output)
+	}
+	cfg.File.Set("output", output)
+
+	stored := "the config file"
+	if store, err := cli.NewCredentialStore(); err == nil && cfg.File.GetString("credentials.store") != "none" {
+		answer, err := promptDefault(in, "\n5. Store credentials in "+store.Name()+"? (y/n)", "y", false)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(strings.ToLower(answer), "y") {
+			if err := store.Set(cli.CredentialAccount(cfg.Profile, "key"), key); err != nil {
+				return err
+			}
+			if err := store.Set(cli.CredentialAccount(cfg.Profile, "token"), token); err != nil {
+				return err
+			}
+			stored = store.Name()
+		}
+	}
+	if stored == "the config file" {
+		cfg.File.Set("profiles."+profile+".key", key)
+		cfg.File.Set("profiles."+profile+".token", token)
+		if cfg.File.GetString("profile") == "" {
+			cfg.File.Set("profile", profile)
+		}
+	}
+
+	if err := cli.SaveConfigFile(cfg.ConfigPath, cfg.File); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "\nSaved %s; credentials stored in %s.\nTry: trelli lists list\n", cfg.ConfigPath, stored)
+	return nil
+}
+
+// promptDefault prompts with a default shown in brackets (masked when
+// secret) and returns the default on empty input.
+func promptDefault(in *bufio.Reader, label, def string, secret bool) (string, error) {
+	shown := def
+	if secret && len(def) > 4 {
+		shown = "****" + def[len(def)-4:]
+	}
+	if shown != "" {
+		label += " [" + shown + "]"
+	}
+	answer, err := cli.Prompt(in, label+": ", false)
+	if err != nil {
+		return "", err
+	}
+	return cli.FirstNonEmpty(answer, def), nil
+}
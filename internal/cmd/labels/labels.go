@@ -0,0 +1,283 @@
+// Package labels implements "trelli labels": rename and merge board labels.
+package labels
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "labels",
+	Aliases: []string{"label"},
+	Summary: "Rename and merge board labels",
+	Description: `Labels are given by name, color, or id, as with cards create --labels.
+
+rename changes a label's name everywhere it is used. merge moves every
+card with one or more --from labels, archived cards included, to the
+--to label and then deletes the --from labels. A --from label is only
+deleted once all its cards have the --to label, so a failed merge can be
+run again. Deleting labels asks for confirmation.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "list", Aliases: []string{"ls"}, Usage: []string{"list [[--board] <boardIdOrShortLink>]"}, Flags: []cli.Flag{cli.BoardFlag}},
+		{Name: "rename", Usage: []string{"rename [--board <boardIdOrShortLink>] --from <label> --to <name>"}, Flags: []cli.Flag{cli.BoardFlag,
+			{Name: "from", Arg: "label", Desc: "Label to rename, or comma-separated labels to merge"},
+			{Name: "to", Arg: "label", Desc: "New name (rename), or the label to merge into (merge)"},
+		}},
+		{Name: "merge", Usage: []string{"merge [--board <boardIdOrShortLink>] --from <label,...> --to <label> [--yes|--force]"}, Flags: []cli.Flag{cli.YesFlag, cli.ForceFlag}},
+	},
+	Options: []cli.Flag{cli.JSONOption},
+	Run:     runLabels,
+}
+
+// labelMerge is the result of labels merge.
+type labelMerge struct {
+	Board   string      `json:"board"`
+	From    []cli.Label `json:"from"`
+	To      cli.Label   `json:"to"`
+	Cards   []string    `json:"cards"`
+	Deleted []string    `json:"deleted"`
+}
+
+// labelCard is a card with its labels.
+type labelCard struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	IDLabels []string `json:"idLabels"`
+}
+
+func runLabels(client *cli.Client, cfg cli.Config, args []string) error {
+	if len(args) == 0 {
+		cli.PrintCommandHelp("labels")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("labels")
+		return nil
+	case "list", "ls":
+		return runLabelsList(cfg.Context, client, cfg, args[1:])
+	case "rename":
+		return runLabelsRename(cfg.Context, client, cfg, args[1:])
+	case "merge":
+		return runLabelsMerge(cfg.Context, client, cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown labels subcommand %q", args[0])
+	}
+}
+
+func runLabelsList(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("labels list", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boardID := cfg.BoardID
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("labels")); err != nil {
+		return err
+	}
+	var positionalBoard string
+	if err := cli.TakePositional(fs, &positionalBoard); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(positionalBoard, boardID))
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+	labels, err := cli.FetchBoardLabels(ctx, client, boardID)
+	if err != nil {
+		return err
+	}
+	return cli.Render(cfg, cli.NonNil(labels), labelsTable(labels))
+}
+
+func runLabelsRename(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("labels rename", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boardID := cfg.BoardID
+	var from, to string
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&from, "from", "", "Label to rename")
+	fs.StringVar(&to, "to", "", "New name")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("labels")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &from, &to); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(boardID)
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+	if from == "" || strings.TrimSpace(to) == "" {
+		return errors.New("labels rename requires --from and --to")
+	}
+	labels, err := cli.FetchBoardLabels(ctx, client, boardID)
+	if err != nil {
+		return err
+	}
+	label, err := boardLabel(labels, from)
+	if err != nil {
+		return fmt.Errorf("%w on board %s", err, boardID)
+	}
+	if i := slices.IndexFunc(labels, func(l cli.Label) bool { return l.ID != label.ID && strings.EqualFold(l.Name, to) }); i >= 0 {
+		return fmt.Errorf("board %s already has a label named %q; use labels merge to combine them", boardID, labels[i].Name)
+	}
+	updated, err := client.Boards.UpdateLabel(ctx, label.ID, url.Values{"name": {to}})
+	if err != nil {
+		return err
+	}
+	// Keep the name cache from resolving the old name.
+	if _, err := cli.FetchBoardLabels(ctx, client, boardID); err != nil {
+		return err
+	}
+	if cfg.Structured() {
+		return cli.Render(cfg, updated)
+	}
+	fmt.Printf("Renamed label %q to %q on board %s\n", cli.FirstNonEmpty(label.Name, label.Color), to, boardID)
+	return nil
+}
+
+func runLabelsMerge(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("labels merge", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boardID := cfg.BoardID
+	var from, to string
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&from, "from", "", "Labels to merge")
+	fs.StringVar(&to, "to", "", "Label to merge into")
+	cli.AddConfirmFlags(fs, &cfg)
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("labels")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &from, &to); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(boardID)
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+	if len(cli.SplitIDs(from)) == 0 || to == "" {
+		return errors.New("labels merge requires --from and --to")
+	}
+	labels, err := cli.FetchBoardLabels(ctx, client, boardID)
+	if err != nil {
+		return err
+	}
+	target, err := boardLabel(labels, to)
+	if err != nil {
+		return fmt.Errorf("%w on board %s", err, boardID)
+	}
+	result := labelMerge{Board: boardID, To: target, Cards: []string{}, Deleted: []string{}}
+	for _, s := range cli.SplitIDs(from) {
+		l, err := boardLabel(labels, s)
+		if err != nil {
+			return fmt.Errorf("%w on board %s", err, boardID)
+		}
+		if l.ID == target.ID {
+			return fmt.Errorf("cannot merge label %q into itself", s)
+		}
+		if !slices.ContainsFunc(result.From, func(f cli.Label) bool { return f.ID == l.ID }) {
+			result.From = append(result.From, l)
+		}
+	}
+
+	query := url.Values{}
+	query.Set("filter", "all")
+	query.Set("fields", "id,name,idLabels")
+	var cards []labelCard
+	if err := client.Do(ctx, http.MethodGet, "/1/boards/"+url.PathEscape(boardID)+"/cards", query, nil, &cards); err != nil {
+		return err
+	}
+	var relabel []labelCard
+	counts := make([]int, len(result.From))
+	for _, c := range cards {
+		has := false
+		for i, f := range result.From {
+			if slices.Contains(c.IDLabels, f.ID) {
+				counts[i]++
+				has = true
+			}
+		}
+		if has && !slices.Contains(c.IDLabels, target.ID) {
+			relabel = append(relabel, c)
+		}
+	}
+
+	targets := make([]string, len(result.From))
+	for i, f := range result.From {
+		targets[i] = fmt.Sprintf("%s  %s (%d cards)", f.ID, cli.LabelName(f), counts[i])
+	}
+	if err := cli.Confirm(cfg, fmt.Sprintf("move %d cards to label %s and delete %d labels", len(relabel), cli.LabelName(target), len(result.From)), targets); err != nil {
+		return err
+	}
+	var failed []string
+	client.Progress.SetTask("Relabeling cards", len(relabel))
+	defer client.Progress.EndTask()
+	for _, c := range relabel {
+		err := client.Cards.AddLabel(ctx, c.ID, target.ID)
+		client.Progress.Advance(1)
+		switch {
+		case errors.Is(err, cli.ErrDryRun):
+		case err != nil:
+			slog.Warn(fmt.Sprintf("adding label %s to card %s: %s", cli.LabelName(target), c.ID, err), "card", c.ID)
+			failed = append(failed, c.ID)
+		default:
+			result.Cards = append(result.Cards, c.ID)
+		}
+	}
+	// Deleting a label takes it off its cards, so the old labels stay
+	// until every card has the new one.
+	if len(failed) > 0 {
+		return fmt.Errorf("could not relabel %d of %d cards; the labels were not deleted, run the merge again", len(failed), len(relabel))
+	}
+	for _, f := range result.From {
+		if err := client.Boards.DeleteLabel(ctx, f.ID); err != nil && !errors.Is(err, cli.ErrDryRun) {
+			return fmt.Errorf("deleting label %s: %w", cli.LabelName(f), err)
+		}
+		result.Deleted = append(result.Deleted, f.ID)
+	}
+	if cfg.DryRun {
+		return cli.ErrDryRun
+	}
+	if _, err := cli.FetchBoardLabels(ctx, client, boardID); err != nil {
+		return err
+	}
+	if cfg.Structured() {
+		return cli.Render(cfg, result)
+	}
+	names := make([]string, len(result.From))
+	for i, f := range result.From {
+		names[i] = cli.LabelName(f)
+	}
+	fmt.Printf("Added label %s to %d cards and deleted %s on board %s\n", cli.LabelName(target), len(result.Cards), strings.Join(names, ", "), boardID)
+	return nil
+}
+
+// boardLabel finds one of the board's labels by id, name, or color.
+func boardLabel(labels []cli.Label, s string) (cli.Label, error) {
+	id, err := cli.MatchLabel(labels, s)
+	if err != nil {
+		return cli.Label{}, err
+	}
+	if i := slices.IndexFunc(labels, func(l cli.Label) bool { return l.ID == id }); i >= 0 {
+		return labels[i], nil
+	}
+	return cli.Label{}, fmt.Errorf("no label %s", s)
+}
+
+func labelsTable(labels []cli.Label) cli.Table {
+	t := cli.Table{Columns: []string{"ID", "NAME", "COLOR"}, Empty: "No labels."}
+	for _, l := range labels {
+		t.Rows = append(t.Rows, []string{l.ID, l.Name, l.Color})
+	}
+	return t
+}
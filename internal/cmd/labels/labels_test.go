@@ -0,0 +1,35 @@
+package labels
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// TestMain registers the command as main does, for the tests that run it
+// through the CLI.
+func TestMain(m *testing.M) {
+	cli.Register(Command)
+	os.Exit(m.Run())
+}
+
+func TestLabelsMergeRename(t *testing.T) {
+	stub := clitest.NewStub(t)
+	clitest.CheckGolden(t, "labels_list", clitest.RunCLI(t, stub, "labels", "list", "b1"))
+	clitest.CheckGolden(t, "labels_merge_dry_run", clitest.RunCLI(t, stub, "--dry-run", "labels", "merge", "--from", "bug", "--to", "Feature"))
+	clitest.CheckGolden(t, "labels_merge", clitest.RunCLI(t, stub, "labels", "merge", "--from", "bug", "--to", "Feature", "--yes"))
+	clitest.CheckGolden(t, "labels_rename", clitest.RunCLI(t, stub, "labels", "rename", "--from", "Bug", "--to", "Defect"))
+	for args, want := range map[string]string{
+		"merge --from Bug --to Feature":      "needs confirmation",
+		"merge --from Bug --to red":          "into itself",
+		"merge --from Bug,Nope --to Feature": `no label named or colored "Nope" on board b1`,
+		"rename --from Bug --to feature":     "use labels merge",
+	} {
+		if got := clitest.RunCLI(t, stub, append([]string{"labels"}, strings.Fields(args)...)...); !strings.Contains(got, want) {
+			t.Errorf("labels %s = %s, want %q", args, got, want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Package lists implements "trelli lists": list-level commands.
+package lists
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:        "lists",
+	Aliases:     []string{"list", "l"},
+	Summary:     "List-level commands",
+	Description: "List all lists for a board. Defaults to --board from global flag or TRELLO_BOARD_ID.\nwip compares each list's open cards with its WIP limit, set per list name with trelli config set wip.<list name> <n>, and fails when a list is over it; cards move warns about moves that go over a limit.",
+	Subcommands: []cli.Subcommand{
+		{Name: "list", Aliases: []string{"ls"}, Usage: []string{"list [[--board] <boardIdOrShortLink>]"}, Flags: []cli.Flag{
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias"},
+		}},
+		{Name: "wip", Usage: []string{"wip [[--board] <boardIdOrShortLink>]"}},
+	},
+	Options: []cli.Flag{cli.JSONOption},
+	Run:     runLists,
+}
+
+func runLists(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) == 0 {
+		cli.PrintCommandHelp("lists")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("lists")
+		return nil
+	case "list":
+		fs := flag.NewFlagSet("lists list", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		boardID := cfg.BoardID
+		fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("lists")); err != nil {
+			return err
+		}
+		// boardID already holds the global default, so a positional board
+		// is collected separately and takes precedence.
+		var positionalBoard string
+		if err := cli.TakePositional(fs, &positionalBoard); err != nil {
+			return err
+		}
+		boardID = cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(positionalBoard, boardID))
+		if strings.TrimSpace(boardID) == "" {
+			return cli.ErrNoBoard
+		}
+
+		lists, err := cli.FetchBoardLists(ctx, client, boardID)
+		if err != nil {
+			return err
+		}
+		sort.Slice(lists, func(i, j int) bool { return lists[i].Pos < lists[j].Pos })
+		return cli.Render(cfg, lists, listsTable(lists))
+	case "wip":
+		return runListsWIP(ctx, client, cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown lists subcommand %q", args[0])
+	}
+}
+
+func listsTable(lists []cli.TrelloList) cli.Table {
+	t := cli.Table{Columns: []string{"ID", "NAME", "CLOSED"}, Empty: "No lists found."}
+	for _, l := range lists {
+		t.Rows = append(t.Rows, []string{l.ID, l.Name, strconv.FormatBool(l.Closed)})
+	}
+	return t
+}
@@ -0,0 +1,93 @@
+package lists
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+func runListsWIP(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("lists wip", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boardID := cfg.BoardID
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("lists")); err != nil {
+		return err
+	}
+	var positionalBoard string
+	if err := cli.TakePositional(fs, &positionalBoard); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(positionalBoard, boardID))
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+	limits, err := cfg.File.WipLimits()
+	if err != nil {
+		return err
+	}
+	if len(limits) == 0 {
+		return errors.New("no WIP limits configured; set one with trelli config set wip.<list name> <n>")
+	}
+
+	var lists []cli.TrelloList
+	var cards []cli.Card
+	query := url.Values{}
+	query.Set("filter", "open")
+	query.Set("fields", "idList,closed")
+	if err := client.GetAll(ctx,
+		cli.BoardListsRequest(boardID, &lists),
+		cli.GetRequest{Path: "/1/boards/" + url.PathEscape(boardID) + "/cards", Query: query, Out: &cards},
+	); err != nil {
+		return err
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].Pos < lists[j].Pos })
+	counts := map[string]int{}
+	for _, c := range cards {
+		if !c.Closed {
+			counts[c.IDList]++
+		}
+	}
+	statuses := []cli.WipStatus{}
+	over := 0
+	for _, l := range lists {
+		limit, ok := limits[strings.ToLower(l.Name)]
+		if !ok || l.Closed {
+			continue
+		}
+		s := cli.WipStatus{ID: l.ID, Name: l.Name, Cards: counts[l.ID], Limit: limit, Over: counts[l.ID] > limit}
+		if s.Over {
+			over++
+		}
+		statuses = append(statuses, s)
+	}
+	if err := cli.Render(cfg, statuses, wipTable(statuses)); err != nil {
+		return err
+	}
+	if over > 0 {
+		return fmt.Errorf("%d of %d lists are over their WIP limit", over, len(statuses))
+	}
+	return nil
+}
+
+func wipTable(statuses []cli.WipStatus) cli.Table {
+	t := cli.Table{Columns: []string{"ID", "LIST", "CARDS", "LIMIT", "STATUS"}, Empty: "No list on this board has a WIP limit."}
+	for _, s := range statuses {
+		status := "ok"
+		switch {
+		case s.Over:
+			status = "over"
+		case s.Cards == s.Limit:
+			status = "full"
+		}
+		t.Rows = append(t.Rows, []string{s.ID, s.Name, fmt.Sprint(s.Cards), fmt.Sprint(s.Limit), status})
+	}
+	return t
+}
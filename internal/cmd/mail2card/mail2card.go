@@ -0,0 +1,262 @@
+// Package mail2card implements "trelli mail2card": create a card from an email message.
+package mail2card
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"regexp"
+	"strings"
+
+	"trelli/internal/cli"
+	"trelli/pkg/trello"
+)
+
+var Command = cli.Command{
+	Name:    "mail2card",
+	Summary: "Create a card from an email message",
+	Description: `Read an RFC 822 email message from stdin (or --file) and create a card
+from it: the subject becomes the card name, the text body the
+description, and every attachment is uploaded to the card. Multipart
+messages, base64 and quoted-printable encodings, and encoded subjects are
+decoded; when a message has no plain-text part, the HTML part is reduced
+to text.
+
+The command is meant to be piped into from a mail filter such as procmail
+or maildrop. It exits non-zero when the card cannot be created, so the
+filter can keep the message.`,
+	Usage: []string{"(--list <id> | --list-name <name>) [--board <id>] [--file <message.eml>]"},
+	Options: []cli.Flag{
+		{Name: "list", Arg: "id", Desc: "List for the card"},
+		{Name: "list-name", Arg: "name", Desc: "List for the card, by name on the board"},
+		{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias for --list-name (default: the default board)"},
+		{Name: "file", Arg: "path", Desc: "Message to read (default: stdin; - also means stdin)"},
+		cli.JSONOption,
+	},
+	Sections: []cli.HelpSection{{Title: "Examples", Body: `trelli mail2card --list-name Inbox < message.eml
+
+# ~/.procmailrc
+:0 c
+* ^To:.*tasks@example\.com
+| trelli mail2card --board EnGi --list-name Inbox`}},
+	Run: runMail2Card,
+}
+
+// mailMessage is the part of an email message mail2card turns into a card.
+type mailMessage struct {
+	Subject string
+	Body    string
+	Files   []trello.File
+}
+
+// mailCard is a card created by mail2card with its uploaded attachments.
+type mailCard struct {
+	cli.Card
+	Attachments []cli.Attachment `json:"attachments"`
+}
+
+func runMail2Card(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	fs := flag.NewFlagSet("mail2card", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var listID, listName, file string
+	boardID := cfg.BoardID
+	fs.StringVar(&listID, "list", "", "List id for the card")
+	fs.StringVar(&listName, "list-name", "", "List name for the card (resolved on board)")
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&file, "file", "", "Message to read")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("mail2card")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &file); err != nil {
+		return err
+	}
+	if listID == "" && listName == "" {
+		return errors.New("mail2card requires --list or --list-name")
+	}
+
+	var in io.Reader = os.Stdin
+	if file != "" && file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	msg, err := parseMail(in)
+	if err != nil {
+		return err
+	}
+
+	card, err := cli.CreateCard(ctx, client, cfg, cli.CardDraft{IDList: listID, ListName: listName, Board: boardID, Name: msg.Subject, Desc: msg.Body})
+	if err != nil && !errors.Is(err, cli.ErrDryRun) {
+		return err
+	}
+	created := mailCard{Card: card, Attachments: []cli.Attachment{}}
+	for _, f := range msg.Files {
+		a, err := client.Cards.AttachFile(ctx, card.ID, f)
+		if err != nil && !errors.Is(err, cli.ErrDryRun) {
+			return fmt.Errorf("attaching %s: %w", f.Name, err)
+		}
+		created.Attachments = append(created.Attachments, a)
+	}
+	if cfg.DryRun {
+		return cli.ErrDryRun
+	}
+	if len(msg.Files) > 0 {
+		names := make([]string, len(msg.Files))
+		for i, f := range msg.Files {
+			names[i] = f.Name
+		}
+		slog.Info("Attached "+strings.Join(names, ", "), "card", card.ID, "attachments", len(names))
+	}
+	return cli.Render(cfg, created, cli.CardsTable([]cli.Card{card}))
+}
+
+// parseMail reads an RFC 822 message. The subject defaults to the sender
+// when empty; the body is the first text/plain part, or the first
+// text/html part reduced to text; parts with a file name or an attachment
+// disposition become files.
+func parseMail(r io.Reader) (mailMessage, error) {
+	m, err := mail.ReadMessage(r)
+	if err != nil {
+		return mailMessage{}, fmt.Errorf("reading message: %w", err)
+	}
+	dec := mime.WordDecoder{CharsetReader: charsetReader}
+	subject, err := dec.DecodeHeader(m.Header.Get("Subject"))
+	if err != nil {
+		subject = m.Header.Get("Subject")
+	}
+	msg := mailMessage{Subject: strings.Join(strings.Fields(subject), " ")}
+	if msg.Subject == "" {
+		msg.Subject = "(no subject)"
+		if from, err := m.Header.AddressList("From"); err == nil && len(from) > 0 {
+			msg.Subject = "Mail from " + cli.FirstNonEmpty(from[0].Name, from[0].Address)
+		}
+	}
+	var plain, htmlBody string
+	err = walkMailPart(m.Header, m.Body, func(contentType, filename string, attachment bool, data []byte) error {
+		switch {
+		case attachment || filename != "":
+			msg.Files = append(msg.Files, trello.File{Name: cli.FirstNonEmpty(filename, "attachment"), MimeType: contentType, Data: data})
+		case contentType == "text/plain" && plain == "":
+			plain = string(data)
+		case contentType == "text/html" && htmlBody == "":
+			htmlBody = htmlToText(string(data))
+		}
+		return nil
+	})
+	if err != nil {
+		return mailMessage{}, err
+	}
+	msg.Body = strings.TrimSpace(strings.ReplaceAll(cli.FirstNonEmpty(plain, htmlBody), "\r\n", "\n"))
+	return msg, nil
+}
+
+// mailHeader is the header access walkMailPart needs from both
+// mail.Header and multipart.Part headers.
+type mailHeader interface {
+	Get(key string) string
+}
+
+// walkMailPart decodes a MIME entity and calls visit for every leaf part
+// with its media type, file name, whether it is marked as an attachment,
+// and its decoded content (converted to UTF-8 for text).
+func walkMailPart(h mailHeader, body io.Reader, visit func(contentType, filename string, attachment bool, data []byte) error) error {
+	contentType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		contentType, params = "text/plain", map[string]string{}
+	}
+	if strings.HasPrefix(contentType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("reading message: %w", err)
+			}
+			if err := walkMailPart(part.Header, part, visit); err != nil {
+				return err
+			}
+		}
+	}
+
+	var data []byte
+	switch strings.ToLower(strings.TrimSpace(h.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		data, err = io.ReadAll(base64.NewDecoder(base64.StdEncoding, body))
+	case "quoted-printable":
+		data, err = io.ReadAll(quotedprintable.NewReader(body))
+	default:
+		data, err = io.ReadAll(body)
+	}
+	if err != nil {
+		return fmt.Errorf("decoding %s part: %w", contentType, err)
+	}
+
+	disposition, dparams, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+	filename := cli.FirstNonEmpty(dparams["filename"], params["name"])
+	if decoded, err := (&mime.WordDecoder{CharsetReader: charsetReader}).DecodeHeader(filename); err == nil {
+		filename = decoded
+	}
+	attachment := disposition == "attachment"
+	if strings.HasPrefix(contentType, "text/") && !attachment && filename == "" {
+		cr, err := charsetReader(params["charset"], bytes.NewReader(data))
+		if err == nil {
+			data, _ = io.ReadAll(cr)
+		}
+	}
+	return visit(contentType, filename, attachment, data)
+}
+
+// charsetReader converts the charsets mail commonly uses besides UTF-8;
+// others are passed through unchanged.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "windows-1252":
+		raw, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		runes := make([]rune, len(raw))
+		for i, b := range raw {
+			runes[i] = rune(b)
+		}
+		return strings.NewReader(string(runes)), nil
+	}
+	return input, nil
+}
+
+var (
+	htmlBlockPattern = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/tr|/h[1-6])\b[^>]*>`)
+	htmlDropPattern  = regexp.MustCompile(`(?is)<(script|style|head)\b.*?</(script|style|head)>`)
+	htmlTagPattern   = regexp.MustCompile(`<[^>]*>`)
+	blankRunPattern  = regexp.MustCompile(`\n\s*\n\s*\n+`)
+)
+
+// htmlToText reduces an HTML body to plain text: block ends become line
+// breaks, tags are dropped, and entities are unescaped.
+func htmlToText(s string) string {
+	s = htmlDropPattern.ReplaceAllString(s, "")
+	s = htmlBlockPattern.ReplaceAllString(s, "\n")
+	s = htmlTagPattern.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+	return blankRunPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+}
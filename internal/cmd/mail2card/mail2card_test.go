@@ -0,0 +1,78 @@
+package mail2card
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// TestMain registers the command as main does, for the tests that run it
+// through the CLI.
+func TestMain(m *testing.M) {
+	cli.Register(Command)
+	os.Exit(m.Run())
+}
+
+func TestMail2Card(t *testing.T) {
+	stub := clitest.NewStub(t)
+	clitest.CheckGolden(t, "mail2card_dry_run", clitest.RunCLI(t, stub, "--dry-run", "mail2card", "--list-name", "To Do", "--file", "testdata/mail/printer.eml"))
+
+	var writes []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data"):
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Errorf("upload: %v", err)
+			}
+			f, h, err := r.FormFile("file")
+			if err != nil {
+				t.Fatalf("upload without a file part: %v", err)
+			}
+			data, _ := io.ReadAll(f)
+			writes = append(writes, fmt.Sprintf("%s %s name=%s mimeType=%s file=%s %q", r.Method, r.URL.Path, r.FormValue("name"), r.FormValue("mimeType"), h.Filename, data))
+		case r.Method != http.MethodGet:
+			r.ParseForm()
+			var fields []string
+			for k, v := range r.Form {
+				if k != "key" && k != "token" {
+					fields = append(fields, fmt.Sprintf("%s=%q", k, strings.Join(v, ",")))
+				}
+			}
+			slices.Sort(fields)
+			writes = append(writes, fmt.Sprintf("%s %s %s", r.Method, r.URL.Path, strings.Join(fields, " ")))
+		}
+		stub.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	clitest.CheckGolden(t, "mail2card", clitest.RunCLI(t, srv, "--json", "mail2card", "--list", "l1", "--file", "testdata/mail/printer.eml"))
+	clitest.CheckGolden(t, "mail2card_writes", strings.Join(writes, "\n")+"\n")
+}
+
+func TestParseMailHTMLOnly(t *testing.T) {
+	raw := "From: Grace Hopper <grace@example.com>\r\n" +
+		"Content-Type: text/html; charset=iso-8859-1\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"<html><head><style>p {}</style></head><body><p>Caf=E9 &amp; more</p><ul><li>one</li><li>two</li></ul></body></html>\r\n"
+	msg, err := parseMail(strings.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Subject != "Mail from Grace Hopper" {
+		t.Errorf("subject = %q", msg.Subject)
+	}
+	if want := "Café & more\none\ntwo"; msg.Body != want {
+		t.Errorf("body = %q, want %q", msg.Body, want)
+	}
+	if len(msg.Files) != 0 {
+		t.Errorf("files = %v", msg.Files)
+	}
+}
@@ -0,0 +1,309 @@
+// Package mcp implements "trelli mcp": serve Trello tools to AI assistants over MCP (stdio).
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"trelli/internal/cli"
+	"trelli/pkg/trello"
+)
+
+var Command = cli.Command{
+	Name:    "mcp",
+	Summary: "Serve Trello tools to AI assistants over MCP (stdio)",
+	Description: `Run a Model Context Protocol server on stdin/stdout so LLM agents can
+read and change boards through a fixed set of tools with typed
+parameters. Requests share one client: rate limiting, retries, caching,
+--dry-run, and the undo journal apply, and the agent never sees the
+token. --read-only offers only the tools that read.`,
+	Usage: []string{"[--read-only]"},
+	Options: []cli.Flag{
+		{Name: "read-only", Desc: "Expose only list_boards, list_lists, list_cards, and search_cards"},
+	},
+	Sections:     []cli.HelpSection{{Title: "Client configuration", Body: `{"mcpServers": {"trelli": {"command": "trelli", "args": ["mcp"]}}}`}},
+	Run:          runMCP,
+	BareIsAction: true,
+}
+
+// mcpProtocolVersions are the protocol revisions served, newest first.
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// mcpTool is a tool as tools/list describes it, with the function that
+// runs it. Tools take the same arguments as the rpc methods where both
+// exist.
+type mcpTool struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	InputSchema mcpSchema `json:"inputSchema"`
+	run         cli.RPCMethod
+	writes      bool
+}
+
+// mcpSchema is the JSON Schema subset tool parameters need.
+type mcpSchema struct {
+	Type        string               `json:"type"`
+	Description string               `json:"description,omitempty"`
+	Properties  map[string]mcpSchema `json:"properties,omitempty"`
+	Items       *mcpSchema           `json:"items,omitempty"`
+	Required    []string             `json:"required,omitempty"`
+}
+
+func mcpObject(required []string, props map[string]mcpSchema) mcpSchema {
+	return mcpSchema{Type: "object", Properties: props, Required: required}
+}
+
+func mcpString(desc string) mcpSchema { return mcpSchema{Type: "string", Description: desc} }
+
+var (
+	mcpBoardParam    = mcpString("Board id, shortLink, or alias; defaults to the configured default board")
+	mcpListIDParam   = mcpString("List id")
+	mcpListNameParam = mcpString("List name, matched case-insensitively on the board; alternative to idList")
+)
+
+var mcpTools = []mcpTool{
+	{
+		Name:        "list_boards",
+		Description: "List the Trello boards of the authenticated member.",
+		InputSchema: mcpObject(nil, map[string]mcpSchema{
+			"filter": mcpString(`"open", "closed", or empty for all`),
+		}),
+		run: cli.RPCListBoards,
+	},
+	{
+		Name:        "list_lists",
+		Description: "List the open lists of a board, with their ids.",
+		InputSchema: mcpObject(nil, map[string]mcpSchema{"board": mcpBoardParam}),
+		run:         mcpListLists,
+	},
+	{
+		Name:        "list_cards",
+		Description: "List the open cards of a list, given by id or by name.",
+		InputSchema: mcpObject(nil, map[string]mcpSchema{
+			"idList":   mcpListIDParam,
+			"listName": mcpListNameParam,
+			"board":    mcpBoardParam,
+			"limit":    {Type: "integer", Description: "Maximum number of cards"},
+		}),
+		run: mcpListCards,
+	},
+	{
+		Name:        "search_cards",
+		Description: `Search cards with Trello search syntax, e.g. "login label:bug is:open".`,
+		InputSchema: mcpObject([]string{"query"}, map[string]mcpSchema{
+			"query": mcpString("Search terms and operators"),
+			"board": mcpString("Board id, shortLink, or alias; empty searches all boards"),
+			"limit": {Type: "integer", Description: "Maximum number of cards (default 10)"},
+		}),
+		run: cli.RPCSearchCards,
+	},
+	{
+		Name:        "create_card",
+		Description: "Create a card in a list, given by id or by name.",
+		InputSchema: mcpObject([]string{"name"}, map[string]mcpSchema{
+			"name":      mcpString("Card title"),
+			"idList":    mcpListIDParam,
+			"listName":  mcpListNameParam,
+			"board":     mcpBoardParam,
+			"desc":      mcpString("Card description (Markdown)"),
+			"due":       mcpString("Due date/time, ISO 8601"),
+			"idLabels":  {Type: "array", Items: &mcpSchema{Type: "string"}, Description: "Label ids"},
+			"idMembers": {Type: "array", Items: &mcpSchema{Type: "string"}, Description: "Member ids"},
+		}),
+		run:    cli.RPCCreateCard,
+		writes: true,
+	},
+	{
+		Name:        "add_comment",
+		Description: "Add a comment to a card.",
+		InputSchema: mcpObject([]string{"card", "text"}, map[string]mcpSchema{
+			"card": mcpString("Card id or shortLink"),
+			"text": mcpString("Comment text (Markdown)"),
+		}),
+		run:    cli.RPCAddComment,
+		writes: true,
+	},
+	{
+		Name:        "move_card",
+		Description: "Move a card to another list, given by id or by name.",
+		InputSchema: mcpObject([]string{"card"}, map[string]mcpSchema{
+			"card":     mcpString("Card id or shortLink"),
+			"idList":   mcpListIDParam,
+			"listName": mcpListNameParam,
+			"board":    mcpBoardParam,
+		}),
+		run:    mcpMoveCard,
+		writes: true,
+	},
+}
+
+func runMCP(client *cli.Client, cfg cli.Config, args []string) error {
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help" || args[0] == "help") {
+		cli.PrintCommandHelp("mcp")
+		return nil
+	}
+	fs := flag.NewFlagSet("mcp", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var readOnly bool
+	fs.BoolVar(&readOnly, "read-only", false, "Expose only tools that read")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("mcp")); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	// Dry-run requests must not interleave with protocol messages.
+	client.DryRunOut = os.Stderr
+	return cli.ServeRPC(cfg.Context, client, cfg, mcpMethods(readOnly), os.Stdin, os.Stdout)
+}
+
+// mcpMethods returns the MCP requests served, with the tools allowed.
+func mcpMethods(readOnly bool) map[string]cli.RPCMethod {
+	var tools []mcpTool
+	for _, t := range mcpTools {
+		if !readOnly || !t.writes {
+			tools = append(tools, t)
+		}
+	}
+	return map[string]cli.RPCMethod{
+		"initialize": mcpInitialize,
+		"ping": func(context.Context, *cli.Client, cli.Config, json.RawMessage) (any, error) {
+			return struct{}{}, nil
+		},
+		"tools/list": func(context.Context, *cli.Client, cli.Config, json.RawMessage) (any, error) {
+			return map[string][]mcpTool{"tools": tools}, nil
+		},
+		"tools/call": func(ctx context.Context, client *cli.Client, cfg cli.Config, params json.RawMessage) (any, error) {
+			return mcpCallTool(ctx, client, cfg, tools, params)
+		},
+	}
+}
+
+func mcpInitialize(_ context.Context, _ *cli.Client, _ cli.Config, params json.RawMessage) (any, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	// Capabilities and client info are not needed; unknown fields are fine.
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, cli.InvalidRPCParams("invalid params: " + err.Error())
+		}
+	}
+	protocol := mcpProtocolVersions[0]
+	if slices.Contains(mcpProtocolVersions, p.ProtocolVersion) {
+		protocol = p.ProtocolVersion
+	}
+	return map[string]any{
+		"protocolVersion": protocol,
+		"capabilities":    map[string]any{"tools": map[string]any{}},
+		"serverInfo":      map[string]string{"name": "trelli", "version": cli.Version},
+	}, nil
+}
+
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type mcpToolResult struct {
+	Content []mcpContent `json:"content"`
+	IsError bool         `json:"isError,omitempty"`
+}
+
+// mcpCallTool runs a tool. Failures of the tool itself, including bad
+// arguments, are results with isError set so the model can read and
+// correct them; only an unknown tool is a protocol error.
+func mcpCallTool(ctx context.Context, client *cli.Client, cfg cli.Config, tools []mcpTool, params json.RawMessage) (any, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, cli.InvalidRPCParams("invalid params: " + err.Error())
+	}
+	i := slices.IndexFunc(tools, func(t mcpTool) bool { return t.Name == p.Name })
+	if i < 0 {
+		return nil, cli.InvalidRPCParams(fmt.Sprintf("unknown tool: %s", p.Name))
+	}
+	result, err := tools[i].run(ctx, client, cfg, p.Arguments)
+	if err != nil {
+		text := err.Error()
+		var rerr *cli.RPCError
+		if !errors.As(err, &rerr) {
+			payload := cli.NewErrorPayload(err)
+			text = strings.TrimSuffix(payload.Message+"; "+payload.Hint, "; ")
+		}
+		return mcpToolResult{Content: []mcpContent{{Type: "text", Text: text}}, IsError: true}, nil
+	}
+	raw, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return mcpToolResult{Content: []mcpContent{{Type: "text", Text: string(raw)}}}, nil
+}
+
+func mcpListLists(ctx context.Context, client *cli.Client, cfg cli.Config, params json.RawMessage) (any, error) {
+	var p struct {
+		Board string `json:"board"`
+	}
+	if err := cli.DecodeRPCParams(params, &p); err != nil {
+		return nil, err
+	}
+	boardID := cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(p.Board, cfg.BoardID))
+	if boardID == "" {
+		return nil, cli.InvalidRPCParams(`"board" is required: ` + cli.ErrNoBoard.Error())
+	}
+	lists, err := cli.FetchBoardLists(ctx, client, boardID)
+	return cli.NonNil(lists), err
+}
+
+func mcpListCards(ctx context.Context, client *cli.Client, cfg cli.Config, params json.RawMessage) (any, error) {
+	var p struct {
+		IDList   string `json:"idList"`
+		ListName string `json:"listName"`
+		Board    string `json:"board"`
+		Limit    int    `json:"limit"`
+	}
+	if err := cli.DecodeRPCParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.IDList == "" && p.ListName == "" {
+		return nil, cli.InvalidRPCParams(`"idList" or "listName" is required`)
+	}
+	listID, err := cli.ResolveListID(ctx, client, cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(p.Board, cfg.BoardID)), p.IDList, p.ListName)
+	if err != nil {
+		return nil, err
+	}
+	cards, err := client.Cards.List(ctx, listID, trello.ListCardsOptions{Limit: p.Limit})
+	return cli.NonNil(cards), err
+}
+
+func mcpMoveCard(ctx context.Context, client *cli.Client, cfg cli.Config, params json.RawMessage) (any, error) {
+	var p struct {
+		Card     string `json:"card"`
+		IDList   string `json:"idList"`
+		ListName string `json:"listName"`
+		Board    string `json:"board"`
+	}
+	if err := cli.DecodeRPCParams(params, &p); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(p.Card) == "" {
+		return nil, cli.InvalidRPCParams(`"card" is required`)
+	}
+	if p.IDList == "" && p.ListName == "" {
+		return nil, cli.InvalidRPCParams(`"idList" or "listName" is required`)
+	}
+	listID, err := cli.ResolveListID(ctx, client, cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(p.Board, cfg.BoardID)), p.IDList, p.ListName)
+	if err != nil {
+		return nil, err
+	}
+	return cli.MoveCard(ctx, client, cfg, p.Card, listID)
+}
@@ -0,0 +1,44 @@
+package mcp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+func TestMCP(t *testing.T) {
+	stub := clitest.NewStub(t)
+	cfg, _, err := clitest.TestConfig(t, stub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := []string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "2025-03-26", "capabilities": {}, "clientInfo": {"name": "test", "version": "1"}}}`,
+		`{"jsonrpc": "2.0", "method": "notifications/initialized"}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "tools/list"}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "list_cards", "arguments": {"listName": "to do", "limit": 2}}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "tools/call", "params": {"name": "move_card", "arguments": {"card": "c1", "idList": "l2"}}}`,
+		`{"jsonrpc": "2.0", "id": 5, "method": "tools/call", "params": {"name": "create_card", "arguments": {"listName": "Someday", "name": "x"}}}`,
+		`{"jsonrpc": "2.0", "id": 6, "method": "tools/call", "params": {"name": "delete_board", "arguments": {}}}`,
+		`{"jsonrpc": "2.0", "id": 7, "method": "ping"}`,
+	}
+	for _, tt := range []struct {
+		name     string
+		readOnly bool
+	}{{"mcp_session", false}, {"mcp_session_read_only", true}} {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := cli.ServeRPC(cfg.Context, client, cfg, mcpMethods(tt.readOnly), strings.NewReader(strings.Join(session, "\n")), &out); err != nil {
+				t.Fatal(err)
+			}
+			clitest.CheckGolden(t, tt.name, out.String())
+		})
+	}
+}
@@ -0,0 +1,361 @@
+// Package metrics implements "trelli metrics": export board health as Prometheus metrics.
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "metrics",
+	Summary: "Export board health as Prometheus metrics",
+	Description: `serve exposes metrics about one or more boards at /metrics on
+127.0.0.1 in the Prometheus text format, for Prometheus to scrape and
+Grafana to graph and alert on. The boards are read every --refresh; if a
+refresh fails, the previous values keep being served and
+trelli_refresh_errors_total goes up. The created and archived counters
+count board actions seen since the exporter started. Ctrl-C stops the
+server.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "serve", Usage: []string{"serve [--board <ids>] [--port <n>] [--addr <host>] [--refresh <duration>]"}, Flags: []cli.Flag{
+			{Name: "board", Arg: "ids", Desc: "Comma-separated board ids, shortLinks, or aliases (default: the default board)"},
+			{Name: "port", Arg: "n", Desc: "Port to listen on (default 9090)"},
+			{Name: "addr", Arg: "host", Desc: "Address to bind (default 127.0.0.1; anything else exposes the metrics)"},
+			{Name: "refresh", Arg: "duration", Desc: "Time between reads of the boards (default 1m)"},
+		}},
+	},
+	Sections: []cli.HelpSection{
+		{Title: "Metrics", Body: `trelli_cards_per_list{board,board_id,list}  open cards in each open list
+trelli_overdue_cards{board,board_id}         open cards past due and not complete
+trelli_cards_created_total{board,board_id}   cards created, copied, or converted
+trelli_cards_archived_total{board,board_id}  cards archived
+trelli_refresh_timestamp_seconds             time of the last successful refresh
+trelli_refresh_errors_total                  failed refreshes`},
+		{Title: "Scraping", Body: `trelli metrics serve --board EnGi,ops --port 9090
+Then add a scrape job for 127.0.0.1:9090 to prometheus.yml.`},
+	},
+	Run:  cli.Local(runMetrics),
+	Mode: cli.ModeOnline,
+}
+
+const (
+	defaultMetricsPort    = 9090
+	defaultMetricsRefresh = time.Minute
+)
+
+// metricsCreatedTypes are the action types that add a card to a board.
+var metricsCreatedTypes = []string{"createCard", "copyCard", "convertToCardFromCheckItem"}
+
+func runMetrics(cfg cli.Config, args []string) error {
+	if len(args) == 0 {
+		cli.PrintCommandHelp("metrics")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("metrics")
+		return nil
+	case "serve":
+		return runMetricsServe(cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown metrics subcommand %q", args[0])
+	}
+}
+
+func runMetricsServe(cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	fs := flag.NewFlagSet("metrics serve", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boards := cfg.BoardID
+	var addr string
+	var port int
+	var refresh time.Duration
+	fs.StringVar(&boards, "board", boards, "Comma-separated board ids")
+	fs.StringVar(&addr, "addr", "127.0.0.1", "Address to bind")
+	fs.IntVar(&port, "port", defaultMetricsPort, "Port to listen on")
+	fs.DurationVar(&refresh, "refresh", defaultMetricsRefresh, "Time between reads of the boards")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("metrics")); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	var boardIDs []string
+	for _, id := range cli.SplitIDs(boards) {
+		boardIDs = append(boardIDs, cfg.File.ResolveBoardAlias(id))
+	}
+	if len(boardIDs) == 0 {
+		return cli.ErrNoBoard
+	}
+	if port < 0 || port > 65535 {
+		return fmt.Errorf("invalid --port %d", port)
+	}
+	if refresh < 10*time.Second {
+		return errors.New("--refresh must be at least 10s")
+	}
+
+	cfg.NoProgress = true
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		return err
+	}
+	client.LongRunning()
+	exporter := newMetricsExporter(client, boardIDs)
+	if err := exporter.update(ctx); err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", net.JoinHostPort(addr, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	host := ln.Addr().String()
+	if ip := net.ParseIP(addr); addr != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		slog.Warn(fmt.Sprintf("serving on %s; anyone who can reach it can read the board metrics", ln.Addr()), "addr", ln.Addr().String())
+		host = ""
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", exporter)
+	srv := &http.Server{
+		Handler:           cli.GuardLocal(mux, host),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	slog.Info(fmt.Sprintf("Serving metrics at http://%s/metrics (Ctrl-C to stop)", ln.Addr()), "addr", ln.Addr().String())
+
+	go func() {
+		tick := time.NewTicker(refresh)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+			}
+			if err := exporter.update(ctx); err != nil && ctx.Err() == nil {
+				slog.Warn(fmt.Sprintf("refreshing metrics: %v; serving the previous values", err))
+			}
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}()
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	client.Stats.Print(os.Stderr, cfg.JSON)
+	return nil
+}
+
+// metricsExporter serves the latest metrics of boards. Only the refresh
+// loop calls update, so just the rendered body is shared with handlers.
+type metricsExporter struct {
+	client *cli.Client
+	boards []string
+	now    func() time.Time
+
+	stats         map[string]*boardMetrics
+	cursors       map[string]*cli.NotifyCursor
+	refreshed     time.Time
+	refreshErrors int
+
+	mu   sync.RWMutex
+	body []byte
+}
+
+// boardMetrics are the values exported for one board.
+type boardMetrics struct {
+	ID       string
+	Name     string
+	Lists    []listMetrics
+	Overdue  int
+	Created  int
+	Archived int
+}
+
+type listMetrics struct {
+	Name  string
+	Cards int
+}
+
+func newMetricsExporter(client *cli.Client, boards []string) *metricsExporter {
+	return &metricsExporter{
+		client:  client,
+		boards:  boards,
+		now:     time.Now,
+		stats:   map[string]*boardMetrics{},
+		cursors: map[string]*cli.NotifyCursor{},
+	}
+}
+
+// update reads the boards and rebuilds the exposition. A board that
+// fails keeps its previous values.
+func (e *metricsExporter) update(ctx context.Context) error {
+	var errs []error
+	for _, id := range e.boards {
+		if err := e.updateBoard(ctx, id); err != nil {
+			errs = append(errs, fmt.Errorf("board %s: %w", id, err))
+		}
+	}
+	err := errors.Join(errs...)
+	if err != nil {
+		e.refreshErrors++
+	} else {
+		e.refreshed = e.now()
+	}
+	body := e.render()
+	e.mu.Lock()
+	e.body = body
+	e.mu.Unlock()
+	return err
+}
+
+func (e *metricsExporter) updateBoard(ctx context.Context, boardID string) error {
+	var board cli.Board
+	var lists []cli.TrelloList
+	var cards []struct {
+		IDList      string `json:"idList"`
+		Due         string `json:"due"`
+		DueComplete bool   `json:"dueComplete"`
+		Closed      bool   `json:"closed"`
+	}
+	boardQuery := url.Values{}
+	boardQuery.Set("fields", "id,name")
+	cardQuery := url.Values{}
+	cardQuery.Set("fields", "idList,due,dueComplete,closed")
+	err := e.client.GetAll(ctx,
+		cli.GetRequest{Path: "/1/boards/" + url.PathEscape(boardID), Query: boardQuery, Out: &board},
+		cli.BoardListsRequest(boardID, &lists),
+		cli.GetRequest{Path: "/1/boards/" + url.PathEscape(boardID) + "/cards", Query: cardQuery, Out: &cards},
+	)
+	if err != nil {
+		return err
+	}
+
+	m := e.stats[boardID]
+	if m == nil {
+		m = &boardMetrics{}
+	}
+	cursor := e.cursors[boardID]
+	if cursor == nil {
+		// Counters start at zero: only actions from now on are counted.
+		start, err := cli.StartNotifyCursor(ctx, e.client, boardID)
+		if err != nil {
+			return err
+		}
+		cursor = &start
+	} else {
+		next := *cursor
+		types := append(slices.Clone(metricsCreatedTypes), "updateCard")
+		var created, archived int
+		err := cli.NotifyNewActions(ctx, e.client, boardID, &next, types, func(_ context.Context, a cli.NotifyAction) error {
+			switch {
+			case slices.Contains(metricsCreatedTypes, a.Type):
+				created++
+			case a.Type == "updateCard" && a.Data.Old["closed"] == false && a.Data.Card != nil && a.Data.Card.Closed != nil && *a.Data.Card.Closed:
+				archived++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		cursor = &next
+		m.Created += created
+		m.Archived += archived
+	}
+
+	now := e.now()
+	counts := map[string]int{}
+	m.Overdue = 0
+	for _, c := range cards {
+		if c.Closed {
+			continue
+		}
+		counts[c.IDList]++
+		if due, err := time.Parse(time.RFC3339, c.Due); err == nil && !c.DueComplete && due.Before(now) {
+			m.Overdue++
+		}
+	}
+	m.ID, m.Name, m.Lists = boardID, board.Name, nil
+	for _, l := range lists {
+		if !l.Closed {
+			m.Lists = append(m.Lists, listMetrics{Name: l.Name, Cards: counts[l.ID]})
+		}
+	}
+	e.stats[boardID] = m
+	e.cursors[boardID] = cursor
+	return nil
+}
+
+// render writes the metrics in the Prometheus text exposition format.
+func (e *metricsExporter) render() []byte {
+	var b bytes.Buffer
+	var boards []*boardMetrics
+	for _, id := range e.boards {
+		if m := e.stats[id]; m != nil {
+			boards = append(boards, m)
+		}
+	}
+	family := func(name, kind, help string, samples func(board *boardMetrics)) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, m := range boards {
+			samples(m)
+		}
+	}
+	family("trelli_cards_per_list", "gauge", "Open cards in each open list.", func(m *boardMetrics) {
+		for _, l := range m.Lists {
+			fmt.Fprintf(&b, "trelli_cards_per_list{%s,list=%s} %d\n", metricsBoardLabels(m), metricsLabel(l.Name), l.Cards)
+		}
+	})
+	family("trelli_overdue_cards", "gauge", "Open cards past their due date and not complete.", func(m *boardMetrics) {
+		fmt.Fprintf(&b, "trelli_overdue_cards{%s} %d\n", metricsBoardLabels(m), m.Overdue)
+	})
+	family("trelli_cards_created_total", "counter", "Cards created, copied, or converted since the exporter started.", func(m *boardMetrics) {
+		fmt.Fprintf(&b, "trelli_cards_created_total{%s} %d\n", metricsBoardLabels(m), m.Created)
+	})
+	family("trelli_cards_archived_total", "counter", "Cards archived since the exporter started.", func(m *boardMetrics) {
+		fmt.Fprintf(&b, "trelli_cards_archived_total{%s} %d\n", metricsBoardLabels(m), m.Archived)
+	})
+	fmt.Fprintf(&b, "# HELP trelli_refresh_timestamp_seconds Time of the last successful refresh.\n# TYPE trelli_refresh_timestamp_seconds gauge\ntrelli_refresh_timestamp_seconds %d\n", e.refreshed.Unix())
+	fmt.Fprintf(&b, "# HELP trelli_refresh_errors_total Refreshes that failed for at least one board.\n# TYPE trelli_refresh_errors_total counter\ntrelli_refresh_errors_total %d\n", e.refreshErrors)
+	return b.Bytes()
+}
+
+func metricsBoardLabels(m *boardMetrics) string {
+	return "board=" + metricsLabel(m.Name) + ",board_id=" + metricsLabel(m.ID)
+}
+
+// metricsLabel quotes a label value, escaping backslashes, quotes, and
+// line breaks as the exposition format requires.
+func metricsLabel(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func (e *metricsExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.RLock()
+	body := e.body
+	e.mu.RUnlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(body)
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+func TestMetricsExporter(t *testing.T) {
+	clitest.WithStubRoutes(t, map[string]string{
+		"/1/boards/b1/actions": `[{"id": "a1", "type": "commentCard", "date": "2026-02-01T10:00:00.000Z", "data": {}}]`,
+	})
+	stub := clitest.NewStub(t)
+	cfg, _, err := clitest.TestConfig(t, stub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.LongRunning()
+	exporter := newMetricsExporter(client, []string{"b1"})
+	exporter.now = func() time.Time { return time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC) }
+	if err := exporter.update(cfg.Context); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only actions after the first refresh count.
+	clitest.WithStubRoutes(t, map[string]string{
+		"/1/boards/b1/actions": `[
+			{"id": "a4", "type": "updateCard", "date": "2026-03-01T11:00:00.000Z", "data": {"card": {"id": "c3", "closed": true}, "old": {"closed": false}}},
+			{"id": "a3", "type": "updateCard", "date": "2026-03-01T10:30:00.000Z", "data": {"card": {"id": "c1", "closed": false}, "old": {"closed": true}}},
+			{"id": "a2", "type": "createCard", "date": "2026-03-01T10:00:00.000Z", "data": {"card": {"id": "c2"}}},
+			{"id": "a1", "type": "commentCard", "date": "2026-02-01T10:00:00.000Z", "data": {}}
+		]`,
+	})
+	if err := exporter.update(cfg.Context); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(exporter)
+	t.Cleanup(srv.Close)
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	clitest.CheckGolden(t, "metrics", string(body))
+}
+
+func TestMetricsLabel(t *testing.T) {
+	if got, want := metricsLabel("a \"b\"\\\nc"), `"a \"b\"\\\nc"`; got != want {
+		t.Errorf("metricsLabel = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,186 @@
+// Package notify implements "trelli notify": post board activity to chat.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "notify",
+	Summary: "Post board activity to chat",
+	Description: `slack polls a board for new actions and posts a message for each one of
+the chosen types to a Slack incoming webhook, until interrupted. Actions
+from before the start are not posted. The webhook URL is a secret: pass
+it in SLACK_WEBHOOK_URL rather than on the command line where possible.
+With --dry-run, messages are printed instead of posted.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "slack", Usage: []string{"slack [--board <id>] [--webhook-url <url>] [--events <types>] [--interval <duration>]"}, Flags: []cli.Flag{
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "webhook-url", Arg: "url", Desc: "Slack incoming webhook URL (default $SLACK_WEBHOOK_URL)"},
+			{Name: "events", Arg: "types", Desc: "Action types to post, comma-separated or repeated (default createCard,commentCard)"},
+			{Name: "interval", Arg: "duration", Desc: "Time between polls (default 30s)"},
+		}},
+	},
+	Sections: []cli.HelpSection{cli.ActionTypesHelp},
+	Run:      runNotify,
+}
+
+func runNotify(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) == 0 {
+		cli.PrintCommandHelp("notify")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("notify")
+		return nil
+	case "slack":
+		fs := flag.NewFlagSet("notify slack", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		boardID := cfg.BoardID
+		webhook := os.Getenv("SLACK_WEBHOOK_URL")
+		var types cli.ActionTypesFlag
+		interval := 30 * time.Second
+		fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+		fs.StringVar(&webhook, "webhook-url", webhook, "Slack incoming webhook URL")
+		fs.Var(&types, "events", "Action types, comma-separated or repeated")
+		fs.DurationVar(&interval, "interval", interval, "Time between polls")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("notify")); err != nil {
+			return err
+		}
+		if fs.NArg() > 0 {
+			return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+		}
+		boardID = cfg.File.ResolveBoardAlias(boardID)
+		if strings.TrimSpace(boardID) == "" {
+			return cli.ErrNoBoard
+		}
+		if strings.TrimSpace(webhook) == "" {
+			return errors.New("notify slack requires --webhook-url or SLACK_WEBHOOK_URL")
+		}
+		if interval < time.Second {
+			return errors.New("--interval must be at least 1s")
+		}
+		if len(types) == 0 {
+			types = cli.ActionTypesFlag{"createCard", "commentCard"}
+		}
+
+		transport, err := cli.NewTransport(cfg)
+		if err != nil {
+			return err
+		}
+		slack := &slackWebhook{url: webhook, http: &http.Client{Transport: transport, Timeout: cfg.Timeout}}
+		post := func(ctx context.Context, a cli.NotifyAction) error {
+			text := slackMessage(a)
+			if cfg.DryRun {
+				// The webhook URL is a credential and is never printed.
+				err := client.PrintDryRun(http.MethodPost, "(Slack webhook)", nil, url.Values{"text": {text}})
+				if errors.Is(err, cli.ErrDryRun) {
+					return nil
+				}
+				return err
+			}
+			if err := slack.post(ctx, text); err != nil {
+				return fmt.Errorf("posting to Slack: %w", err)
+			}
+			return nil
+		}
+
+		client.LongRunning()
+		cursor, err := cli.StartNotifyCursor(ctx, client, boardID)
+		if err != nil {
+			return err
+		}
+		slog.Info(fmt.Sprintf("Watching board %s for %s every %s; Ctrl-C stops", boardID, strings.Join(types, ", "), interval), "board", boardID)
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-tick.C:
+			}
+			if err := cli.NotifyNewActions(ctx, client, boardID, &cursor, types, post); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				slog.Warn(fmt.Sprintf("polling board %s: %v", boardID, err), "board", boardID)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown notify subcommand %q", args[0])
+	}
+}
+
+// slackMarkup formats in Slack mrkdwn, linking the card.
+var slackMarkup = cli.ActionMarkup{
+	Member: func(s string) string { return "*" + slackEscape(s) + "*" },
+	List:   func(s string) string { return "_" + slackEscape(s) + "_" },
+	Card: func(name, shortLink string) string {
+		if shortLink != "" {
+			return "<https://trello.com/c/" + shortLink + "|" + slackEscape(name) + ">"
+		}
+		return "*" + slackEscape(name) + "*"
+	},
+	Name:  func(s string) string { return `"` + slackEscape(s) + `"` },
+	Quote: func(s string) string { return "> " + strings.ReplaceAll(slackEscape(s), "\n", "\n> ") },
+}
+
+// slackMessage formats an action in Slack mrkdwn, linking the card.
+func slackMessage(a cli.NotifyAction) string {
+	return cli.DescribeAction(a, slackMarkup)
+}
+
+// slackEscape escapes the characters Slack treats as markup.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// slackWebhook posts messages to a Slack incoming webhook.
+type slackWebhook struct {
+	url  string
+	http *http.Client
+}
+
+func (s *slackWebhook) post(ctx context.Context, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		// Errors from url.Parse quote the URL; keep the secret out of logs.
+		return errors.New("invalid Slack webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.http.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			return uerr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
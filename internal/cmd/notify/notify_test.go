@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+func TestNotifySlack(t *testing.T) {
+	var polls []string
+	trello := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		q := r.URL.Query()
+		if q.Get("limit") == "1" {
+			io.WriteString(w, `[{"id": "a0", "date": "2026-02-10T09:30:00.000Z"}]`)
+			return
+		}
+		polls = append(polls, q.Get("since")+" "+q.Get("filter"))
+		// a0 is dated exactly since and was seen when the cursor started.
+		io.WriteString(w, `[
+			{"id": "a3", "type": "updateCard", "date": "2026-02-10T09:32:00.000Z", "data": {"card": {"name": "Fix login", "shortLink": "AbCd"}, "listBefore": {"name": "To Do"}, "listAfter": {"name": "Done"}}, "memberCreator": {"username": "grace"}},
+			{"id": "a2", "type": "commentCard", "date": "2026-02-10T09:31:00.000Z", "data": {"text": "Fixed <b>here</b>\nand there", "card": {"name": "Fix login", "shortLink": "AbCd"}}, "memberCreator": {"fullName": "Ada Lovelace"}},
+			{"id": "a1", "type": "createCard", "date": "2026-02-10T09:30:00.000Z", "data": {"card": {"name": "Q&A", "shortLink": "QaQa"}, "list": {"name": "To Do"}}, "memberCreator": {"fullName": "Ada Lovelace"}},
+			{"id": "a0", "type": "createCard", "date": "2026-02-10T09:30:00.000Z", "data": {"card": {"name": "Old"}}}
+		]`)
+	}))
+	t.Cleanup(trello.Close)
+	cfg, _, err := clitest.TestConfig(t, trello)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.LongRunning()
+
+	var posted []string
+	post := func(_ context.Context, a cli.NotifyAction) error {
+		posted = append(posted, slackMessage(a))
+		return nil
+	}
+	types := []string{"createCard", "commentCard", "updateCard"}
+	cursor, err := cli.StartNotifyCursor(cfg.Context, client, "b1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := cli.NotifyNewActions(cfg.Context, client, "b1", &cursor, types, post); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clitest.CheckGolden(t, "notify_slack", strings.Join(posted, "\n---\n")+"\n")
+	want := []string{
+		"2026-02-10T09:30:00.000Z createCard,commentCard,updateCard",
+		"2026-02-10T09:32:00.000Z createCard,commentCard,updateCard",
+	}
+	if strings.Join(polls, "|") != strings.Join(want, "|") {
+		t.Errorf("polls = %q, want %q", polls, want)
+	}
+}
+
+func TestSlackWebhookPost(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		if got["text"] == "fail" {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "no_service")
+		}
+	}))
+	t.Cleanup(srv.Close)
+	s := &slackWebhook{url: srv.URL + "/services/T0/B0/secret", http: srv.Client()}
+	if err := s.post(context.Background(), "hello"); err != nil || got["text"] != "hello" {
+		t.Fatalf("post = %v, body %v", err, got)
+	}
+	err := s.post(context.Background(), "fail")
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Fatalf("post error = %v, want one without the webhook URL", err)
+	}
+}
@@ -0,0 +1,124 @@
+// Package open implements "trelli open": open a card or board in the browser.
+package open
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "open",
+	Summary: "Open a card or board in the browser",
+	Description: `Open the web page of a card or board in the default browser. A list
+opens its board. Without flags the default board is opened.`,
+	Usage: []string{"[--card <cardId> | --list <listId> | --board <boardIdOrShortLink>] [--print]"},
+	Options: []cli.Flag{
+		{Name: "card", Arg: "id", Desc: "Card id or shortLink"},
+		{Name: "list", Arg: "id", Desc: "List id (opens its board)"},
+		{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+		{Name: "print", Desc: "Print the URL instead of opening a browser"},
+		cli.JSONOption,
+	},
+	Run:          runOpen,
+	BareIsAction: true,
+}
+
+func runOpen(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help" || args[0] == "help") {
+		cli.PrintCommandHelp("open")
+		return nil
+	}
+	fs := flag.NewFlagSet("open", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var cardID, listID string
+	var printOnly bool
+	boardID := cfg.BoardID
+	fs.StringVar(&cardID, "card", "", "Card id")
+	fs.StringVar(&listID, "list", "", "List id (opens its board)")
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.BoolVar(&printOnly, "print", false, "Print the URL instead of opening it")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("open")); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if strings.TrimSpace(cardID) != "" && strings.TrimSpace(listID) != "" {
+		return errors.New("open takes one of --card, --list, or --board")
+	}
+
+	link, err := resourceURL(ctx, client, cfg.File.ResolveBoardAlias(boardID), listID, cardID)
+	if err != nil {
+		return err
+	}
+	if cfg.Structured() {
+		if !printOnly {
+			if err := openBrowser(link); err != nil {
+				return err
+			}
+		}
+		return cli.Render(cfg, map[string]string{"url": link})
+	}
+	if printOnly {
+		fmt.Println(link)
+		return nil
+	}
+	slog.Info("Opening "+link, "url", link)
+	return openBrowser(link)
+}
+
+// resourceURL returns the web URL of the card, else the list's board, else
+// the board. Lists have no page of their own.
+func resourceURL(ctx context.Context, client *cli.Client, boardID, listID, cardID string) (string, error) {
+	switch {
+	case strings.TrimSpace(cardID) != "":
+		card, err := client.Cards.Get(ctx, cardID, "shortUrl,url")
+		if err != nil {
+			return "", err
+		}
+		return cli.FirstNonEmpty(card.ShortURL, card.URL), nil
+	case strings.TrimSpace(listID) != "":
+		list, err := client.Lists.Get(ctx, listID, "idBoard")
+		if err != nil {
+			return "", err
+		}
+		boardID = list.IDBoard
+	}
+	if strings.TrimSpace(boardID) == "" {
+		return "", errors.New("open needs --card, --list, or --board (no default board configured)")
+	}
+	board, err := client.Boards.Get(ctx, boardID, "url")
+	if err != nil {
+		return "", err
+	}
+	return board.URL, nil
+}
+
+// openBrowser opens link in the default browser.
+func openBrowser(link string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", link)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", link)
+	default:
+		cmd = exec.Command("xdg-open", link)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("opening browser (use --print to get the URL): %w", err)
+	}
+	// Do not wait: some openers block until the browser exits.
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
@@ -0,0 +1,386 @@
+// Package report implements "trelli report": generate shareable board reports.
+package report
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "report",
+	Summary: "Generate shareable board reports",
+	Description: `weekly writes a Markdown report of a board for the last week (or
+--since/--until): the cards completed and created in the period, the
+cards overdue at its end, and a breakdown per member. A card is completed
+when it is moved into a --done-list (default Done) or its due date is
+marked complete. Completions and creations are credited to whoever made
+them; overdue and open cards to the card's members.
+
+--template replaces the built-in Markdown with a Go text/template file.
+It sees the data --json prints, by JSON field name ({{.board.name}},
+{{range .completed}}), and the functions date (2006-01-02 of a timestamp),
+md (Markdown-escaped text), and json.
+
+members counts each member's actions on the board in the period (default
+the last week): cards created, cards completed (moved into a --done-list
+or due date marked complete), comments, other moves between lists, and
+all actions, for retrospectives without a spreadsheet. Members without
+actions in the period are left out.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "weekly", Usage: []string{"weekly [[--board] <id>] [--since <age|date>] [--until <date>] [--done-list <names>] [--template <file>]"}, Flags: []cli.Flag{
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "since", Arg: "age|date", Desc: "Start of the period: an age such as 1w, 7d, or 36h, or a date (default 1w)"},
+			{Name: "until", Arg: "date", Desc: "End of the period (default: now)"},
+			{Name: "done-list", Arg: "names", Desc: "Comma-separated lists that mean done (default Done)"},
+			{Name: "template", Arg: "file", Desc: "Go template file to render instead of the built-in Markdown"},
+		}},
+		{Name: "members", Usage: []string{"members [[--board] <id>] [--since <age|date>] [--until <date>] [--done-list <names>]"}, Flags: []cli.Flag{
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "since", Arg: "age|date", Desc: "Start of the period: an age such as 1w, 7d, or 36h, or a date (default 1w)"},
+			{Name: "until", Arg: "date", Desc: "End of the period (default: now)"},
+			{Name: "done-list", Arg: "names", Desc: "Comma-separated lists that mean done (default Done)"},
+		}},
+	},
+	Options: []cli.Flag{cli.JSONOption},
+	Sections: []cli.HelpSection{{Title: "Examples", Body: `trelli report weekly --board EnGi > week.md
+trelli report weekly --since 2026-02-02 --until 2026-02-09 --done-list Done,Shipped
+trelli report weekly --template team.tmpl | mail -s "Weekly" team@example.com
+trelli report members --board EnGi --since 2w`}},
+	Run: runReport,
+}
+
+// weeklyReport is what report weekly renders, and prints with --json.
+type weeklyReport struct {
+	Board     cli.Board      `json:"board"`
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	Completed []reportCard   `json:"completed"`
+	Created   []reportCard   `json:"created"`
+	Overdue   []reportCard   `json:"overdue"`
+	Members   []reportMember `json:"members"`
+}
+
+type reportCard struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	URL     string   `json:"url"`
+	List    string   `json:"list,omitempty"`
+	Due     string   `json:"due,omitempty"`
+	Date    string   `json:"date,omitempty"`
+	By      string   `json:"by,omitempty"`
+	Members []string `json:"members,omitempty"`
+}
+
+type reportMember struct {
+	Name      string `json:"name"`
+	Username  string `json:"username"`
+	Completed int    `json:"completed"`
+	Created   int    `json:"created"`
+	Overdue   int    `json:"overdue"`
+	Open      int    `json:"open"`
+}
+
+// weeklyTemplate is the built-in report.
+const weeklyTemplate = `# {{md .board.name}}: week of {{date .from}}
+
+{{date .from}} to {{date .to}}, [board]({{.board.url}})
+
+## Completed ({{len .completed}})
+
+{{range .completed}}- [{{md .name}}]({{.url}}){{with .by}} by {{md .}}{{end}}
+{{else}}Nothing completed.
+{{end}}
+## New cards ({{len .created}})
+
+{{range .created}}- [{{md .name}}]({{.url}}){{with .list}} in {{md .}}{{end}}{{with .by}} by {{md .}}{{end}}
+{{else}}No new cards.
+{{end}}
+## Overdue ({{len .overdue}})
+
+{{range .overdue}}- [{{md .name}}]({{.url}}), due {{date .due}}{{with .list}} in {{md .}}{{end}}{{with .members}} ({{range $i, $m := .}}{{if $i}}, {{end}}{{md $m}}{{end}}){{end}}
+{{else}}Nothing overdue.
+{{end}}
+## By member
+
+{{if .members}}| Member | Completed | Created | Overdue | Open |
+| --- | ---: | ---: | ---: | ---: |
+{{range .members}}| {{md .name}} | {{.completed}} | {{.created}} | {{.overdue}} | {{.open}} |
+{{end}}{{else}}No member activity.
+{{end}}`
+
+func runReport(client *cli.Client, cfg cli.Config, args []string) error {
+	if len(args) == 0 {
+		cli.PrintCommandHelp("report")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("report")
+		return nil
+	case "weekly":
+		return runReportWeekly(cfg.Context, client, cfg, args[1:])
+	case "members":
+		return runReportMembers(cfg.Context, client, cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown report subcommand %q", args[0])
+	}
+}
+
+func runReportWeekly(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("report weekly", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boardID := cfg.BoardID
+	since, until, doneLists := "1w", "", "Done"
+	var tmplFile string
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&since, "since", since, "Start of the period")
+	fs.StringVar(&until, "until", "", "End of the period")
+	fs.StringVar(&doneLists, "done-list", doneLists, "Lists that mean done")
+	fs.StringVar(&tmplFile, "template", "", "Go template file")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("report")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &boardID); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(boardID)
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+	from, to, err := parsePeriod(since, until)
+	if err != nil {
+		return err
+	}
+
+	// Parse the template before spending API calls on the report.
+	tmpl := weeklyTemplate
+	if tmplFile != "" {
+		data, err := os.ReadFile(tmplFile)
+		if err != nil {
+			return err
+		}
+		tmpl = string(data)
+	}
+	t, err := template.New("report").Funcs(reportFuncs).Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("--template: %w", err)
+	}
+
+	report, err := buildWeeklyReport(ctx, client, boardID, from, to, cli.SplitIDs(doneLists))
+	if err != nil {
+		return err
+	}
+	if cfg.Structured() {
+		return cli.Render(cfg, report)
+	}
+	// With --redact the template sees the redacted report, as a renderer
+	// would, and names it spells out itself are redacted in its output.
+	raw, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	data, err := cli.JSONValue(json.RawMessage(cfg.Redact.RedactJSON(raw)))
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return fmt.Errorf("--template: %w", err)
+	}
+	_, err = io.WriteString(os.Stdout, cfg.Redact.RedactText(buf.String()))
+	return err
+}
+
+// parsePeriod reads --since and --until; until defaults to now.
+func parsePeriod(since, until string) (from, to time.Time, err error) {
+	to = time.Now().UTC()
+	if until != "" {
+		if to, err = cli.ParseDate(until); err != nil {
+			return from, to, fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+	if from, err = cli.ParseSince(since, to); err != nil {
+		return from, to, fmt.Errorf("invalid --since: %w", err)
+	}
+	if !from.Before(to) {
+		return from, to, errors.New("--since must be before --until")
+	}
+	return from, to, nil
+}
+
+var reportFuncs = template.FuncMap{
+	"json": cli.TemplateJSON,
+	"date": func(v any) string {
+		s, _ := v.(string)
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t.UTC().Format("2006-01-02")
+		}
+		return s
+	},
+	"md": markdownEscape,
+}
+
+// markdownEscape escapes the characters that would turn text into Markdown
+// or break a link or table cell.
+func markdownEscape(v any) string {
+	s := fmt.Sprint(v)
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\`*_[]<>|#", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// buildWeeklyReport reads the board's cards and members and its actions
+// in [from, to).
+func buildWeeklyReport(ctx context.Context, client *cli.Client, boardID string, from, to time.Time, doneLists []string) (weeklyReport, error) {
+	var board cli.Board
+	var lists []cli.TrelloList
+	var members []cli.Member
+	var cards []cli.ReportBoardCard
+	query := url.Values{}
+	query.Set("fields", "id,name,idList,shortUrl,url,due,dueComplete,closed,idMembers")
+	membersQuery := url.Values{}
+	membersQuery.Set("fields", "id,username,fullName")
+	if err := client.GetAll(ctx,
+		cli.BoardRequest(boardID, &board),
+		cli.BoardListsRequest(boardID, &lists),
+		cli.GetRequest{Path: "/1/boards/" + url.PathEscape(boardID) + "/members", Query: membersQuery, Out: &members},
+		cli.GetRequest{Path: "/1/boards/" + url.PathEscape(boardID) + "/cards", Query: query, Out: &cards},
+	); err != nil {
+		return weeklyReport{}, err
+	}
+
+	listNames := map[string]string{}
+	for _, l := range lists {
+		listNames[l.ID] = l.Name
+	}
+	byID := map[string]cli.ReportBoardCard{}
+	for _, c := range cards {
+		byID[c.ID] = c
+	}
+	memberNames := map[string]string{}
+	stats := map[string]*reportMember{}
+	for _, m := range members {
+		memberNames[m.ID] = cli.FirstNonEmpty(m.FullName, m.Username)
+		stats[m.Username] = &reportMember{Name: cli.FirstNonEmpty(m.FullName, m.Username), Username: m.Username}
+	}
+	credit := func(username, fullName string) *reportMember {
+		if stats[username] == nil {
+			// Former members still get credit for their work.
+			stats[username] = &reportMember{Name: cli.FirstNonEmpty(fullName, username), Username: username}
+		}
+		return stats[username]
+	}
+
+	report := weeklyReport{Board: board, From: from, To: to, Completed: []reportCard{}, Created: []reportCard{}, Overdue: []reportCard{}}
+	describe := func(a cli.NotifyAction) reportCard {
+		c := a.Data.Card
+		rc := reportCard{ID: c.ID, Name: c.Name, URL: "https://trello.com/c/" + c.ShortLink, Date: a.Date, By: cli.FirstNonEmpty(a.MemberCreator.FullName, a.MemberCreator.Username)}
+		if current, ok := byID[c.ID]; ok {
+			rc.Name, rc.URL, rc.List = current.Name, cli.FirstNonEmpty(current.ShortURL, rc.URL), listNames[current.IDList]
+		}
+		return rc
+	}
+	completed := map[string]bool{}
+	cursor := cli.NotifyCursor{Since: from.Format("2006-01-02T15:04:05.000Z"), Seen: map[string]bool{}}
+	err := cli.NotifyNewActions(ctx, client, boardID, &cursor, reportActionTypes, func(_ context.Context, a cli.NotifyAction) error {
+		if a.Data.Card == nil || a.Date >= to.Format("2006-01-02T15:04:05.000Z") {
+			return nil
+		}
+		switch {
+		case a.Type == "updateCard" && isDoneAction(a, doneLists):
+			if completed[a.Data.Card.ID] {
+				return nil
+			}
+			completed[a.Data.Card.ID] = true
+			report.Completed = append(report.Completed, describe(a))
+			credit(a.MemberCreator.Username, a.MemberCreator.FullName).Completed++
+		case a.Type != "updateCard":
+			rc := describe(a)
+			if rc.List == "" && a.Data.List != nil {
+				rc.List = a.Data.List.Name
+			}
+			report.Created = append(report.Created, rc)
+			credit(a.MemberCreator.Username, a.MemberCreator.FullName).Created++
+		}
+		return nil
+	})
+	if err != nil {
+		return weeklyReport{}, err
+	}
+
+	usernames := map[string]string{}
+	for _, m := range members {
+		usernames[m.ID] = m.Username
+	}
+	for _, c := range cards {
+		if c.Closed {
+			continue
+		}
+		due, err := time.Parse(time.RFC3339, c.Due)
+		overdue := err == nil && !c.DueComplete && due.Before(to)
+		var names []string
+		for _, id := range c.IDMembers {
+			if s := stats[usernames[id]]; s != nil {
+				s.Open++
+				if overdue {
+					s.Overdue++
+				}
+			}
+			if name := memberNames[id]; name != "" {
+				names = append(names, name)
+			}
+		}
+		if overdue {
+			report.Overdue = append(report.Overdue, reportCard{ID: c.ID, Name: c.Name, URL: c.ShortURL, List: listNames[c.IDList], Due: c.Due, Members: names})
+		}
+	}
+	slices.SortStableFunc(report.Overdue, func(a, b reportCard) int { return strings.Compare(a.Due, b.Due) })
+
+	for _, s := range stats {
+		if s.Completed+s.Created+s.Overdue+s.Open > 0 {
+			report.Members = append(report.Members, *s)
+		}
+	}
+	slices.SortFunc(report.Members, func(a, b reportMember) int {
+		return cmp.Or(cmp.Compare(b.Completed, a.Completed), strings.Compare(a.Name, b.Name))
+	})
+	if report.Members == nil {
+		report.Members = []reportMember{}
+	}
+	return report, nil
+}
+
+// reportActionTypes are the actions that create or complete cards.
+var reportActionTypes = []string{"createCard", "copyCard", "convertToCardFromCheckItem", "moveCardToBoard", "updateCard"}
+
+// isDoneAction reports whether an updateCard action completed the card:
+// moved it into a done list or marked its due date complete.
+func isDoneAction(a cli.NotifyAction, doneLists []string) bool {
+	if a.Data.ListAfter != nil && slices.ContainsFunc(doneLists, func(name string) bool { return strings.EqualFold(name, a.Data.ListAfter.Name) }) {
+		return true
+	}
+	if _, ok := a.Data.Old["dueComplete"]; ok && a.Data.Card.DueComplete != nil {
+		return *a.Data.Card.DueComplete
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package report
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+// memberActivity is one member's row in report members.
+type memberActivity struct {
+	Name      string `json:"name"`
+	Username  string `json:"username"`
+	Created   int    `json:"created"`
+	Completed int    `json:"completed"`
+	Comments  int    `json:"comments"`
+	Moved     int    `json:"moved"`
+	Actions   int    `json:"actions"`
+}
+
+func runReportMembers(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("report members", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boardID := cfg.BoardID
+	since, until, doneLists := "1w", "", "Done"
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&since, "since", since, "Start of the period")
+	fs.StringVar(&until, "until", "", "End of the period")
+	fs.StringVar(&doneLists, "done-list", doneLists, "Lists that mean done")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("report")); err != nil {
+		return err
+	}
+	var positionalBoard string
+	if err := cli.TakePositional(fs, &positionalBoard); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(positionalBoard, boardID))
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+	from, to, err := parsePeriod(since, until)
+	if err != nil {
+		return err
+	}
+	rows, err := buildMemberActivity(ctx, client, boardID, from, to, cli.SplitIDs(doneLists))
+	if err != nil {
+		return err
+	}
+	return cli.Render(cfg, rows, memberActivityTable(rows))
+}
+
+// buildMemberActivity counts the board's actions in [from, to) per member,
+// most active first.
+func buildMemberActivity(ctx context.Context, client *cli.Client, boardID string, from, to time.Time, doneLists []string) ([]memberActivity, error) {
+	var members []cli.Member
+	query := url.Values{}
+	query.Set("fields", "id,username,fullName")
+	if err := client.Do(ctx, http.MethodGet, "/1/boards/"+url.PathEscape(boardID)+"/members", query, nil, &members); err != nil {
+		return nil, err
+	}
+	stats := map[string]*memberActivity{}
+	for _, m := range members {
+		stats[m.Username] = &memberActivity{Name: cli.FirstNonEmpty(m.FullName, m.Username), Username: m.Username}
+	}
+
+	end := to.Format("2006-01-02T15:04:05.000Z")
+	cursor := cli.NotifyCursor{Since: from.Format("2006-01-02T15:04:05.000Z"), Seen: map[string]bool{}}
+	err := cli.NotifyNewActions(ctx, client, boardID, &cursor, nil, func(_ context.Context, a cli.NotifyAction) error {
+		if a.Date >= end {
+			return nil
+		}
+		who := a.MemberCreator
+		s := stats[who.Username]
+		if s == nil {
+			// Former members still get credit for their work.
+			s = &memberActivity{Name: cli.FirstNonEmpty(who.FullName, who.Username), Username: who.Username}
+			stats[who.Username] = s
+		}
+		s.Actions++
+		switch {
+		case a.Type == "commentCard":
+			s.Comments++
+		case a.Type == "updateCard" && a.Data.Card != nil && isDoneAction(a, doneLists):
+			s.Completed++
+		case a.Type == "updateCard" && a.Data.ListAfter != nil:
+			s.Moved++
+		case slices.Contains(reportActionTypes, a.Type) && a.Type != "updateCard":
+			s.Created++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []memberActivity{}
+	for _, s := range stats {
+		if s.Actions > 0 {
+			rows = append(rows, *s)
+		}
+	}
+	slices.SortFunc(rows, func(a, b memberActivity) int {
+		return cmp.Or(cmp.Compare(b.Actions, a.Actions), strings.Compare(a.Name, b.Name))
+	})
+	return rows, nil
+}
+
+func memberActivityTable(rows []memberActivity) cli.Table {
+	t := cli.Table{Columns: []string{"MEMBER", "USERNAME", "CREATED", "COMPLETED", "COMMENTS", "MOVED", "ACTIONS"}, Empty: "No member activity."}
+	for _, r := range rows {
+		t.Rows = append(t.Rows, []string{r.Name, r.Username, strconv.Itoa(r.Created), strconv.Itoa(r.Completed), strconv.Itoa(r.Comments), strconv.Itoa(r.Moved), strconv.Itoa(r.Actions)})
+	}
+	return t
+}
@@ -0,0 +1,62 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// TestMain registers the command as main does, for the tests that run it
+// through the CLI.
+func TestMain(m *testing.M) {
+	cli.Register(Command)
+	os.Exit(m.Run())
+}
+
+var reportRoutes = map[string]string{
+	"/1/boards/b1/members": `[{"id": "m1", "username": "ada", "fullName": "Ada Lovelace"}, {"id": "m2", "username": "grace", "fullName": "Grace Hopper"}]`,
+	"/1/boards/b1/cards": `[
+		{"id": "c1", "name": "Fix login, again", "idList": "l1", "due": "2026-02-01T12:00:00.000Z", "dueComplete": false, "shortUrl": "https://trello.com/c/AbCd", "closed": false, "idMembers": ["m1", "m2"]},
+		{"id": "c2", "name": "Write \"release\" notes", "idList": "l1", "due": "2026-02-20T12:00:00.000Z", "dueComplete": true, "shortUrl": "https://trello.com/c/EfGh", "closed": false, "idMembers": ["m2"]},
+		{"id": "c3", "name": "Old idea", "idList": "l2", "shortUrl": "https://trello.com/c/IjKl", "closed": true}
+	]`,
+	"/1/boards/b1/actions": `[
+		{"id": "a5", "type": "updateCard", "date": "2026-02-12T10:00:00.000Z", "data": {"card": {"id": "c1", "name": "Fix login, again", "shortLink": "AbCd"}, "listBefore": {"name": "To Do"}, "listAfter": {"name": "Done"}}, "memberCreator": {"username": "ada", "fullName": "Ada Lovelace"}},
+		{"id": "a4", "type": "updateCard", "date": "2026-02-06T10:00:00.000Z", "data": {"card": {"id": "c2", "name": "Write \"release\" notes", "shortLink": "EfGh", "dueComplete": true}, "old": {"dueComplete": false}}, "memberCreator": {"username": "grace", "fullName": "Grace Hopper"}},
+		{"id": "a3", "type": "updateCard", "date": "2026-02-05T10:00:00.000Z", "data": {"card": {"id": "c3", "name": "Old idea", "shortLink": "IjKl"}, "listBefore": {"name": "To Do"}, "listAfter": {"name": "Done"}}, "memberCreator": {"username": "ada", "fullName": "Ada Lovelace"}},
+		{"id": "a2", "type": "createCard", "date": "2026-02-04T10:00:00.000Z", "data": {"card": {"id": "c2", "name": "Write notes", "shortLink": "EfGh"}, "list": {"name": "To Do"}}, "memberCreator": {"username": "ada", "fullName": "Ada Lovelace"}},
+		{"id": "a1", "type": "updateCard", "date": "2026-02-03T10:00:00.000Z", "data": {"card": {"id": "c1", "name": "Fix login, again", "shortLink": "AbCd"}, "old": {"name": "Fix login"}}, "memberCreator": {"username": "ada", "fullName": "Ada Lovelace"}},
+		{"id": "a0", "type": "createCard", "date": "2026-01-30T10:00:00.000Z", "data": {"card": {"id": "c1", "name": "Fix login", "shortLink": "AbCd"}, "list": {"name": "To Do"}}, "memberCreator": {"username": "ada", "fullName": "Ada Lovelace"}}
+	]`,
+}
+
+func TestReportWeekly(t *testing.T) {
+	clitest.WithStubRoutes(t, reportRoutes)
+	stub := clitest.NewStub(t)
+	clitest.CheckGolden(t, "report_weekly", clitest.RunCLI(t, stub, "report", "weekly", "--since", "2026-02-02", "--until", "2026-02-09"))
+	clitest.CheckGolden(t, "report_weekly_json", clitest.RunCLI(t, stub, "--json", "report", "weekly", "--since", "1w", "--until", "2026-02-09"))
+	clitest.CheckGolden(t, "report_weekly_redact", clitest.RunCLI(t, stub, "--redact", "report", "weekly", "--since", "2026-02-02", "--until", "2026-02-09"))
+
+	tmpl := filepath.Join(t.TempDir(), "short.tmpl")
+	if err := os.WriteFile(tmpl, []byte("{{.board.name}}: {{len .completed}} done, {{len .overdue}} overdue\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := clitest.RunCLI(t, stub, "report", "weekly", "--until", "2026-02-09", "--template", tmpl); got != "Engineering: 2 done, 1 overdue\n" {
+		t.Errorf("custom template = %q", got)
+	}
+}
+
+func TestReportMembers(t *testing.T) {
+	clitest.WithStubRoutes(t, reportRoutes)
+	clitest.WithStubRoutes(t, map[string]string{"/1/boards/b1/actions": strings.Replace(reportRoutes["/1/boards/b1/actions"], `{"id": "a4"`,
+		`{"id": "a7", "type": "commentCard", "date": "2026-02-07T10:00:00.000Z", "data": {"text": "Done?", "card": {"id": "c1", "name": "Fix login, again"}}, "memberCreator": {"username": "grace", "fullName": "Grace Hopper"}},
+		{"id": "a6", "type": "updateCard", "date": "2026-02-06T11:00:00.000Z", "data": {"card": {"id": "c1", "name": "Fix login, again"}, "listBefore": {"name": "To Do"}, "listAfter": {"name": "Review"}}, "memberCreator": {"username": "linus", "fullName": "Linus"}},
+		{"id": "a4"`, 1)})
+	stub := clitest.NewStub(t)
+	clitest.CheckGolden(t, "report_members", clitest.RunCLI(t, stub, "report", "members", "--since", "2026-02-02", "--until", "2026-02-09"))
+	clitest.CheckGolden(t, "report_members_json", clitest.RunCLI(t, stub, "--json", "report", "members", "b1", "--since", "2026-02-06", "--until", "2026-02-09"))
+}
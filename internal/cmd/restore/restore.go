@@ -0,0 +1,197 @@
+// Package restore implements "trelli restore": recreate board content from a backup.
+package restore
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"trelli/internal/cli"
+	"trelli/internal/cmd/importcmd"
+)
+
+var Command = cli.Command{
+	Name:    "restore",
+	Summary: "Recreate board content from a backup",
+	Description: `Recreate what a backup (from backup or boards export) holds and a board
+lacks: the lists and named labels, then each card of the backup with its
+description, labels, due date, checklists, and comments. Cards that still
+exist on the board are skipped, as are cards restored before, which link
+to the card they were restored from. Comments are posted by you, starting
+with their author and date.
+
+The board defaults to the one backed up; --into-board restores into
+another. --only-lists restores only the cards of the named lists. Pass the
+global --dry-run to see what would be created first.`,
+	Usage: []string{`[--file] <backup.json> [--into-board <id>] [--only-lists <names>]`},
+	Options: []cli.Flag{
+		{Name: "file", Arg: "path", Desc: "Backup file written by backup or boards export"},
+		{Name: "into-board", Arg: "id", Desc: "Board id, shortLink, or alias to restore into (default: the backed up board)"},
+		{Name: "only-lists", Arg: "names", Desc: "Comma-separated list names whose cards to restore (default: all)"},
+		cli.JSONOption,
+	},
+	Sections: []cli.HelpSection{{Title: "Examples", Body: `trelli --dry-run restore backups/EnGi-2026-01-31T023000Z.json --only-lists Done
+trelli restore --file backups/EnGi-2026-01-31T023000Z.json --into-board NeWb`}},
+	Run: runRestore,
+}
+
+func runRestore(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		cli.PrintCommandHelp("restore")
+		return nil
+	}
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var file, boardID, onlyLists string
+	fs.StringVar(&file, "file", "", "Backup file")
+	fs.StringVar(&boardID, "into-board", "", "Board to restore into")
+	fs.StringVar(&onlyLists, "only-lists", "", "List names whose cards to restore")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("restore")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &file); err != nil {
+		return err
+	}
+	if file == "" {
+		return errors.New("missing --file")
+	}
+	backup, err := ReadBackup(file)
+	if err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(boardID, backup.Board.ID))
+	if strings.TrimSpace(boardID) == "" {
+		return errors.New("missing --into-board and the backup names no board")
+	}
+
+	layout, items, ids, err := restoreItems(backup, cli.SplitIDs(onlyLists))
+	if err != nil {
+		return err
+	}
+	items, skipped, err := skipExistingCards(ctx, client, boardID, items, ids)
+	if err != nil {
+		return err
+	}
+	steps, err := cli.ImportItems(ctx, client, cfg, boardID, layout, items)
+	if err != nil {
+		return err
+	}
+	steps = append(steps, skipped...)
+	return cli.Render(cfg, cli.NonNil(steps), cli.ImportTable(steps))
+}
+
+// restoreItems maps a backup to the lists and labels to create and a card
+// per open card, keeping only the cards of onlyLists when given. ids holds
+// the id of each item's card.
+func restoreItems(backup cli.BoardExport, onlyLists []string) (layout cli.ImportLayout, items []cli.ImportItem, ids []string, err error) {
+	lists := slices.Clone(backup.Lists)
+	slices.SortStableFunc(lists, func(a, b cli.TrelloList) int { return cmp.Compare(a.Pos, b.Pos) })
+	listOrder := map[string]int{}
+	listNames := map[string]string{}
+	for _, l := range lists {
+		if l.Closed || (len(onlyLists) > 0 && !slices.ContainsFunc(onlyLists, func(name string) bool { return strings.EqualFold(name, l.Name) })) {
+			continue
+		}
+		listOrder[l.ID] = len(layout.Lists)
+		listNames[l.ID] = l.Name
+		layout.Lists = append(layout.Lists, l.Name)
+	}
+	for _, name := range onlyLists {
+		if !slices.ContainsFunc(layout.Lists, func(l string) bool { return strings.EqualFold(l, name) }) {
+			return layout, nil, nil, fmt.Errorf("no open list %q in the backup", name)
+		}
+	}
+	labelNames := map[string]string{}
+	for _, l := range backup.Labels {
+		if l.Name != "" {
+			labelNames[l.ID] = l.Name
+			layout.Labels = append(layout.Labels, l)
+		}
+	}
+
+	var cards []cli.ExportedCard
+	for _, c := range backup.Cards {
+		if _, ok := listOrder[c.IDList]; ok && !c.Closed {
+			cards = append(cards, c)
+		}
+	}
+	slices.SortStableFunc(cards, func(a, b cli.ExportedCard) int {
+		return cmp.Or(cmp.Compare(listOrder[a.IDList], listOrder[b.IDList]), cmp.Compare(a.Pos, b.Pos))
+	})
+	for _, c := range cards {
+		item := cli.ImportItem{
+			Key:         cli.ShortLinkOf(c.Card),
+			Name:        c.Name,
+			Desc:        c.Desc,
+			List:        listNames[c.IDList],
+			Due:         c.Due,
+			DueComplete: c.DueComplete,
+			Link:        cli.FirstNonEmpty(c.ShortURL, c.URL),
+			Checklists:  c.Checklists,
+		}
+		for _, id := range c.IDLabels {
+			if name, ok := labelNames[id]; ok {
+				item.Labels = append(item.Labels, name)
+			}
+		}
+		// Comments are stored newest first.
+		for i := len(c.Comments) - 1; i >= 0; i-- {
+			cm := c.Comments[i]
+			item.Comments = append(item.Comments, importcmd.ImportedComment(cli.FirstNonEmpty(cm.MemberCreator.FullName, cm.MemberCreator.Username), cm.Date, cm.Data.Text))
+		}
+		items = append(items, item)
+		ids = append(ids, c.ID)
+	}
+	return layout, items, ids, nil
+}
+
+// skipExistingCards drops the items whose card is still on the board, as
+// when restoring into the backed up board, and returns them as steps.
+func skipExistingCards(ctx context.Context, client *cli.Client, boardID string, items []cli.ImportItem, ids []string) ([]cli.ImportItem, []cli.ImportStep, error) {
+	cards, err := cli.FetchLinkedCards(ctx, client, boardID)
+	if err != nil {
+		return nil, nil, err
+	}
+	closed := map[string]bool{}
+	for _, c := range cards {
+		closed[c.ID] = c.Closed
+	}
+	var keep []cli.ImportItem
+	var skipped []cli.ImportStep
+	for i, item := range items {
+		isClosed, ok := closed[ids[i]]
+		switch {
+		case !ok:
+			keep = append(keep, item)
+		case isClosed:
+			skipped = append(skipped, cli.ImportStep{Action: "skip (archived)", Issue: item.Key, Name: item.Name, List: item.List, Card: ids[i]})
+		default:
+			skipped = append(skipped, cli.ImportStep{Action: "skip (exists)", Issue: item.Key, Name: item.Name, List: item.List, Card: ids[i]})
+		}
+	}
+	return keep, skipped, nil
+}
+
+// ReadBackup reads a backup written by backup or boards export.
+func ReadBackup(file string) (cli.BoardExport, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return cli.BoardExport{}, err
+	}
+	var backup cli.BoardExport
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return cli.BoardExport{}, fmt.Errorf("reading %s: %w", file, err)
+	}
+	if backup.Lists == nil || backup.Cards == nil {
+		return cli.BoardExport{}, fmt.Errorf("reading %s: not a backup written by backup or boards export", file)
+	}
+	return backup, nil
+}
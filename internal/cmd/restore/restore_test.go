@@ -0,0 +1,32 @@
+package restore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRestoreItems(t *testing.T) {
+	backup, err := ReadBackup("testdata/backup/EnGi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, items, ids, err := restoreItems(backup, []string{"TO DO"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || ids[1] != "c7" {
+		t.Fatalf("items = %+v, ids = %q", items, ids)
+	}
+	item := items[1]
+	if !item.DueComplete || len(item.Checklists) != 1 || len(item.Comments) != 2 || item.Link != "https://trello.com/c/CuSt" {
+		t.Errorf("item = %+v", item)
+	}
+	// Oldest first, with their author.
+	if !strings.HasPrefix(item.Comments[0], "grace wrote on 2026-01-20 10:00 UTC:") || !strings.HasSuffix(item.Comments[1], "\n\nSent.") {
+		t.Errorf("comments = %q", item.Comments)
+	}
+
+	if _, _, _, err := restoreItems(backup, []string{"Doing"}); err == nil {
+		t.Error("restoreItems accepted a list missing from the backup")
+	}
+}
@@ -0,0 +1,54 @@
+// Package rpc implements "trelli rpc": serve JSON-RPC 2.0 on stdin/stdout for editor integrations.
+package rpc
+
+import (
+	"fmt"
+	"os"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "rpc",
+	Summary: "Serve JSON-RPC 2.0 on stdin/stdout for editor integrations",
+	Description: `Run as a backend process for editor plugins: read one JSON-RPC 2.0
+request per line on stdin and write one response per line on stdout, in
+order, until stdin closes. Requests share one client, so credentials,
+rate limiting, caching, and the undo journal work as for commands.
+Diagnostics go to stderr; stdout carries only responses.`,
+	Usage: []string{""},
+	Sections: []cli.HelpSection{{Title: "Methods", Body: `boards.list    {"filter"?}                     Boards of the member
+cards.search   {"query", "board"?, "limit"?}   Cards matching Trello search syntax,
+                                               on one board or all boards
+cards.create   {"name", "idList" or "listName", "board"?, "desc"?, "due"?,
+                "idLabels"?, "idMembers"?}     Create a card
+comments.add   {"card", "text"}                Comment on a card
+
+Failures from Trello use code -32000 with {"message", "status", "hint"}
+as data. Requests without an id are notifications and get no response.
+
+Example:
+  {"jsonrpc": "2.0", "id": 1, "method": "cards.search", "params": {"query": "login"}}`}},
+	Run:          runRPC,
+	BareIsAction: true,
+}
+
+var rpcMethods = map[string]cli.RPCMethod{
+	"boards.list":  cli.RPCListBoards,
+	"cards.search": cli.RPCSearchCards,
+	"cards.create": cli.RPCCreateCard,
+	"comments.add": cli.RPCAddComment,
+}
+
+func runRPC(client *cli.Client, cfg cli.Config, args []string) error {
+	if len(args) > 0 {
+		if args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+			cli.PrintCommandHelp("rpc")
+			return nil
+		}
+		return fmt.Errorf("unexpected argument %q", args[0])
+	}
+	// Dry-run requests must not interleave with responses.
+	client.DryRunOut = os.Stderr
+	return cli.ServeRPC(cfg.Context, client, cfg, rpcMethods, os.Stdin, os.Stdout)
+}
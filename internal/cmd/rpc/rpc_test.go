@@ -0,0 +1,40 @@
+package rpc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+func TestRPC(t *testing.T) {
+	stub := clitest.NewStub(t)
+	cfg, _, err := clitest.TestConfig(t, stub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := strings.Join([]string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "boards.list"}`,
+		`{"jsonrpc": "2.0", "id": "s", "method": "cards.search", "params": {"query": "login", "board": "b1", "limit": 5}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "cards.create", "params": {"listName": "to do", "name": "From the editor"}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "comments.add", "params": {"card": "c1", "text": "Done"}}`,
+		`{"jsonrpc": "2.0", "method": "comments.add", "params": {"card": "c1", "text": "notification"}}`,
+		`{"jsonrpc": "2.0", "id": 6, "method": "cards.create", "params": {"name": "No list"}}`,
+		`{"jsonrpc": "2.0", "id": 7, "method": "cards.search", "params": {"query": "x", "bogus": true}}`,
+		`{"jsonrpc": "2.0", "id": 8, "method": "cards.delete"}`,
+		`{"id": 9, "method": "boards.list"}`,
+		`{"jsonrpc": "2.0", "id": 10, "method": "cards.create", "params": {"listName": "Someday", "name": "x"}}`,
+		`not json`,
+	}, "\n")
+	var out bytes.Buffer
+	if err := cli.ServeRPC(cfg.Context, client, cfg, rpcMethods, strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	clitest.CheckGolden(t, "rpc_session", out.String())
+}
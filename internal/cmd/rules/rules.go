@@ -0,0 +1,517 @@
+// Package rules implements "trelli rules": automate a board with rules.
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "rules",
+	Summary: "Automate a board with rules",
+	Description: `run applies the rules of a rules file to a board. A rule pairs a
+condition with actions taken on the card that meets it:
+
+  when: moved_to: <list>      a card was moved into the list
+        label_added: <label>  a label was added to a card
+        due_passed: true      a card is past due and not complete
+  then: complete: true|false  set the due date complete or not
+        comment: <text>       comment on the card
+        move_to: <list>       move the card to a list of the board
+        archive: true         archive the card
+
+Moves and labels are read from the board's actions feed, after the point
+the last run reached, which is kept in a state file next to the rules
+file (rules.state.json for rules.yaml). The first run starts at the
+newest action unless --since says otherwise. A due_passed rule fires once
+per card and due date. A moved_to or label_added rule that fails on a
+card is tried again on the next runs, from the action that failed, up to
+5 times; a due_passed rule until it succeeds. Without --watch the rules run once, for cron;
+with it they run every --interval until interrupted. Rules also see the
+changes other rules make, so avoid rules that undo each other. Pass the
+global --dry-run to see what the rules would do.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "run", Usage: []string{"run [--config] <rules.yaml> [--board <id>] [--since <age|date>] [--state <file>] [--watch] [--interval <duration>]"}, Flags: []cli.Flag{
+			{Name: "config", Arg: "path", Desc: "Rules file"},
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the rules file's board, then the default board)"},
+			{Name: "since", Arg: "age|date", Desc: "Read actions from this point instead of where the last run stopped"},
+			{Name: "state", Arg: "path", Desc: "State file (default <rules>.state.json)"},
+			{Name: "watch", Desc: "Keep running, polling every --interval"},
+			{Name: "interval", Arg: "duration", Desc: "Time between polls with --watch (default 1m)"},
+		}},
+	},
+	Options: []cli.Flag{cli.JSONOption},
+	Sections: []cli.HelpSection{{Title: "Rules file", Body: `board: EnGi
+rules:
+  - name: Complete finished cards
+    when: {moved_to: Done}
+    then:
+      - complete: true
+      - comment: Shipped, thanks!
+  - name: Triage bugs
+    when: {label_added: Bug}
+    then:
+      - move_to: Triage
+  - name: Chase overdue cards
+    when: {due_passed: true}
+    then:
+      - comment: This card is past due; please update the date.`}},
+	Run: runRules,
+}
+
+type rulesFile struct {
+	Board string `json:"board"`
+	Rules []rule `json:"rules"`
+}
+
+type rule struct {
+	Name string        `json:"name"`
+	When ruleCondition `json:"when"`
+	Then []ruleAction  `json:"then"`
+}
+
+type ruleCondition struct {
+	MovedTo    string `json:"moved_to"`
+	LabelAdded string `json:"label_added"`
+	DuePassed  bool   `json:"due_passed"`
+}
+
+// ruleAction is one action; exactly one field is set.
+type ruleAction struct {
+	Complete *bool  `json:"complete"`
+	Comment  string `json:"comment"`
+	MoveTo   string `json:"move_to"`
+	Archive  bool   `json:"archive"`
+}
+
+// rulesState is where rules run left off: the actions cursor, the rules
+// that failed on an action from the feed and are retried on the next run,
+// and the due date each due_passed rule last fired for, by card id and
+// rule name.
+type rulesState struct {
+	Since string            `json:"since"`
+	Seen  []string          `json:"seen,omitempty"`
+	Retry []ruleRetry       `json:"retry,omitempty"`
+	Due   map[string]string `json:"due,omitempty"`
+}
+
+// ruleRetry is a rule that failed on a card at action Step of its then
+// list; the actions before Step were taken and are not repeated.
+type ruleRetry struct {
+	Rule      string `json:"rule"`
+	Step      int    `json:"step"`
+	Card      string `json:"card"`
+	ShortLink string `json:"shortLink,omitempty"`
+	Name      string `json:"name"`
+	Attempts  int    `json:"attempts"`
+}
+
+// maxRuleAttempts bounds how often a failing rule is tried on a card
+// before it is given up.
+const maxRuleAttempts = 5
+
+// ruleFiring is an action a rule took on a card.
+type ruleFiring struct {
+	Rule   string `json:"rule"`
+	Card   string `json:"card"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+}
+
+func runRules(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) == 0 {
+		cli.PrintCommandHelp("rules")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("rules")
+		return nil
+	case "run":
+		fs := flag.NewFlagSet("rules run", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		var file, boardID, since, statePath string
+		var watch bool
+		interval := time.Minute
+		fs.StringVar(&file, "config", "", "Rules file")
+		fs.StringVar(&boardID, "board", "", "Board id, shortLink, or alias")
+		fs.StringVar(&since, "since", "", "Read actions from this point")
+		fs.StringVar(&statePath, "state", "", "State file")
+		fs.BoolVar(&watch, "watch", false, "Keep running")
+		fs.DurationVar(&interval, "interval", interval, "Time between polls")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("rules")); err != nil {
+			return err
+		}
+		if err := cli.TakePositional(fs, &file); err != nil {
+			return err
+		}
+		if file == "" {
+			return errors.New("missing --config")
+		}
+		if interval < time.Second {
+			return errors.New("--interval must be at least 1s")
+		}
+		rules, err := loadRules(file)
+		if err != nil {
+			return err
+		}
+		boardID = cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(boardID, rules.Board, cfg.BoardID))
+		if strings.TrimSpace(boardID) == "" {
+			return errors.New("missing --board and no board in the rules file or default board configured")
+		}
+		if statePath == "" {
+			statePath = strings.TrimSuffix(file, filepath.Ext(file)) + ".state.json"
+		}
+		state, err := readRulesState(statePath)
+		if err != nil {
+			return err
+		}
+		if since != "" {
+			from, err := cli.ParseSince(since, time.Now().UTC())
+			if err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+			state.Since, state.Seen = from.Format("2006-01-02T15:04:05.000Z"), nil
+		}
+		if state.Since == "" {
+			cursor, err := cli.StartNotifyCursor(ctx, client, boardID)
+			if err != nil {
+				return err
+			}
+			state.Since, state.Seen = cursor.Since, seenIDs(cursor.Seen)
+		}
+
+		e := &rulesEngine{client: client, cfg: cfg, boardID: boardID, rules: rules.Rules}
+		if !watch {
+			firings, failed, runErr := e.run(ctx, &state, time.Now().UTC())
+			if cfg.DryRun {
+				if runErr != nil {
+					return runErr
+				}
+				return cli.ErrDryRun
+			}
+			// Actions taken before an error are in state too, and must not
+			// be taken again.
+			if err := cli.WriteJSONFile(statePath, state); err != nil {
+				return err
+			}
+			if runErr != nil {
+				return runErr
+			}
+			if err := cli.Render(cfg, cli.NonNil(firings), rulesTable(firings)); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d rule actions failed", failed)
+			}
+			return nil
+		}
+
+		client.LongRunning()
+		slog.Info(fmt.Sprintf("Running %d rules on board %s every %s; Ctrl-C stops", len(rules.Rules), boardID, interval), "board", boardID)
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			firings, _, err := e.run(ctx, &state, time.Now().UTC())
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				slog.Warn(fmt.Sprintf("running rules on board %s: %v", boardID, err), "board", boardID)
+			}
+			if !cfg.DryRun {
+				if err := cli.WriteJSONFile(statePath, state); err != nil {
+					return err
+				}
+			}
+			for _, f := range firings {
+				slog.Info(fmt.Sprintf("%s: %s %q", f.Rule, f.Action, f.Name), "card", f.Card)
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-tick.C:
+			}
+		}
+	default:
+		return fmt.Errorf("unknown rules subcommand %q", args[0])
+	}
+}
+
+func loadRules(file string) (rulesFile, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return rulesFile{}, err
+	}
+	var rf rulesFile
+	if err := cli.DecodeYAML(data, &rf); err != nil {
+		return rulesFile{}, fmt.Errorf("%s: %w", file, err)
+	}
+	if err := rf.validate(); err != nil {
+		return rulesFile{}, fmt.Errorf("%s: %w", file, err)
+	}
+	return rf, nil
+}
+
+func (rf rulesFile) validate() error {
+	if len(rf.Rules) == 0 {
+		return errors.New("no rules")
+	}
+	names := map[string]bool{}
+	for i, r := range rf.Rules {
+		if strings.TrimSpace(r.Name) == "" {
+			return fmt.Errorf("rules[%d]: missing name", i)
+		}
+		if names[r.Name] {
+			return fmt.Errorf("rule %q: duplicate name", r.Name)
+		}
+		names[r.Name] = true
+		conditions := 0
+		for _, set := range []bool{r.When.MovedTo != "", r.When.LabelAdded != "", r.When.DuePassed} {
+			if set {
+				conditions++
+			}
+		}
+		if conditions != 1 {
+			return fmt.Errorf("rule %q: when needs exactly one of moved_to, label_added, due_passed", r.Name)
+		}
+		if len(r.Then) == 0 {
+			return fmt.Errorf("rule %q: no actions", r.Name)
+		}
+		for j, a := range r.Then {
+			actions := 0
+			for _, set := range []bool{a.Complete != nil, a.Comment != "", a.MoveTo != "", a.Archive} {
+				if set {
+					actions++
+				}
+			}
+			if actions != 1 {
+				return fmt.Errorf("rule %q: then[%d] needs exactly one of complete, comment, move_to, archive: true", r.Name, j)
+			}
+		}
+	}
+	return nil
+}
+
+func readRulesState(file string) (rulesState, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return rulesState{}, nil
+	}
+	if err != nil {
+		return rulesState{}, err
+	}
+	var state rulesState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return rulesState{}, fmt.Errorf("reading %s: %w", file, err)
+	}
+	return state, nil
+}
+
+func seenIDs(seen map[string]bool) []string {
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+type rulesEngine struct {
+	client  *cli.Client
+	cfg     cli.Config
+	boardID string
+	rules   []rule
+}
+
+// run retries the rules that failed before, applies the rules to the
+// actions after state's cursor and to the cards past due at now, and
+// advances state. It returns what the rules did and how many actions
+// failed; failures are logged. State reflects every action taken, also
+// when run returns an error.
+func (e *rulesEngine) run(ctx context.Context, state *rulesState, now time.Time) ([]ruleFiring, int, error) {
+	var firings []ruleFiring
+	failed := 0
+	// fire takes the actions of r from step on and returns the step it
+	// stopped at, len(r.Then) when all were taken.
+	fire := func(r rule, step int, cardID, shortLink, name string) int {
+		done, err := e.apply(ctx, r.Then[step:], cardID)
+		for _, action := range done {
+			firings = append(firings, ruleFiring{Rule: r.Name, Card: cli.FirstNonEmpty(shortLink, cardID), Name: name, Action: action})
+		}
+		if err != nil {
+			failed++
+			slog.Warn(fmt.Sprintf("rule %q on card %s: %v", r.Name, cli.FirstNonEmpty(shortLink, cardID), err), "card", cardID)
+		}
+		return step + len(done)
+	}
+	// retry fires a rule on a card from the feed and keeps it for the next
+	// run when it fails.
+	var retries []ruleRetry
+	retry := func(r rule, p ruleRetry) {
+		p.Rule, p.Attempts = r.Name, p.Attempts+1
+		if p.Step = fire(r, p.Step, p.Card, p.ShortLink, p.Name); p.Step == len(r.Then) {
+			return
+		}
+		if p.Attempts >= maxRuleAttempts {
+			slog.Warn(fmt.Sprintf("giving up on rule %q on card %s after %d attempts", r.Name, cli.FirstNonEmpty(p.ShortLink, p.Card), p.Attempts), "card", p.Card)
+			return
+		}
+		retries = append(retries, p)
+	}
+
+	byName := map[string]rule{}
+	for _, r := range e.rules {
+		byName[r.Name] = r
+	}
+	for _, p := range state.Retry {
+		// Rules removed from the file, or shortened, are not retried.
+		if r, ok := byName[p.Rule]; ok && p.Step < len(r.Then) {
+			retry(r, p)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, id := range state.Seen {
+		seen[id] = true
+	}
+	cursor := cli.NotifyCursor{Since: state.Since, Seen: seen}
+	err := cli.NotifyNewActions(ctx, e.client, e.boardID, &cursor, []string{"updateCard", "addLabelToCard"}, func(_ context.Context, a cli.NotifyAction) error {
+		if a.Data.Card == nil {
+			return nil
+		}
+		for _, r := range e.rules {
+			if ruleMatches(r.When, a) {
+				retry(r, ruleRetry{Card: a.Data.Card.ID, ShortLink: a.Data.Card.ShortLink, Name: a.Data.Card.Name})
+			}
+		}
+		return nil
+	})
+	state.Since, state.Seen, state.Retry = cursor.Since, seenIDs(cursor.Seen), retries
+	if err != nil {
+		return firings, failed, err
+	}
+
+	var dueRules []rule
+	for _, r := range e.rules {
+		if r.When.DuePassed {
+			dueRules = append(dueRules, r)
+		}
+	}
+	if len(dueRules) == 0 {
+		return firings, failed, nil
+	}
+	cards, err := fetchDueCards(ctx, e.client, e.boardID)
+	if err != nil {
+		return firings, failed, err
+	}
+	// Only cards still past due are kept, so a card fires again when it
+	// is overdue once more after being completed or rescheduled.
+	fired := map[string]string{}
+	for _, c := range cards {
+		due, err := time.Parse(time.RFC3339, c.Due)
+		if err != nil || c.DueComplete || !due.Before(now) {
+			continue
+		}
+		for _, r := range dueRules {
+			key := c.ID + " " + r.Name
+			if state.Due[key] == c.Due || fire(r, 0, c.ID, cli.ShortLinkOf(cli.Card{ID: c.ID, ShortURL: c.ShortURL}), c.Name) == len(r.Then) {
+				fired[key] = c.Due
+			}
+		}
+	}
+	state.Due = fired
+	return firings, failed, nil
+}
+
+// ruleMatches reports whether action a meets condition w. Names match
+// case-insensitively.
+func ruleMatches(w ruleCondition, a cli.NotifyAction) bool {
+	switch {
+	case w.MovedTo != "":
+		return a.Type == "updateCard" && a.Data.ListAfter != nil && a.Data.ListBefore != nil && strings.EqualFold(a.Data.ListAfter.Name, w.MovedTo)
+	case w.LabelAdded != "":
+		return a.Type == "addLabelToCard" && a.Data.Label != nil && strings.EqualFold(a.Data.Label.Name, w.LabelAdded)
+	}
+	return false
+}
+
+// apply takes actions on a card in order, stopping at the first that
+// fails, and returns the ones taken. In a dry run the requests are
+// printed and every action counts as taken.
+func (e *rulesEngine) apply(ctx context.Context, actions []ruleAction, cardID string) ([]string, error) {
+	var done []string
+	for _, a := range actions {
+		var desc string
+		var err error
+		switch {
+		case a.Complete != nil:
+			desc = "complete"
+			if !*a.Complete {
+				desc = "mark incomplete"
+			}
+			_, err = e.client.Cards.Update(ctx, cardID, url.Values{"dueComplete": {strconv.FormatBool(*a.Complete)}})
+		case a.Comment != "":
+			desc = "comment"
+			_, err = cli.AddComment(ctx, e.client, e.cfg, cardID, a.Comment)
+		case a.MoveTo != "":
+			desc = "move to " + a.MoveTo
+			var listID string
+			if listID, err = cli.ResolveListID(ctx, e.client, e.boardID, "", a.MoveTo); err == nil {
+				_, err = cli.MoveCard(ctx, e.client, e.cfg, cardID, listID)
+			}
+		case a.Archive:
+			desc = "archive"
+			var card cli.Card
+			if card, err = e.client.Cards.Archive(ctx, cardID); err == nil {
+				cli.RecordUndo(e.cfg, cli.JournalEntry{Action: "cards.archive", Target: card.ID, Summary: "archive card " + card.Name})
+			}
+		}
+		if err != nil && !errors.Is(err, cli.ErrDryRun) {
+			return done, fmt.Errorf("%s: %w", desc, err)
+		}
+		done = append(done, desc)
+	}
+	return done, nil
+}
+
+// dueCard is a card as due_passed rules need it.
+type dueCard struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	ShortURL    string `json:"shortUrl"`
+	Due         string `json:"due"`
+	DueComplete bool   `json:"dueComplete"`
+}
+
+func fetchDueCards(ctx context.Context, client *cli.Client, boardID string) ([]dueCard, error) {
+	query := url.Values{}
+	query.Set("fields", "id,name,shortUrl,due,dueComplete")
+	var cards []dueCard
+	err := client.Do(ctx, http.MethodGet, "/1/boards/"+url.PathEscape(boardID)+"/cards", query, nil, &cards)
+	return cards, err
+}
+
+func rulesTable(firings []ruleFiring) cli.Table {
+	t := cli.Table{Columns: []string{"RULE", "CARD", "NAME", "ACTION"}, Empty: "No rules fired."}
+	for _, f := range firings {
+		t.Rows = append(t.Rows, []string{f.Rule, f.Card, f.Name, f.Action})
+	}
+	return t
+}
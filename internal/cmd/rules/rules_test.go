@@ -0,0 +1,143 @@
+package rules
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// TestMain registers the command as main does, for the tests that run it
+// through the CLI.
+func TestMain(m *testing.M) {
+	cli.Register(Command)
+	os.Exit(m.Run())
+}
+
+const testRules = `board: b1
+rules:
+  - name: Complete finished cards
+    when: {moved_to: Done}
+    then:
+      - complete: true
+      - comment: Shipped, thanks!
+  - name: Park bugs
+    when:
+      label_added: bug
+    then:
+      - move_to: done
+  - name: Chase overdue cards
+    when: {due_passed: true}
+    then:
+      - comment: This card is past due.
+`
+
+func TestRulesRun(t *testing.T) {
+	clitest.WithStubRoutes(t, map[string]string{
+		"/1/boards/b1/actions": `[
+			{"id": "a3", "type": "updateCard", "date": "2026-02-05T10:00:00.000Z", "data": {"card": {"id": "c1", "name": "Fix login, again", "shortLink": "AbCd"}, "old": {"name": "Fix login"}}, "memberCreator": {"username": "ada"}},
+			{"id": "a2", "type": "addLabelToCard", "date": "2026-02-04T10:00:00.000Z", "data": {"card": {"id": "c1", "name": "Fix login", "shortLink": "AbCd"}, "label": {"name": "Bug"}}, "memberCreator": {"username": "ada"}},
+			{"id": "a1", "type": "updateCard", "date": "2026-02-03T10:00:00.000Z", "data": {"card": {"id": "c2", "name": "Write notes", "shortLink": "EfGh"}, "listBefore": {"name": "To Do"}, "listAfter": {"name": "Done"}}, "memberCreator": {"username": "ada"}}
+		]`,
+	})
+	stub := clitest.NewStub(t)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "rules.yaml")
+	if err := os.WriteFile(file, []byte(testRules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	clitest.CheckGolden(t, "rules_run", clitest.RunCLI(t, stub, "rules", "run", file, "--since", "2026-02-01"))
+
+	state, err := os.ReadFile(filepath.Join(dir, "rules.state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(state), `"since": "2026-02-05T10:00:00.000Z"`) || !strings.Contains(string(state), `"c1 Chase overdue cards": "2026-03-01T12:00:00.000Z"`) {
+		t.Errorf("state file:\n%s", state)
+	}
+	// Nothing is new on the second run.
+	if got := clitest.RunCLI(t, stub, "rules", "run", "--config", file); got != "No rules fired.\n" {
+		t.Errorf("second run = %q", got)
+	}
+}
+
+func TestLoadRulesErrors(t *testing.T) {
+	dir := t.TempDir()
+	for src, want := range map[string]string{
+		"rules: []": "no rules",
+		"rules:\n  - name: a\n    when: {moved_to: Done, due_passed: true}\n    then: [{archive: true}]":                                                     `rule "a": when needs exactly one of`,
+		"rules:\n  - name: a\n    when: {moved_to: Done}\n    then:\n      - archive: true\n        comment: x":                                              `rule "a": then[0] needs exactly one of`,
+		"rules:\n  - name: a\n    when: {moved_to: Done}\n    then: [{archive: true}]\n  - name: a\n    when: {moved_to: Done}\n    then: [{archive: true}]": `rule "a": duplicate name`,
+		"rules:\n  - name: a\n    when: {moved: Done}\n    then: [{archive: true}]":                                                                          `rules[0].when: unknown field "moved"`,
+	} {
+		file := filepath.Join(dir, "rules.yaml")
+		if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadRules(file); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("loadRules(%q) error = %v, want %q", src, err, want)
+		}
+	}
+}
+
+func TestRulesRunRetry(t *testing.T) {
+	clitest.WithStubRoutes(t, map[string]string{
+		"/1/boards/b1/actions": `[
+			{"id": "a1", "type": "updateCard", "date": "2026-02-03T10:00:00.000Z", "data": {"card": {"id": "c2", "name": "Write notes", "shortLink": "EfGh"}, "listBefore": {"name": "To Do"}, "listAfter": {"name": "Done"}}, "memberCreator": {"username": "ada"}}
+		]`,
+	})
+	stub := clitest.NewStub(t)
+	failComments, failCards := true, true
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failComments && r.URL.Path == "/1/cards/c2/actions/comments" || failCards && r.Method == http.MethodGet && r.URL.Path == "/1/boards/b1/cards" {
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, `{"message": "internal error"}`)
+			return
+		}
+		stub.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(failing.Close)
+	srv, writes := clitest.WriteRecorder(t, failing)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "rules.yaml")
+	if err := os.WriteFile(file, []byte(testRules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The comment fails after the card was completed, and the due pass
+	// fails too; the state still records both.
+	got := clitest.RunCLI(t, srv, "rules", "run", file, "--since", "2026-02-01")
+	if !strings.Contains(got, "--- error") {
+		t.Errorf("first run = %q, want the due pass error", got)
+	}
+	want := []string{"PUT /1/cards/c2", "POST /1/cards/c2/actions/comments"}
+	if !slices.Equal(*writes, want) {
+		t.Errorf("first run writes = %q, want %q", *writes, want)
+	}
+	state, err := readRulesState(filepath.Join(dir, "rules.state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Since != "2026-02-03T10:00:00.000Z" || len(state.Retry) != 1 || state.Retry[0].Step != 1 {
+		t.Errorf("state after a failed run = %+v", state)
+	}
+
+	// The next run only posts the comment.
+	failComments, failCards = false, false
+	*writes = nil
+	clitest.RunCLI(t, srv, "rules", "run", file)
+	want = []string{"POST /1/cards/c2/actions/comments", "POST /1/cards/c1/actions/comments"}
+	if !slices.Equal(*writes, want) {
+		t.Errorf("retry writes = %q, want %q", *writes, want)
+	}
+	if state, _ := readRulesState(filepath.Join(dir, "rules.state.json")); len(state.Retry) != 0 {
+		t.Errorf("retries left after success: %+v", state.Retry)
+	}
+}
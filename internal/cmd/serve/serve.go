@@ -0,0 +1,274 @@
+// Package serve implements "trelli serve": serve a local REST API backed by the client.
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+	"trelli/pkg/trello"
+)
+
+var Command = cli.Command{
+	Name:    "serve",
+	Summary: "Serve a local REST API backed by the client",
+	Description: `Serve a small JSON API on 127.0.0.1 so local tools, editors, and
+dashboards can read and change boards without holding Trello credentials.
+Requests go through the same client as commands: rate limiting, retries,
+ETag revalidation, and the undo journal apply. Identical GETs within
+--cache-ttl are answered once. Ctrl-C stops the server.`,
+	Usage: []string{"[--port <n>] [--addr <host>] [--cache-ttl <duration>]"},
+	Options: []cli.Flag{
+		{Name: "port", Arg: "n", Desc: "Port to listen on (default 7070)"},
+		{Name: "addr", Arg: "host", Desc: "Address to bind (default 127.0.0.1; anything else exposes your token's access)"},
+		{Name: "cache-ttl", Arg: "duration", Desc: "Answer identical GETs from memory for this long (default 10s; 0 disables)"},
+	},
+	Sections: []cli.HelpSection{{Title: "Endpoints", Body: `GET  /boards                     Boards of the member
+GET  /boards/{board}/lists       Open lists of a board (id, shortLink, or alias)
+GET  /lists/{list}/cards         Cards of a list
+GET  /cards/{card}               One card
+POST /cards                      Create a card: {"name", "idList" or "listName"
+                                 with optional "board", "desc", "due",
+                                 "idLabels", "idMembers"}
+POST /cards/{card}/move          Move a card: {"idList" or "listName", "board"}
+
+Errors are {"error": {"message", "status", "hint"}} with a matching HTTP
+status. Requests whose Host is not the listening address, or that come
+from a web page (Origin header), are rejected; POST bodies must be JSON.`}},
+	Run:  cli.Local(runServe),
+	Mode: cli.ModeOnline,
+}
+
+const (
+	defaultServePort     = 7070
+	defaultServeCacheTTL = 10 * time.Second
+	maxServeBody         = 1 << 20
+)
+
+func runServe(cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help" || args[0] == "help") {
+		cli.PrintCommandHelp("serve")
+		return nil
+	}
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var addr string
+	var port int
+	var ttl time.Duration
+	fs.StringVar(&addr, "addr", "127.0.0.1", "Address to bind")
+	fs.IntVar(&port, "port", defaultServePort, "Port to listen on")
+	fs.DurationVar(&ttl, "cache-ttl", defaultServeCacheTTL, "Memoize identical GETs for this long")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("serve")); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if port < 0 || port > 65535 {
+		return fmt.Errorf("invalid --port %d", port)
+	}
+
+	// The spinner means nothing to a server; -v still traces requests.
+	cfg.NoProgress = true
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		client.LongRunning()
+	}
+
+	ln, err := net.Listen("tcp", net.JoinHostPort(addr, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	host := ln.Addr().String()
+	if ip := net.ParseIP(addr); addr != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		slog.Warn(fmt.Sprintf("serving on %s; anyone who can reach it acts with your Trello token", ln.Addr()), "addr", ln.Addr().String())
+		// Clients name the machine however they reach it.
+		host = ""
+	}
+	srv := &http.Server{
+		Handler:           newServeHandler(client, cfg, host),
+		ReadHeaderTimeout: 10 * time.Second,
+		// Requests end with the server: Ctrl-C cancels calls in flight.
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	slog.Info(fmt.Sprintf("Serving on http://%s (Ctrl-C to stop)", ln.Addr()), "addr", ln.Addr().String())
+
+	if ttl > 0 {
+		go func() {
+			tick := time.NewTicker(ttl)
+			defer tick.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-tick.C:
+					client.Memo.Reset()
+				}
+			}
+		}()
+	}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}()
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	client.Stats.Print(os.Stderr, cfg.JSON)
+	return nil
+}
+
+// newServeHandler returns the routes of trelli serve. host is the address
+// clients must name in the Host header; empty accepts any.
+func newServeHandler(client *cli.Client, cfg cli.Config, host string) http.Handler {
+	s := &server{client: client, cfg: cfg}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /boards", s.boards)
+	mux.HandleFunc("GET /boards/{board}/lists", s.lists)
+	mux.HandleFunc("GET /lists/{list}/cards", s.cards)
+	mux.HandleFunc("GET /cards/{card}", s.card)
+	mux.HandleFunc("POST /cards", s.createCard)
+	mux.HandleFunc("POST /cards/{card}/move", s.moveCard)
+	return cli.GuardLocal(mux, host)
+}
+
+type server struct {
+	client *cli.Client
+	cfg    cli.Config
+}
+
+type moveCardBody struct {
+	IDList   string `json:"idList"`
+	ListName string `json:"listName"`
+	Board    string `json:"board"`
+}
+
+func (s *server) boards(w http.ResponseWriter, r *http.Request) {
+	boards, err := s.client.Boards.List(r.Context(), trello.ListBoardsOptions{Filter: r.URL.Query().Get("filter")})
+	s.reply(w, http.StatusOK, boards, err)
+}
+
+func (s *server) lists(w http.ResponseWriter, r *http.Request) {
+	lists, err := cli.FetchBoardLists(r.Context(), s.client, s.cfg.File.ResolveBoardAlias(r.PathValue("board")))
+	s.reply(w, http.StatusOK, lists, err)
+}
+
+func (s *server) cards(w http.ResponseWriter, r *http.Request) {
+	var limit int
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			cli.ServeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", v))
+			return
+		}
+		limit = n
+	}
+	cards, err := s.client.Cards.List(r.Context(), r.PathValue("list"), trello.ListCardsOptions{Limit: limit})
+	s.reply(w, http.StatusOK, cards, err)
+}
+
+func (s *server) card(w http.ResponseWriter, r *http.Request) {
+	card, err := s.client.Cards.Get(r.Context(), r.PathValue("card"), "")
+	s.reply(w, http.StatusOK, card, err)
+}
+
+func (s *server) createCard(w http.ResponseWriter, r *http.Request) {
+	var draft cli.CardDraft
+	if !decodeServeBody(w, r, &draft) {
+		return
+	}
+	if strings.TrimSpace(draft.Name) == "" {
+		cli.ServeError(w, http.StatusBadRequest, errors.New(`"name" is required`))
+		return
+	}
+	card, err := cli.CreateCard(r.Context(), s.client, s.cfg, draft)
+	s.reply(w, http.StatusCreated, card, err)
+}
+
+func (s *server) moveCard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var body moveCardBody
+	if !decodeServeBody(w, r, &body) {
+		return
+	}
+	listID, err := cli.ResolveListID(ctx, s.client, s.boardOr(body.Board), body.IDList, body.ListName)
+	if err != nil {
+		s.reply(w, 0, nil, err)
+		return
+	}
+	card, err := cli.MoveCard(ctx, s.client, s.cfg, r.PathValue("card"), listID)
+	s.reply(w, http.StatusOK, card, err)
+}
+
+func (s *server) boardOr(board string) string {
+	return s.cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(board, s.cfg.BoardID))
+}
+
+// reply writes v as JSON with status, or err with the status that fits it.
+func (s *server) reply(w http.ResponseWriter, status int, v any, err error) {
+	if err != nil {
+		cli.ServeError(w, serveStatus(err), err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = cli.JSONRenderer{}.Render(w, v)
+}
+
+// decodeServeBody reads a JSON request body into v, answering 4xx itself
+// when it cannot. Requiring a JSON content type keeps HTML forms out.
+func decodeServeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if ct := r.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		cli.ServeError(w, http.StatusUnsupportedMediaType, errors.New("Content-Type must be application/json"))
+		return false
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxServeBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		cli.ServeError(w, http.StatusBadRequest, fmt.Errorf("invalid JSON body: %w", err))
+		return false
+	}
+	return true
+}
+
+// serveStatus maps an error to the status returned to local clients. Errors
+// from Trello that are not about the request itself become 502s, since the
+// caller cannot fix them.
+func serveStatus(err error) int {
+	var apiErr *cli.APIError
+	var urlErr *url.Error
+	switch {
+	case errors.Is(err, trello.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, trello.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable
+	case errors.As(err, &apiErr) && apiErr.Status >= 400 && apiErr.Status < 500 &&
+		apiErr.Status != http.StatusUnauthorized && apiErr.Status != http.StatusForbidden:
+		return http.StatusBadRequest
+	case errors.As(err, &apiErr), errors.As(err, &urlErr):
+		return http.StatusBadGateway
+	}
+	// Everything else failed before reaching Trello, such as an unknown
+	// list name.
+	return http.StatusBadRequest
+}
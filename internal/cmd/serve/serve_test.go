@@ -0,0 +1,75 @@
+package serve
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+func TestServe(t *testing.T) {
+	stub := clitest.NewStub(t)
+	cfg, _, err := clitest.TestConfig(t, stub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := httptest.NewServer(nil)
+	t.Cleanup(api.Close)
+	api.Config.Handler = newServeHandler(client, cfg, api.Listener.Addr().String())
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		header map[string]string
+	}{
+		{name: "boards", method: "GET", path: "/boards"},
+		{name: "lists", method: "GET", path: "/boards/b1/lists"},
+		{name: "cards", method: "GET", path: "/lists/l1/cards"},
+		{name: "card_not_found", method: "GET", path: "/cards/nope"},
+		{name: "create", method: "POST", path: "/cards", body: `{"listName": "to do", "name": "From an editor"}`},
+		{name: "create_missing_name", method: "POST", path: "/cards", body: `{"idList": "l1"}`},
+		{name: "create_form", method: "POST", path: "/cards", body: "name=x", header: map[string]string{"Content-Type": "application/x-www-form-urlencoded"}},
+		{name: "move", method: "POST", path: "/cards/c1/move", body: `{"idList": "l2"}`},
+		{name: "cross_origin", method: "GET", path: "/boards", header: map[string]string{"Origin": "https://evil.example"}},
+		{name: "foreign_host", method: "GET", path: "/boards", header: map[string]string{"Host": "evil.example:7070"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, api.URL+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			for k, v := range tt.header {
+				if k == "Host" {
+					req.Host = v
+					continue
+				}
+				req.Header.Set(k, v)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			clitest.CheckGolden(t, "serve_"+tt.name, fmt.Sprintf("%d\n%s", resp.StatusCode, body))
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+// trackedIssue is a GitHub or GitLab issue in the form syncIssues pairs
+// with cards.
+type trackedIssue struct {
+	Number    int
+	Title     string
+	Body      string
+	URL       string
+	Open      bool
+	UpdatedAt time.Time
+	Labels    []string
+}
+
+// issueSource is an issue tracker sync github and sync gitlab pair cards
+// with.
+type issueSource interface {
+	// name is the tracker's name in attachment titles, e.g. "GitHub".
+	name() string
+	// issues returns the open issues, and the closed ones too when all is
+	// set.
+	issues(ctx context.Context, all bool) ([]trackedIssue, error)
+	// setOpen reopens or closes an issue; under --dry-run it prints the
+	// request through client's dry-run writer instead.
+	setOpen(ctx context.Context, client *cli.Client, issue trackedIssue, open bool) error
+}
+
+// issueSyncOptions configure syncIssues.
+type issueSyncOptions struct {
+	BoardID  string
+	ListID   string
+	LabelMap map[string]string
+	TwoWay   bool
+	// FollowCard, when set, is called for each open issue whose card is
+	// open too, and returns the action it took to bring the issue in step
+	// with the card, or "" when it had nothing to do.
+	FollowCard func(ctx context.Context, issue trackedIssue, card cli.LinkedCard) (string, error)
+}
+
+// issueSyncChange is one change sync github or sync gitlab made (or,
+// under --dry-run, planned).
+type issueSyncChange struct {
+	Action string `json:"action"`
+	Issue  int    `json:"issue"`
+	Title  string `json:"title"`
+	Card   string `json:"card,omitempty"`
+}
+
+func issueSyncTable(changes []issueSyncChange) cli.Table {
+	t := cli.Table{Columns: []string{"ACTION", "ISSUE", "CARD", "TITLE"}, Empty: "Already in sync."}
+	for _, c := range changes {
+		t.Rows = append(t.Rows, []string{c.Action, "#" + strconv.Itoa(c.Issue), c.Card, c.Title})
+	}
+	return t
+}
+
+// syncIssues pairs the issues of src with the cards on opts.BoardID by an
+// attachment linking the issue, creates cards for unpaired open issues,
+// and under opts.TwoWay carries open/closed state over from whichever side
+// changed last. It renders the changes made.
+func syncIssues(ctx context.Context, client *cli.Client, cfg cli.Config, src issueSource, opts issueSyncOptions) error {
+	issues, err := src.issues(ctx, opts.TwoWay)
+	if err != nil {
+		return err
+	}
+	cards, err := cli.FetchLinkedCards(ctx, client, opts.BoardID)
+	if err != nil {
+		return err
+	}
+	labels, err := cli.FetchBoardLabels(ctx, client, opts.BoardID)
+	if err != nil {
+		return err
+	}
+	byURL := map[string]cli.LinkedCard{}
+	for _, c := range cards {
+		for _, a := range c.Attachments {
+			byURL[a.URL] = c
+		}
+	}
+
+	var changes []issueSyncChange
+	for _, issue := range issues {
+		card, paired := byURL[issue.URL]
+		var action string
+		var err error
+		switch {
+		case !paired && issue.Open:
+			var created cli.Card
+			created, err = createIssueCard(ctx, client, cfg, src, opts.ListID, issue, issueLabelIDs(issue.Labels, labels, opts.LabelMap))
+			card.ID, action = created.ID, "create card"
+		case !paired:
+		case opts.TwoWay && card.Closed == issue.Open && issue.UpdatedAt.After(card.DateLastActivity):
+			// The tracker changed last: the card follows the issue.
+			action = "archive card"
+			if issue.Open {
+				action = "unarchive card"
+				_, err = client.Cards.Unarchive(ctx, card.ID)
+			} else {
+				_, err = client.Cards.Archive(ctx, card.ID)
+				if err == nil {
+					cli.RecordUndo(cfg, cli.JournalEntry{Action: "cards.archive", Target: card.ID, Summary: "archive card " + card.Name})
+				}
+			}
+		case opts.TwoWay && card.Closed == issue.Open:
+			// Trello changed last: the issue follows the card.
+			action = "close issue"
+			if !card.Closed {
+				action = "reopen issue"
+			}
+			err = src.setOpen(ctx, client, issue, !card.Closed)
+		case issue.Open && !card.Closed && opts.FollowCard != nil:
+			action, err = opts.FollowCard(ctx, issue, card)
+		}
+		if err != nil && !errors.Is(err, cli.ErrDryRun) {
+			return fmt.Errorf("issue #%d: %w", issue.Number, err)
+		}
+		if action != "" {
+			changes = append(changes, issueSyncChange{Action: action, Issue: issue.Number, Title: issue.Title, Card: card.ID})
+		}
+	}
+	if cfg.DryRun {
+		return cli.ErrDryRun
+	}
+	return cli.Render(cfg, cli.NonNil(changes), issueSyncTable(changes))
+}
+
+// createIssueCard creates the card for an issue and attaches the issue URL,
+// which pairs them on later runs. When the attachment fails the new card
+// is archived, so that the next run does not create a second one.
+func createIssueCard(ctx context.Context, client *cli.Client, cfg cli.Config, src issueSource, listID string, issue trackedIssue, labelIDs []string) (cli.Card, error) {
+	card, err := cli.CreateCard(ctx, client, cfg, cli.CardDraft{
+		IDList:   listID,
+		Name:     fmt.Sprintf("#%d %s", issue.Number, issue.Title),
+		Desc:     issue.Body,
+		IDLabels: labelIDs,
+	})
+	if err != nil {
+		return cli.Card{}, err
+	}
+	if _, err := client.Cards.AttachURL(ctx, card.ID, issue.URL, fmt.Sprintf("%s #%d", src.name(), issue.Number)); err != nil {
+		if _, archiveErr := client.Cards.Archive(ctx, card.ID); archiveErr != nil {
+			return cli.Card{}, fmt.Errorf("attaching the issue to card %s: %w (archiving the unpaired card also failed: %v)", card.ID, err, archiveErr)
+		}
+		return cli.Card{}, fmt.Errorf("attaching the issue to card %s: %w (archived the card)", card.ID, err)
+	}
+	return card, nil
+}
+
+// parseLabelMap parses from=to pairs separated by commas; the keys (issue
+// labels, or list names for --list-labels) are matched case-insensitively.
+func parseLabelMap(s string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+			return nil, fmt.Errorf("invalid entry %q: want from=to", pair)
+		}
+		m[strings.ToLower(strings.TrimSpace(from))] = strings.TrimSpace(to)
+	}
+	return m, nil
+}
+
+// issueLabelIDs returns the board labels for an issue's labels: the mapped
+// Trello label (by id or name) when --label-map names one, otherwise a
+// board label of the same name.
+func issueLabelIDs(names []string, labels []cli.Label, mapping map[string]string) []string {
+	var ids []string
+	for _, name := range names {
+		target := name
+		if mapped, ok := mapping[strings.ToLower(name)]; ok {
+			target = mapped
+		}
+		for _, bl := range labels {
+			if bl.ID == target || strings.EqualFold(bl.Name, target) {
+				ids = append(ids, bl.ID)
+				break
+			}
+		}
+	}
+	return ids
+}
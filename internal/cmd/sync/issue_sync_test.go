@@ -0,0 +1,91 @@
+package sync
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"trelli/internal/clitest"
+)
+
+// issueStub describes the issue tracker API newIssueStub serves.
+type issueStub struct {
+	// AuthHeader must carry AuthValue, or requests are refused.
+	AuthHeader, AuthValue string
+	// Issues is served at IssuesPath (escaped); updates are accepted with
+	// UpdateMethod below it.
+	IssuesPath   string
+	Issues       string
+	UpdateMethod string
+}
+
+// newIssueStub serves s and records the issue updates it gets as the path
+// below the project or repository followed by the body, e.g.
+// `/issues/5 {"state":"closed"}`.
+func newIssueStub(t *testing.T, s issueStub, updated *[]string) *httptest.Server {
+	t.Helper()
+	base := strings.TrimSuffix(s.IssuesPath, "/issues")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get(s.AuthHeader) != s.AuthValue {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"message": "Unauthorized"}`)
+			return
+		}
+		switch path := r.URL.EscapedPath(); {
+		case r.Method == http.MethodGet && path == s.IssuesPath:
+			io.WriteString(w, s.Issues)
+		case r.Method == s.UpdateMethod && strings.HasPrefix(path, s.IssuesPath+"/"):
+			body, _ := io.ReadAll(r.Body)
+			*updated = append(*updated, strings.TrimPrefix(path, base)+" "+string(body))
+			io.WriteString(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message": "Not Found"}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSyncIssuesAttachFailure(t *testing.T) {
+	var updated []string
+	gh := newIssueStub(t, githubStub, &updated)
+	t.Setenv("GITHUB_TOKEN", "gh-token")
+	stub := clitest.NewStub(t)
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/1/cards/c9/attachments" {
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, `{"message": "internal error"}`)
+			return
+		}
+		stub.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(failing.Close)
+	srv, writes := clitest.WriteRecorder(t, failing)
+
+	got := clitest.RunCLI(t, srv, "sync", "github", "--repo", "acme/app", "--list", "l1", "--github-url", gh.URL)
+	if want := "issue #2: attaching the issue to card c9"; !strings.Contains(got, want) {
+		t.Errorf("sync github with a failing attachment = %q, want an error containing %q", got, want)
+	}
+	want := []string{"POST /1/cards", "POST /1/cards/c9/attachments", "PUT /1/cards/c9"}
+	if !slices.Equal(*writes, want) {
+		t.Errorf("writes = %q, want %q (the unpaired card archived)", *writes, want)
+	}
+}
+
+func TestParseLabelMap(t *testing.T) {
+	m, err := parseLabelMap("Bug=Defect, enhancement = lb2,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["bug"] != "Defect" || m["enhancement"] != "lb2" || len(m) != 2 {
+		t.Errorf("parseLabelMap = %v", m)
+	}
+	if _, err := parseLabelMap("bug"); err == nil {
+		t.Error("parseLabelMap accepted an entry without =")
+	}
+}
@@ -0,0 +1,330 @@
+// Package sync implements "trelli sync": maintain a local JSON mirror
+// of a board, or sync boards with GitHub or GitLab issues.
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "sync",
+	Summary: "Maintain a local JSON mirror of a board, or sync boards with GitHub or GitLab issues",
+	Description: `pull downloads a board (lists, labels, open cards) into a JSON file and
+records the date of the newest board action. Later pulls fetch only the
+actions since then and re-read just the cards they touched, dropping
+deleted cards. --full re-downloads everything.
+
+github creates a card for each open issue of a repository that has none
+yet. Cards are paired with issues by an attachment linking the issue, so
+renaming or moving a card keeps the pairing. GitHub labels become board
+labels of the same name, or the ones --label-map names. --two-way also
+archives cards whose issues were closed and closes issues whose cards
+were archived (and reopens/unarchives); whichever side changed last wins.
+The GitHub token is read from GITHUB_TOKEN or GH_TOKEN; it is optional
+for public repositories but required for --two-way.
+
+gitlab does the same for the issues of a GitLab project, pairing cards
+by an attachment linking the issue. --list-labels additionally keeps the
+labels of open issues in step with their card's list: moving a card to a
+mapped list adds that list's label to the issue and removes the labels
+of the other mapped lists, e.g. --list-labels
+"Doing=workflow::doing,Review=workflow::review". The token is read from
+GITLAB_TOKEN and is required for --two-way and --list-labels; the
+instance from --gitlab-url or GITLAB_URL (default https://gitlab.com).
+
+boards keeps the cards of two boards in sync: cards in the synced lists
+(--lists, by name; default all lists of the source) are copied to the
+list of the same name on the other board, created when missing, and
+later moves and archiving follow. Leaving the synced lists archives the
+copy. The pairs are stored in a mapping file (--state); cards without a
+pair are first matched by name within the same list. Both boards change
+unless --one-way, which only changes the target; when both sides moved a
+card, the one changed last wins. With --dry-run, the plan is printed.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "pull", Usage: []string{"pull [[--board] <boardIdOrShortLink>] [--file <path>] [--full]"}, Flags: []cli.Flag{
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "file", Arg: "path", Desc: "Mirror file (default trelli-<board>.json)"},
+			{Name: "full", Desc: "Re-download the whole board"},
+		}},
+		{Name: "github", Usage: []string{"github --repo <owner/name> (--list <id> | --list-name <name>) [--board <id>] [--label-map <gh=trello,...>] [--two-way]"}, Flags: []cli.Flag{
+			{Name: "repo", Arg: "owner/name", Desc: "GitHub repository"},
+			{Name: "list", Arg: "id", Desc: "List for new cards"},
+			{Name: "list-name", Arg: "name", Desc: "List for new cards, by name on the board"},
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "label-map", Arg: "pairs", Desc: "GitHub label to Trello label name or id, e.g. bug=Bug,enhancement=Feature"},
+			{Name: "two-way", Desc: "Also sync open/closed state both ways"},
+			{Name: "github-url", Arg: "url", Desc: "GitHub API base URL (default $GITHUB_API_URL or https://api.github.com)"},
+		}},
+		{Name: "gitlab", Usage: []string{"gitlab --project <group/name> (--list <id> | --list-name <name>) [--board <id>] [--label-map <gl=trello,...>] [--two-way] [--list-labels <list=label,...>]"}, Flags: []cli.Flag{
+			{Name: "project", Arg: "group/name", Desc: "GitLab project path"},
+			{Name: "list", Arg: "id", Desc: "List for new cards"},
+			{Name: "list-name", Arg: "name", Desc: "List for new cards, by name on the board"},
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "label-map", Arg: "pairs", Desc: "GitLab label to Trello label name or id, e.g. bug=Bug,feature=Feature"},
+			{Name: "two-way", Desc: "Also sync open/closed state both ways"},
+			{Name: "list-labels", Arg: "pairs", Desc: "Trello list to GitLab label the issue gets while its card is there"},
+			{Name: "gitlab-url", Arg: "url", Desc: "GitLab base URL (default $GITLAB_URL or https://gitlab.com)"},
+		}},
+		{Name: "boards", Usage: []string{"boards --source <id> --target <id> [--lists <names>] [--one-way] [--state <path>]"}, Flags: []cli.Flag{
+			{Name: "source", Arg: "id", Desc: "Source board id, shortLink, or alias (default: the default board)"},
+			{Name: "target", Arg: "id", Desc: "Target board id, shortLink, or alias"},
+			{Name: "lists", Arg: "names", Desc: `Comma-separated list names to sync, e.g. "To Do,Doing,Done"`},
+			{Name: "one-way", Desc: "Only change the target board"},
+			{Name: "state", Arg: "path", Desc: "Mapping file (default trelli-sync-<source>-<target>.json)"},
+		}},
+	},
+	Options: []cli.Flag{cli.JSONOption},
+	Run:     runSync,
+}
+
+// boardMirror is the local JSON copy of a board kept by sync pull. Since is
+// the date of the newest action already applied.
+type boardMirror struct {
+	Board  cli.Board        `json:"board"`
+	Lists  []cli.TrelloList `json:"lists"`
+	Labels []cli.Label      `json:"labels"`
+	Cards  []cli.Card       `json:"cards"`
+	Since  string           `json:"since"`
+	Synced time.Time        `json:"synced"`
+}
+
+// boardAction is the subset of a board action sync needs to find what
+// changed.
+type boardAction struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Date string `json:"date"`
+	Data struct {
+		Card *struct {
+			ID string `json:"id"`
+		} `json:"card"`
+	} `json:"data"`
+}
+
+type syncSummary struct {
+	Board   string `json:"board"`
+	File    string `json:"file"`
+	Full    bool   `json:"full"`
+	Actions int    `json:"actions"`
+	Updated int    `json:"updated"`
+	Removed int    `json:"removed"`
+	Cards   int    `json:"cards"`
+}
+
+func runSync(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) == 0 {
+		cli.PrintCommandHelp("sync")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("sync")
+		return nil
+	case "pull":
+		fs := flag.NewFlagSet("sync pull", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		boardID := cfg.BoardID
+		var file string
+		var full bool
+		fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+		fs.StringVar(&file, "file", "", "Mirror file (default trelli-<board>.json)")
+		fs.BoolVar(&full, "full", false, "Re-download the whole board")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("sync")); err != nil {
+			return err
+		}
+		if err := cli.TakePositional(fs, &boardID); err != nil {
+			return err
+		}
+		boardID = cfg.File.ResolveBoardAlias(boardID)
+		if strings.TrimSpace(boardID) == "" {
+			return cli.ErrNoBoard
+		}
+		if file == "" {
+			file = "trelli-" + boardID + ".json"
+		}
+
+		var mirror boardMirror
+		if !full {
+			data, err := os.ReadFile(file)
+			switch {
+			case errors.Is(err, os.ErrNotExist):
+				full = true
+			case err != nil:
+				return err
+			default:
+				if err := json.Unmarshal(data, &mirror); err != nil {
+					return fmt.Errorf("reading %s: %w (use --full to rebuild it)", file, err)
+				}
+				full = mirror.Since == ""
+			}
+		}
+
+		summary := syncSummary{Board: boardID, File: file, Full: full}
+		var err error
+		if full {
+			mirror, err = pullBoard(ctx, client, boardID)
+		} else {
+			summary.Actions, summary.Updated, summary.Removed, err = pullChanges(ctx, client, boardID, &mirror)
+		}
+		if err != nil {
+			return err
+		}
+		mirror.Synced = time.Now().UTC()
+		summary.Cards = len(mirror.Cards)
+		if err := cli.WriteJSONFile(file, mirror); err != nil {
+			return err
+		}
+		if cfg.Structured() {
+			return cli.Render(cfg, summary)
+		}
+		if full {
+			fmt.Printf("Pulled %s: %d lists, %d cards -> %s\n", mirror.Board.Name, len(mirror.Lists), len(mirror.Cards), file)
+			return nil
+		}
+		fmt.Printf("Synced %s: %d actions, %d cards updated, %d removed -> %s\n", mirror.Board.Name, summary.Actions, summary.Updated, summary.Removed, file)
+		return nil
+	case "github":
+		return runSyncGitHub(ctx, client, cfg, args[1:])
+	case "gitlab":
+		return runSyncGitLab(ctx, client, cfg, args[1:])
+	case "boards":
+		return runSyncBoards(ctx, client, cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown sync subcommand %q", args[0])
+	}
+}
+
+// pullBoard downloads the whole board. The newest action is read first so
+// changes made during the download are picked up by the next pull.
+func pullBoard(ctx context.Context, client *cli.Client, boardID string) (boardMirror, error) {
+	var m boardMirror
+	var newest []boardAction
+	query := url.Values{}
+	query.Set("limit", "1")
+	query.Set("fields", "id,date")
+	if err := client.Do(ctx, http.MethodGet, "/1/boards/"+url.PathEscape(boardID)+"/actions", query, nil, &newest); err != nil {
+		return m, err
+	}
+	if len(newest) > 0 {
+		m.Since = newest[0].Date
+	} else {
+		m.Since = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	if err := client.GetAll(ctx,
+		cli.BoardRequest(boardID, &m.Board),
+		cli.BoardListsRequest(boardID, &m.Lists),
+		cli.BoardLabelsRequest(boardID, &m.Labels),
+	); err != nil {
+		return m, err
+	}
+	cardsQuery := url.Values{}
+	cardsQuery.Set("fields", cli.CardFields(cli.Config{}))
+	err := cli.StreamArray(ctx, client, "/1/boards/"+url.PathEscape(boardID)+"/cards", cardsQuery, func(c cli.Card) error {
+		m.Cards = append(m.Cards, c)
+		return nil
+	})
+	return m, err
+}
+
+// pullChanges applies the actions since m.Since: lists and labels are
+// re-read, touched cards re-fetched, and deleted cards dropped.
+func pullChanges(ctx context.Context, client *cli.Client, boardID string, m *boardMirror) (actions, updated, removed int, err error) {
+	// Actions arrive newest first, so the first one seen for a card decides
+	// whether it is still on the board.
+	present := map[string]bool{}
+	newest := m.Since
+	before := ""
+	for {
+		query := url.Values{}
+		query.Set("since", m.Since)
+		query.Set("limit", fmt.Sprint(cli.ActionPageSize))
+		query.Set("fields", "id,type,date,data")
+		if before != "" {
+			query.Set("before", before)
+		}
+		var page []boardAction
+		if err := client.Do(ctx, http.MethodGet, "/1/boards/"+url.PathEscape(boardID)+"/actions", query, nil, &page); err != nil {
+			return 0, 0, 0, err
+		}
+		for _, a := range page {
+			actions++
+			if a.Date > newest {
+				newest = a.Date
+			}
+			if a.Data.Card == nil {
+				continue
+			}
+			if _, seen := present[a.Data.Card.ID]; !seen {
+				present[a.Data.Card.ID] = a.Type != "deleteCard" && a.Type != "moveCardFromBoard"
+			}
+		}
+		if len(page) < cli.ActionPageSize {
+			break
+		}
+		before = page[len(page)-1].ID
+	}
+	if actions == 0 {
+		return 0, 0, 0, nil
+	}
+
+	reqs := []cli.GetRequest{
+		cli.BoardRequest(boardID, &m.Board),
+		cli.BoardListsRequest(boardID, &m.Lists),
+		cli.BoardLabelsRequest(boardID, &m.Labels),
+	}
+	ids := make([]string, 0, len(present))
+	for id, ok := range present {
+		if ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	fresh := make([]cli.Card, len(ids))
+	for i, id := range ids {
+		reqs = append(reqs, cli.CardRequest(id, cli.CardFields(cli.Config{}), &fresh[i]))
+	}
+	if err := client.GetAll(ctx, reqs...); err != nil {
+		return 0, 0, 0, err
+	}
+
+	byID := map[string]cli.Card{}
+	for _, c := range fresh {
+		byID[c.ID] = c
+	}
+	cards := make([]cli.Card, 0, len(m.Cards)+len(fresh))
+	for _, c := range m.Cards {
+		if ok, seen := present[c.ID]; seen && !ok {
+			removed++
+			continue
+		}
+		if f, ok := byID[c.ID]; ok {
+			c = f
+			delete(byID, c.ID)
+		}
+		cards = append(cards, c)
+	}
+	for _, id := range ids {
+		if c, ok := byID[id]; ok {
+			cards = append(cards, c)
+		}
+	}
+	m.Cards = cards
+	m.Since = newest
+	return actions, len(fresh), removed, nil
+}
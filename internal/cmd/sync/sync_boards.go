@@ -0,0 +1,352 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+// boardSyncState is the mapping sync boards keeps between runs: which
+// target card mirrors which source card, and where the pair was when last
+// synced.
+type boardSyncState struct {
+	Source string           `json:"source"`
+	Target string           `json:"target"`
+	Pairs  []*boardSyncPair `json:"pairs"`
+	Synced time.Time        `json:"synced"`
+}
+
+// boardSyncPair is a card and its mirror. List is the synced list the pair
+// was in at the last run, or empty when it was archived.
+type boardSyncPair struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	List   string `json:"list"`
+}
+
+// boardSyncStep is one change sync boards makes, or under --dry-run would
+// make. Board is the board changed.
+type boardSyncStep struct {
+	Action string `json:"action"`
+	Board  string `json:"board"`
+	Card   string `json:"card"`
+	List   string `json:"list,omitempty"`
+	ID     string `json:"id,omitempty"`
+
+	apply func(context.Context) error
+}
+
+func boardSyncTable(steps []*boardSyncStep) cli.Table {
+	t := cli.Table{Columns: []string{"ACTION", "BOARD", "CARD", "LIST", "ID"}, Empty: "Boards are in sync."}
+	for _, s := range steps {
+		t.Rows = append(t.Rows, []string{s.Action, s.Board, s.Card, s.List, s.ID})
+	}
+	return t
+}
+
+// syncSide is one of the two boards: its lists by id and by lower-case
+// name, and all its cards, archived ones included.
+type syncSide struct {
+	board   string
+	lists   map[string]string
+	listIDs map[string]string
+	cards   map[string]cli.VaultCard
+	order   []string
+}
+
+func fetchSyncSide(ctx context.Context, client *cli.Client, boardID string) (*syncSide, error) {
+	var lists []cli.TrelloList
+	if err := client.GetAll(ctx, cli.BoardListsRequest(boardID, &lists)); err != nil {
+		return nil, err
+	}
+	s := &syncSide{board: boardID, lists: map[string]string{}, listIDs: map[string]string{}, cards: map[string]cli.VaultCard{}}
+	for _, l := range lists {
+		if l.Closed {
+			continue
+		}
+		s.lists[l.ID] = l.Name
+		if _, ok := s.listIDs[strings.ToLower(l.Name)]; !ok {
+			s.listIDs[strings.ToLower(l.Name)] = l.ID
+		}
+	}
+	query := url.Values{}
+	query.Set("filter", "all")
+	query.Set("fields", "id,name,desc,due,idList,closed,dateLastActivity")
+	err := cli.StreamArray(ctx, client, "/1/boards/"+url.PathEscape(boardID)+"/cards", query, func(c cli.VaultCard) error {
+		s.cards[c.ID] = c
+		s.order = append(s.order, c.ID)
+		return nil
+	})
+	return s, err
+}
+
+// place returns the synced list a card is in, by its name on the board, or
+// "" when the card is archived or outside the synced lists.
+func (s *syncSide) place(c cli.VaultCard, synced map[string]bool) string {
+	name := s.lists[c.IDList]
+	if c.Closed || !synced[strings.ToLower(name)] {
+		return ""
+	}
+	return name
+}
+
+func runSyncBoards(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("sync boards", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var source, target, lists, file string
+	var oneWay bool
+	fs.StringVar(&source, "source", "", "Source board id, shortLink, or alias")
+	fs.StringVar(&target, "target", "", "Target board id, shortLink, or alias")
+	fs.StringVar(&lists, "lists", "", "Comma-separated names of the lists to sync")
+	fs.BoolVar(&oneWay, "one-way", false, "Only change the target board")
+	fs.StringVar(&file, "state", "", "Mapping file")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("sync")); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	source = cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(source, cfg.BoardID))
+	target = cfg.File.ResolveBoardAlias(target)
+	if strings.TrimSpace(source) == "" || strings.TrimSpace(target) == "" {
+		return errors.New("sync boards requires --source and --target")
+	}
+	if source == target {
+		return errors.New("--source and --target are the same board")
+	}
+	if file == "" {
+		file = "trelli-sync-" + source + "-" + target + ".json"
+	}
+
+	state := boardSyncState{Source: source, Target: target}
+	data, err := os.ReadFile(file)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(data, &state); err != nil {
+			return fmt.Errorf("reading %s: %w", file, err)
+		}
+		if state.Source != source || state.Target != target {
+			return fmt.Errorf("%s maps %s to %s, not %s to %s", file, state.Source, state.Target, source, target)
+		}
+	}
+
+	src, err := fetchSyncSide(ctx, client, source)
+	if err != nil {
+		return err
+	}
+	dst, err := fetchSyncSide(ctx, client, target)
+	if err != nil {
+		return err
+	}
+	synced := map[string]bool{}
+	for _, name := range strings.Split(lists, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			synced[strings.ToLower(name)] = true
+		}
+	}
+	if len(synced) == 0 {
+		for _, name := range src.lists {
+			synced[strings.ToLower(name)] = true
+		}
+	}
+
+	steps := planBoardSync(client, cfg, &state, src, dst, synced, oneWay)
+	if cfg.DryRun {
+		return cli.Render(cfg, cli.NonNil(steps), boardSyncTable(steps))
+	}
+	for i, step := range steps {
+		if err := step.apply(ctx); err != nil {
+			// Keep the pairs made so far, so a rerun does not duplicate them.
+			_ = cli.WriteJSONFile(file, state)
+			return fmt.Errorf("%s %q: %w (%d changes made before it)", step.Action, step.Card, err, i)
+		}
+	}
+	state.Synced = time.Now().UTC()
+	if err := cli.WriteJSONFile(file, state); err != nil {
+		return err
+	}
+	return cli.Render(cfg, cli.NonNil(steps), boardSyncTable(steps))
+}
+
+// planBoardSync compares the boards with the state and returns the steps
+// that bring them in line. A pair follows the side that moved it since the
+// last run; when both did, the card changed last wins. Leaving the synced
+// lists counts as archiving. Unpaired cards are matched by name and list
+// first, then copied. Applying the steps records new pairs in state.
+func planBoardSync(client *cli.Client, cfg cli.Config, state *boardSyncState, src, dst *syncSide, synced map[string]bool, oneWay bool) []*boardSyncStep {
+	var steps []*boardSyncStep
+	paired := map[string]bool{}
+	kept := []*boardSyncPair{}
+	for _, p := range state.Pairs {
+		s, sok := src.cards[p.Source]
+		t, tok := dst.cards[p.Target]
+		if !sok || !tok {
+			// A deleted card ends the pair and its mirror is archived, except
+			// that one way, a deleted target card is copied again.
+			if !oneWay || !sok {
+				paired[p.Source], paired[p.Target] = true, true
+			}
+			if sok && !oneWay && !s.Closed {
+				steps = append(steps, archiveStep(client, cfg, src, s, nil))
+			}
+			if tok && !t.Closed {
+				steps = append(steps, archiveStep(client, cfg, dst, t, nil))
+			}
+			continue
+		}
+		paired[p.Source], paired[p.Target] = true, true
+		kept = append(kept, p)
+		sp, tp := src.place(s, synced), dst.place(t, synced)
+		if strings.EqualFold(sp, tp) {
+			p.List = sp
+			continue
+		}
+		srcMoved, dstMoved := !strings.EqualFold(sp, p.List), !strings.EqualFold(tp, p.List)
+		if oneWay || srcMoved && (!dstMoved || s.DateLastActivity >= t.DateLastActivity) {
+			steps = append(steps, placeSteps(client, cfg, dst, t, sp, p)...)
+		} else {
+			steps = append(steps, placeSteps(client, cfg, src, s, tp, p)...)
+		}
+	}
+	state.Pairs = kept
+
+	copyCards := func(from, to *syncSide, forward bool) {
+		for _, id := range from.order {
+			c := from.cards[id]
+			place := from.place(c, synced)
+			if paired[id] || place == "" {
+				continue
+			}
+			paired[id] = true
+			pair := func(otherID string) *boardSyncPair {
+				if forward {
+					return &boardSyncPair{Source: c.ID, Target: otherID, List: place}
+				}
+				return &boardSyncPair{Source: otherID, Target: c.ID, List: place}
+			}
+			// A card of the same name in the same list is its match.
+			match := ""
+			for _, oid := range to.order {
+				o := to.cards[oid]
+				if !paired[oid] && o.Name == c.Name && strings.EqualFold(to.place(o, synced), place) {
+					match = oid
+					break
+				}
+			}
+			if match != "" {
+				paired[match] = true
+				state.Pairs = append(state.Pairs, pair(match))
+				steps = append(steps, &boardSyncStep{Action: "link", Board: to.board, Card: c.Name, List: place, ID: match, apply: func(context.Context) error { return nil }})
+				continue
+			}
+			if step := ensureListStep(client, to, place); step != nil {
+				steps = append(steps, step)
+			}
+			step := &boardSyncStep{Action: "create", Board: to.board, Card: c.Name, List: place}
+			steps = append(steps, step)
+			step.apply = func(ctx context.Context) error {
+				card, err := cli.CreateCard(ctx, client, cfg, cli.CardDraft{IDList: to.listIDs[strings.ToLower(place)], Name: c.Name, Desc: c.Desc, Due: c.Due})
+				if err != nil {
+					return err
+				}
+				state.Pairs = append(state.Pairs, pair(card.ID))
+				step.ID = card.ID
+				return nil
+			}
+		}
+	}
+	copyCards(src, dst, true)
+	if !oneWay {
+		copyCards(dst, src, false)
+	}
+	return steps
+}
+
+// placeSteps returns the steps that put card in place on side: archiving
+// it when place is empty, otherwise unarchiving it and moving it to the
+// list of that name, created when missing. The last one records place in p.
+func placeSteps(client *cli.Client, cfg cli.Config, side *syncSide, card cli.VaultCard, place string, p *boardSyncPair) []*boardSyncStep {
+	done := func() { p.List = place }
+	if place == "" {
+		if card.Closed {
+			// Outside the synced lists and archived alike.
+			p.List = place
+			return nil
+		}
+		return []*boardSyncStep{archiveStep(client, cfg, side, card, done)}
+	}
+	var steps []*boardSyncStep
+	if step := ensureListStep(client, side, place); step != nil {
+		steps = append(steps, step)
+	}
+	if card.Closed {
+		steps = append(steps, &boardSyncStep{Action: "unarchive", Board: side.board, Card: card.Name, List: side.lists[card.IDList], ID: card.ID, apply: func(ctx context.Context) error {
+			_, err := client.Cards.Unarchive(ctx, card.ID)
+			return err
+		}})
+	}
+	if !strings.EqualFold(side.lists[card.IDList], place) {
+		steps = append(steps, &boardSyncStep{Action: "move", Board: side.board, Card: card.Name, List: place, ID: card.ID, apply: func(ctx context.Context) error {
+			_, err := cli.MoveCard(ctx, client, cfg, card.ID, side.listIDs[strings.ToLower(place)])
+			return err
+		}})
+	}
+	if len(steps) == 0 {
+		p.List = place
+		return nil
+	}
+	last := steps[len(steps)-1]
+	apply := last.apply
+	last.apply = func(ctx context.Context) error {
+		if err := apply(ctx); err != nil {
+			return err
+		}
+		done()
+		return nil
+	}
+	return steps
+}
+
+func archiveStep(client *cli.Client, cfg cli.Config, side *syncSide, card cli.VaultCard, done func()) *boardSyncStep {
+	return &boardSyncStep{Action: "archive", Board: side.board, Card: card.Name, List: side.lists[card.IDList], ID: card.ID, apply: func(ctx context.Context) error {
+		if _, err := client.Cards.Archive(ctx, card.ID); err != nil {
+			return err
+		}
+		cli.RecordUndo(cfg, cli.JournalEntry{Action: "cards.archive", Target: card.ID, Summary: "archive card " + card.Name})
+		if done != nil {
+			done()
+		}
+		return nil
+	}}
+}
+
+// ensureListStep returns a step creating the list name on side, or nil when
+// it exists or an earlier step creates it.
+func ensureListStep(client *cli.Client, side *syncSide, name string) *boardSyncStep {
+	key := strings.ToLower(name)
+	if _, ok := side.listIDs[key]; ok {
+		return nil
+	}
+	side.listIDs[key] = ""
+	return &boardSyncStep{Action: "create list", Board: side.board, List: name, apply: func(ctx context.Context) error {
+		l, err := client.Lists.Create(ctx, side.board, name)
+		if err != nil {
+			return err
+		}
+		side.listIDs[key] = l.ID
+		side.lists[l.ID] = l.Name
+		return nil
+	}}
+}
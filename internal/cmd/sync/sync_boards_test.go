@@ -0,0 +1,56 @@
+package sync
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// TestMain registers the command as main does, for the tests that run it
+// through the CLI.
+func TestMain(m *testing.M) {
+	cli.Register(Command)
+	os.Exit(m.Run())
+}
+
+func TestSyncBoardsState(t *testing.T) {
+	stub := clitest.NewStub(t)
+	file := filepath.Join(t.TempDir(), "mapping.json")
+	// c3 was archived since the last run and c8, c2's copy, deleted.
+	err := cli.WriteJSONFile(file, boardSyncState{Source: "b1", Target: "b2", Pairs: []*boardSyncPair{
+		{Source: "c3", Target: "c4", List: "To Do"},
+		{Source: "c2", Target: "c8", List: "To Do"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := clitest.RunCLI(t, stub, "sync", "boards", "--source", "b1", "--target", "b2", "--lists", "To Do,Doing,Done", "--state", file)
+	clitest.CheckGolden(t, "sync_boards_state", out)
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var state boardSyncState
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+	// The stub answers every write with card c9.
+	wantPairs := []boardSyncPair{{Source: "c3", Target: "c4", List: ""}, {Source: "c1", Target: "c9", List: "To Do"}, {Source: "c9", Target: "c5", List: "to do"}}
+	if len(state.Pairs) != len(wantPairs) {
+		t.Fatalf("pairs = %d, want %d: %s", len(state.Pairs), len(wantPairs), data)
+	}
+	for i, p := range state.Pairs {
+		if *p != wantPairs[i] {
+			t.Errorf("pair %d = %+v, want %+v", i, *p, wantPairs[i])
+		}
+	}
+	if state.Synced.IsZero() {
+		t.Error("synced time not recorded")
+	}
+}
@@ -0,0 +1,211 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+const defaultGitHubAPI = "https://api.github.com"
+
+// githubIssue is the subset of a GitHub issue sync github uses.
+type githubIssue struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	State     string    `json:"state"`
+	HTMLURL   string    `json:"html_url"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Labels    []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	// PullRequest is set for pull requests, which the issues API also
+	// returns.
+	PullRequest json.RawMessage `json:"pull_request,omitempty"`
+}
+
+// githubClient is a minimal GitHub REST client for the issues of one
+// repository. The token is only ever sent in the Authorization header.
+type githubClient struct {
+	base  string
+	repo  string
+	token string
+	http  *http.Client
+}
+
+func runSyncGitHub(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("sync github", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var repo, listID, listName, labelMap, apiURL string
+	var twoWay bool
+	boardID := cfg.BoardID
+	fs.StringVar(&repo, "repo", "", "GitHub repository owner/name")
+	fs.StringVar(&listID, "list", "", "List id for new cards")
+	fs.StringVar(&listName, "list-name", "", "List name for new cards (resolved on board)")
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&labelMap, "label-map", "", "GitHub label to Trello label name or id, e.g. bug=Bug,enhancement=Feature")
+	fs.BoolVar(&twoWay, "two-way", false, "Also sync open/closed state between issues and cards")
+	fs.StringVar(&apiURL, "github-url", cli.FirstNonEmpty(os.Getenv("GITHUB_API_URL"), defaultGitHubAPI), "GitHub API base URL")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("sync")); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return errors.New("sync github requires --repo owner/name")
+	}
+	boardID = cfg.File.ResolveBoardAlias(boardID)
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+	mapping, err := parseLabelMap(labelMap)
+	if err != nil {
+		return err
+	}
+
+	transport, err := cli.NewTransport(cfg)
+	if err != nil {
+		return err
+	}
+	gh := &githubClient{
+		base:  strings.TrimSuffix(apiURL, "/"),
+		repo:  repo,
+		token: cli.FirstNonEmpty(os.Getenv("GITHUB_TOKEN"), os.Getenv("GH_TOKEN")),
+		http:  &http.Client{Transport: transport, Timeout: cfg.Timeout},
+	}
+	if twoWay && gh.token == "" {
+		return errors.New("--two-way closes and reopens issues: set GITHUB_TOKEN (or GH_TOKEN)")
+	}
+
+	resolvedListID, err := cli.ResolveListID(ctx, client, boardID, listID, listName)
+	if err != nil {
+		return err
+	}
+	return syncIssues(ctx, client, cfg, gh, issueSyncOptions{
+		BoardID:  boardID,
+		ListID:   resolvedListID,
+		LabelMap: mapping,
+		TwoWay:   twoWay,
+	})
+}
+
+func (g *githubClient) name() string { return "GitHub" }
+
+// issues returns the open issues of the repository, or all of them,
+// following pagination and skipping pull requests.
+func (g *githubClient) issues(ctx context.Context, all bool) ([]trackedIssue, error) {
+	state := "open"
+	if all {
+		state = "all"
+	}
+	next := g.base + "/repos/" + g.repo + "/issues?per_page=100&state=" + state
+	var issues []trackedIssue
+	for next != "" {
+		var page []githubIssue
+		header, err := g.do(ctx, http.MethodGet, next, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range page {
+			if issue.PullRequest != nil {
+				continue
+			}
+			labels := make([]string, len(issue.Labels))
+			for i, l := range issue.Labels {
+				labels[i] = l.Name
+			}
+			issues = append(issues, trackedIssue{
+				Number:    issue.Number,
+				Title:     issue.Title,
+				Body:      issue.Body,
+				URL:       issue.HTMLURL,
+				Open:      issue.State == "open",
+				UpdatedAt: issue.UpdatedAt,
+				Labels:    labels,
+			})
+		}
+		next = nextPageURL(header.Get("Link"))
+	}
+	return issues, nil
+}
+
+func (g *githubClient) setOpen(ctx context.Context, client *cli.Client, issue trackedIssue, open bool) error {
+	u := fmt.Sprintf("%s/repos/%s/issues/%d", g.base, g.repo, issue.Number)
+	state := "closed"
+	if open {
+		state = "open"
+	}
+	if client.DryRun {
+		return client.PrintDryRun(http.MethodPatch, u, nil, url.Values{"state": {state}})
+	}
+	_, err := g.do(ctx, http.MethodPatch, u, map[string]string{"state": state}, nil)
+	return err
+}
+
+func (g *githubClient) do(ctx context.Context, method, u string, body, out any) (http.Header, error) {
+	var payload io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		payload = strings.NewReader(string(raw))
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+	resp, err := g.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(raw, &e)
+		return nil, fmt.Errorf("github: %s %s: %s (%d)", method, req.URL.Path, cli.FirstNonEmpty(e.Message, http.StatusText(resp.StatusCode)), resp.StatusCode)
+	}
+	if out != nil {
+		if err := json.Unmarshal(raw, out); err != nil {
+			return nil, fmt.Errorf("github: decoding %s: %w", req.URL.Path, err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// nextPageURL returns the rel="next" target of an RFC 8288 Link header.
+func nextPageURL(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(part, ";")
+		if ok && strings.Contains(params, `rel="next"`) {
+			return strings.Trim(strings.TrimSpace(target), "<>")
+		}
+	}
+	return ""
+}
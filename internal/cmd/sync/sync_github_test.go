@@ -0,0 +1,47 @@
+package sync
+
+import (
+	"net/http"
+	"testing"
+
+	"trelli/internal/clitest"
+)
+
+// githubIssues are served by githubStub: #1 is paired with c1, #2 has
+// no card, #3 is a pull request, #4 was closed after c2 last changed, and
+// #5 is still open although c3 was archived later.
+const githubIssues = `[
+	{"number": 1, "title": "Fix login", "state": "open", "html_url": "https://github.com/acme/app/issues/1", "updated_at": "2026-02-01T00:00:00Z"},
+	{"number": 2, "title": "Crash on save", "body": "Stack trace attached.", "state": "open", "html_url": "https://github.com/acme/app/issues/2", "updated_at": "2026-02-01T00:00:00Z", "labels": [{"name": "bug"}, {"name": "enhancement"}]},
+	{"number": 3, "title": "Bump deps", "state": "open", "html_url": "https://github.com/acme/app/pull/3", "updated_at": "2026-02-01T00:00:00Z", "pull_request": {}},
+	{"number": 4, "title": "Release notes", "state": "closed", "html_url": "https://github.com/acme/app/issues/4", "updated_at": "2026-02-05T00:00:00Z"},
+	{"number": 5, "title": "Old idea", "state": "open", "html_url": "https://github.com/acme/app/issues/5", "updated_at": "2026-02-05T00:00:00Z"}
+]`
+
+var githubStub = issueStub{
+	AuthHeader:   "Authorization",
+	AuthValue:    "Bearer gh-token",
+	IssuesPath:   "/repos/acme/app/issues",
+	Issues:       githubIssues,
+	UpdateMethod: http.MethodPatch,
+}
+
+func TestSyncGitHub(t *testing.T) {
+	stub := clitest.NewStub(t)
+	var patched []string
+	gh := newIssueStub(t, githubStub, &patched)
+	t.Setenv("GITHUB_TOKEN", "gh-token")
+
+	got := clitest.RunCLI(t, stub, "sync", "github", "--repo", "acme/app", "--list-name", "To Do",
+		"--label-map", "enhancement=Feature", "--github-url", gh.URL)
+	clitest.CheckGolden(t, "sync_github", got)
+	if len(patched) != 0 {
+		t.Errorf("one-way sync updated issues: %v", patched)
+	}
+
+	got = clitest.RunCLI(t, stub, "--json", "sync", "github", "--repo", "acme/app", "--list", "l1", "--two-way", "--github-url", gh.URL)
+	clitest.CheckGolden(t, "sync_github_two_way", got)
+	if want := `/issues/5 {"state":"closed"}`; len(patched) != 1 || patched[0] != want {
+		t.Errorf("issue updates = %v, want [%s]", patched, want)
+	}
+}
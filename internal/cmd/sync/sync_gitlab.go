@@ -0,0 +1,264 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+const defaultGitLabURL = "https://gitlab.com"
+
+// gitlabIssue is the subset of a GitLab issue sync gitlab uses.
+type gitlabIssue struct {
+	IID         int       `json:"iid"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	State       string    `json:"state"`
+	WebURL      string    `json:"web_url"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Labels      []string  `json:"labels"`
+}
+
+// gitlabClient is a minimal GitLab REST client for the issues of one
+// project. The token is only ever sent in the PRIVATE-TOKEN header.
+type gitlabClient struct {
+	base    string
+	project string
+	token   string
+	http    *http.Client
+}
+
+func runSyncGitLab(ctx context.Context, client *cli.Client, cfg cli.Config, args []string) error {
+	fs := flag.NewFlagSet("sync gitlab", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var project, listID, listName, labelMap, listLabels, baseURL string
+	var twoWay bool
+	boardID := cfg.BoardID
+	fs.StringVar(&project, "project", "", "GitLab project path, e.g. group/proj")
+	fs.StringVar(&listID, "list", "", "List id for new cards")
+	fs.StringVar(&listName, "list-name", "", "List name for new cards (resolved on board)")
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.StringVar(&labelMap, "label-map", "", "GitLab label to Trello label name or id, e.g. bug=Bug,feature=Feature")
+	fs.StringVar(&listLabels, "list-labels", "", "Trello list to GitLab label, e.g. Doing=workflow::doing")
+	fs.BoolVar(&twoWay, "two-way", false, "Also sync open/closed state between issues and cards")
+	fs.StringVar(&baseURL, "gitlab-url", cli.FirstNonEmpty(os.Getenv("GITLAB_URL"), defaultGitLabURL), "GitLab base URL")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("sync")); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	project = strings.Trim(project, "/")
+	if !strings.Contains(project, "/") {
+		return errors.New("sync gitlab requires --project group/name")
+	}
+	boardID = cfg.File.ResolveBoardAlias(boardID)
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+	mapping, err := parseLabelMap(labelMap)
+	if err != nil {
+		return err
+	}
+	// Keys are lower-case list names.
+	listLabel, err := parseLabelMap(listLabels)
+	if err != nil {
+		return err
+	}
+
+	transport, err := cli.NewTransport(cfg)
+	if err != nil {
+		return err
+	}
+	gl := &gitlabClient{
+		base:    strings.TrimSuffix(baseURL, "/"),
+		project: project,
+		token:   os.Getenv("GITLAB_TOKEN"),
+		http:    &http.Client{Transport: transport, Timeout: cfg.Timeout},
+	}
+	if (twoWay || len(listLabel) > 0) && gl.token == "" {
+		return errors.New("--two-way and --list-labels change issues: set GITLAB_TOKEN")
+	}
+
+	resolvedListID, err := cli.ResolveListID(ctx, client, boardID, listID, listName)
+	if err != nil {
+		return err
+	}
+	opts := issueSyncOptions{
+		BoardID:  boardID,
+		ListID:   resolvedListID,
+		LabelMap: mapping,
+		TwoWay:   twoWay,
+	}
+	if len(listLabel) > 0 {
+		lists, err := cli.FetchBoardLists(ctx, client, boardID)
+		if err != nil {
+			return err
+		}
+		listNames := map[string]string{}
+		for _, l := range lists {
+			listNames[l.ID] = l.Name
+		}
+		opts.FollowCard = func(ctx context.Context, issue trackedIssue, card cli.LinkedCard) (string, error) {
+			add, remove := listLabelChanges(issue.Labels, listNames[card.IDList], listLabel)
+			if len(add) == 0 && len(remove) == 0 {
+				return "", nil
+			}
+			form := url.Values{}
+			action := "label issue"
+			if len(add) > 0 {
+				form.Set("add_labels", strings.Join(add, ","))
+				action += " +" + strings.Join(add, " +")
+			}
+			if len(remove) > 0 {
+				form.Set("remove_labels", strings.Join(remove, ","))
+				action += " -" + strings.Join(remove, " -")
+			}
+			return action, gl.updateIssue(ctx, client, issue.Number, form)
+		}
+	}
+	return syncIssues(ctx, client, cfg, gl, opts)
+}
+
+// listLabelChanges returns the labels to add to and remove from an issue
+// so that of the labels --list-labels maps lists to, it has exactly the
+// one of its card's list, or none when that list is not mapped.
+func listLabelChanges(have []string, list string, listLabel map[string]string) (add, remove []string) {
+	want := listLabel[strings.ToLower(list)]
+	if want != "" && !slices.ContainsFunc(have, func(l string) bool { return strings.EqualFold(l, want) }) {
+		add = append(add, want)
+	}
+	for _, l := range have {
+		if strings.EqualFold(l, want) {
+			continue
+		}
+		for _, mapped := range listLabel {
+			if strings.EqualFold(l, mapped) {
+				remove = append(remove, l)
+				break
+			}
+		}
+	}
+	return add, remove
+}
+
+func (g *gitlabClient) name() string { return "GitLab" }
+
+func (g *gitlabClient) projectURL() string {
+	return g.base + "/api/v4/projects/" + url.PathEscape(g.project)
+}
+
+// issues returns the project's open issues, or all of them, following
+// pagination.
+func (g *gitlabClient) issues(ctx context.Context, all bool) ([]trackedIssue, error) {
+	state := "opened"
+	if all {
+		state = "all"
+	}
+	next := g.projectURL() + "/issues?per_page=100&state=" + state
+	var issues []trackedIssue
+	for next != "" {
+		var page []gitlabIssue
+		header, err := g.do(ctx, http.MethodGet, next, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range page {
+			issues = append(issues, trackedIssue{
+				Number:    issue.IID,
+				Title:     issue.Title,
+				Body:      issue.Description,
+				URL:       issue.WebURL,
+				Open:      issue.State == "opened",
+				UpdatedAt: issue.UpdatedAt,
+				Labels:    issue.Labels,
+			})
+		}
+		next = nextPageURL(header.Get("Link"))
+	}
+	return issues, nil
+}
+
+func (g *gitlabClient) setOpen(ctx context.Context, client *cli.Client, issue trackedIssue, open bool) error {
+	event := "close"
+	if open {
+		event = "reopen"
+	}
+	return g.updateIssue(ctx, client, issue.Number, url.Values{"state_event": {event}})
+}
+
+// updateIssue sets issue fields such as state_event or add_labels; under
+// --dry-run it prints the request through the Trello client's dry-run
+// writer instead.
+func (g *gitlabClient) updateIssue(ctx context.Context, client *cli.Client, iid int, fields url.Values) error {
+	u := fmt.Sprintf("%s/issues/%d", g.projectURL(), iid)
+	if client.DryRun {
+		return client.PrintDryRun(http.MethodPut, u, nil, fields)
+	}
+	body := map[string]string{}
+	for k := range fields {
+		body[k] = fields.Get(k)
+	}
+	_, err := g.do(ctx, http.MethodPut, u, body, nil)
+	return err
+}
+
+func (g *gitlabClient) do(ctx context.Context, method, u string, body, out any) (http.Header, error) {
+	var payload io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		payload = strings.NewReader(string(raw))
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, payload)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if g.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", g.token)
+	}
+	resp, err := g.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		// GitLab reports errors as {"message": ...} or {"error": ...}.
+		var e struct {
+			Message any    `json:"message"`
+			Error   string `json:"error"`
+		}
+		_ = json.Unmarshal(raw, &e)
+		msg := e.Error
+		if e.Message != nil {
+			msg = fmt.Sprint(e.Message)
+		}
+		return nil, fmt.Errorf("gitlab: %s %s: %s (%d)", method, req.URL.Path, cli.FirstNonEmpty(msg, http.StatusText(resp.StatusCode)), resp.StatusCode)
+	}
+	if out != nil {
+		if err := json.Unmarshal(raw, out); err != nil {
+			return nil, fmt.Errorf("gitlab: decoding %s: %w", req.URL.Path, err)
+		}
+	}
+	return resp.Header, nil
+}
@@ -0,0 +1,81 @@
+package sync
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"trelli/internal/clitest"
+)
+
+// gitlabIssues are served by gitlabStub and mirror githubIssues: #1 is
+// paired with c1, #2 has no card, #4 was closed after c2 last changed, and
+// #5 is still open although c3 was archived later.
+const gitlabIssues = `[
+	{"iid": 1, "title": "Fix login", "state": "opened", "web_url": "https://gitlab.com/acme/app/-/issues/1", "updated_at": "2026-02-01T00:00:00Z", "labels": ["workflow::doing"]},
+	{"iid": 2, "title": "Crash on save", "description": "Stack trace attached.", "state": "opened", "web_url": "https://gitlab.com/acme/app/-/issues/2", "updated_at": "2026-02-01T00:00:00Z", "labels": ["bug", "feature"]},
+	{"iid": 4, "title": "Release notes", "state": "closed", "web_url": "https://gitlab.com/acme/app/-/issues/4", "updated_at": "2026-02-05T00:00:00Z", "labels": []},
+	{"iid": 5, "title": "Old idea", "state": "opened", "web_url": "https://gitlab.com/acme/app/-/issues/5", "updated_at": "2026-02-05T00:00:00Z", "labels": []}
+]`
+
+var gitlabCardRoutes = map[string]string{
+	"/1/boards/b1/cards": `[
+		{"id": "c1", "name": "Fix login, again", "idList": "l1", "closed": false, "dateLastActivity": "2026-02-10T09:30:00.000Z", "attachments": [{"id": "at1", "url": "https://gitlab.com/acme/app/-/issues/1"}]},
+		{"id": "c2", "name": "Write \"release\" notes", "idList": "l1", "closed": false, "dateLastActivity": "2026-02-01T00:00:00.000Z", "attachments": [{"id": "at2", "url": "https://gitlab.com/acme/app/-/issues/4"}]},
+		{"id": "c3", "name": "Old idea", "idList": "l2", "closed": true, "dateLastActivity": "2026-03-01T00:00:00.000Z", "attachments": [{"id": "at3", "url": "https://gitlab.com/acme/app/-/issues/5"}]}
+	]`,
+}
+
+var gitlabStub = issueStub{
+	AuthHeader:   "PRIVATE-TOKEN",
+	AuthValue:    "gl-token",
+	IssuesPath:   "/api/v4/projects/acme%2Fapp/issues",
+	Issues:       gitlabIssues,
+	UpdateMethod: http.MethodPut,
+}
+
+func TestSyncGitLab(t *testing.T) {
+	stub := clitest.NewStub(t)
+	clitest.WithStubRoutes(t, gitlabCardRoutes)
+	var updated []string
+	gl := newIssueStub(t, gitlabStub, &updated)
+	t.Setenv("GITLAB_TOKEN", "gl-token")
+
+	got := clitest.RunCLI(t, stub, "sync", "gitlab", "--project", "acme/app", "--list-name", "To Do",
+		"--label-map", "feature=Feature", "--gitlab-url", gl.URL)
+	clitest.CheckGolden(t, "sync_gitlab", got)
+	if len(updated) != 0 {
+		t.Errorf("one-way sync updated issues: %v", updated)
+	}
+
+	got = clitest.RunCLI(t, stub, "--json", "sync", "gitlab", "--project", "acme/app", "--list", "l1", "--two-way",
+		"--list-labels", "To Do=workflow::todo,Doing=workflow::doing", "--gitlab-url", gl.URL)
+	clitest.CheckGolden(t, "sync_gitlab_two_way", got)
+	want := []string{
+		`/issues/1 {"add_labels":"workflow::todo","remove_labels":"workflow::doing"}`,
+		`/issues/5 {"state_event":"close"}`,
+	}
+	if !reflect.DeepEqual(updated, want) {
+		t.Errorf("issue updates = %v, want %v", updated, want)
+	}
+}
+
+func TestListLabelChanges(t *testing.T) {
+	listLabel := map[string]string{"doing": "workflow::doing", "review": "workflow::review"}
+	tests := []struct {
+		have        []string
+		list        string
+		add, remove []string
+	}{
+		{have: []string{"bug"}, list: "Doing", add: []string{"workflow::doing"}},
+		{have: []string{"bug", "workflow::doing"}, list: "Doing"},
+		{have: []string{"workflow::doing"}, list: "Review", add: []string{"workflow::review"}, remove: []string{"workflow::doing"}},
+		{have: []string{"workflow::review", "bug"}, list: "Done", remove: []string{"workflow::review"}},
+	}
+	for _, tt := range tests {
+		add, remove := listLabelChanges(tt.have, tt.list, listLabel)
+		if !reflect.DeepEqual(add, tt.add) || !reflect.DeepEqual(remove, tt.remove) {
+			t.Errorf("listLabelChanges(%v, %q) = %v, %v; want %v, %v", tt.have, tt.list, add, remove, tt.add, tt.remove)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Package time implements "trelli time": track time on cards through comments.
+package time
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "time",
+	Summary: "Track time on cards through comments",
+	Description: `log records time spent on a card as a comment of the form
+
+  [time] 1h30m: what was done
+
+so it stays on the card for everyone to see. report totals the time
+comments posted on a board in a period (default the last week) per card
+and member. Comments written by hand in the same form count too; the
+duration is anything Go's time.ParseDuration reads, such as 45m or 1.5h.`,
+	Subcommands: []cli.Subcommand{
+		{Name: "log", Usage: []string{"log [--card] <cardId> --duration <duration> [--note <text>]"}, Flags: []cli.Flag{cli.CardFlag,
+			{Name: "duration", Arg: "duration", Desc: "Time spent, e.g. 1h30m or 45m"},
+			{Name: "note", Arg: "text", Desc: "What the time was spent on"},
+		}},
+		{Name: "report", Usage: []string{"report [[--board] <id>] [--since <age|date>]"}, Flags: []cli.Flag{
+			{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+			{Name: "since", Arg: "age|date", Desc: "Count time logged after this: an age such as 1w, 7d, or 36h, or a date (default 1w)"},
+		}},
+	},
+	Options: []cli.Flag{cli.JSONOption},
+	Sections: []cli.HelpSection{{Title: "Examples", Body: `trelli time log AbCd --duration 1h30m --note "Reproduced on staging"
+trelli time report --board EnGi --since 2026-02-01`}},
+	Run: runTime,
+}
+
+// timeCommentPattern matches the first line of a time comment.
+var timeCommentPattern = regexp.MustCompile(`^\[time\]\s+(\S+?)(?::\s*(.*))?$`)
+
+// timeEntry is the time on one card by one member.
+type timeEntry struct {
+	Card    string `json:"card"`
+	Name    string `json:"name"`
+	Member  string `json:"member"`
+	Minutes int    `json:"minutes"`
+	Time    string `json:"time"`
+	Entries int    `json:"entries"`
+}
+
+func runTime(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) == 0 {
+		cli.PrintCommandHelp("time")
+		return nil
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		cli.PrintCommandHelp("time")
+		return nil
+	case "log":
+		fs := flag.NewFlagSet("time log", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		var cardID, duration, note string
+		fs.StringVar(&cardID, "card", "", "Card id")
+		fs.StringVar(&duration, "duration", "", "Time spent")
+		fs.StringVar(&note, "note", "", "What the time was spent on")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("time")); err != nil {
+			return err
+		}
+		if err := cli.TakePositional(fs, &cardID); err != nil {
+			return err
+		}
+		if strings.TrimSpace(cardID) == "" || duration == "" {
+			return errors.New("time log requires --card and --duration")
+		}
+		d, err := time.ParseDuration(duration)
+		if err != nil || d < time.Minute {
+			return fmt.Errorf("invalid --duration %q: want at least a minute, e.g. 1h30m or 45m", duration)
+		}
+		created, err := cli.AddComment(ctx, client, cfg, cardID, timeComment(d, note))
+		if err != nil {
+			return err
+		}
+		return cli.Render(cfg, created, cli.CommentsTable([]cli.CommentAction{created}))
+	case "report":
+		fs := flag.NewFlagSet("time report", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		boardID := cfg.BoardID
+		since := "1w"
+		fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+		fs.StringVar(&since, "since", since, "Count time logged after this")
+		if err := cli.ParseFlagSet(fs, args[1:], cli.CommandHelp("time")); err != nil {
+			return err
+		}
+		// boardID already holds the global default, so a positional board
+		// is collected separately and takes precedence.
+		var positionalBoard string
+		if err := cli.TakePositional(fs, &positionalBoard); err != nil {
+			return err
+		}
+		boardID = cfg.File.ResolveBoardAlias(cli.FirstNonEmpty(positionalBoard, boardID))
+		if strings.TrimSpace(boardID) == "" {
+			return cli.ErrNoBoard
+		}
+		from, err := cli.ParseSince(since, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		entries, err := timeReport(ctx, client, boardID, from)
+		if err != nil {
+			return err
+		}
+		return cli.Render(cfg, cli.NonNil(entries), timeTable(entries))
+	default:
+		return fmt.Errorf("unknown time subcommand %q", args[0])
+	}
+}
+
+// timeComment formats a time comment, rounding d to the minute.
+func timeComment(d time.Duration, note string) string {
+	text := "[time] " + formatWorkTime(int(d.Round(time.Minute)/time.Minute))
+	if note = strings.TrimSpace(note); note != "" {
+		text += ": " + note
+	}
+	return text
+}
+
+// parseTimeComment returns the minutes a time comment records.
+func parseTimeComment(text string) (int, bool) {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	m := timeCommentPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return 0, false
+	}
+	d, err := time.ParseDuration(m[1])
+	if err != nil || d < time.Minute/2 {
+		return 0, false
+	}
+	return int(d.Round(time.Minute) / time.Minute), true
+}
+
+// formatWorkTime writes minutes as 1h30m, 2h, or 45m.
+func formatWorkTime(minutes int) string {
+	h, m := minutes/60, minutes%60
+	switch {
+	case h == 0:
+		return strconv.Itoa(m) + "m"
+	case m == 0:
+		return strconv.Itoa(h) + "h"
+	}
+	return fmt.Sprintf("%dh%dm", h, m)
+}
+
+// timeReport totals the time comments on a board after from per card and
+// member, by card name and then member.
+func timeReport(ctx context.Context, client *cli.Client, boardID string, from time.Time) ([]timeEntry, error) {
+	totals := map[[2]string]*timeEntry{}
+	cursor := cli.NotifyCursor{Since: from.Format("2006-01-02T15:04:05.000Z"), Seen: map[string]bool{}}
+	err := cli.NotifyNewActions(ctx, client, boardID, &cursor, []string{"commentCard"}, func(_ context.Context, a cli.NotifyAction) error {
+		minutes, ok := parseTimeComment(a.Data.Text)
+		if !ok || a.Data.Card == nil {
+			return nil
+		}
+		member := cli.FirstNonEmpty(a.MemberCreator.FullName, a.MemberCreator.Username)
+		key := [2]string{a.Data.Card.ID, member}
+		e := totals[key]
+		if e == nil {
+			e = &timeEntry{Card: cli.FirstNonEmpty(a.Data.Card.ShortLink, a.Data.Card.ID), Member: member}
+			totals[key] = e
+		}
+		// Actions come oldest first; the latest name wins.
+		e.Name = a.Data.Card.Name
+		e.Minutes += minutes
+		e.Entries++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var entries []timeEntry
+	for _, e := range totals {
+		e.Time = formatWorkTime(e.Minutes)
+		entries = append(entries, *e)
+	}
+	slices.SortFunc(entries, func(a, b timeEntry) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), strings.Compare(a.Card, b.Card), strings.Compare(a.Member, b.Member))
+	})
+	return entries, nil
+}
+
+func timeTable(entries []timeEntry) cli.Table {
+	t := cli.Table{Columns: []string{"CARD", "NAME", "MEMBER", "TIME", "ENTRIES"}, Empty: "No time logged."}
+	total := 0
+	for _, e := range entries {
+		t.Rows = append(t.Rows, []string{e.Card, e.Name, e.Member, e.Time, strconv.Itoa(e.Entries)})
+		total += e.Minutes
+	}
+	if len(entries) > 0 {
+		t.Rows = append(t.Rows, []string{"", "Total", "", formatWorkTime(total), ""})
+	}
+	return t
+}
@@ -0,0 +1,50 @@
+package time
+
+import (
+	"os"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+// TestMain registers the command as main does, for the tests that run it
+// through the CLI.
+func TestMain(m *testing.M) {
+	cli.Register(Command)
+	os.Exit(m.Run())
+}
+
+func TestTimeReport(t *testing.T) {
+	clitest.WithStubRoutes(t, map[string]string{
+		"/1/boards/b1/actions": `[
+			{"id": "a4", "type": "commentCard", "date": "2026-02-06T10:00:00.000Z", "data": {"text": "[time] 45m", "card": {"id": "c1", "name": "Fix login, again", "shortLink": "AbCd"}}, "memberCreator": {"username": "ada", "fullName": "Ada Lovelace"}},
+			{"id": "a3", "type": "commentCard", "date": "2026-02-05T10:00:00.000Z", "data": {"text": "Looks good to me", "card": {"id": "c1", "name": "Fix login", "shortLink": "AbCd"}}, "memberCreator": {"username": "grace", "fullName": "Grace Hopper"}},
+			{"id": "a2", "type": "commentCard", "date": "2026-02-04T10:00:00.000Z", "data": {"text": "[time] 1.5h: release notes draft\n\nmore later", "card": {"id": "c2", "name": "Write notes", "shortLink": "EfGh"}}, "memberCreator": {"username": "grace", "fullName": "Grace Hopper"}},
+			{"id": "a1", "type": "commentCard", "date": "2026-02-03T10:00:00.000Z", "data": {"text": "[time] 1h: reproduced", "card": {"id": "c1", "name": "Fix login", "shortLink": "AbCd"}}, "memberCreator": {"username": "ada", "fullName": "Ada Lovelace"}}
+		]`,
+	})
+	stub := clitest.NewStub(t)
+	clitest.CheckGolden(t, "time_report", clitest.RunCLI(t, stub, "time", "report", "--since", "2026-02-01"))
+	clitest.CheckGolden(t, "time_report_json", clitest.RunCLI(t, stub, "--json", "time", "report", "b1", "--since", "2026-02-01"))
+}
+
+func TestParseTimeComment(t *testing.T) {
+	for text, want := range map[string]int{
+		"[time] 1h30m: reproduced": 90,
+		"[time] 45m":               45,
+		"  [time]  2h\n\nnotes":    120,
+	} {
+		if got, ok := parseTimeComment(text); !ok || got != want {
+			t.Errorf("parseTimeComment(%q) = %d, %v; want %d", text, got, ok, want)
+		}
+	}
+	for _, text := range []string{"", "time: 1h", "[time] soon", "[time] -1h", "[time] 20s", "see [time] 1h"} {
+		if _, ok := parseTimeComment(text); ok {
+			t.Errorf("parseTimeComment(%q) succeeded", text)
+		}
+	}
+	if got := timeComment(90*60e9, " reproduced "); got != "[time] 1h30m: reproduced" {
+		t.Errorf("timeComment = %q", got)
+	}
+}
@@ -0,0 +1,189 @@
+// Package undo implements "trelli undo": reverse recent changes.
+package undo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "undo",
+	Summary: "Reverse recent changes",
+	Description: `Reverse the most recent mutations made with this profile, newest first.
+cards create, move, archive, label, and assign, comments add, checklists
+create, and checklists add-item are journaled next to the config file
+(last 200). Undo archives created cards, moves cards back, unarchives
+cards, takes added labels and members off and puts removed ones back, and
+deletes added comments, checklists, and items. It asks for confirmation
+like other destructive commands.`,
+	Usage: []string{"[--last <n>] [--yes]", "--list"},
+	Options: []cli.Flag{
+		{Name: "last", Arg: "n", Desc: "Undo the n most recent actions (default 1)"},
+		{Name: "list", Desc: "Show journaled actions instead of undoing"},
+		{Name: "yes", Short: "y", Desc: "Skip the confirmation prompt"},
+		{Name: "force", Desc: "Proceed without a prompt when stdin is not a terminal"},
+		cli.JSONOption,
+	},
+	Run:          runUndo,
+	BareIsAction: true,
+}
+
+// maxJournalEntries bounds the undo journal; older entries are dropped.
+const maxJournalEntries = 200
+
+// writeJournal replaces the journal with the newest maxJournalEntries of
+// entries. Only undo rewrites the journal; mutations append to it.
+func writeJournal(entries []cli.JournalEntry) error {
+	p, err := cli.JournalPath()
+	if err != nil {
+		return err
+	}
+	if len(entries) > maxJournalEntries {
+		entries = entries[len(entries)-maxJournalEntries:]
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+func runUndo(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help" || args[0] == "help") {
+		cli.PrintCommandHelp("undo")
+		return nil
+	}
+	fs := flag.NewFlagSet("undo", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	last := 1
+	var list bool
+	fs.IntVar(&last, "last", last, "Number of most recent actions to undo")
+	fs.BoolVar(&list, "list", false, "Show the journal instead of undoing")
+	cli.AddConfirmFlags(fs, &cfg)
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("undo")); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	all, err := cli.ReadJournal()
+	if err != nil {
+		return err
+	}
+	profile := cli.FirstNonEmpty(cfg.Profile, "default")
+	// Mutations only append; entries beyond the newest maxJournalEntries
+	// are dropped when undo next rewrites the journal.
+	var mine []int
+	for i := len(all) - 1; i >= max(0, len(all)-maxJournalEntries); i-- {
+		if all[i].Profile == profile {
+			mine = append(mine, i)
+		}
+	}
+
+	if list {
+		entries := make([]cli.JournalEntry, 0, len(mine))
+		for _, i := range mine {
+			entries = append(entries, all[i])
+		}
+		return cli.Render(cfg, entries, journalTable(entries))
+	}
+
+	if last < 1 {
+		return errors.New("--last must be at least 1")
+	}
+	if len(mine) == 0 {
+		return errors.New("nothing to undo")
+	}
+	if last > len(mine) {
+		last = len(mine)
+	}
+	targets := make([]string, 0, last)
+	for _, i := range mine[:last] {
+		targets = append(targets, fmt.Sprintf("%s (%s)", all[i].Summary, all[i].UndoDescription()))
+	}
+	if err := cli.Confirm(cfg, fmt.Sprintf("undo %d action(s)", last), targets); err != nil {
+		return err
+	}
+
+	undone := map[int]bool{}
+	var undoErr error
+	for _, i := range mine[:last] {
+		if err := all[i].Reverse(ctx, client); err != nil {
+			if errors.Is(err, cli.ErrDryRun) {
+				continue
+			}
+			undoErr = fmt.Errorf("undo %s: %w", all[i].Summary, err)
+			break
+		}
+		undone[i] = true
+		if !cfg.Structured() {
+			fmt.Printf("Undid %s\n", all[i].Summary)
+		}
+	}
+	if cfg.DryRun {
+		return cli.ErrDryRun
+	}
+
+	// Keep what other invocations journaled while undo ran.
+	current, err := cli.ReadJournal()
+	if err != nil {
+		return err
+	}
+	if len(current) > len(all) {
+		all = append(all, current[len(all):]...)
+	}
+	kept := make([]cli.JournalEntry, 0, len(all))
+	done := make([]cli.JournalEntry, 0, len(undone))
+	for i, e := range all {
+		if undone[i] {
+			done = append(done, e)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	if err := writeJournal(kept); err != nil {
+		return err
+	}
+	if undoErr != nil {
+		if errors.Is(undoErr, context.Canceled) {
+			slog.Warn(fmt.Sprintf("Interrupted after undoing %d of %d action(s)", len(done), last), "undone", len(done), "requested", last)
+		}
+		return undoErr
+	}
+	if cfg.Structured() {
+		return cli.Render(cfg, map[string]any{"undone": done})
+	}
+	return nil
+}
+
+func journalTable(entries []cli.JournalEntry) cli.Table {
+	t := cli.Table{Columns: []string{"TIME", "ACTION", "SUMMARY", "UNDO"}, Empty: "Nothing to undo."}
+	for _, e := range entries {
+		t.Rows = append(t.Rows, []string{e.Time.Local().Format("2006-01-02 15:04"), e.Action, e.Summary, e.UndoDescription()})
+	}
+	return t
+}
@@ -0,0 +1,33 @@
+package undo
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+func TestRecordUndoConcurrent(t *testing.T) {
+	cfg, _, err := clitest.TestConfig(t, clitest.NewStub(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cli.RecordUndo(cfg, cli.JournalEntry{Action: "cards.create", Target: fmt.Sprintf("c%d", i)})
+		}()
+	}
+	wg.Wait()
+	entries, err := cli.ReadJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 50 {
+		t.Errorf("journaled %d of 50 concurrent entries", len(entries))
+	}
+}
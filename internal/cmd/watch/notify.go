@@ -0,0 +1,17 @@
+package watch
+
+import (
+	"strconv"
+	"strings"
+
+	"trelli/internal/cli"
+)
+
+// plainMarkup formats for a terminal.
+var plainMarkup = cli.ActionMarkup{
+	Member: func(s string) string { return s },
+	List:   func(s string) string { return s },
+	Card:   func(name, _ string) string { return strconv.Quote(name) },
+	Name:   strconv.Quote,
+	Quote:  func(s string) string { return "  " + strings.ReplaceAll(s, "\n", "\n  ") },
+}
@@ -0,0 +1,53 @@
+package watch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"trelli/internal/cli"
+)
+
+func TestDescribeAction(t *testing.T) {
+	tests := []struct {
+		action string
+		want   string
+	}{
+		{`{"type": "updateCard", "data": {"card": {"name": "Fix bug"}, "listBefore": {"name": "Doing"}, "listAfter": {"name": "Done"}}, "memberCreator": {"username": "alice"}}`,
+			`alice moved "Fix bug" from Doing to Done`},
+		{`{"type": "updateCard", "data": {"card": {"name": "Fix login"}, "old": {"name": "Fix bug"}}, "memberCreator": {"username": "alice"}}`,
+			`alice renamed "Fix bug" to "Fix login"`},
+		{`{"type": "updateCard", "data": {"card": {"name": "Fix bug", "due": "2026-03-01T12:00:00.000Z"}, "old": {"due": null}}, "memberCreator": {"username": "alice"}}`,
+			`alice set the due date of "Fix bug" to 2026-03-01 12:00`},
+		{`{"type": "updateCard", "data": {"card": {"name": "Fix bug", "due": null}, "old": {"due": "2026-03-01T12:00:00.000Z"}}, "memberCreator": {"username": "alice"}}`,
+			`alice removed the due date of "Fix bug"`},
+		{`{"type": "updateCard", "data": {"card": {"name": "Fix bug", "dueComplete": true}, "old": {"dueComplete": false}}, "memberCreator": {"username": "alice"}}`,
+			`alice marked "Fix bug" complete`},
+		{`{"type": "updateCard", "data": {"card": {"name": "Fix bug"}, "old": {"desc": ""}}, "memberCreator": {"username": "alice"}}`,
+			`alice edited the description of "Fix bug"`},
+		{`{"type": "addMemberToCard", "data": {"card": {"name": "Fix bug"}}, "member": {"fullName": "Grace Hopper", "username": "grace"}, "memberCreator": {"username": "alice"}}`,
+			`alice added Grace Hopper to "Fix bug"`},
+		{`{"type": "addMemberToCard", "data": {"card": {"name": "Fix bug"}}, "member": {"username": "alice"}, "memberCreator": {"username": "alice"}}`,
+			`alice joined "Fix bug"`},
+		{`{"type": "addLabelToCard", "data": {"card": {"name": "Fix bug"}, "label": {"name": "", "color": "red"}}, "memberCreator": {"username": "alice"}}`,
+			`alice added label "red" to "Fix bug"`},
+		{`{"type": "updateCheckItemStateOnCard", "data": {"card": {"name": "Fix bug"}, "checkItem": {"name": "Reproduce", "state": "complete"}}, "memberCreator": {"username": "alice"}}`,
+			`alice completed "Reproduce" on "Fix bug"`},
+		{`{"type": "addAttachmentToCard", "data": {"card": {"name": "Fix bug"}, "attachment": {"name": "trace.log"}}, "memberCreator": {"username": "alice"}}`,
+			`alice attached "trace.log" to "Fix bug"`},
+		{`{"type": "updateList", "data": {"list": {"name": "Doing", "closed": true}}, "memberCreator": {"username": "alice"}}`,
+			`alice archived list Doing`},
+		{`{"type": "updateList", "data": {"list": {"name": "Doing"}, "old": {"name": "In progress"}}, "memberCreator": {"username": "alice"}}`,
+			`alice renamed list In progress to Doing`},
+		{`{"type": "enablePowerUp", "data": {}, "memberCreator": {"username": "alice"}}`,
+			`alice: enablePowerUp`},
+	}
+	for _, tt := range tests {
+		var a cli.NotifyAction
+		if err := json.Unmarshal([]byte(tt.action), &a); err != nil {
+			t.Fatal(err)
+		}
+		if got := cli.DescribeAction(a, plainMarkup); got != tt.want {
+			t.Errorf("describeAction(%s) = %q, want %q", a.Type, got, tt.want)
+		}
+	}
+}
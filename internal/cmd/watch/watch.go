@@ -0,0 +1,216 @@
+// Package watch implements "trelli watch": print board changes as they happen.
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"trelli/internal/cli"
+)
+
+var Command = cli.Command{
+	Name:    "watch",
+	Summary: "Print board changes as they happen",
+	Description: `Poll a board's actions and print each new one as it happens, until
+interrupted: a line per change, or with --json one JSON object per line
+(NDJSON) for piping into other tools. Actions from before the start are
+not printed. --exec also runs a shell command per change, with the event
+as JSON on stdin and its type, card id, and action id in TRELLI_ACTION_TYPE,
+TRELLI_CARD_ID, and TRELLI_ACTION_ID.
+
+--via-webhook receives changes as they happen instead of polling: watch
+listens on --addr:--port, registers a Trello webhook for the board with
+--public-url as its callback, and removes it on exit. --public-url must
+forward to the listener, for example an ngrok tunnel. Requests are only
+accepted with a valid signature, made with the API secret of your key
+(TRELLO_API_SECRET or --webhook-secret).`,
+	Usage: []string{
+		"[[--board] <boardIdOrShortLink>] [--interval <duration>] [--type <types>] [--exec <command>]",
+		"--via-webhook --public-url <url> [--port <n>] [--addr <host>] [--webhook-secret <secret>] [[--board] <id>] [--type <types>] [--exec <command>]",
+	},
+	Options: []cli.Flag{
+		{Name: "board", Arg: "id", Desc: "Board id, shortLink, or alias (default: the default board)"},
+		{Name: "interval", Arg: "duration", Desc: "Time between polls (default 30s)"},
+		{Name: "type", Arg: "types", Desc: "Action types to show, comma-separated or repeated (default: all)"},
+		{Name: "exec", Arg: "command", Desc: "Shell command to run for each change"},
+		{Name: "via-webhook", Desc: "Receive changes through a webhook instead of polling"},
+		{Name: "public-url", Arg: "url", Desc: "Public URL forwarding to the listener, e.g. an ngrok URL"},
+		{Name: "port", Arg: "n", Desc: "Port the webhook listener binds (default 8092)"},
+		{Name: "addr", Arg: "host", Desc: "Address the webhook listener binds (default 127.0.0.1)"},
+		{Name: "webhook-secret", Arg: "secret", Desc: "API secret that signs webhook requests (default $TRELLO_API_SECRET)"},
+		cli.JSONOption,
+	},
+	Sections: []cli.HelpSection{cli.ActionTypesHelp, {Title: "Examples", Body: `trelli watch --board EnGi --type createCard,updateCard --type commentCard
+trelli --json watch | jq -r 'select(.type == "commentCard") | .text'
+trelli watch --exec 'notify-send "Trello" "$(jq -r .summary)"'
+ngrok http 8092 &
+TRELLO_API_SECRET=... trelli watch --via-webhook --public-url https://<id>.ngrok.app`}},
+	Run:          runWatch,
+	BareIsAction: true,
+}
+
+// watchEvent is a change as watch prints it with --json and passes to
+// --exec.
+type watchEvent struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Date      string `json:"date"`
+	Member    string `json:"member,omitempty"`
+	Card      string `json:"card,omitempty"`
+	CardID    string `json:"cardId,omitempty"`
+	ShortLink string `json:"shortLink,omitempty"`
+	List      string `json:"list,omitempty"`
+	Text      string `json:"text,omitempty"`
+	Summary   string `json:"summary"`
+}
+
+func newWatchEvent(a cli.NotifyAction) watchEvent {
+	e := watchEvent{
+		ID:      a.ID,
+		Type:    a.Type,
+		Date:    a.Date,
+		Member:  cli.FirstNonEmpty(a.MemberCreator.FullName, a.MemberCreator.Username),
+		Text:    a.Data.Text,
+		Summary: cli.DescribeAction(a, plainMarkup),
+	}
+	if c := a.Data.Card; c != nil {
+		e.Card, e.CardID, e.ShortLink = c.Name, c.ID, c.ShortLink
+	}
+	switch {
+	case a.Data.ListAfter != nil:
+		e.List = a.Data.ListAfter.Name
+	case a.Data.List != nil:
+		e.List = a.Data.List.Name
+	}
+	return e
+}
+
+func runWatch(client *cli.Client, cfg cli.Config, args []string) error {
+	ctx := cfg.Context
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "--help" || args[0] == "help") {
+		cli.PrintCommandHelp("watch")
+		return nil
+	}
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	boardID := cfg.BoardID
+	interval := 30 * time.Second
+	var types cli.ActionTypesFlag
+	var command string
+	var viaWebhook bool
+	hook := webhookOptions{addr: "127.0.0.1", port: defaultWebhookPort, secret: os.Getenv("TRELLO_API_SECRET")}
+	fs.StringVar(&boardID, "board", boardID, "Board id, shortLink, or alias")
+	fs.DurationVar(&interval, "interval", interval, "Time between polls")
+	fs.Var(&types, "type", "Action types, comma-separated or repeated")
+	fs.StringVar(&command, "exec", "", "Shell command to run for each change")
+	fs.BoolVar(&viaWebhook, "via-webhook", false, "Receive changes through a webhook")
+	fs.StringVar(&hook.publicURL, "public-url", "", "Public URL forwarding to the listener")
+	fs.IntVar(&hook.port, "port", hook.port, "Port the webhook listener binds")
+	fs.StringVar(&hook.addr, "addr", hook.addr, "Address the webhook listener binds")
+	fs.StringVar(&hook.secret, "webhook-secret", hook.secret, "API secret that signs webhook requests")
+	if err := cli.ParseFlagSet(fs, args, cli.CommandHelp("watch")); err != nil {
+		return err
+	}
+	if err := cli.TakePositional(fs, &boardID); err != nil {
+		return err
+	}
+	boardID = cfg.File.ResolveBoardAlias(boardID)
+	if strings.TrimSpace(boardID) == "" {
+		return cli.ErrNoBoard
+	}
+	if interval < time.Second {
+		return errors.New("--interval must be at least 1s")
+	}
+
+	handle := watchHandler(cfg, os.Stdout, command)
+	if viaWebhook {
+		switch {
+		case hook.publicURL == "":
+			return errors.New("--via-webhook requires --public-url")
+		case hook.secret == "":
+			return errors.New("--via-webhook verifies requests with your API secret: set TRELLO_API_SECRET (or --webhook-secret)")
+		case hook.port < 0 || hook.port > 65535:
+			return fmt.Errorf("invalid --port %d", hook.port)
+		}
+		return runWatchWebhook(ctx, client, boardID, types, hook, handle)
+	}
+
+	client.LongRunning()
+	cursor, err := cli.StartNotifyCursor(ctx, client, boardID)
+	if err != nil {
+		return err
+	}
+	slog.Info(fmt.Sprintf("Watching board %s every %s; Ctrl-C stops", boardID, interval), "board", boardID)
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick.C:
+		}
+		if err := cli.NotifyNewActions(ctx, client, boardID, &cursor, types, handle); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			slog.Warn(fmt.Sprintf("polling board %s: %v", boardID, err), "board", boardID)
+		}
+	}
+}
+
+// watchHandler returns the function that prints an action to w, as a line
+// of text or with --json an NDJSON object, and runs command for it.
+func watchHandler(cfg cli.Config, w io.Writer, command string) func(context.Context, cli.NotifyAction) error {
+	return func(ctx context.Context, a cli.NotifyAction) error {
+		e := newWatchEvent(a)
+		var line bytes.Buffer
+		enc := json.NewEncoder(&line)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+		// --exec gets the event as it is; only what we print is redacted.
+		if cfg.Structured() {
+			w.Write(cfg.Redact.RedactJSON(line.Bytes()))
+		} else {
+			when := e.Date
+			if t, err := time.Parse(time.RFC3339, e.Date); err == nil {
+				when = t.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%s  %s\n", when, cfg.Redact.RedactText(e.Summary))
+		}
+		if command == "" {
+			return nil
+		}
+		return runWatchExec(ctx, command, e, line.Bytes())
+	}
+}
+
+// runWatchExec runs command through the shell with the event on stdin.
+// Its output goes to ours.
+func runWatchExec(ctx context.Context, command string, e watchEvent, event []byte) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stdin = bytes.NewReader(event)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), "TRELLI_ACTION_TYPE="+e.Type, "TRELLI_ACTION_ID="+e.ID, "TRELLI_CARD_ID="+e.CardID)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("--exec: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package watch
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"trelli/internal/cli"
+	"trelli/internal/clitest"
+)
+
+func TestWatchNDJSON(t *testing.T) {
+	trello := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("limit") == "1" {
+			io.WriteString(w, `[{"id": "a0", "date": "2026-02-10T09:30:00.000Z"}]`)
+			return
+		}
+		if r.URL.Query().Has("filter") {
+			t.Errorf("filter = %q, want all types", r.URL.Query().Get("filter"))
+		}
+		io.WriteString(w, `[
+			{"id": "a2", "type": "updateCard", "date": "2026-02-10T09:32:00.000Z", "data": {"card": {"id": "c1", "name": "Fix login", "shortLink": "AbCd"}, "listBefore": {"name": "To Do"}, "listAfter": {"name": "Done"}}, "memberCreator": {"username": "grace"}},
+			{"id": "a1", "type": "commentCard", "date": "2026-02-10T09:31:00.000Z", "data": {"text": "On it\nnow", "card": {"id": "c1", "name": "Fix login", "shortLink": "AbCd"}}, "memberCreator": {"fullName": "Ada Lovelace"}}
+		]`)
+	}))
+	t.Cleanup(trello.Close)
+	cfg, _, err := clitest.TestConfig(t, trello, "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := cli.Connect(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.LongRunning()
+
+	command := ""
+	out := filepath.Join(t.TempDir(), "events")
+	if runtime.GOOS != "windows" {
+		command = `printf '%s %s ' "$TRELLI_ACTION_TYPE" "$TRELLI_CARD_ID" >> ` + out + ` && cat >> ` + out
+	}
+	var buf bytes.Buffer
+	cursor, err := cli.StartNotifyCursor(cfg.Context, client, "b1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.NotifyNewActions(cfg.Context, client, "b1", &cursor, nil, watchHandler(cfg, &buf, command)); err != nil {
+		t.Fatal(err)
+	}
+	clitest.CheckGolden(t, "watch_ndjson", buf.String())
+
+	if command == "" {
+		return
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); !strings.HasPrefix(got, "commentCard c1 {") || strings.Count(got, "\n") != 2 {
+		t.Errorf("--exec saw %q", got)
+	}
+}
+
+func TestWatchRedact(t *testing.T) {
+	trello := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("limit") == "1" {
+			io.WriteString(w, `[{"id": "a0", "date": "2026-02-10T09:30:00.000Z"}]`)
+			return
+		}
+		io.WriteString(w, `[
+			{"id": "a1", "type": "commentCard", "date": "2026-02-10T09:31:00.000Z", "data": {"text": "On it, @grace", "card": {"id": "c1", "name": "Fix login", "shortLink": "AbCd"}}, "memberCreator": {"id": "m1", "username": "ada", "fullName": "Ada Lovelace"}}
+		]`)
+	}))
+	t.Cleanup(trello.Close)
+	for _, tt := range []struct {
+		name string
+		args []string
+	}{
+		{"watch_redact", []string{"--redact"}},
+		{"watch_redact_json", []string{"--redact", "--json"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _, err := clitest.TestConfig(t, trello, tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client, err := cli.Connect(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.LongRunning()
+			cursor, err := cli.StartNotifyCursor(cfg.Context, client, "b1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := cli.NotifyNewActions(cfg.Context, client, "b1", &cursor, nil, watchHandler(cfg, &buf, "")); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(buf.String(), "Ada") || strings.Contains(buf.String(), "ada") {
+				t.Errorf("--redact shows a name:\n%s", buf.String())
+			}
+			clitest.CheckGolden(t, tt.name, buf.String())
+		})
+	}
+}
+
+func TestWatchLine(t *testing.T) {
+	cfg, _, err := clitest.TestConfig(t, clitest.NewStub(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a cli.NotifyAction
+	a.Type, a.Date = "createCard", "not a date"
+	a.MemberCreator.Username = "grace"
+	var buf bytes.Buffer
+	if err := watchHandler(cfg, &buf, "")(cfg.Context, a); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "not a date  grace created a card\n"; got != want {
+		t.Errorf("line = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,172 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"trelli/internal/cli"
+	"trelli/pkg/trello"
+)
+
+const (
+	defaultWebhookPort = 8092
+	maxWebhookBody     = 1 << 20
+	// webhookSeen bounds the action ids remembered to drop redeliveries.
+	webhookSeen = 1000
+)
+
+// webhookOptions configures watch --via-webhook.
+type webhookOptions struct {
+	publicURL string
+	addr      string
+	port      int
+	secret    string
+}
+
+// webhookReceiver verifies the webhook requests Trello sends and queues
+// the actions of the wanted types, once each.
+type webhookReceiver struct {
+	secret      string
+	callbackURL string
+	types       map[string]bool
+	actions     chan cli.NotifyAction
+
+	mu   sync.Mutex
+	seen map[string]bool
+	ids  []string
+}
+
+func newWebhookReceiver(secret, callbackURL string, types []string) *webhookReceiver {
+	r := &webhookReceiver{secret: secret, callbackURL: callbackURL, actions: make(chan cli.NotifyAction, 100), seen: map[string]bool{}}
+	if len(types) > 0 {
+		r.types = map[string]bool{}
+		for _, t := range types {
+			r.types[t] = true
+		}
+	}
+	return r
+}
+
+func (wr *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodHead:
+		// Trello checks the callback URL answers before registering it.
+		return
+	case http.MethodPost:
+	default:
+		w.Header().Set("Allow", "HEAD, POST")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+	if !trello.VerifyWebhook(wr.secret, wr.callbackURL, body, r.Header.Get("X-Trello-Webhook")) {
+		slog.Warn(fmt.Sprintf("rejected a webhook request from %s with a bad signature", r.RemoteAddr), "remote", r.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var payload struct {
+		Action cli.NotifyAction `json:"action"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Action.ID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	a := payload.Action
+	if (wr.types == nil || wr.types[a.Type]) && wr.firstDelivery(a.ID) {
+		select {
+		case wr.actions <- a:
+		case <-r.Context().Done():
+		}
+	}
+}
+
+// firstDelivery reports whether the action was not seen before; Trello
+// redelivers actions whose delivery it thinks failed.
+func (wr *webhookReceiver) firstDelivery(id string) bool {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	if wr.seen[id] {
+		return false
+	}
+	wr.seen[id] = true
+	wr.ids = append(wr.ids, id)
+	if len(wr.ids) > webhookSeen {
+		delete(wr.seen, wr.ids[0])
+		wr.ids = wr.ids[1:]
+	}
+	return true
+}
+
+// runWatchWebhook receives the board's actions through a webhook pointing
+// at opts.publicURL, which must forward to the local listener, and hands
+// them to handle until ctx ends. The webhook is removed on the way out.
+func runWatchWebhook(ctx context.Context, client *cli.Client, boardID string, types []string, opts webhookOptions, handle func(context.Context, cli.NotifyAction) error) error {
+	// Webhooks need the board's id, not its shortLink.
+	board, err := client.Boards.Get(ctx, boardID, "id,name")
+	if err != nil {
+		return err
+	}
+	receiver := newWebhookReceiver(opts.secret, opts.publicURL, types)
+	ln, err := net.Listen("tcp", net.JoinHostPort(opts.addr, strconv.Itoa(opts.port)))
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           receiver,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
+	defer func() {
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}()
+
+	hook, err := client.Webhooks.Create(ctx, opts.publicURL, board.ID, "trelli watch")
+	if errors.Is(err, cli.ErrDryRun) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("registering the webhook (is %s forwarded to %s?): %w", opts.publicURL, ln.Addr(), err)
+	}
+	defer func() {
+		// ctx is done by now; removing the webhook gets a moment of its own.
+		cleanup, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := client.Webhooks.Delete(cleanup, hook.ID); err != nil {
+			slog.Warn(fmt.Sprintf("removing webhook %s: %v; delete it with DELETE /1/webhooks/%s", hook.ID, err, hook.ID), "webhook", hook.ID)
+		}
+	}()
+	slog.Info(fmt.Sprintf("Watching board %s through webhook %s on %s; Ctrl-C stops", board.Name, hook.ID, ln.Addr()), "board", board.ID, "webhook", hook.ID)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-served:
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+			return err
+		case a := <-receiver.actions:
+			if err := handle(ctx, a); err != nil && ctx.Err() == nil {
+				slog.Warn(fmt.Sprintf("%s %s: %v", a.Type, a.ID, err), "action", a.ID)
+			}
+		}
+	}
+}
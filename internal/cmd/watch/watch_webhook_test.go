@@ -0,0 +1,56 @@
+package watch
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookReceiver(t *testing.T) {
+	const callback = "https://example.ngrok.app/"
+	receiver := newWebhookReceiver("api-secret", callback, []string{"createCard"})
+	srv := httptest.NewServer(receiver)
+	t.Cleanup(srv.Close)
+
+	post := func(body, secret string) int {
+		t.Helper()
+		mac := hmac.New(sha1.New, []byte(secret))
+		mac.Write([]byte(body + callback))
+		req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(body))
+		req.Header.Set("X-Trello-Webhook", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	resp, err := http.Head(srv.URL)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("HEAD = %v, %v; want 200", resp, err)
+	}
+	created := `{"action": {"id": "a1", "type": "createCard", "date": "2026-02-10T09:30:00.000Z", "data": {"card": {"id": "c1", "name": "Fix login"}}}}`
+	if status := post(created, "wrong-secret"); status != http.StatusUnauthorized {
+		t.Errorf("forged request = %d, want 401", status)
+	}
+	for _, body := range []string{
+		created,
+		created, // a redelivery
+		`{"action": {"id": "a2", "type": "commentCard", "date": "2026-02-10T09:31:00.000Z"}}`,
+	} {
+		if status := post(body, "api-secret"); status != http.StatusOK {
+			t.Errorf("signed request = %d, want 200", status)
+		}
+	}
+	if got := len(receiver.actions); got != 1 {
+		t.Fatalf("queued %d actions, want 1", got)
+	}
+	if a := <-receiver.actions; a.ID != "a1" || a.Data.Card.Name != "Fix login" {
+		t.Errorf("queued %+v", a)
+	}
+}
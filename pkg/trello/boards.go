@@ -0,0 +1,105 @@
+package trello
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// BoardsService reads boards and their lists, labels, and members.
+type BoardsService struct {
+	d Doer
+}
+
+// ListBoardsOptions narrows BoardsService.List.
+type ListBoardsOptions struct {
+	// Filter is e.g. "open" or "closed"; empty means all boards.
+	Filter string
+	// Fields is a comma-separated field list; empty means id,name,url,closed.
+	Fields string
+}
+
+// List returns the boards visible to the authenticated member.
+func (s BoardsService) List(ctx context.Context, opts ListBoardsOptions) ([]Board, error) {
+	query := url.Values{}
+	query.Set("fields", firstNonEmpty(opts.Fields, "id,name,url,closed"))
+	if opts.Filter != "" {
+		query.Set("filter", opts.Filter)
+	}
+	var boards []Board
+	err := s.d.Do(ctx, http.MethodGet, "/1/members/me/boards", query, nil, &boards)
+	return boards, err
+}
+
+// Get returns a board by id or shortLink with fields, or all Board fields
+// when fields is empty.
+func (s BoardsService) Get(ctx context.Context, boardID, fields string) (Board, error) {
+	query := url.Values{}
+	query.Set("fields", firstNonEmpty(fields, "id,name,shortLink,url,closed"))
+	var board Board
+	err := s.d.Do(ctx, http.MethodGet, "/1/boards/"+url.PathEscape(boardID), query, nil, &board)
+	return board, err
+}
+
+// Lists returns the open lists of a board.
+func (s BoardsService) Lists(ctx context.Context, boardID string) ([]List, error) {
+	query := url.Values{}
+	query.Set("fields", "id,name,closed,pos")
+	var lists []List
+	err := s.d.Do(ctx, http.MethodGet, "/1/boards/"+url.PathEscape(boardID)+"/lists", query, nil, &lists)
+	return lists, err
+}
+
+// Labels returns the labels defined on a board.
+func (s BoardsService) Labels(ctx context.Context, boardID string) ([]Label, error) {
+	query := url.Values{}
+	query.Set("fields", "id,name,color")
+	var labels []Label
+	err := s.d.Do(ctx, http.MethodGet, "/1/boards/"+url.PathEscape(boardID)+"/labels", query, nil, &labels)
+	return labels, err
+}
+
+// CreateLabel adds a label to a board; an empty color makes a label
+// without color.
+func (s BoardsService) CreateLabel(ctx context.Context, boardID, name, color string) (Label, error) {
+	form := url.Values{}
+	form.Set("name", name)
+	form.Set("color", firstNonEmpty(color, "null"))
+	var label Label
+	err := s.d.Do(ctx, http.MethodPost, "/1/boards/"+url.PathEscape(boardID)+"/labels", nil, form, &label)
+	return label, err
+}
+
+// UpdateLabel sets the given label fields, e.g. name or color.
+func (s BoardsService) UpdateLabel(ctx context.Context, labelID string, fields url.Values) (Label, error) {
+	var label Label
+	err := s.d.Do(ctx, http.MethodPut, "/1/labels/"+url.PathEscape(labelID), nil, fields, &label)
+	return label, err
+}
+
+// DeleteLabel removes a label from its board and from every card that
+// has it.
+func (s BoardsService) DeleteLabel(ctx context.Context, labelID string) error {
+	return s.d.Do(ctx, http.MethodDelete, "/1/labels/"+url.PathEscape(labelID), nil, nil, nil)
+}
+
+// Create adds a board named name without Trello's default lists and
+// labels.
+func (s BoardsService) Create(ctx context.Context, name string) (Board, error) {
+	form := url.Values{}
+	form.Set("name", name)
+	form.Set("defaultLists", "false")
+	form.Set("defaultLabels", "false")
+	var board Board
+	err := s.d.Do(ctx, http.MethodPost, "/1/boards", nil, form, &board)
+	return board, err
+}
+
+// Members returns the members of a board.
+func (s BoardsService) Members(ctx context.Context, boardID string) ([]Member, error) {
+	query := url.Values{}
+	query.Set("fields", "id,username,fullName")
+	var members []Member
+	err := s.d.Do(ctx, http.MethodGet, "/1/boards/"+url.PathEscape(boardID)+"/members", query, nil, &members)
+	return members, err
+}
@@ -0,0 +1,187 @@
+package trello
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CardFields are the fields Card holds, requested by default.
+const CardFields = "id,name,desc,idList,shortUrl,url,due,closed,dateLastActivity"
+
+// CardsService reads and changes cards.
+type CardsService struct {
+	d Doer
+}
+
+// ListCardsOptions narrows CardsService.List.
+type ListCardsOptions struct {
+	// Fields is a comma-separated field list; empty means CardFields.
+	Fields string
+	// Limit caps the number of cards; zero means Trello's default.
+	Limit int
+}
+
+// List returns the open cards of a list.
+func (s CardsService) List(ctx context.Context, listID string, opts ListCardsOptions) ([]Card, error) {
+	query := url.Values{}
+	query.Set("fields", firstNonEmpty(opts.Fields, CardFields))
+	if opts.Limit > 0 {
+		query.Set("limit", fmt.Sprintf("%d", opts.Limit))
+	}
+	var cards []Card
+	err := s.d.Do(ctx, http.MethodGet, "/1/lists/"+url.PathEscape(listID)+"/cards", query, nil, &cards)
+	return cards, err
+}
+
+// Get returns a card by id with fields, or CardFields when fields is empty.
+func (s CardsService) Get(ctx context.Context, cardID, fields string) (Card, error) {
+	query := url.Values{}
+	query.Set("fields", firstNonEmpty(fields, CardFields))
+	var card Card
+	err := s.d.Do(ctx, http.MethodGet, "/1/cards/"+url.PathEscape(cardID), query, nil, &card)
+	return card, err
+}
+
+// SearchCardsOptions narrows CardsService.Search.
+type SearchCardsOptions struct {
+	// BoardIDs restricts the search to these boards; empty searches all
+	// boards of the member.
+	BoardIDs []string
+	// Limit caps the number of cards, up to 1000; zero means Trello's
+	// default of 10.
+	Limit int
+}
+
+// Search returns the cards matching query, which uses Trello's search
+// syntax (e.g. "login label:bug is:open"). The last word also matches as a
+// prefix, as it does in the Trello search box.
+func (s CardsService) Search(ctx context.Context, query string, opts SearchCardsOptions) ([]Card, error) {
+	q := url.Values{}
+	q.Set("query", query)
+	q.Set("modelTypes", "cards")
+	q.Set("card_fields", CardFields)
+	q.Set("partial", "true")
+	if len(opts.BoardIDs) > 0 {
+		q.Set("idBoards", strings.Join(opts.BoardIDs, ","))
+	}
+	if opts.Limit > 0 {
+		q.Set("cards_limit", fmt.Sprintf("%d", opts.Limit))
+	}
+	var res struct {
+		Cards []Card `json:"cards"`
+	}
+	err := s.d.Do(ctx, http.MethodGet, "/1/search", q, nil, &res)
+	return res.Cards, err
+}
+
+// CreateCardRequest describes a new card; ListID and Name are required.
+type CreateCardRequest struct {
+	ListID    string
+	Name      string
+	Desc      string
+	Due       string // ISO 8601
+	LabelIDs  []string
+	MemberIDs []string
+}
+
+// Create adds a card.
+func (s CardsService) Create(ctx context.Context, req CreateCardRequest) (Card, error) {
+	form := url.Values{}
+	form.Set("idList", req.ListID)
+	form.Set("name", req.Name)
+	if strings.TrimSpace(req.Desc) != "" {
+		form.Set("desc", req.Desc)
+	}
+	if strings.TrimSpace(req.Due) != "" {
+		form.Set("due", req.Due)
+	}
+	if len(req.LabelIDs) > 0 {
+		form.Set("idLabels", strings.Join(req.LabelIDs, ","))
+	}
+	if len(req.MemberIDs) > 0 {
+		form.Set("idMembers", strings.Join(req.MemberIDs, ","))
+	}
+	var card Card
+	err := s.d.Do(ctx, http.MethodPost, "/1/cards", nil, form, &card)
+	return card, err
+}
+
+// Update sets the given card fields, e.g. idList or closed.
+func (s CardsService) Update(ctx context.Context, cardID string, fields url.Values) (Card, error) {
+	var card Card
+	err := s.d.Do(ctx, http.MethodPut, "/1/cards/"+url.PathEscape(cardID), nil, fields, &card)
+	return card, err
+}
+
+// Move moves a card to another list.
+func (s CardsService) Move(ctx context.Context, cardID, listID string) (Card, error) {
+	return s.Update(ctx, cardID, url.Values{"idList": {listID}})
+}
+
+// Archive closes a card.
+func (s CardsService) Archive(ctx context.Context, cardID string) (Card, error) {
+	return s.Update(ctx, cardID, url.Values{"closed": {"true"}})
+}
+
+// Unarchive reopens a closed card.
+func (s CardsService) Unarchive(ctx context.Context, cardID string) (Card, error) {
+	return s.Update(ctx, cardID, url.Values{"closed": {"false"}})
+}
+
+// Delete removes a card permanently. Unlike Archive it cannot be undone.
+func (s CardsService) Delete(ctx context.Context, cardID string) error {
+	return s.d.Do(ctx, http.MethodDelete, "/1/cards/"+url.PathEscape(cardID), nil, nil, nil)
+}
+
+// AddLabel puts a board label on a card.
+func (s CardsService) AddLabel(ctx context.Context, cardID, labelID string) error {
+	return s.d.Do(ctx, http.MethodPost, "/1/cards/"+url.PathEscape(cardID)+"/idLabels", nil, url.Values{"value": {labelID}}, nil)
+}
+
+// RemoveLabel takes a label off a card.
+func (s CardsService) RemoveLabel(ctx context.Context, cardID, labelID string) error {
+	return s.d.Do(ctx, http.MethodDelete, "/1/cards/"+url.PathEscape(cardID)+"/idLabels/"+url.PathEscape(labelID), nil, nil, nil)
+}
+
+// AddMember assigns a board member to a card.
+func (s CardsService) AddMember(ctx context.Context, cardID, memberID string) error {
+	return s.d.Do(ctx, http.MethodPost, "/1/cards/"+url.PathEscape(cardID)+"/idMembers", nil, url.Values{"value": {memberID}}, nil)
+}
+
+// RemoveMember takes a member off a card.
+func (s CardsService) RemoveMember(ctx context.Context, cardID, memberID string) error {
+	return s.d.Do(ctx, http.MethodDelete, "/1/cards/"+url.PathEscape(cardID)+"/idMembers/"+url.PathEscape(memberID), nil, nil, nil)
+}
+
+// AttachURL attaches a link to a card; name defaults to the URL.
+func (s CardsService) AttachURL(ctx context.Context, cardID, link, name string) (Attachment, error) {
+	form := url.Values{}
+	form.Set("url", link)
+	if name != "" {
+		form.Set("name", name)
+	}
+	var a Attachment
+	err := s.d.Do(ctx, http.MethodPost, "/1/cards/"+url.PathEscape(cardID)+"/attachments", nil, form, &a)
+	return a, err
+}
+
+// AttachFile uploads file as an attachment of a card. The service's Doer
+// must implement Uploader.
+func (s CardsService) AttachFile(ctx context.Context, cardID string, file File) (Attachment, error) {
+	up, ok := s.d.(Uploader)
+	if !ok {
+		return Attachment{}, errors.New("trello: uploading files needs a Doer that implements Uploader")
+	}
+	form := url.Values{}
+	form.Set("name", file.Name)
+	if file.MimeType != "" {
+		form.Set("mimeType", file.MimeType)
+	}
+	var a Attachment
+	err := up.Upload(ctx, "/1/cards/"+url.PathEscape(cardID)+"/attachments", form, file, &a)
+	return a, err
+}
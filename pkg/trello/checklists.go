@@ -0,0 +1,58 @@
+package trello
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ChecklistsService reads and changes checklists and their items.
+type ChecklistsService struct {
+	d Doer
+}
+
+// List returns a card's checklists with all items.
+func (s ChecklistsService) List(ctx context.Context, cardID string) ([]Checklist, error) {
+	query := url.Values{}
+	query.Set("checkItems", "all")
+	query.Set("checkItem_fields", "name,state,pos")
+	var checklists []Checklist
+	err := s.d.Do(ctx, http.MethodGet, "/1/cards/"+url.PathEscape(cardID)+"/checklists", query, nil, &checklists)
+	return checklists, err
+}
+
+// Create adds an empty checklist to a card.
+func (s ChecklistsService) Create(ctx context.Context, cardID, name string) (Checklist, error) {
+	var checklist Checklist
+	err := s.d.Do(ctx, http.MethodPost, "/1/cards/"+url.PathEscape(cardID)+"/checklists", nil, url.Values{"name": {name}}, &checklist)
+	return checklist, err
+}
+
+// Delete removes a checklist.
+func (s ChecklistsService) Delete(ctx context.Context, checklistID string) error {
+	return s.d.Do(ctx, http.MethodDelete, "/1/checklists/"+url.PathEscape(checklistID), nil, nil, nil)
+}
+
+// AddItem appends an item to a checklist.
+func (s ChecklistsService) AddItem(ctx context.Context, checklistID, name string, checked bool) (ChecklistItem, error) {
+	form := url.Values{}
+	form.Set("name", name)
+	if checked {
+		form.Set("checked", "true")
+	}
+	var item ChecklistItem
+	err := s.d.Do(ctx, http.MethodPost, "/1/checklists/"+url.PathEscape(checklistID)+"/checkItems", nil, form, &item)
+	return item, err
+}
+
+// SetItemState marks an item on a card "complete" or "incomplete".
+func (s ChecklistsService) SetItemState(ctx context.Context, cardID, itemID, state string) (ChecklistItem, error) {
+	var item ChecklistItem
+	err := s.d.Do(ctx, http.MethodPut, "/1/cards/"+url.PathEscape(cardID)+"/checkItem/"+url.PathEscape(itemID), nil, url.Values{"state": {state}}, &item)
+	return item, err
+}
+
+// DeleteItem removes an item from a checklist.
+func (s ChecklistsService) DeleteItem(ctx context.Context, checklistID, itemID string) error {
+	return s.d.Do(ctx, http.MethodDelete, "/1/checklists/"+url.PathEscape(checklistID)+"/checkItems/"+url.PathEscape(itemID), nil, nil, nil)
+}
@@ -0,0 +1,274 @@
+package trello
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// DefaultBaseURL is the Trello REST API.
+const DefaultBaseURL = "https://api.trello.com"
+
+// Doer performs one API call: method on path (e.g. /1/cards) with query
+// parameters and, for writes, a form body, decoding the JSON response into
+// out when out is non-nil.
+type Doer interface {
+	Do(ctx context.Context, method, path string, query, form url.Values, out any) error
+}
+
+// Uploader is implemented by Doers that can also POST a file as
+// multipart/form-data, which CardsService.AttachFile needs.
+type Uploader interface {
+	Upload(ctx context.Context, path string, form url.Values, file File, out any) error
+}
+
+// Services groups the resource services bound to one Doer.
+type Services struct {
+	Boards     BoardsService
+	Lists      ListsService
+	Cards      CardsService
+	Comments   CommentsService
+	Checklists ChecklistsService
+	Members    MembersService
+	Webhooks   WebhooksService
+}
+
+// NewServices returns the resource services sending requests through d.
+func NewServices(d Doer) Services {
+	return Services{
+		Boards:     BoardsService{d},
+		Lists:      ListsService{d},
+		Cards:      CardsService{d},
+		Comments:   CommentsService{d},
+		Checklists: ChecklistsService{d},
+		Members:    MembersService{d},
+		Webhooks:   WebhooksService{d},
+	}
+}
+
+// Client sends authenticated requests to the Trello API.
+type Client struct {
+	BaseURL string
+	Key     string
+	Token   string
+	HTTP    *http.Client
+	// Layers wrap the transport of HTTP for every request, outermost
+	// first. New sets them to Retry, RateLimit, and Timeout as its options
+	// configure them; middleware added with Use sits inside them and so
+	// sees every attempt.
+	Layers []Middleware
+
+	Services
+}
+
+// NewClient returns a client for the public API with default retries and
+// pacing. It is New without options.
+func NewClient(key, token string) *Client {
+	return New(key, token)
+}
+
+// Do implements Doer.
+func (c *Client) Do(ctx context.Context, method, p string, query, form url.Values, out any) error {
+	u, err := c.Endpoint(p, query)
+	if err != nil {
+		return err
+	}
+	raw, _, err := c.Send(ctx, method, u, form, "")
+	if err != nil {
+		return err
+	}
+	if out == nil || len(bytes.TrimSpace(raw)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// Upload implements Uploader: it POSTs form and file as multipart/form-data.
+func (c *Client) Upload(ctx context.Context, p string, form url.Values, file File, out any) error {
+	u, err := c.Endpoint(p, nil)
+	if err != nil {
+		return err
+	}
+	body, contentType, err := multipartBody(form, file)
+	if err != nil {
+		return err
+	}
+	raw, _, err := readResponse(c.open(ctx, http.MethodPost, u, bytes.NewReader(body), contentType, ""))
+	if err != nil {
+		return err
+	}
+	if out == nil || len(bytes.TrimSpace(raw)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// multipartBody encodes form and file, as the "file" part, into a
+// multipart/form-data body and returns it with its content type.
+func multipartBody(form url.Values, file File) ([]byte, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for k, values := range form {
+		for _, v := range values {
+			if err := w.WriteField(k, v); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": "file", "filename": file.Name}))
+	h.Set("Content-Type", firstNonEmpty(file.MimeType, "application/octet-stream"))
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(file.Data); err != nil {
+		return nil, "", err
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+// Endpoint returns the URL for API path p with query and the credentials.
+func (c *Client) Endpoint(p string, query url.Values) (*url.URL, error) {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	q := make(url.Values, len(query)+2)
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set("key", c.Key)
+	q.Set("token", c.Token)
+	u.Path = path.Join(u.Path, p)
+	u.RawQuery = q.Encode()
+	return u, nil
+}
+
+// Send performs a request through Layers and returns the response body,
+// or an *APIError for non-2xx responses. The headers are returned for
+// Retry-After and ETag. A non-empty etag makes the request conditional;
+// ErrNotModified reports a 304.
+func (c *Client) Send(ctx context.Context, method string, u *url.URL, form url.Values, etag string) ([]byte, http.Header, error) {
+	return readResponse(c.Open(ctx, method, u, form, etag))
+}
+
+func readResponse(resp *http.Response, err error) ([]byte, http.Header, error) {
+	if resp == nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if err != nil {
+		return nil, resp.Header, err
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, resp.Header, ErrNotModified
+	}
+	raw, err := io.ReadAll(resp.Body)
+	return raw, resp.Header, err
+}
+
+// Open performs a request through Layers and returns the response with its
+// body unread for the caller to close. Non-2xx responses other than a 304
+// to a conditional request come back with an *APIError and a drained body.
+func (c *Client) Open(ctx context.Context, method string, u *url.URL, form url.Values, etag string) (*http.Response, error) {
+	var body io.Reader
+	var contentType string
+	if method != http.MethodGet && form != nil {
+		body = strings.NewReader(form.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	}
+	return c.open(ctx, method, u, body, contentType, etag)
+}
+
+func (c *Client) open(ctx context.Context, method string, u *url.URL, body io.Reader, contentType, etag string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = RedactURL(req.URL)
+		}
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && etag != "" {
+		return resp, nil
+	}
+	if resp.StatusCode >= 300 {
+		raw, _ := io.ReadAll(resp.Body)
+		message := strings.TrimSpace(string(raw))
+		var payload errorBody
+		if json.Unmarshal(raw, &payload) == nil {
+			message = firstNonEmpty(payload.Message, payload.Error, message)
+		}
+		return resp, newResponseError(resp.StatusCode, message, resp.Header)
+	}
+	return resp, nil
+}
+
+// httpClient returns HTTP, or the default client, with Layers around its
+// transport.
+func (c *Client) httpClient() *http.Client {
+	hc := c.HTTP
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	if len(c.Layers) == 0 {
+		return hc
+	}
+	layered := *hc
+	rt := layered.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(c.Layers) - 1; i >= 0; i-- {
+		rt = c.Layers[i](rt)
+	}
+	layered.Transport = rt
+	return &layered
+}
+
+// RedactURL returns u with credential query parameters masked.
+func RedactURL(u *url.URL) string {
+	redacted := *u
+	query := redacted.Query()
+	for _, k := range []string{"key", "token"} {
+		if query.Has(k) {
+			query.Set(k, "REDACTED")
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,204 @@
+package trello
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewOptions(t *testing.T) {
+	var userAgent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		if r.URL.Path != "/1/boards/b1" || r.URL.Query().Get("key") != "k" || r.URL.Query().Get("token") != "t" {
+			t.Errorf("request %s", r.URL)
+		}
+		io.WriteString(w, `{"id": "b1", "name": "Engineering"}`)
+	}))
+	defer srv.Close()
+
+	hc := &http.Client{}
+	client := New("k", "t",
+		WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				req = req.Clone(req.Context())
+				req.Header.Set("User-Agent", "trello-test")
+				return next.RoundTrip(req)
+			})
+		}),
+		WithBaseURL(srv.URL),
+		WithHTTPClient(hc),
+		WithTimeout(5*time.Second),
+		WithRetry(-1),
+		WithRateLimit(0),
+	)
+	if client.BaseURL != srv.URL || len(client.Layers) != 3 {
+		t.Errorf("New with options = %+v", client)
+	}
+	if client.HTTP == hc || hc.Transport != nil {
+		t.Error("middleware should wrap a copy of the given HTTP client")
+	}
+
+	board, err := client.Boards.Get(context.Background(), "b1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board.Name != "Engineering" || userAgent != "trello-test" {
+		t.Errorf("Boards.Get = %+v, User-Agent %q", board, userAgent)
+	}
+
+	defaults := New("k", "t")
+	if defaults.BaseURL != DefaultBaseURL || len(defaults.Layers) != 3 {
+		t.Errorf("New defaults = %+v", defaults)
+	}
+}
+
+func TestLayers(t *testing.T) {
+	var attempts atomic.Int32
+	var seen int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := attempts.Add(1)
+		switch {
+		case r.URL.Path == "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case n%3 != 0:
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, `{"id": "c1"}`)
+	}))
+	defer srv.Close()
+	count := WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			seen++
+			return next.RoundTrip(req)
+		})
+	})
+	ctx := context.Background()
+
+	var retries int
+	client := New("k", "t", WithBaseURL(srv.URL), WithRateLimit(0), count,
+		WithRetryPolicy(RetryPolicy{MaxRetries: 2, OnRetry: func(*http.Request) { retries++ }}))
+	var card Card
+	if err := client.Do(ctx, http.MethodGet, "/1/cards/c1", nil, nil, &card); err != nil || card.ID != "c1" {
+		t.Fatalf("Do = %+v, %v", card, err)
+	}
+	if attempts.Load() != 3 || seen != 3 || retries != 2 {
+		t.Errorf("GET: %d attempts, %d seen by middleware, %d retries; want 3 each but 2 retries", attempts.Load(), seen, retries)
+	}
+
+	// A POST may have taken effect, so a 503 is final.
+	attempts.Store(0)
+	err := client.Do(ctx, http.MethodPost, "/1/cards", nil, url.Values{"name": {"x"}}, nil)
+	if !errors.Is(err, ErrServer) || attempts.Load() != 1 {
+		t.Errorf("POST: %v after %d attempts", err, attempts.Load())
+	}
+
+	attempts.Store(0)
+	if err := New("k", "t", WithBaseURL(srv.URL), WithRateLimit(0), WithRetry(0)).Do(ctx, http.MethodGet, "/1/cards/c1", nil, nil, nil); !errors.Is(err, ErrServer) || attempts.Load() != 1 {
+		t.Errorf("WithRetry(0): %v after %d attempts", err, attempts.Load())
+	}
+
+	slow := New("k", "t", WithBaseURL(srv.URL), WithRateLimit(0), WithRetry(0), WithTimeout(50*time.Millisecond))
+	if err := slow.Do(ctx, http.MethodGet, "/slow", nil, nil, nil); !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "request timed out after 50ms") {
+		t.Errorf("slow request: %v", err)
+	}
+	slow = New("k", "t", WithBaseURL(srv.URL), WithRateLimit(0), WithTimeout(time.Second))
+	if err := slow.Do(ctx, http.MethodGet, "/slow", nil, nil, &card); err != nil {
+		t.Errorf("request within the timeout: %v", err)
+	}
+}
+
+func TestSend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/1/cards" || r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+			t.Errorf("request %s %s (%s)", r.Method, r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		if r.PostForm.Get("name") != "Fix login" || r.URL.Query().Get("token") != "t" {
+			t.Errorf("form %v, query %v", r.PostForm, r.URL.Query())
+		}
+		w.Header().Set("ETag", `"v1"`)
+		io.WriteString(w, `{"id": "c1", "name": "Fix login"}`)
+	}))
+	defer srv.Close()
+
+	client := New("k", "t", WithBaseURL(srv.URL), WithRateLimit(0))
+	u, err := client.Endpoint("/1/cards", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, header, err := client.Send(context.Background(), http.MethodPost, u, url.Values{"name": {"Fix login"}}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != `{"id": "c1", "name": "Fix login"}` || header.Get("ETag") != `"v1"` {
+		t.Errorf("Send = %s, ETag %q", raw, header.Get("ETag"))
+	}
+}
+
+func TestErrorDecoding(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"message": "invalid value for idList", "error": "ERROR"}`)
+		case "/text":
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "model not found\n")
+		case "/token":
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, "expired token")
+		case "/limited":
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			io.WriteString(w, `{"error": "API_TOKEN_LIMIT_EXCEEDED"}`)
+		case "/cached":
+			if r.Header.Get("If-None-Match") != `"v1"` {
+				t.Errorf("If-None-Match = %q", r.Header.Get("If-None-Match"))
+			}
+			w.WriteHeader(http.StatusNotModified)
+		}
+	}))
+	defer srv.Close()
+
+	client := New("k", "t", WithBaseURL(srv.URL), WithRetry(0), WithRateLimit(0))
+	send := func(p, etag string) error {
+		u, err := client.Endpoint(p, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = client.Send(context.Background(), http.MethodGet, u, nil, etag)
+		return err
+	}
+
+	err := send("/json", "")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusBadRequest || apiErr.Message != "invalid value for idList" {
+		t.Errorf("JSON error = %v", err)
+	}
+	if err := send("/text", ""); !errors.Is(err, ErrNotFound) || err.Error() != "trello API error (404): model not found" {
+		t.Errorf("text error = %v", err)
+	}
+	var tokenErr *TokenError
+	if err := send("/token", ""); !errors.As(err, &tokenErr) || !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("token error = %v", err)
+	}
+	var limitErr *RateLimitError
+	if err := send("/limited", ""); !errors.As(err, &limitErr) || limitErr.RetryAfter != 2*time.Second || limitErr.Message != "API_TOKEN_LIMIT_EXCEEDED" {
+		t.Errorf("rate limit error = %v", err)
+	}
+	if err := send("/cached", `"v1"`); !errors.Is(err, ErrNotModified) {
+		t.Errorf("conditional request = %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package trello
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// CommentsService reads and changes card comments.
+type CommentsService struct {
+	d Doer
+}
+
+// List returns up to limit of a card's comments, newest first.
+func (s CommentsService) List(ctx context.Context, cardID string, limit int) ([]Comment, error) {
+	query := url.Values{}
+	query.Set("filter", "commentCard")
+	query.Set("fields", "data,date,type")
+	query.Set("memberCreator_fields", "username,fullName")
+	query.Set("limit", fmt.Sprintf("%d", limit))
+	var comments []Comment
+	err := s.d.Do(ctx, http.MethodGet, "/1/cards/"+url.PathEscape(cardID)+"/actions", query, nil, &comments)
+	return comments, err
+}
+
+// Add comments on a card.
+func (s CommentsService) Add(ctx context.Context, cardID, text string) (Comment, error) {
+	var comment Comment
+	err := s.d.Do(ctx, http.MethodPost, "/1/cards/"+url.PathEscape(cardID)+"/actions/comments", nil, url.Values{"text": {text}}, &comment)
+	return comment, err
+}
+
+// Delete removes a comment by its action id.
+func (s CommentsService) Delete(ctx context.Context, commentID string) error {
+	return s.d.Do(ctx, http.MethodDelete, "/1/actions/"+url.PathEscape(commentID), nil, nil, nil)
+}
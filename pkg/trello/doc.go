@@ -0,0 +1,13 @@
+// Package trello is a small client for the Trello REST API, used by the
+// trelli CLI and usable on its own.
+//
+//	client := trello.New(os.Getenv("TRELLO_API_KEY"), os.Getenv("TRELLO_TOKEN"),
+//		trello.WithRetry(5), trello.WithRateLimit(5))
+//	boards, err := client.Boards.List(ctx, trello.ListBoardsOptions{Filter: "open"})
+//	card, err := client.Cards.Create(ctx, trello.CreateCardRequest{ListID: listID, Name: "Write tests"})
+//
+// Resource services (Boards, Lists, Cards, Comments, Checklists, Members,
+// Webhooks) build requests and send them through a Doer. Client is the
+// plain HTTP Doer, with retries and rate limiting; NewServices binds the
+// services to any other Doer, such as one adding caching.
+package trello
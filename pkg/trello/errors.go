@@ -0,0 +1,112 @@
+package trello
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrNotModified is returned by Client.Send for a 304 response to a
+// conditional request.
+var ErrNotModified = errors.New("not modified")
+
+// Sentinels matched by errors.Is against an *APIError of the corresponding
+// status, so callers need not inspect status codes:
+//
+//	if errors.Is(err, trello.ErrNotFound) { ... }
+var (
+	ErrUnauthorized = errors.New("trello: unauthorized")
+	ErrForbidden    = errors.New("trello: forbidden")
+	ErrNotFound     = errors.New("trello: not found")
+	ErrRateLimited  = errors.New("trello: rate limited")
+	ErrServer       = errors.New("trello: server error")
+)
+
+// APIError is returned for non-2xx Trello responses.
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("trello API error (%d)", e.Status)
+	}
+	return fmt.Sprintf("trello API error (%d): %s", e.Status, e.Message)
+}
+
+// Is reports whether target is the sentinel for e's status.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.Status == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.Status == http.StatusForbidden
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound
+	case ErrRateLimited:
+		return e.Status == http.StatusTooManyRequests
+	case ErrServer:
+		return e.Status >= 500
+	}
+	return false
+}
+
+// TokenError is an APIError for a 401 that blames the token itself, such
+// as "expired token" or "invalid token", rather than missing permissions.
+type TokenError struct {
+	*APIError
+}
+
+func (e *TokenError) Error() string {
+	return "trello: token expired or revoked: " + e.APIError.Error()
+}
+
+func (e *TokenError) Unwrap() error {
+	return e.APIError
+}
+
+// RateLimitError is an APIError for a 429. RetryAfter is the server's
+// requested delay, or zero when it sent none.
+type RateLimitError struct {
+	*APIError
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter <= 0 {
+		return e.APIError.Error()
+	}
+	return fmt.Sprintf("%s (retry after %s)", e.APIError.Error(), e.RetryAfter)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.APIError
+}
+
+// NewAPIError returns the error for a failed response: a *TokenError for
+// token-specific 401s, a *RateLimitError for 429s, an *APIError otherwise.
+func NewAPIError(status int, message string) error {
+	return newResponseError(status, message, nil)
+}
+
+// newResponseError is NewAPIError with the response headers, which carry
+// Retry-After.
+func newResponseError(status int, message string, header http.Header) error {
+	err := &APIError{Status: status, Message: message}
+	switch {
+	case status == http.StatusUnauthorized && strings.Contains(strings.ToLower(message), "token"):
+		return &TokenError{APIError: err}
+	case status == http.StatusTooManyRequests:
+		retryAfter, _ := parseRetryAfter(header.Get("Retry-After"), time.Now())
+		return &RateLimitError{APIError: err, RetryAfter: retryAfter}
+	}
+	return err
+}
+
+type errorBody struct {
+	Message string `json:"message"`
+	Error   string `json:"error"`
+}
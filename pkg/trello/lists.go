@@ -0,0 +1,40 @@
+package trello
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ListsService reads and creates lists.
+type ListsService struct {
+	d Doer
+}
+
+// Get returns a list by id with fields, or id,name,closed,pos when fields
+// is empty.
+func (s ListsService) Get(ctx context.Context, listID, fields string) (List, error) {
+	query := url.Values{}
+	query.Set("fields", firstNonEmpty(fields, "id,name,closed,pos"))
+	var list List
+	err := s.d.Do(ctx, http.MethodGet, "/1/lists/"+url.PathEscape(listID), query, nil, &list)
+	return list, err
+}
+
+// Create adds an open list named name at the end of a board.
+func (s ListsService) Create(ctx context.Context, boardID, name string) (List, error) {
+	form := url.Values{}
+	form.Set("idBoard", boardID)
+	form.Set("name", name)
+	form.Set("pos", "bottom")
+	var list List
+	err := s.d.Do(ctx, http.MethodPost, "/1/lists", nil, form, &list)
+	return list, err
+}
+
+// Update sets the given list fields, e.g. name, pos, or closed.
+func (s ListsService) Update(ctx context.Context, listID string, fields url.Values) (List, error) {
+	var list List
+	err := s.d.Do(ctx, http.MethodPut, "/1/lists/"+url.PathEscape(listID), nil, fields, &list)
+	return list, err
+}
@@ -0,0 +1,21 @@
+package trello
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// MembersService reads members.
+type MembersService struct {
+	d Doer
+}
+
+// Me returns the member the token belongs to.
+func (s MembersService) Me(ctx context.Context) (Member, error) {
+	query := url.Values{}
+	query.Set("fields", "id,username,fullName")
+	var me Member
+	err := s.d.Do(ctx, http.MethodGet, "/1/members/me", query, nil, &me)
+	return me, err
+}
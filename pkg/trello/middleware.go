@@ -0,0 +1,87 @@
+package trello
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Middleware wraps the transport of a Client to observe or alter each
+// request attempt, for logging, metrics, recording, or custom headers.
+// Retries, pacing, and timeouts are middleware too (Retry, RateLimit, and
+// Timeout), kept in Client.Layers outside the chain that Use builds, so
+// middleware added there sees every retried attempt individually.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Use wraps the transport of c.HTTP in mw, in order: each middleware wraps
+// everything added before it, so the last one added sees a request first
+// and its response last. Set HTTP before calling Use; replacing it
+// afterwards drops the middleware.
+//
+//	client.Use(func(next http.RoundTripper) http.RoundTripper {
+//		return trello.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+//			req = req.Clone(req.Context())
+//			req.Header.Set("User-Agent", "my-tool/1.0")
+//			return next.RoundTrip(req)
+//		})
+//	})
+func (c *Client) Use(mw ...Middleware) {
+	var hc http.Client
+	if c.HTTP != nil {
+		hc = *c.HTTP
+	}
+	rt := hc.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for _, m := range mw {
+		rt = m(rt)
+	}
+	hc.Transport = rt
+	c.HTTP = &hc
+}
+
+// Timeout returns middleware that bounds each attempt, until its response
+// body is closed, to d; zero means no limit beyond the caller's context.
+func Timeout(d time.Duration) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		if d <= 0 {
+			return next
+		}
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, cancel := context.WithTimeout(req.Context(), d)
+			resp, err := next.RoundTrip(req.WithContext(ctx))
+			if err != nil {
+				cancel()
+				if errors.Is(err, context.DeadlineExceeded) && req.Context().Err() == nil {
+					return nil, fmt.Errorf("request timed out after %s: %w", d, err)
+				}
+				return nil, err
+			}
+			resp.Body = cancelOnClose{resp.Body, cancel}
+			return resp, nil
+		})
+	}
+}
+
+// cancelOnClose ends the context of an attempt when its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,92 @@
+package trello
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option configures a Client built by New.
+type Option func(*options)
+
+type options struct {
+	c          *Client
+	retry      RetryPolicy
+	limiter    *RateLimiter
+	timeout    time.Duration
+	middleware []Middleware
+}
+
+// New returns a client for key and token with default retries and pacing,
+// adjusted by opts:
+//
+//	client := trello.New(key, token,
+//		trello.WithBaseURL("http://localhost:8080"),
+//		trello.WithRetry(5),
+//		trello.WithRateLimit(5),
+//	)
+//
+// Retries, pacing, and the timeout become the client's Layers, in that
+// order from the outside; middleware wraps the transport of the final HTTP
+// client inside them, whatever the order of the options.
+func New(key, token string, opts ...Option) *Client {
+	c := &Client{
+		BaseURL: DefaultBaseURL,
+		Key:     key,
+		Token:   token,
+		HTTP:    &http.Client{},
+	}
+	o := options{c: c, retry: RetryPolicy{MaxRetries: DefaultMaxRetries}, limiter: NewRateLimiter(DefaultRate)}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(o.middleware) > 0 {
+		c.Use(o.middleware...)
+	}
+	// Pacing comes before the timeout so that queueing does not count
+	// against it.
+	c.Layers = []Middleware{Retry(o.retry), RateLimit(o.limiter), Timeout(o.timeout)}
+	c.Services = NewServices(c)
+	return c
+}
+
+// WithBaseURL sends requests to u instead of DefaultBaseURL, e.g. a mock
+// server or gateway.
+func WithBaseURL(u string) Option {
+	return func(o *options) { o.c.BaseURL = u }
+}
+
+// WithHTTPClient sends requests with hc, for custom transports, proxies, or
+// TLS settings.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *options) { o.c.HTTP = hc }
+}
+
+// WithTimeout bounds each request attempt; zero means no limit beyond the
+// caller's context.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) { o.timeout = d }
+}
+
+// WithRetry retries 429s, transient 5xx responses, and network errors up to
+// maxRetries times; zero disables retries.
+func WithRetry(maxRetries int) Option {
+	return func(o *options) { o.retry.MaxRetries = max(maxRetries, 0) }
+}
+
+// WithRetryPolicy retries as p says, replacing WithRetry.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *options) {
+		p.MaxRetries = max(p.MaxRetries, 0)
+		o.retry = p
+	}
+}
+
+// WithRateLimit paces requests to perSecond; zero or less disables pacing.
+func WithRateLimit(perSecond float64) Option {
+	return func(o *options) { o.limiter = NewRateLimiter(perSecond) }
+}
+
+// WithMiddleware wraps the transport in mw, as Client.Use does.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(o *options) { o.middleware = append(o.middleware, mw...) }
+}
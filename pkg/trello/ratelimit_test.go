@@ -0,0 +1,67 @@
+package trello
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSpacing(t *testing.T) {
+	const rate = 20.0 // one request per 50ms after a burst of 20
+	l := NewRateLimiter(rate)
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if burst := time.Since(start); burst > 25*time.Millisecond {
+		t.Errorf("burst of 20 took %v, want no waiting", burst)
+	}
+
+	start = time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := time.Since(start); got < 150*time.Millisecond {
+		t.Errorf("4 requests after the burst took %v, want at least 150ms at %v/s", got, rate)
+	}
+
+	if err := (*RateLimiter)(nil).Wait(ctx); err != nil {
+		t.Errorf("nil limiter: %v", err)
+	}
+	if NewRateLimiter(0) != nil {
+		t.Error("NewRateLimiter(0) should disable limiting")
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	l := NewRateLimiter(1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next token is a second away; the wait must end with ctx.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait = %v, want the context's deadline", err)
+	}
+	if got := time.Since(start); got > 500*time.Millisecond {
+		t.Errorf("canceled Wait took %v", got)
+	}
+
+	// The abandoned reservation is returned, so the next caller waits
+	// for one token, not two.
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < -0.5 {
+		t.Errorf("tokens after a canceled Wait = %.2f, want the reservation returned", tokens)
+	}
+}
@@ -0,0 +1,143 @@
+package trello
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultMaxRetries = 3
+	retryBaseDelay    = 500 * time.Millisecond
+	retryMaxDelay     = 30 * time.Second
+)
+
+// Retryable reports whether a failed attempt may be repeated. Trello rejects
+// rate-limited requests before acting on them, so 429 is retried for every
+// method; transient 5xx responses and network errors only for idempotent
+// methods, since a POST may already have taken effect.
+func Retryable(method string, err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Status {
+		case http.StatusTooManyRequests:
+			return true
+		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return method != http.MethodPost
+		}
+		return false
+	}
+	// Misconfiguration does not heal on retry.
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	if (errors.As(err, &dnsErr) && dnsErr.IsNotFound) || errors.As(err, &certErr) {
+		return false
+	}
+	return method != http.MethodPost
+}
+
+// RetryDelay honors Retry-After when present and otherwise backs off
+// exponentially, randomizing the upper half of each step.
+func RetryDelay(attempt int, header http.Header) time.Duration {
+	if d, ok := parseRetryAfter(header.Get("Retry-After"), time.Now()); ok {
+		return min(d, retryMaxDelay)
+	}
+	ceiling := retryBaseDelay << attempt
+	if ceiling <= 0 || ceiling > retryMaxDelay {
+		ceiling = retryMaxDelay
+	}
+	return ceiling/2 + time.Duration(rand.Int63n(int64(ceiling/2)))
+}
+
+// parseRetryAfter accepts delay-seconds or an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// RetryPolicy configures Retry.
+type RetryPolicy struct {
+	// MaxRetries bounds the attempts repeated after the first.
+	MaxRetries int
+	// Retryable reports whether a failed attempt may be repeated; nil
+	// means the package's Retryable.
+	Retryable func(method string, err error) bool
+	// OnRetry, when set, is called before each repeated attempt, e.g. to
+	// count retries.
+	OnRetry func(req *http.Request)
+}
+
+// Retry returns middleware that repeats attempts failing with a 429, a
+// transient 5xx response, or a network error as p allows, waiting
+// RetryDelay in between. A request whose body cannot be replayed is sent
+// once.
+func Retry(p RetryPolicy) Middleware {
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = Retryable
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+			for attempt := 0; ; attempt++ {
+				resp, err := next.RoundTrip(req)
+				failure := err
+				if err == nil && resp.StatusCode >= 400 {
+					failure = &APIError{Status: resp.StatusCode}
+				}
+				if failure == nil || attempt >= p.MaxRetries || !replayable || !retryable(req.Method, failure) || req.Context().Err() != nil {
+					return resp, err
+				}
+				var header http.Header
+				if resp != nil {
+					header = resp.Header
+					io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+				}
+				if err := sleep(req.Context(), RetryDelay(attempt, header)); err != nil {
+					return nil, err
+				}
+				if p.OnRetry != nil {
+					p.OnRetry(req)
+				}
+				req = req.Clone(req.Context())
+				if req.GetBody != nil {
+					if req.Body, err = req.GetBody(); err != nil {
+						return nil, err
+					}
+				}
+			}
+		})
+	}
+}
+
+// sleep waits d unless ctx ends first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
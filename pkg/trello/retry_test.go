@@ -0,0 +1,94 @@
+package trello
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"7", 7 * time.Second, true},
+		{" 0 ", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+	} {
+		got, ok := parseRetryAfter(tc.value, now)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tc.value, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	for _, tc := range []struct {
+		status int
+		get    bool
+		post   bool
+	}{
+		{http.StatusTooManyRequests, true, true},
+		{http.StatusInternalServerError, true, false},
+		{http.StatusBadGateway, true, false},
+		{http.StatusServiceUnavailable, true, false},
+		{http.StatusGatewayTimeout, true, false},
+		{http.StatusNotImplemented, false, false},
+		{http.StatusBadRequest, false, false},
+		{http.StatusUnauthorized, false, false},
+		{http.StatusNotFound, false, false},
+	} {
+		err := fmt.Errorf("request: %w", &APIError{Status: tc.status})
+		if got := Retryable(http.MethodGet, err); got != tc.get {
+			t.Errorf("Retryable(GET, %d) = %v", tc.status, got)
+		}
+		if got := Retryable(http.MethodPost, err); got != tc.post {
+			t.Errorf("Retryable(POST, %d) = %v", tc.status, got)
+		}
+	}
+
+	network := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	if !Retryable(http.MethodGet, network) || Retryable(http.MethodPost, network) {
+		t.Error("network errors should be retried for GET only")
+	}
+	if Retryable(http.MethodGet, &net.DNSError{Err: "no such host", IsNotFound: true}) {
+		t.Error("unknown hosts should not be retried")
+	}
+	if Retryable(http.MethodGet, context.Canceled) {
+		t.Error("canceled requests should not be retried")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	header := http.Header{}
+	header.Set("Retry-After", "4")
+	if got := RetryDelay(0, header); got != 4*time.Second {
+		t.Errorf("RetryDelay with Retry-After: 4 = %v", got)
+	}
+	header.Set("Retry-After", "3600")
+	if got := RetryDelay(0, header); got != retryMaxDelay {
+		t.Errorf("RetryDelay with Retry-After: 3600 = %v, want the %v cap", got, retryMaxDelay)
+	}
+
+	for attempt := 0; attempt < 70; attempt++ {
+		ceiling := min(retryBaseDelay<<attempt, retryMaxDelay)
+		if ceiling <= 0 {
+			ceiling = retryMaxDelay
+		}
+		for i := 0; i < 20; i++ {
+			if got := RetryDelay(attempt, http.Header{}); got < ceiling/2 || got >= ceiling {
+				t.Fatalf("RetryDelay(%d) = %v, want in [%v, %v)", attempt, got, ceiling/2, ceiling)
+			}
+		}
+	}
+}
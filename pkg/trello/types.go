@@ -0,0 +1,103 @@
+package trello
+
+// Board is a Trello board.
+type Board struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ShortLink string `json:"shortLink,omitempty"`
+	URL       string `json:"url"`
+	Closed    bool   `json:"closed"`
+}
+
+// List is a list on a board.
+type List struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Closed  bool    `json:"closed"`
+	Pos     float64 `json:"pos"`
+	IDBoard string  `json:"idBoard,omitempty"`
+}
+
+// Card is a card on a list.
+type Card struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Desc     string `json:"desc"`
+	IDList   string `json:"idList"`
+	IDBoard  string `json:"idBoard,omitempty"`
+	ShortURL string `json:"shortUrl"`
+	URL      string `json:"url"`
+	Due      string `json:"due"`
+	Closed   bool   `json:"closed"`
+	// DateLastActivity is when the card or anything on it last changed.
+	DateLastActivity string `json:"dateLastActivity,omitempty"`
+	// DueReminder is how many minutes before Due Trello reminds the
+	// card's members; -1 means no reminder. Only set when requested.
+	DueReminder *int `json:"dueReminder,omitempty"`
+}
+
+// Label is a board label that can be put on cards.
+type Label struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// Member is a Trello user.
+type Member struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	FullName string `json:"fullName"`
+}
+
+// Comment is a commentCard action.
+type Comment struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Date string `json:"date"`
+	Data struct {
+		Text string `json:"text"`
+	} `json:"data"`
+	MemberCreator struct {
+		Username string `json:"username"`
+		FullName string `json:"fullName"`
+	} `json:"memberCreator"`
+}
+
+// Checklist is a named list of check items on a card.
+type Checklist struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	CheckItems []ChecklistItem `json:"checkItems"`
+}
+
+// ChecklistItem is one item of a checklist; State is "complete" or "incomplete".
+type ChecklistItem struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	State string  `json:"state"`
+	Pos   float64 `json:"pos"`
+}
+
+// Attachment is a file or link attached to a card.
+type Attachment struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// File is a file to upload, such as a card attachment.
+type File struct {
+	Name     string
+	MimeType string
+	Data     []byte
+}
+
+// Webhook posts changes to a model, such as a board, to CallbackURL.
+type Webhook struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	IDModel     string `json:"idModel"`
+	CallbackURL string `json:"callbackURL"`
+	Active      bool   `json:"active"`
+}
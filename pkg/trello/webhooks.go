@@ -0,0 +1,45 @@
+package trello
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+)
+
+// WebhooksService registers and removes webhooks.
+type WebhooksService struct {
+	d Doer
+}
+
+// Create registers a webhook that POSTs the actions on model (a board,
+// list, card, or member id; not a shortLink) to callbackURL. Trello first
+// sends a HEAD request to callbackURL and fails the call unless it gets a
+// 200.
+func (s WebhooksService) Create(ctx context.Context, callbackURL, modelID, description string) (Webhook, error) {
+	form := url.Values{}
+	form.Set("callbackURL", callbackURL)
+	form.Set("idModel", modelID)
+	form.Set("description", description)
+	var hook Webhook
+	err := s.d.Do(ctx, http.MethodPost, "/1/webhooks", nil, form, &hook)
+	return hook, err
+}
+
+// Delete removes a webhook.
+func (s WebhooksService) Delete(ctx context.Context, webhookID string) error {
+	return s.d.Do(ctx, http.MethodDelete, "/1/webhooks/"+url.PathEscape(webhookID), nil, nil, nil)
+}
+
+// VerifyWebhook reports whether signature, the X-Trello-Webhook header of
+// a webhook request, signs body for callbackURL with the API secret of the
+// key that registered the webhook.
+func VerifyWebhook(secret, callbackURL string, body []byte, signature string) bool {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(body)
+	mac.Write([]byte(callbackURL))
+	want := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(want), []byte(signature))
+}